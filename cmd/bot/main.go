@@ -14,10 +14,10 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/getsentry/sentry-go"
-	"github.com/robfig/cron"
 )
 
 type Config struct {
@@ -27,21 +27,18 @@ type Config struct {
 		Release string `json:"release"`
 		Debug   bool   `json:"debug"`
 	} `json:"sentry_config"`
-	CraftserveUrl             string `json:"craftserve_url"`
-	ThxGiveawayCron           string `json:"thx_giveaway_cron_line"`
-	ThxGiveawayTimeString     string `json:"thx_giveaway_time_string"`
-	MessageGiveawayCron       string `json:"message_giveaway_cron_line"`
-	UnconditionalGiveawayCron string `json:"unconditional_giveaway_cron_line"`
-	ConditionalGiveawayCron   string `json:"conditional_giveaway_cron_line"`
-	SystemToken               string `json:"system_token"`
-	CsrvSecret                string `json:"csrv_secret"`
-	RegisterCommands          bool   `json:"register_commands"`
-	Environment               string `json:"environment"` // development or production
-	RoleLevelPrefix           string `json:"role_level_prefix"`
-	VoucherConfig             struct {
+	CraftserveUrl    string `json:"craftserve_url"`
+	SystemToken      string `json:"system_token"`
+	CsrvSecret       string `json:"csrv_secret"`
+	CsrvApiUrl       string `json:"csrv_api_url"` // defaults to craftserve_url, e.g. http://127.0.0.1:8081 for cmd/csrvmock
+	RegisterCommands bool   `json:"register_commands"`
+	Environment      string `json:"environment"` // development or production
+	RoleLevelPrefix  string `json:"role_level_prefix"`
+	VoucherConfig    struct {
 		ValuePLN         int `json:"value_pln"`
 		ExpirationInDays int `json:"expiration_in_days"`
 	} `json:"voucher"`
+	VoucherPoolConfig services.VoucherPoolConfig `json:"voucher_pool"`
 }
 
 var BotConfig Config
@@ -105,18 +102,31 @@ func main() {
 	var userRepo = repos.NewUserRepo(dbMap)
 	var giveawaysRepo = repos.NewGiveawaysRepo(dbMap)
 	var statusRepo = repos.NewStatusRepo(dbMap)
+	var voucherRepo = repos.NewVoucherRepo(dbMap)
+	var auditLogRepo = repos.NewAuditLogRepo(dbMap)
 
-	log.Debug("Creating tables...")
-	err = db.CreateTablesIfNotExists()
+	log.Debug("Running migrations...")
+	err = db.MigrateMySQLDatabases(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	var csrvClient = services.NewCsrvClient(BotConfig.CsrvSecret, BotConfig.Environment, BotConfig.CraftserveUrl, BotConfig.VoucherConfig.ValuePLN, BotConfig.VoucherConfig.ExpirationInDays)
+	csrvApiUrl := BotConfig.CsrvApiUrl
+	if csrvApiUrl == "" {
+		csrvApiUrl = BotConfig.CraftserveUrl
+	}
+	var csrvClient = services.NewCsrvClient(BotConfig.CsrvSecret, BotConfig.Environment, csrvApiUrl)
+	var voucherPool = services.NewVoucherPool(csrvClient, voucherRepo, BotConfig.VoucherPoolConfig)
+	var voucherService = services.NewVoucherService(csrvClient, voucherPool, voucherRepo, serverRepo, BotConfig.VoucherConfig.ValuePLN, BotConfig.VoucherConfig.ExpirationInDays)
 	var githubClient = services.NewGithubClient()
-	var giveawayService = services.NewGiveawayService(csrvClient, BotConfig.CraftserveUrl, serverRepo, giveawaysRepo)
-	var helperService = services.NewHelperService(serverRepo, userRepo, giveawaysRepo)
+	var thxAbuseService = services.NewThxAbuseService(giveawaysRepo)
+	var auditService = services.NewAuditService(BotConfig.CraftserveUrl, auditLogRepo, serverRepo)
+	var eligibilityService = services.NewEligibilityService(userRepo)
+	var giveawayService = services.NewGiveawayService(voucherService, thxAbuseService, auditService, eligibilityService, BotConfig.CraftserveUrl, serverRepo, giveawaysRepo)
+	var helperService = services.NewHelperService(BotConfig.CraftserveUrl, serverRepo, userRepo, giveawaysRepo)
+	var blacklistService = services.NewBlacklistService(userRepo, helperService, auditService)
 	var savedRoleService = services.NewSavedRoleService(userRepo)
+	var giveawayScheduler = services.NewGiveawayScheduler(giveawayService, serverRepo)
 
 	log.Debug("Initializing discordgo session")
 	session, err := discordgo.New("Bot " + BotConfig.SystemToken)
@@ -127,20 +137,24 @@ func main() {
 	session.Identify.Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsGuildMembers
 	log.Debugf("Running with intents: Guilds, GuildMessages, GuildMembers (%v)", session.Identify.Intents)
 
-	var giveawayCommand = commands.NewGiveawayCommand(giveawaysRepo, BotConfig.ThxGiveawayTimeString, BotConfig.CraftserveUrl, BotConfig.VoucherConfig.ValuePLN)
-	var thxCommand = commands.NewThxCommand(giveawaysRepo, userRepo, serverRepo, BotConfig.ThxGiveawayTimeString, BotConfig.CraftserveUrl, BotConfig.VoucherConfig.ValuePLN)
-	var thxmeCommand = commands.NewThxmeCommand(giveawaysRepo, userRepo, serverRepo, BotConfig.ThxGiveawayTimeString)
-	var csrvbotCommand = commands.NewCsrvbotCommand(BotConfig.CraftserveUrl, BotConfig.ThxGiveawayTimeString, BotConfig.VoucherConfig.ValuePLN, serverRepo, giveawaysRepo, userRepo, csrvClient, giveawayService, helperService)
+	var giveawayCommand = commands.NewGiveawayCommand(giveawaysRepo, serverRepo, BotConfig.CraftserveUrl, voucherService)
+	var thxCommand = commands.NewThxCommand(giveawaysRepo, userRepo, serverRepo, BotConfig.CraftserveUrl, voucherService, thxAbuseService, eligibilityService)
+	var thxmeCommand = commands.NewThxmeCommand(giveawaysRepo, userRepo, serverRepo, thxAbuseService, eligibilityService)
+	var csrvbotCommand = commands.NewCsrvbotCommand(BotConfig.CraftserveUrl, serverRepo, giveawaysRepo, userRepo, voucherService, giveawayService, helperService, giveawayScheduler, thxAbuseService, auditService)
 	var docCommand = commands.NewDocCommand(githubClient)
-	var resendCommand = commands.NewResendCommand(giveawaysRepo, BotConfig.CraftserveUrl)
-	var statusCommand = commands.NewStatusCommand(serverRepo, statusRepo)
-	var interactionCreateListener = listeners.NewInteractionCreateListener(giveawayCommand, thxCommand, thxmeCommand, csrvbotCommand, docCommand, resendCommand, statusCommand, BotConfig.ThxGiveawayTimeString, BotConfig.CraftserveUrl, giveawaysRepo, serverRepo, helperService, BotConfig.VoucherConfig.ValuePLN)
-	var guildCreateListener = listeners.NewGuildCreateListener(serverRepo, giveawayService, helperService, savedRoleService)
+	var winsCommand = commands.NewWinsCommand(giveawaysRepo, BotConfig.CraftserveUrl)
+	var rankingCommand = commands.NewRankingCommand(giveawaysRepo, BotConfig.CraftserveUrl)
+	var profileCommand = commands.NewProfileCommand(giveawaysRepo, serverRepo, BotConfig.CraftserveUrl)
+	var statusCommand = commands.NewStatusCommand(serverRepo, statusRepo, auditService)
+	var interactionCreateListener = listeners.NewInteractionCreateListener(giveawayCommand, thxCommand, thxmeCommand, csrvbotCommand, docCommand, winsCommand, rankingCommand, profileCommand, statusCommand, BotConfig.CraftserveUrl, giveawaysRepo, serverRepo, helperService, voucherService, giveawayService, thxAbuseService, eligibilityService)
+	var guildCreateListener = listeners.NewGuildCreateListener(serverRepo, giveawayService, helperService, savedRoleService, giveawayScheduler)
+	var guildDeleteListener = listeners.NewGuildDeleteListener(giveawayScheduler)
 	var guildMemberAddListener = listeners.NewGuildMemberAddListener(userRepo)
 	var guildMemberUpdateListener = listeners.NewGuildMemberUpdateListener(userRepo, savedRoleService)
 	var messageCreateListener = listeners.NewMessageCreateListener(giveawaysRepo)
 	session.AddHandler(interactionCreateListener.Handle)
 	session.AddHandler(guildCreateListener.Handle)
+	session.AddHandler(guildDeleteListener.Handle)
 	session.AddHandler(guildMemberAddListener.Handle)
 	session.AddHandler(guildMemberUpdateListener.Handle)
 	session.AddHandler(messageCreateListener.Handle)
@@ -153,6 +167,30 @@ func main() {
 
 	log.WithField("username", session.State.User).Info("Bot logged in")
 
+	log.Debug("Recovering interrupted giveaway draws")
+	giveawayService.RecoverDraws(ctx, session)
+
+	log.Debug("Starting pending voucher retries")
+	go giveawayService.RunPendingVouchers(ctx, session, time.Minute)
+
+	log.Debug("Starting voucher pool refills")
+	go voucherService.RunVoucherPool(ctx, session, 5*time.Minute)
+
+	log.Debug("Starting voucher redemption checks")
+	go giveawayService.RunRedemptionChecks(ctx, session, time.Hour)
+
+	log.Debug("Starting thx expiry")
+	go giveawayService.RunThxExpiry(ctx, session, 10*time.Minute)
+
+	log.Debug("Starting helper reconciliation")
+	go helperService.RunHelperReconciliation(ctx, session, 6*time.Hour)
+
+	log.Debug("Starting blacklist expiry")
+	go blacklistService.RunBlacklistExpiry(ctx, session, 10*time.Minute)
+
+	log.Debug("Scheduling custom giveaways")
+	giveawayService.ScheduleCustomGiveaways(ctx, session)
+
 	if BotConfig.RegisterCommands {
 		log.Debug("Registering commands")
 		giveawayCommand.Register(ctx, session)
@@ -160,43 +198,14 @@ func main() {
 		thxmeCommand.Register(ctx, session)
 		csrvbotCommand.Register(ctx, session)
 		docCommand.Register(ctx, session)
-		resendCommand.Register(ctx, session)
+		winsCommand.Register(ctx, session)
+		rankingCommand.Register(ctx, session)
+		profileCommand.Register(ctx, session)
 		statusCommand.Register(ctx, session)
 	} else {
 		log.Debug("Skipping command registration")
 	}
 
-	log.Debugf("Creating cron jobs: %s | %s | %s | %s", BotConfig.ThxGiveawayCron, BotConfig.MessageGiveawayCron, BotConfig.UnconditionalGiveawayCron, BotConfig.ConditionalGiveawayCron)
-	c := cron.New()
-	err = c.AddFunc(BotConfig.ThxGiveawayCron, func() {
-		giveawayService.FinishGiveaways(ctx, session)
-	})
-	if err != nil {
-		log.Fatalf("Could not set thx giveaway cron job: %v", err)
-	}
-
-	err = c.AddFunc(BotConfig.MessageGiveawayCron, func() {
-		giveawayService.FinishMessageGiveaways(ctx, session)
-	})
-	if err != nil {
-		log.Fatalf("Could not set message giveaway cron job: %v", err)
-	}
-
-	err = c.AddFunc(BotConfig.UnconditionalGiveawayCron, func() {
-		giveawayService.FinishJoinableGiveaways(ctx, session, false)
-	})
-	if err != nil {
-		log.Fatalf("Could not set unconditional giveaway cron job: %v", err)
-	}
-
-	err = c.AddFunc(BotConfig.ConditionalGiveawayCron, func() {
-		giveawayService.FinishJoinableGiveaways(ctx, session, true)
-	})
-	if err != nil {
-		log.Fatalf("Could not set conditional giveaway cron job: %v", err)
-	}
-	c.Start()
-
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 	<-stop
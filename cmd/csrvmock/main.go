@@ -0,0 +1,43 @@
+package main
+
+import (
+	"csrvbot/pkg/logger"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// csrvmock is a local stand-in for the Craftserve voucher API. Point csrv_api_url in config.json at it
+// and set csrv_secret to the -secret flag to exercise the real voucher client without touching production.
+func main() {
+	log := logger.Logger
+
+	addr := flag.String("addr", "127.0.0.1:8081", "address to listen on")
+	secret := flag.String("secret", "csrvmock", "expected user_access_token cookie")
+	dataPath := flag.String("data", "csrvmock.json", "file the issued vouchers are persisted to, empty to keep them in memory")
+	failures := flag.String("failures", "", fmt.Sprintf("comma separated failure modes for the first requests: %s, %s, %s, %s, %s", FailureNone, FailureStatus500, FailureTimeout, FailureMalformed, FailureEmpty))
+	timeoutDelay := flag.Duration("timeout-delay", time.Minute, "how long a timeout failure holds the request")
+	flag.Parse()
+
+	server, err := NewServer(*secret, *dataPath, *timeoutDelay)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *failures != "" {
+		err = server.QueueFailures(strings.Split(*failures, ","))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	log.Infof("Mock Craftserve voucher API listening on %s", *addr)
+	err = http.ListenAndServe(*addr, server.Handler())
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+}
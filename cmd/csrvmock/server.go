@@ -0,0 +1,372 @@
+package main
+
+import (
+	"crypto/rand"
+	"csrvbot/domain/entities"
+	"csrvbot/dtos"
+	"csrvbot/pkg/logger"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Failure modes returned instead of a voucher, see Server.nextFailure.
+const (
+	FailureNone      = "ok"
+	FailureStatus500 = "500"
+	FailureTimeout   = "timeout"
+	FailureMalformed = "malformed"
+	FailureEmpty     = "empty"
+)
+
+var failureModes = map[string]bool{
+	FailureNone:      true,
+	FailureStatus500: true,
+	FailureTimeout:   true,
+	FailureMalformed: true,
+	FailureEmpty:     true,
+}
+
+type IssuedVoucher struct {
+	entities.Voucher
+	Prefix  string `json:"prefix"`
+	GroupId string `json:"group_id"`
+}
+
+type Server struct {
+	Secret       string
+	DataPath     string
+	TimeoutDelay time.Duration
+
+	mu       sync.Mutex
+	failures []string
+	vouchers []IssuedVoucher
+}
+
+func NewServer(secret, dataPath string, timeoutDelay time.Duration) (*Server, error) {
+	server := &Server{Secret: secret, DataPath: dataPath, TimeoutDelay: timeoutDelay}
+	if dataPath == "" {
+		return server, nil
+	}
+
+	data, err := os.ReadFile(dataPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return server, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &server.vouchers); err != nil {
+		return nil, fmt.Errorf("could not read issued vouchers from %s: %w", dataPath, err)
+	}
+
+	return server, nil
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/admin/voucher/generate", s.handleGenerate)
+	mux.HandleFunc("/api/admin/voucher/", s.handleVoucher)
+	mux.HandleFunc("/mock/failures", s.handleFailures)
+	mux.HandleFunc("/mock/vouchers", s.handleVouchers)
+	mux.HandleFunc("/mock/redeem", s.handleRedeem)
+	return mux
+}
+
+// QueueFailures appends failure modes used by the next generate requests, one per request.
+func (s *Server) QueueFailures(modes []string) error {
+	for _, mode := range modes {
+		if !failureModes[mode] {
+			return fmt.Errorf("unknown failure mode %q", mode)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, modes...)
+	return nil
+}
+
+func (s *Server) nextFailure() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.failures) == 0 {
+		return FailureNone
+	}
+
+	mode := s.failures[0]
+	s.failures = s.failures[1:]
+	return mode
+}
+
+func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cookie, err := r.Cookie("user_access_token")
+	if err != nil || cookie.Value != s.Secret {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var payload dtos.GenerateVoucherPayload
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil {
+		http.Error(w, "invalid payload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validatePayload(payload); err != nil {
+		http.Error(w, "invalid payload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mode := s.nextFailure()
+	logger.Logger.WithField("mode", mode).Infof("Generate request for %d voucher(s) in group %s", *payload.Quantity, *payload.GroupId)
+	switch mode {
+	case FailureStatus500:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	case FailureTimeout:
+		select {
+		case <-r.Context().Done():
+		case <-time.After(s.TimeoutDelay):
+			http.Error(w, "gateway timeout", http.StatusGatewayTimeout)
+		}
+		return
+	case FailureMalformed:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `[{"id": "BROKEN`)
+		return
+	case FailureEmpty:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `[]`)
+		return
+	}
+
+	vouchers, err := s.issue(payload)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Could not persist issued vouchers")
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(vouchers)
+}
+
+func (s *Server) handleVoucher(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cookie, err := r.Cookie("user_access_token")
+	if err != nil || cookie.Value != s.Secret {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	code := strings.TrimPrefix(r.URL.Path, "/api/admin/voucher/")
+	s.mu.Lock()
+	voucher := s.findVoucher(code)
+	var found entities.Voucher
+	if voucher != nil {
+		found = voucher.Voucher
+	}
+	s.mu.Unlock()
+	if voucher == nil {
+		http.Error(w, "voucher not found", http.StatusNotFound)
+		return
+	}
+
+	writeJson(w, found)
+}
+
+// findVoucher must be called with s.mu held.
+func (s *Server) findVoucher(code string) *IssuedVoucher {
+	for i := range s.vouchers {
+		if s.vouchers[i].Id == code {
+			return &s.vouchers[i]
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) issue(payload dtos.GenerateVoucherPayload) ([]entities.Voucher, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	vouchers := make([]entities.Voucher, 0, *payload.Quantity)
+	for i := 0; i < *payload.Quantity; i++ {
+		code, err := randomCode(payload.Length, payload.Charset)
+		if err != nil {
+			return nil, err
+		}
+
+		voucher := entities.Voucher{
+			Id:        *payload.Prefix + code,
+			CreatedAt: time.Now(),
+			Expires:   payload.Expires,
+			Data:      payload.Actions,
+		}
+		vouchers = append(vouchers, voucher)
+		s.vouchers = append(s.vouchers, IssuedVoucher{Voucher: voucher, Prefix: *payload.Prefix, GroupId: *payload.GroupId})
+	}
+
+	return vouchers, s.save()
+}
+
+// save must be called with s.mu held.
+func (s *Server) save() error {
+	if s.DataPath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.vouchers, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.DataPath, data, 0o644)
+}
+
+func (s *Server) handleFailures(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.Lock()
+		failures := append([]string{}, s.failures...)
+		s.mu.Unlock()
+		writeJson(w, failures)
+	case http.MethodPost:
+		var modes []string
+		if err := json.NewDecoder(r.Body).Decode(&modes); err != nil {
+			http.Error(w, "expected a JSON array of failure modes", http.StatusBadRequest)
+			return
+		}
+		if err := s.QueueFailures(modes); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		s.mu.Lock()
+		s.failures = nil
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleVouchers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	vouchers := append([]IssuedVoucher{}, s.vouchers...)
+	s.mu.Unlock()
+	writeJson(w, vouchers)
+}
+
+// handleRedeem marks the voucher given in the code query parameter as redeemed, like a user would on the website.
+func (s *Server) handleRedeem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	voucher := s.findVoucher(r.URL.Query().Get("code"))
+	if voucher == nil {
+		http.Error(w, "voucher not found", http.StatusNotFound)
+		return
+	}
+	if voucher.RedeemedAt != nil {
+		http.Error(w, "voucher already redeemed", http.StatusConflict)
+		return
+	}
+
+	now := time.Now()
+	voucher.RedeemedAt = &now
+	if err := s.save(); err != nil {
+		logger.Logger.WithError(err).Error("Could not persist redeemed voucher")
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func validatePayload(payload dtos.GenerateVoucherPayload) error {
+	switch {
+	case payload.Length <= 0:
+		return errors.New("length must be positive")
+	case payload.Charset == "":
+		return errors.New("charset is required")
+	case payload.Prefix == nil:
+		return errors.New("prefix is required")
+	case payload.GroupId == nil || *payload.GroupId == "":
+		return errors.New("group_id is required")
+	case payload.Expires == nil || !payload.Expires.After(time.Now()):
+		return errors.New("expires must be in the future")
+	case payload.Uses == nil || *payload.Uses < 1:
+		return errors.New("uses must be at least 1")
+	case payload.PerUser == nil || *payload.PerUser < 1:
+		return errors.New("per_user must be at least 1")
+	case payload.Quantity == nil || *payload.Quantity < 1 || *payload.Quantity > 100:
+		return errors.New("quantity must be between 1 and 100")
+	case len(payload.Actions) == 0:
+		return errors.New("at least one action is required")
+	}
+
+	for _, action := range payload.Actions {
+		if len(action.WalletTx) == 0 {
+			return errors.New("action without wallet_tx")
+		}
+		for currency, money := range action.WalletTx {
+			if money.Currency().Code != currency {
+				return fmt.Errorf("wallet_tx currency %s does not match its amount", currency)
+			}
+			if !money.IsPositive() {
+				return fmt.Errorf("wallet_tx amount in %s must be positive", currency)
+			}
+		}
+	}
+
+	return nil
+}
+
+func randomCode(length int, charset string) (string, error) {
+	runes := []rune(charset)
+	code := make([]rune, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(runes))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = runes[n.Int64()]
+	}
+
+	return string(code), nil
+}
+
+func writeJson(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/internal/services"
+	"csrvbot/pkg/logger"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+const testSecret = "secret"
+
+var testVoucherConfig = entities.VoucherConfig{
+	Value:          1000,
+	Currency:       entities.DefaultVoucherCurrency,
+	ExpirationDays: 30,
+	Prefix:         "test-",
+	GroupId:        "test",
+}
+
+func TestMain(m *testing.M) {
+	logger.Logger.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// newTestClient starts the mock with the failures queued and returns a client which gives up on a request quickly.
+func newTestClient(t *testing.T, failures ...string) (*Server, *services.CsrvClient) {
+	t.Helper()
+	server, err := NewServer(testSecret, "", time.Minute)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	if err := server.QueueFailures(failures); err != nil {
+		t.Fatalf("QueueFailures: %v", err)
+	}
+
+	httpServer := httptest.NewServer(server.Handler())
+	t.Cleanup(httpServer.Close)
+
+	client := services.NewCsrvClient(testSecret, "production", httpServer.URL)
+	client.HttpClient.Timeout = 100 * time.Millisecond
+	client.RetryDelay = time.Millisecond
+	return server, client
+}
+
+func (s *Server) queuedFailures() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.failures...)
+}
+
+func (s *Server) issuedVouchers() []IssuedVoucher {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]IssuedVoucher{}, s.vouchers...)
+}
+
+func TestCsrvClient_GenerateVoucher(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures []string
+		wantErr  error
+		// failures the client did not use up, an unusable 2xx response must not be retried
+		wantLeft int
+	}{
+		{name: "ok"},
+		{name: "500 is retried", failures: []string{FailureStatus500, FailureStatus500}},
+		{name: "timeout is retried", failures: []string{FailureTimeout}},
+		{name: "malformed is not retried", failures: []string{FailureMalformed, FailureNone}, wantErr: services.ErrUnexpectedResponse, wantLeft: 1},
+		{name: "empty is not retried", failures: []string{FailureEmpty, FailureNone}, wantErr: services.ErrUnexpectedResponse, wantLeft: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newTestClient(t, tt.failures...)
+
+			code, err := client.GenerateVoucher(context.Background(), testVoucherConfig)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GenerateVoucher() error = %v, want %v", err, tt.wantErr)
+			}
+			if left := len(server.queuedFailures()); left != tt.wantLeft {
+				t.Errorf("%d queued failures left, want %d", left, tt.wantLeft)
+			}
+			issued := server.issuedVouchers()
+			if tt.wantErr != nil {
+				if len(issued) != 0 {
+					t.Errorf("mock issued %d vouchers on a failed request", len(issued))
+				}
+				return
+			}
+
+			if len(issued) != 1 || issued[0].Id != code {
+				t.Fatalf("mock issued %v, want only %s", issued, code)
+			}
+			if issued[0].GroupId != testVoucherConfig.GroupId || issued[0].Prefix != testVoucherConfig.Prefix {
+				t.Errorf("voucher issued in group %s with prefix %s", issued[0].GroupId, issued[0].Prefix)
+			}
+
+			voucher, err := client.GetVoucher(context.Background(), code)
+			if err != nil {
+				t.Fatalf("GetVoucher: %v", err)
+			}
+			if voucher.Id != code || voucher.RedeemedAt != nil {
+				t.Errorf("GetVoucher() = %+v, want the unredeemed %s", voucher, code)
+			}
+		})
+	}
+}
+
+func TestCsrvClient_GenerateVoucherGivesUp(t *testing.T) {
+	tests := []struct {
+		name    string
+		failure string
+	}{
+		{name: "500", failure: FailureStatus500},
+		{name: "timeout", failure: FailureTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := []string{tt.failure, tt.failure, tt.failure, FailureNone}
+			server, client := newTestClient(t, failures...)
+
+			_, err := client.GenerateVoucher(context.Background(), testVoucherConfig)
+			if err == nil || errors.Is(err, services.ErrUnexpectedResponse) {
+				t.Fatalf("GenerateVoucher() error = %v, want a failed request", err)
+			}
+			if left := len(server.queuedFailures()); left != len(failures)-client.MaxAttempts {
+				t.Errorf("%d queued failures left, want %d", left, len(failures)-client.MaxAttempts)
+			}
+			if issued := server.issuedVouchers(); len(issued) != 0 {
+				t.Errorf("mock issued %d vouchers on a failed request", len(issued))
+			}
+		})
+	}
+}
+
+func TestCsrvClient_GetVoucherNotFound(t *testing.T) {
+	_, client := newTestClient(t)
+
+	_, err := client.GetVoucher(context.Background(), "missing")
+	if err == nil {
+		t.Fatal("GetVoucher() of an unknown code succeeded")
+	}
+}
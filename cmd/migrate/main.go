@@ -0,0 +1,126 @@
+package main
+
+import (
+	"csrvbot/pkg"
+	"csrvbot/pkg/database"
+	"csrvbot/pkg/logger"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+type Config struct {
+	MysqlConfig []database.MySQLConfiguration `json:"mysql_config"`
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-db name] status|up|down|redo\n", os.Args[0])
+	flag.PrintDefaults()
+}
+
+func main() {
+	ctx := pkg.CreateContext()
+	log := logger.GetLoggerFromContext(ctx)
+
+	dbName := flag.String("db", "main", "name of the database from mysql_config")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = "config.json"
+	}
+
+	configFile, err := os.Open(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var config Config
+	err = json.NewDecoder(configFile).Decode(&config)
+	if err != nil {
+		log.Fatal("main#Decoder.Decode(&config)", err)
+	}
+
+	var databaseConfig []database.MySQLConfiguration
+	for _, mysqlConfig := range config.MysqlConfig {
+		if mysqlConfig.Name == *dbName {
+			databaseConfig = append(databaseConfig, mysqlConfig)
+		}
+	}
+
+	db := database.NewProvider()
+	err = db.InitMySQLDatabases(ctx, databaseConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dbMap, err := db.GetMySQLDatabase(*dbName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	migrator, err := database.NewMigrator(dbMap.Db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch flag.Arg(0) {
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tCHECKSUM")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.DateTime)
+			}
+			checksum := "ok"
+			if status.Modified {
+				checksum = "modified"
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, appliedAt, checksum)
+		}
+		_ = w.Flush()
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Infof("Applied %d migrations", applied)
+	case "down":
+		migration, err := migrator.Down(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if migration == nil {
+			log.Info("No migrations to revert")
+			return
+		}
+		log.Infof("Reverted migration %04d_%s", migration.Version, migration.Name)
+	case "redo":
+		migration, err := migrator.Redo(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if migration == nil {
+			log.Info("No migrations to redo")
+			return
+		}
+		log.Infof("Redone migration %04d_%s", migration.Version, migration.Name)
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
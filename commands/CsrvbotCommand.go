@@ -6,30 +6,44 @@ import (
 	"csrvbot/internal/services"
 	"csrvbot/pkg/discord"
 	"csrvbot/pkg/logger"
+	"csrvbot/pkg/schedule"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/Craftserve/monies"
 	"github.com/bwmarrin/discordgo"
 )
 
+const (
+	thxReviewPageSize = 5
+	auditLogPageSize  = 10
+)
+
 type CsrvbotCommand struct {
 	Name                     string
 	Description              string
 	DMPermission             bool
 	DefaultMemberPermissions int64
-	VoucherValue             int
 	Zero                     float64
-	GiveawayHours            string
+	One                      float64
 	CraftserveUrl            string
 	ServerRepo               entities.ServerRepo
 	GiveawaysRepo            entities.GiveawaysRepo
 	UserRepo                 entities.UserRepo
-	CsrvClient               services.CsrvClient
+	VoucherService           *services.VoucherService
 	GiveawayService          services.GiveawayService
 	HelperService            services.HelperService
+	GiveawayScheduler        *services.GiveawayScheduler
+	ThxAbuseService          *services.ThxAbuseService
+	AuditService             *services.AuditService
 }
 
 const (
@@ -41,13 +55,25 @@ const (
 	UnblacklistSubcommand       = "unblacklist"
 	HelperBlacklistSubcommand   = "helperblacklist"
 	HelperUnblacklistSubcommand = "helperunblacklist"
+	CustomGiveawaySubcommand    = "giveaway"
+	UnclaimedSubcommand         = "unclaimed"
+	LimitHitsSubcommand         = "limithits"
+	ReviewSubcommand            = "review"
+	AuditSubcommand             = "audit"
+
+	// CustomGiveawaySubcommand Subcommands
+	CreateSubcommand = "create"
+
+	// BlacklistSubcommand and HelperBlacklistSubcommand Subcommands
+	BlacklistAddSubcommand  = "add"
+	BlacklistListSubcommand = "list"
 
 	// SettingSubcommand Subcommands
 	GiveawayChannelSubcommand              = "giveawaychannel"
 	ThxInfoChannelSubcommand               = "thxinfochannel"
 	AdminRoleSubcommand                    = "adminrole"
-	HelperRoleSubcommand                   = "helperrole"
-	HelperThxAmountSubcommand              = "helperthxamount"
+	HelperTierSubcommand                   = "helpertier"
+	HelpersSubcommand                      = "helpers"
 	WinnerCountSubcommand                  = "winnercount"
 	UnconditionalGiveawayChannelSubcommand = "unconditionalgiveawaychannel"
 	UnconditionalWinnerCountSubcommand     = "unconditionalwinnercount"
@@ -55,24 +81,57 @@ const (
 	ConditionalWinnerCountSubcommand       = "conditionalwinnercount"
 	ConditionalGiveawayLevelsSubcommand    = "conditionalgiveawaylevels"
 	StatusChannelSubcommand                = "statuschannel"
+	ThxWeightingSubcommand                 = "thxweighting"
+	ScheduleSubcommand                     = "schedule"
+	VoucherSubcommand                      = "voucher"
+	ExpiredVouchersSubcommand              = "expiredvouchers"
+	ThxLimitsSubcommand                    = "thxlimits"
+	ThxExpirySubcommand                    = "thxexpiry"
+	AdminLogChannelSubcommand              = "adminlogchannel"
+	AuditChannelSubcommand                 = "auditchannel"
+	EligibilitySubcommand                  = "eligibility"
 )
 
-func NewCsrvbotCommand(craftserveUrl, giveawayHours string, voucherValue int, serverRepo entities.ServerRepo, giveawaysRepo entities.GiveawaysRepo, userRepo entities.UserRepo, csrvClient *services.CsrvClient, giveawayService *services.GiveawayService, helperService *services.HelperService) CsrvbotCommand {
+func giveawayTypeChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(services.ScheduledGiveawayTypes))
+	for i, giveawayType := range services.ScheduledGiveawayTypes {
+		choices[i] = &discordgo.ApplicationCommandOptionChoice{
+			Name:  discord.GiveawayTypeNames[giveawayType],
+			Value: giveawayType,
+		}
+	}
+	return choices
+}
+
+func auditActionChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(entities.AuditActions))
+	for i, action := range entities.AuditActions {
+		choices[i] = &discordgo.ApplicationCommandOptionChoice{
+			Name:  discord.AuditActionNames[action],
+			Value: action,
+		}
+	}
+	return choices
+}
+
+func NewCsrvbotCommand(craftserveUrl string, serverRepo entities.ServerRepo, giveawaysRepo entities.GiveawaysRepo, userRepo entities.UserRepo, voucherService *services.VoucherService, giveawayService *services.GiveawayService, helperService *services.HelperService, giveawayScheduler *services.GiveawayScheduler, thxAbuseService *services.ThxAbuseService, auditService *services.AuditService) CsrvbotCommand {
 	return CsrvbotCommand{
 		Name:                     "csrvbot",
 		Description:              "Komendy konfiguracyjne i administracyjne",
 		DMPermission:             false,
 		DefaultMemberPermissions: discordgo.PermissionManageMessages,
-		VoucherValue:             voucherValue,
 		Zero:                     0.0,
-		GiveawayHours:            giveawayHours,
+		One:                      1.0,
 		CraftserveUrl:            craftserveUrl,
 		ServerRepo:               serverRepo,
 		GiveawaysRepo:            giveawaysRepo,
 		UserRepo:                 userRepo,
-		CsrvClient:               *csrvClient,
+		VoucherService:           voucherService,
 		GiveawayService:          *giveawayService,
 		HelperService:            *helperService,
+		GiveawayScheduler:        giveawayScheduler,
+		ThxAbuseService:          thxAbuseService,
+		AuditService:             auditService,
 	}
 }
 
@@ -135,29 +194,52 @@ func (h CsrvbotCommand) Register(ctx context.Context, s *discordgo.Session) {
 						},
 					},
 					{
-						Name:        HelperRoleSubcommand,
-						Description: "Rola którą dostanie użytkownik, gdy osiągnie daną ilość thx",
+						Name:        HelperTierSubcommand,
+						Description: "Ustawia próg podziękowań dla roli helpera, kolejne role tworzą drabinkę",
 						Type:        discordgo.ApplicationCommandOptionSubCommand,
 						Options: []*discordgo.ApplicationCommandOption{
 							{
 								Type:        discordgo.ApplicationCommandOptionRole,
 								Name:        "role",
-								Description: "Rola, która ma dostęp do akceptowania thx i komend administracyjnych",
+								Description: "Rola helpera",
+								Required:    true,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "amount",
+								Description: "Liczba thx, powyżej której użytkownik dostaje rolę, 0 usuwa rolę z drabinki",
 								Required:    true,
+								MinValue:    &h.Zero,
 							},
 						},
 					},
 					{
-						Name:        HelperThxAmountSubcommand,
-						Description: "Ilość wymaganych thx do uzyskania roli helpera",
+						Name:        HelpersSubcommand,
+						Description: "Okres liczenia thx do ról helpera i ogłaszanie zmian rang",
 						Type:        discordgo.ApplicationCommandOptionSubCommand,
 						Options: []*discordgo.ApplicationCommandOption{
 							{
 								Type:        discordgo.ApplicationCommandOptionInteger,
-								Name:        "amount",
-								Description: "Ilość wymaganych thx do uzyskania roli helpera",
-								Required:    true,
+								Name:        "days",
+								Description: "Z ilu ostatnich dni liczą się thx, 0 liczy wszystkie",
+								Required:    false,
 								MinValue:    &h.Zero,
+								MaxValue:    3650,
+							},
+							{
+								Type: discordgo.ApplicationCommandOptionChannel,
+								ChannelTypes: []discordgo.ChannelType{
+									discordgo.ChannelTypeGuildText,
+								},
+								Name:        "channel",
+								Description: "Kanał, na którym są ogłaszane awanse i degradacje helperów",
+								Required:    false,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "announce",
+								Description: "Czy ogłaszać awanse i degradacje helperów",
+								Required:    false,
 							},
 						},
 					},
@@ -277,9 +359,303 @@ func (h CsrvbotCommand) Register(ctx context.Context, s *discordgo.Session) {
 							},
 						},
 					},
+					{
+						Name:        ThxWeightingSubcommand,
+						Description: "Sposób liczenia losów w giveawayu za podziękowania",
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "strategy",
+								Description: "Sposób liczenia losów",
+								Required:    true,
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{
+										Name:  "Jeden los na uczestnika",
+										Value: entities.ThxWeightingPerParticipant,
+									},
+									{
+										Name:  "Jeden los na podziękowanie",
+										Value: entities.ThxWeightingPerThx,
+									},
+									{
+										Name:  "Malejąca liczba losów za kolejne podziękowania",
+										Value: entities.ThxWeightingDiminishing,
+									},
+								},
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "cap",
+								Description: "Maksymalna liczba losów przy malejącej liczbie losów",
+								Required:    false,
+								MinValue:    &h.One,
+							},
+						},
+					},
+					{
+						Name:        ScheduleSubcommand,
+						Description: "Godziny rozstrzygania giveawayów",
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "giveaway",
+								Description: "Rodzaj giveawaya",
+								Required:    false,
+								Choices:     giveawayTypeChoices(),
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "hours",
+								Description: "Godziny oddzielone przecinkami, np. 6:00,12:00,18:00, albo - aby wyłączyć",
+								Required:    false,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "timezone",
+								Description: "Strefa czasowa serwera, np. Europe/Warsaw",
+								Required:    false,
+							},
+						},
+					},
+					{
+						Name:        VoucherSubcommand,
+						Description: "Kody Craftserve rozdawane w giveawayach",
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "giveaway",
+								Description: "Rodzaj giveawaya",
+								Required:    true,
+								Choices:     giveawayTypeChoices(),
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionNumber,
+								Name:        "value",
+								Description: "Wartość kodu, np. 10 lub 12.50",
+								Required:    false,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "currency",
+								Description: "Waluta kodu, np. PLN",
+								Required:    false,
+								MaxLength:   3,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "expiration",
+								Description: "Ważność kodu w dniach",
+								Required:    false,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "prefix",
+								Description: "Prefiks kodu",
+								Required:    false,
+								MaxLength:   32,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "group",
+								Description: "ID grupy kodów w panelu Craftserve",
+								Required:    false,
+								MaxLength:   64,
+							},
+						},
+					},
+					{
+						Name:        ExpiredVouchersSubcommand,
+						Description: "Co zrobić z kodem, który wygasł niewykorzystany",
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "policy",
+								Description: "Postępowanie z wygasłymi kodami",
+								Required:    true,
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{
+										Name:  "Nic nie rób",
+										Value: entities.ExpiredVoucherPolicyNone,
+									},
+									{
+										Name:  "Wyślij zwycięzcy nowy kod",
+										Value: entities.ExpiredVoucherPolicyReissue,
+									},
+									{
+										Name:  "Rozlosuj nagrodę ponownie",
+										Value: entities.ExpiredVoucherPolicyRedraw,
+									},
+								},
+							},
+						},
+					},
+					{
+						Name:        ThxLimitsSubcommand,
+						Description: "Limity podziękowań, 0 wyłącza limit",
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "cooldown",
+								Description: "Minimalny odstęp w minutach między podziękowaniami dla tej samej osoby",
+								Required:    false,
+								MinValue:    &h.Zero,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "daily",
+								Description: "Maksymalna liczba podziękowań od jednej osoby dziennie",
+								Required:    false,
+								MinValue:    &h.Zero,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "pending",
+								Description: "Maksymalna liczba nierozpatrzonych próśb /thxme od jednej osoby",
+								Required:    false,
+								MinValue:    &h.Zero,
+							},
+						},
+					},
+					{
+						Name:        ThxExpirySubcommand,
+						Description: "Wygasanie próśb /thxme i nierozpatrzonych podziękowań",
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "hours",
+								Description: "Po ilu godzinach wygasa nierozpatrzona prośba /thxme",
+								Required:    false,
+								MinValue:    &h.One,
+								MaxValue:    720,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "policy",
+								Description: "Co zrobić z nierozpatrzonymi podziękowaniami po zakończeniu giveawaya",
+								Required:    false,
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{
+										Name:  "Odrzuć",
+										Value: entities.UnreviewedThxPolicyReject,
+									},
+									{
+										Name:  "Przenieś do następnego giveawaya",
+										Value: entities.UnreviewedThxPolicyCarryOver,
+									},
+								},
+							},
+						},
+					},
+					{
+						Name:        EligibilitySubcommand,
+						Description: "Wymagania udziału we wszystkich giveawayach, 0 wyłącza wymaganie",
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "accountage",
+								Description: "Minimalny wiek konta Discord w dniach",
+								Required:    false,
+								MinValue:    &h.Zero,
+								MaxValue:    3650,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "memberage",
+								Description: "Minimalny czas na serwerze w dniach",
+								Required:    false,
+								MinValue:    &h.Zero,
+								MaxValue:    3650,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionRole,
+								Name:        "role",
+								Description: "Rola wymagana do udziału",
+								Required:    false,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "clearrole",
+								Description: "Usuwa wymaganą rolę",
+								Required:    false,
+							},
+						},
+					},
+					{
+						Name:        AuditChannelSubcommand,
+						Description: "Kanał na którym są wysyłane wpisy z dziennika działań administracji",
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type: discordgo.ApplicationCommandOptionChannel,
+								ChannelTypes: []discordgo.ChannelType{
+									discordgo.ChannelTypeGuildText,
+								},
+								Name:        "channel",
+								Description: "Kanał na wpisy z dziennika, bez kanału wpisy są tylko zapisywane",
+								Required:    false,
+							},
+						},
+					},
+					{
+						Name:        AdminLogChannelSubcommand,
+						Description: "Kanał na którym są wysyłane raporty z automatycznych zmian, np. ról helperów",
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type: discordgo.ApplicationCommandOptionChannel,
+								ChannelTypes: []discordgo.ChannelType{
+									discordgo.ChannelTypeGuildText,
+								},
+								Name:        "channel",
+								Description: "Kanał na raporty, bez kanału raporty nie są wysyłane",
+								Required:    false,
+							},
+						},
+					},
 				},
 				Type: discordgo.ApplicationCommandOptionSubCommandGroup,
 			},
+			{
+				Name:        CustomGiveawaySubcommand,
+				Description: "Giveawaye tworzone przez administrację",
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        CreateSubcommand,
+						Description: "Tworzy nowy giveaway z własną nagrodą i czasem trwania",
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "level",
+								Description: "Wymagany poziom",
+								Required:    false,
+								MinValue:    &h.Zero,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionRole,
+								Name:        "role",
+								Description: "Wymagana rola",
+								Required:    false,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "voucher",
+								Description: "Wartość kodu Craftserve dla każdego zwycięzcy w PLN",
+								Required:    false,
+								MinValue:    &h.One,
+							},
+						},
+					},
+				},
+			},
 			{
 				Name:        DeleteSubcommand,
 				Description: "Usuwa użytkownika z obecnego giveawaya",
@@ -326,14 +702,39 @@ func (h CsrvbotCommand) Register(ctx context.Context, s *discordgo.Session) {
 			},
 			{
 				Name:        BlacklistSubcommand,
-				Description: "Dodaje użytkownika do blacklisty możliwości udziału w giveawayu",
-				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Description: "Blacklista możliwości udziału w giveawayu",
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
 				Options: []*discordgo.ApplicationCommandOption{
 					{
-						Type:        discordgo.ApplicationCommandOptionUser,
-						Name:        "user",
-						Description: "Użytkownik, który ma zostać dodany",
-						Required:    true,
+						Name:        BlacklistAddSubcommand,
+						Description: "Dodaje użytkownika do blacklisty możliwości udziału w giveawayu",
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionUser,
+								Name:        "user",
+								Description: "Użytkownik, który ma zostać dodany",
+								Required:    true,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "reason",
+								Description: "Powód blokady",
+								Required:    true,
+								MaxLength:   255,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "duration",
+								Description: "Czas trwania blokady, np. 12h, 7d lub 2w, bez niego blokada jest bezterminowa",
+								Required:    false,
+							},
+						},
+					},
+					{
+						Name:        BlacklistListSubcommand,
+						Description: "Wyświetla aktywne blokady udziału w giveawayu",
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
 					},
 				},
 			},
@@ -352,14 +753,39 @@ func (h CsrvbotCommand) Register(ctx context.Context, s *discordgo.Session) {
 			},
 			{
 				Name:        HelperBlacklistSubcommand,
-				Description: "Dodaje użytkownika do blacklisty możliwości posiadania rangi helpera",
-				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Description: "Blacklista możliwości posiadania rangi helpera",
+				Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
 				Options: []*discordgo.ApplicationCommandOption{
 					{
-						Type:        discordgo.ApplicationCommandOptionUser,
-						Name:        "user",
-						Description: "Użytkownik, który ma zostać dodany",
-						Required:    true,
+						Name:        BlacklistAddSubcommand,
+						Description: "Dodaje użytkownika do blacklisty możliwości posiadania rangi helpera",
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionUser,
+								Name:        "user",
+								Description: "Użytkownik, który ma zostać dodany",
+								Required:    true,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "reason",
+								Description: "Powód blokady",
+								Required:    true,
+								MaxLength:   255,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "duration",
+								Description: "Czas trwania blokady, np. 12h, 7d lub 2w, bez niego blokada jest bezterminowa",
+								Required:    false,
+							},
+						},
+					},
+					{
+						Name:        BlacklistListSubcommand,
+						Description: "Wyświetla aktywne blokady rang helpera",
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
 					},
 				},
 			},
@@ -376,6 +802,53 @@ func (h CsrvbotCommand) Register(ctx context.Context, s *discordgo.Session) {
 					},
 				},
 			},
+			{
+				Name:        UnclaimedSubcommand,
+				Description: "Wyświetla niewykorzystane kody z giveawayów",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+			},
+			{
+				Name:        LimitHitsSubcommand,
+				Description: "Wyświetla ostatnie podziękowania odrzucone przez limity",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+			},
+			{
+				Name:        ReviewSubcommand,
+				Description: "Wyświetla kolejkę podziękowań do rozpatrzenia",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+			},
+			{
+				Name:        AuditSubcommand,
+				Description: "Wyświetla dziennik działań administracji",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Name:        "user",
+						Description: "Osoba, która wykonała działanie lub której ono dotyczy",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "action",
+						Description: "Rodzaj działania",
+						Required:    false,
+						Choices:     auditActionChoices(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "from",
+						Description: "Pierwszy dzień, w formacie RRRR-MM-DD",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "to",
+						Description: "Ostatni dzień, w formacie RRRR-MM-DD",
+						Required:    false,
+					},
+				},
+			},
 		},
 	})
 	if err != nil {
@@ -412,28 +885,65 @@ func (h CsrvbotCommand) Handle(ctx context.Context, s *discordgo.Session, i *dis
 	case StartSubcommand:
 		h.handleStart(ctx, s, i)
 	case BlacklistSubcommand:
-		h.handleBlacklist(ctx, s, i)
+		switch i.ApplicationCommandData().Options[0].Options[0].Name {
+		case BlacklistAddSubcommand:
+			h.handleBlacklist(ctx, s, i)
+		case BlacklistListSubcommand:
+			h.handleBlacklistList(ctx, s, i)
+		}
 	case UnblacklistSubcommand:
 		h.handleUnblacklist(ctx, s, i)
 	case HelperBlacklistSubcommand:
-		h.handleHelperBlacklist(ctx, s, i)
+		switch i.ApplicationCommandData().Options[0].Options[0].Name {
+		case BlacklistAddSubcommand:
+			h.handleHelperBlacklist(ctx, s, i)
+		case BlacklistListSubcommand:
+			h.handleHelperBlacklistList(ctx, s, i)
+		}
 	case HelperUnblacklistSubcommand:
 		h.handleHelperUnblacklist(ctx, s, i)
+	case CustomGiveawaySubcommand:
+		h.handleCustomGiveaway(ctx, s, i)
+	case UnclaimedSubcommand:
+		h.handleUnclaimed(ctx, s, i)
+	case LimitHitsSubcommand:
+		h.handleLimitHits(ctx, s, i)
+	case ReviewSubcommand:
+		h.handleReview(ctx, s, i)
+	case AuditSubcommand:
+		h.handleAudit(ctx, s, i)
 	}
 }
 
 func (h CsrvbotCommand) handleSettings(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
-	switch i.ApplicationCommandData().Options[0].Options[0].Name {
+	log := logger.GetLoggerFromContext(ctx)
+	setting := i.ApplicationCommandData().Options[0].Options[0].Name
+	// Every change of the server config is audited here, the settings stored elsewhere are audited by their handlers
+	before, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("handleSettings h.ServerRepo.GetServerConfigForGuild")
+	} else {
+		defer func() {
+			after, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+			if err != nil {
+				log.WithError(err).Error("handleSettings h.ServerRepo.GetServerConfigForGuild")
+				return
+			}
+			h.auditChange(ctx, s, i, entities.AuditActionSettings, setting, before, after)
+		}()
+	}
+
+	switch setting {
 	case GiveawayChannelSubcommand:
 		h.handleGiveawayChannelSet(ctx, s, i)
 	case ThxInfoChannelSubcommand:
 		h.handleThxInfoChannelSet(ctx, s, i)
 	case AdminRoleSubcommand:
 		h.handleAdminRoleSet(ctx, s, i)
-	case HelperRoleSubcommand:
-		h.handleHelperRoleSet(ctx, s, i)
-	case HelperThxAmountSubcommand:
-		h.handleHelperThxAmountSet(ctx, s, i)
+	case HelperTierSubcommand:
+		h.handleHelperTierSet(ctx, s, i)
+	case HelpersSubcommand:
+		h.handleHelpersSet(ctx, s, i)
 	case WinnerCountSubcommand:
 		h.handleWinnerCountSet(ctx, s, i)
 	//case UnconditionalGiveawayChannelSubcommand:
@@ -448,6 +958,24 @@ func (h CsrvbotCommand) handleSettings(ctx context.Context, s *discordgo.Session
 		h.handleConditionalGiveawayLevelsSet(ctx, s, i)
 	case StatusChannelSubcommand:
 		h.handleStatusChannelSet(ctx, s, i)
+	case ThxWeightingSubcommand:
+		h.handleThxWeightingSet(ctx, s, i)
+	case ScheduleSubcommand:
+		h.handleScheduleSet(ctx, s, i)
+	case VoucherSubcommand:
+		h.handleVoucherSet(ctx, s, i)
+	case ExpiredVouchersSubcommand:
+		h.handleExpiredVoucherPolicySet(ctx, s, i)
+	case ThxLimitsSubcommand:
+		h.handleThxLimitsSet(ctx, s, i)
+	case ThxExpirySubcommand:
+		h.handleThxExpirySet(ctx, s, i)
+	case AdminLogChannelSubcommand:
+		h.handleAdminLogChannelSet(ctx, s, i)
+	case AuditChannelSubcommand:
+		h.handleAuditChannelSet(ctx, s, i)
+	case EligibilitySubcommand:
+		h.handleEligibilitySet(ctx, s, i)
 	}
 }
 
@@ -487,6 +1015,7 @@ func (h CsrvbotCommand) handleStart(ctx context.Context, s *discordgo.Session, i
 		log.Debug("Starting conditional giveaway")
 		go h.GiveawayService.FinishJoinableGiveaway(ctx, s, guild.ID, true)
 	}
+	h.audit(ctx, s, i, entities.AuditActionGiveawayStart, giveawayType)
 	discord.RespondWithMessage(ctx, s, i, "Podjęto próbę rozstrzygnięcia giveawayu")
 }
 
@@ -511,6 +1040,8 @@ func (h CsrvbotCommand) handleDelete(ctx context.Context, s *discordgo.Session,
 		return
 	}
 	log.Infof("%s removed all entries for user %s", i.Member.User.Username, selectedUser.Username)
+	h.audit(ctx, s, i, entities.AuditActionThxDelete, selectedUser.ID)
+	h.HelperService.CheckHelper(ctx, s, i.GuildID, selectedUser.ID)
 	//participantNames, err := h.GiveawayRepo.GetParticipantNamesForGiveaway(ctx, giveaway.Id)
 	//if err != nil {
 	//	log.WithError(err).Error("handleDelete h.GiveawayRepo.GetParticipantNamesForGiveaway")
@@ -522,17 +1053,12 @@ func (h CsrvbotCommand) handleDelete(ctx context.Context, s *discordgo.Session,
 		return
 	}
 
-	var participantNames []string
-	for _, participant := range participants {
-		participantNames = append(participantNames, participant.UserName)
-	}
-
 	for _, participant := range participants {
 		if participant.UserId != selectedUser.ID {
 			return
 		}
 		log.WithMessage(*participant.MessageId).Debug("Updating thx embed after entry deletion for participant ", participant.UserId)
-		embed := discord.ConstructThxEmbed(h.CraftserveUrl, participantNames, h.GiveawayHours, participant.UserId, "", "reject", h.VoucherValue)
+		embed := discord.ConstructThxEmbed(h.CraftserveUrl, serverConfig, participants, participant.UserId, "", "reject", h.VoucherService.GetVoucherConfig(ctx, serverConfig.GuildId, entities.ThxGiveawayType))
 
 		candidate, err := h.GiveawaysRepo.GetParticipantCandidate(ctx, *participant.MessageId)
 		if err != nil {
@@ -551,7 +1077,7 @@ func (h CsrvbotCommand) handleDelete(ctx context.Context, s *discordgo.Session,
 			return
 		}
 		log.Debug("Updating thx notification message after entry deletion for participant ", participant.UserId)
-		_, err = discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, thxNotification.NotificationMessageId, i.GuildID, i.ChannelID, *participant.MessageId, participant.UserId, "", "reject", h.CraftserveUrl)
+		_, err = discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, thxNotification.NotificationMessageId, i.GuildID, i.ChannelID, *participant.MessageId, participant.UserId, "", "reject", h.CraftserveUrl, h.ThxAbuseService.GetFlags(ctx, *participant.MessageId))
 		if err != nil {
 			log.WithError(err).Error("handleDelete discord.NotifyThxOnThxInfoChannel")
 			return
@@ -560,9 +1086,399 @@ func (h CsrvbotCommand) handleDelete(ctx context.Context, s *discordgo.Session,
 	}
 }
 
+func (h CsrvbotCommand) handleUnclaimed(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	winners, err := h.GiveawaysRepo.GetUnclaimedWinners(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("handleUnclaimed h.GiveawaysRepo.GetUnclaimedWinners")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać niewykorzystanych kodów")
+		return
+	}
+	if len(winners) == 0 {
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Brak niewykorzystanych kodów")
+		return
+	}
+
+	message := fmt.Sprintf("**Niewykorzystane kody (%d):**", len(winners))
+	for n, winner := range winners {
+		line := fmt.Sprintf("\n<@%s> %s", winner.UserId, discord.WinnerCodeLine(winner.GiveawayWinner))
+		if len(message)+len(line) > 1900 {
+			message += fmt.Sprintf("\n...i %d więcej", len(winners)-n)
+			break
+		}
+		message += line
+	}
+	discord.RespondWithEphemeralMessage(ctx, s, i, message)
+}
+
+func (h CsrvbotCommand) handleLimitHits(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	hits, err := h.GiveawaysRepo.GetThxLimitHits(ctx, i.GuildID, 25)
+	if err != nil {
+		log.WithError(err).Error("handleLimitHits h.GiveawaysRepo.GetThxLimitHits")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać odrzuconych podziękowań")
+		return
+	}
+	if len(hits) == 0 {
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Brak podziękowań odrzuconych przez limity")
+		return
+	}
+
+	message := "**Ostatnie podziękowania odrzucone przez limity:**"
+	for _, hit := range hits {
+		message += fmt.Sprintf("\n<t:%d:R> <@%s> → <@%s> · %s", hit.CreatedAt.Unix(), hit.UserId, hit.TargetUserId, discord.ThxLimitNames[hit.Limit])
+	}
+	discord.RespondWithEphemeralMessage(ctx, s, i, message)
+}
+
+func (h CsrvbotCommand) handleReview(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	embed, components, err := h.reviewPage(ctx, i.GuildID, 0)
+	if err != nil {
+		log.WithError(err).Error("handleReview h.reviewPage")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać podziękowań do rozpatrzenia")
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("handleReview session.InteractionRespond")
+	}
+}
+
+// HandleMessageComponents handles the pages of the audit log and the review queue, which switches pages and accepts
+// or rejects the chosen thx of the current thx giveaway.
+func (h CsrvbotCommand) HandleMessageComponents(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx).WithCommand(h.Name)
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("HandleMessageComponents#h.ServerRepo.GetServerConfigForGuild")
+		return
+	}
+	if !discord.HasAdminPermissions(ctx, s, i.Member, serverConfig.AdminRoleId, i.GuildID) {
+		log.Debug("User is not an admin")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie masz uprawnień do tej komendy")
+		return
+	}
+
+	data := i.MessageComponentData()
+	if strings.HasPrefix(data.CustomID, "audit_") {
+		h.handleAuditPage(ctx, s, i, serverConfig)
+		return
+	}
+	action := strings.Split(data.CustomID, "_")
+	if len(action) < 3 {
+		log.Errorf("Invalid review component %s", data.CustomID)
+		return
+	}
+	page, err := strconv.Atoi(action[2])
+	if err != nil || page < 0 {
+		log.Errorf("Invalid review page %s", data.CustomID)
+		return
+	}
+
+	var ids []string
+	accept := false
+	switch action[1] {
+	case "page":
+	case "accept", "reject":
+		ids = data.Values
+		accept = action[1] == "accept"
+	case "acceptall", "rejectall":
+		if len(action) != 4 {
+			log.Errorf("Invalid review component %s", data.CustomID)
+			return
+		}
+		ids = strings.Split(action[3], ".")
+		accept = action[1] == "acceptall"
+	default:
+		log.Errorf("Unknown review action %s", data.CustomID)
+		return
+	}
+
+	// Reviewing a page of thx edits every thx message, which may take longer than an interaction response can
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+	if err != nil {
+		log.WithError(err).Error("HandleMessageComponents#session.InteractionRespond")
+		return
+	}
+
+	content := ""
+	if len(ids) > 0 {
+		reviewed, err := h.reviewThx(ctx, s, serverConfig, i.Member.User, ids, accept)
+		if err != nil {
+			log.WithError(err).Error("HandleMessageComponents#h.reviewThx")
+		}
+		if accept {
+			content = fmt.Sprintf("Zaakceptowano: %d", reviewed)
+		} else {
+			content = fmt.Sprintf("Odrzucono: %d", reviewed)
+		}
+		if err != nil {
+			content += ", nie udało się rozpatrzyć pozostałych podziękowań"
+		} else if reviewed < len(ids) {
+			content += fmt.Sprintf(", pominięto już rozpatrzone: %d", len(ids)-reviewed)
+		}
+	}
+
+	embed, components, err := h.reviewPage(ctx, i.GuildID, page)
+	if err != nil {
+		log.WithError(err).Error("HandleMessageComponents#h.reviewPage")
+		return
+	}
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    &content,
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
+	})
+	if err != nil {
+		log.WithError(err).Error("HandleMessageComponents#session.InteractionResponseEdit")
+	}
+}
+
+// reviewThx accepts or rejects the thx with the given ids which are still pending in the current thx giveaway and
+// returns how many of them were reviewed.
+func (h CsrvbotCommand) reviewThx(ctx context.Context, s *discordgo.Session, serverConfig entities.ServerConfig, reviewer *discordgo.User, ids []string, accept bool) (int, error) {
+	giveaway, err := h.GiveawaysRepo.GetGiveawayForGuild(ctx, serverConfig.GuildId, entities.ThxGiveawayType)
+	if err != nil {
+		return 0, err
+	}
+	pending, err := h.GiveawaysRepo.GetPendingParticipants(ctx, giveaway.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	selected := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	reviewed := 0
+	for n := range pending {
+		participant := &pending[n]
+		if !selected[strconv.Itoa(participant.Id)] {
+			continue
+		}
+		err = h.GiveawayService.ReviewThx(ctx, s, serverConfig, participant, reviewer.ID, reviewer.Username, accept)
+		if err != nil {
+			return reviewed, err
+		}
+		reviewed++
+		h.HelperService.CheckHelper(ctx, s, serverConfig.GuildId, participant.UserId)
+	}
+
+	return reviewed, nil
+}
+
+func (h CsrvbotCommand) reviewPage(ctx context.Context, guildId string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	giveaway, err := h.GiveawaysRepo.GetGiveawayForGuild(ctx, guildId, entities.ThxGiveawayType)
+	if err != nil {
+		return nil, nil, err
+	}
+	pending, err := h.GiveawaysRepo.GetPendingParticipants(ctx, giveaway.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	total := len(pending)
+	pages := (total + thxReviewPageSize - 1) / thxReviewPageSize
+	if page >= pages {
+		page = max(pages-1, 0)
+	}
+	pending = pending[page*thxReviewPageSize : min((page+1)*thxReviewPageSize, total)]
+
+	checks := make(map[string]*entities.ThxAbuseCheck)
+	for _, participant := range pending {
+		if participant.MessageId == nil {
+			continue
+		}
+		check, err := h.GiveawaysRepo.GetThxAbuseCheck(ctx, *participant.MessageId)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		checks[*participant.MessageId] = check
+	}
+
+	return discord.ConstructThxReviewEmbed(h.CraftserveUrl, pending, checks, page, pages, total), discord.ConstructThxReviewComponents(pending, page, pages), nil
+}
+
+func (h CsrvbotCommand) handleAudit(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	var userId, action, from, to string
+	for _, option := range i.ApplicationCommandData().Options[0].Options {
+		switch option.Name {
+		case "user":
+			userId = option.UserValue(s).ID
+		case "action":
+			action = option.StringValue()
+		case "from":
+			from = strings.TrimSpace(option.StringValue())
+		case "to":
+			to = strings.TrimSpace(option.StringValue())
+		}
+	}
+
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("handleAudit h.ServerRepo.GetServerConfigForGuild")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać dziennika działań")
+		return
+	}
+	encodedFilter := strings.Join([]string{userId, action, from, to}, ".")
+	filter, err := parseAuditFilter(serverConfig, encodedFilter)
+	if err != nil {
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nieprawidłowa data, podaj ją w formacie RRRR-MM-DD")
+		return
+	}
+
+	embed, components, err := h.auditPage(ctx, filter, encodedFilter, 0)
+	if err != nil {
+		log.WithError(err).Error("handleAudit h.auditPage")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać dziennika działań")
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("handleAudit session.InteractionRespond")
+	}
+}
+
+// handleAuditPage switches the page of the audit log, the filters are encoded in the custom id of the buttons.
+func (h CsrvbotCommand) handleAuditPage(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, serverConfig entities.ServerConfig) {
+	log := logger.GetLoggerFromContext(ctx).WithCommand(h.Name)
+	customId := strings.TrimPrefix(i.MessageComponentData().CustomID, "audit_page_")
+	separator := strings.LastIndex(customId, "_")
+	if separator < 0 {
+		log.Errorf("Invalid audit component %s", i.MessageComponentData().CustomID)
+		return
+	}
+	page, err := strconv.Atoi(customId[separator+1:])
+	if err != nil || page < 0 {
+		log.Errorf("Invalid audit page %s", i.MessageComponentData().CustomID)
+		return
+	}
+	encodedFilter := customId[:separator]
+	filter, err := parseAuditFilter(serverConfig, encodedFilter)
+	if err != nil {
+		log.WithError(err).Errorf("Invalid audit filter %s", i.MessageComponentData().CustomID)
+		return
+	}
+
+	embed, components, err := h.auditPage(ctx, filter, encodedFilter, page)
+	if err != nil {
+		log.WithError(err).Error("handleAuditPage h.auditPage")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać dziennika działań")
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("handleAuditPage session.InteractionRespond")
+	}
+}
+
+func (h CsrvbotCommand) auditPage(ctx context.Context, filter entities.AuditLogFilter, encodedFilter string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	total, err := h.AuditService.AuditLogRepo.CountAuditLogEntries(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	pages := (total + auditLogPageSize - 1) / auditLogPageSize
+	if page >= pages {
+		page = max(pages-1, 0)
+	}
+
+	entries, err := h.AuditService.AuditLogRepo.GetAuditLogEntries(ctx, filter, page*auditLogPageSize, auditLogPageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	embed := discord.ConstructAuditLogEmbed(h.CraftserveUrl, entries, page, pages, total)
+	return embed, discord.ConstructPaginationComponents("audit_page_"+encodedFilter, page, pages), nil
+}
+
+// parseAuditFilter reads the audit log filters encoded as user.action.from.to, both days are included and are in
+// the time zone of the guild.
+func parseAuditFilter(serverConfig entities.ServerConfig, encodedFilter string) (entities.AuditLogFilter, error) {
+	filter := entities.AuditLogFilter{GuildId: serverConfig.GuildId}
+	parts := strings.Split(encodedFilter, ".")
+	if len(parts) != 4 {
+		return filter, fmt.Errorf("invalid audit filter %q", encodedFilter)
+	}
+	filter.UserId, filter.Action = parts[0], parts[1]
+
+	if parts[2] != "" {
+		since, err := time.ParseInLocation(time.DateOnly, parts[2], serverConfig.Location())
+		if err != nil {
+			return filter, err
+		}
+		filter.Since = since
+	}
+	if parts[3] != "" {
+		until, err := time.ParseInLocation(time.DateOnly, parts[3], serverConfig.Location())
+		if err != nil {
+			return filter, err
+		}
+		filter.Until = until.AddDate(0, 0, 1)
+	}
+
+	return filter, nil
+}
+
+// audit records the action of the admin who invoked the interaction in the audit log.
+func (h CsrvbotCommand) audit(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, action, target string) {
+	h.AuditService.Record(ctx, s, &entities.AuditLogEntry{
+		GuildId:   i.GuildID,
+		ActorId:   i.Member.User.ID,
+		ActorName: i.Member.User.Username,
+		Action:    action,
+		Target:    target,
+	})
+}
+
+// auditChange records the change made by the admin who invoked the interaction, if anything changed.
+func (h CsrvbotCommand) auditChange(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, action, target string, before, after any) {
+	h.AuditService.RecordChange(ctx, s, &entities.AuditLogEntry{
+		GuildId:   i.GuildID,
+		ActorId:   i.Member.User.ID,
+		ActorName: i.Member.User.Username,
+		Action:    action,
+		Target:    target,
+	}, before, after)
+}
+
 func (h CsrvbotCommand) handleBlacklist(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log := logger.GetLoggerFromContext(ctx)
-	selectedUser := i.ApplicationCommandData().Options[0].Options[0].UserValue(s)
+	selectedUser, reason, expiresAt, err := blacklistOptions(s, i)
+	if err != nil {
+		discord.RespondWithMessage(ctx, s, i, "Nieprawidłowy czas trwania, podaj go np. jako 30m, 12h, 7d lub 2w")
+		return
+	}
 	if selectedUser.Bot {
 		log.Debug("User is a bot")
 		discord.RespondWithMessage(ctx, s, i, "Nie możesz dodać bota do blacklisty")
@@ -580,14 +1496,15 @@ func (h CsrvbotCommand) handleBlacklist(ctx context.Context, s *discordgo.Sessio
 	}
 
 	log.Debug("Adding user to blacklist")
-	err = h.UserRepo.AddBlacklistForUser(ctx, selectedUser.ID, i.GuildID, i.Member.User.ID)
+	err = h.UserRepo.AddBlacklistForUser(ctx, selectedUser.ID, i.GuildID, i.Member.User.ID, reason, expiresAt)
 	if err != nil {
 		log.WithError(err).Error("handleBlacklist h.UserRepo.AddBlacklistForUser")
 		discord.RespondWithMessage(ctx, s, i, "Nie udało się dodać użytkownika do blacklisty")
 		return
 	}
-	log.Infof("%s blacklisted %s", i.Member.User.Username, selectedUser.Username)
-	discord.RespondWithMessage(ctx, s, i, "Dodano użytkownika do blacklisty")
+	log.Infof("%s blacklisted %s: %s", i.Member.User.Username, selectedUser.Username, reason)
+	h.auditChange(ctx, s, i, entities.AuditActionBlacklist, selectedUser.ID, nil, map[string]any{"reason": reason, "expiresAt": expiresAt})
+	discord.RespondWithMessage(ctx, s, i, "Dodano użytkownika do blacklisty "+discord.BlacklistExpiry(expiresAt))
 }
 
 func (h CsrvbotCommand) handleUnblacklist(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
@@ -613,12 +1530,17 @@ func (h CsrvbotCommand) handleUnblacklist(ctx context.Context, s *discordgo.Sess
 		return
 	}
 	log.Infof("%s unblacklisted %s", i.Member.User.Username, selectedUser.Username)
+	h.audit(ctx, s, i, entities.AuditActionUnblacklist, selectedUser.ID)
 	discord.RespondWithMessage(ctx, s, i, "Usunięto użytkownika z blacklisty")
 }
 
 func (h CsrvbotCommand) handleHelperBlacklist(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log := logger.GetLoggerFromContext(ctx)
-	selectedUser := i.ApplicationCommandData().Options[0].Options[0].UserValue(s)
+	selectedUser, reason, expiresAt, err := blacklistOptions(s, i)
+	if err != nil {
+		discord.RespondWithMessage(ctx, s, i, "Nieprawidłowy czas trwania, podaj go np. jako 30m, 12h, 7d lub 2w")
+		return
+	}
 	if selectedUser.Bot {
 		log.Debug("User is a bot")
 		discord.RespondWithMessage(ctx, s, i, "Nie możesz dodać bota do helper-blacklisty")
@@ -636,18 +1558,82 @@ func (h CsrvbotCommand) handleHelperBlacklist(ctx context.Context, s *discordgo.
 	}
 
 	log.Debug("Adding user to helper-blacklist")
-	err = h.UserRepo.AddHelperBlacklistForUser(ctx, selectedUser.ID, i.GuildID, i.Member.User.ID)
+	err = h.UserRepo.AddHelperBlacklistForUser(ctx, selectedUser.ID, i.GuildID, i.Member.User.ID, reason, expiresAt)
 	if err != nil {
 		log.WithError(err).Error("handleHelperBlacklist h.UserRepo.AddHelperBlacklistForUser", err)
 		discord.RespondWithMessage(ctx, s, i, "Nie udało się dodać użytkownika do helper-blacklisty")
 		return
 	}
-	log.Infof("%s helper-blacklisted %s", i.Member.User.Username, selectedUser.Username)
-	discord.RespondWithMessage(ctx, s, i, "Użytkownik został zablokowany z możliwości zostania pomocnym")
+	log.Infof("%s helper-blacklisted %s: %s", i.Member.User.Username, selectedUser.Username, reason)
+	h.auditChange(ctx, s, i, entities.AuditActionHelperBlacklist, selectedUser.ID, nil, map[string]any{"reason": reason, "expiresAt": expiresAt})
+	discord.RespondWithMessage(ctx, s, i, "Użytkownik został zablokowany z możliwości zostania pomocnym "+discord.BlacklistExpiry(expiresAt))
 	log.Debug("Checking if user should be removed from helpers")
 	h.HelperService.CheckHelper(ctx, s, i.GuildID, selectedUser.ID)
 }
 
+func (h CsrvbotCommand) handleBlacklistList(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	blacklists, err := h.UserRepo.GetBlacklists(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("handleBlacklistList h.UserRepo.GetBlacklists")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać blacklisty")
+		return
+	}
+
+	lines := make([]string, 0, len(blacklists))
+	for _, blacklist := range blacklists {
+		lines = append(lines, discord.FormatBlacklistEntry(blacklist.UserId, blacklist.BlacklisterId, blacklist.Reason, blacklist.ExpiresAt))
+	}
+	discord.RespondWithEphemeralMessage(ctx, s, i, discord.ConstructBlacklistMessage("**Blacklista giveawayów**", lines))
+}
+
+func (h CsrvbotCommand) handleHelperBlacklistList(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	blacklists, err := h.UserRepo.GetHelperBlacklists(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("handleHelperBlacklistList h.UserRepo.GetHelperBlacklists")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać helper-blacklisty")
+		return
+	}
+
+	lines := make([]string, 0, len(blacklists))
+	for _, blacklist := range blacklists {
+		lines = append(lines, discord.FormatBlacklistEntry(blacklist.UserId, blacklist.BlacklisterId, blacklist.Reason, blacklist.ExpiresAt))
+	}
+	discord.RespondWithEphemeralMessage(ctx, s, i, discord.ConstructBlacklistMessage("**Helper-blacklista**", lines))
+}
+
+var blacklistDurationRegexp = regexp.MustCompile(`^(\d+)\s*([mhdw])$`)
+
+// blacklistOptions reads the options of the add subcommand of the blacklist groups, expiresAt is nil without
+// a duration.
+func blacklistOptions(s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.User, string, *time.Time, error) {
+	var user *discordgo.User
+	var reason string
+	var expiresAt *time.Time
+	for _, option := range i.ApplicationCommandData().Options[0].Options[0].Options {
+		switch option.Name {
+		case "user":
+			user = option.UserValue(s)
+		case "reason":
+			reason = strings.TrimSpace(option.StringValue())
+		case "duration":
+			match := blacklistDurationRegexp.FindStringSubmatch(strings.TrimSpace(option.StringValue()))
+			if match == nil {
+				return nil, "", nil, fmt.Errorf("invalid duration %q", option.StringValue())
+			}
+			amount, err := strconv.Atoi(match[1])
+			if err != nil || amount <= 0 {
+				return nil, "", nil, fmt.Errorf("invalid duration %q", option.StringValue())
+			}
+			unit := map[string]time.Duration{"m": time.Minute, "h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour}[match[2]]
+			end := time.Now().Add(time.Duration(amount) * unit)
+			expiresAt = &end
+		}
+	}
+	return user, reason, expiresAt, nil
+}
+
 func (h CsrvbotCommand) handleHelperUnblacklist(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log := logger.GetLoggerFromContext(ctx)
 	selectedUser := i.ApplicationCommandData().Options[0].Options[0].UserValue(s)
@@ -671,6 +1657,7 @@ func (h CsrvbotCommand) handleHelperUnblacklist(ctx context.Context, s *discordg
 		return
 	}
 	log.Infof("%s helper-unblacklisted %s", i.Member.User.Username, selectedUser.Username)
+	h.audit(ctx, s, i, entities.AuditActionHelperUnblacklist, selectedUser.ID)
 	discord.RespondWithMessage(ctx, s, i, "Użytkownik został usunięty z helper-blacklisty")
 	log.Debug("Checking if user should be re-added to helpers")
 	h.HelperService.CheckHelper(ctx, s, i.GuildID, selectedUser.ID)
@@ -738,16 +1725,74 @@ func (h CsrvbotCommand) handleThxInfoChannelSet(ctx context.Context, s *discordg
 		discord.RespondWithMessage(ctx, s, i, "Kanał do powiadomień o thx jest już ustawiony na "+channel.Mention())
 		return
 	}
-	serverConfig.ThxInfoChannel = channelId
-	log.Debug("Updating server config with new thx info channel")
+	serverConfig.ThxInfoChannel = channelId
+	log.Debug("Updating server config with new thx info channel")
+	err = h.ServerRepo.UpdateServerConfig(ctx, &serverConfig)
+	if err != nil {
+		log.WithError(err).Error("handleThxInfoChannelSet h.ServerRepo.UpdateServerConfig", err)
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić kanału")
+		return
+	}
+	log.Infof("%s set thx info channel to %s (%s)", i.Member.User.Username, channel.Name, channel.ID)
+	discord.RespondWithMessage(ctx, s, i, "Ustawiono kanał do powiadomień o thx na "+channel.Mention())
+}
+
+func (h CsrvbotCommand) handleAdminLogChannelSet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	var channelId string
+	if options := i.ApplicationCommandData().Options[0].Options[0].Options; len(options) > 0 {
+		channelId = options[0].ChannelValue(s).ID
+	}
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("handleAdminLogChannelSet h.ServerRepo.GetServerConfigForGuild", err)
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić kanału")
+		return
+	}
+	serverConfig.AdminLogChannel = channelId
+	log.Debug("Updating server config with new admin log channel")
+	err = h.ServerRepo.UpdateServerConfig(ctx, &serverConfig)
+	if err != nil {
+		log.WithError(err).Error("handleAdminLogChannelSet h.ServerRepo.UpdateServerConfig", err)
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić kanału")
+		return
+	}
+	if channelId == "" {
+		log.Infof("%s disabled admin log channel", i.Member.User.Username)
+		discord.RespondWithMessage(ctx, s, i, "Raporty z automatycznych zmian nie będą wysyłane")
+		return
+	}
+	log.Infof("%s set admin log channel to %s", i.Member.User.Username, channelId)
+	discord.RespondWithMessage(ctx, s, i, fmt.Sprintf("Ustawiono kanał na raporty z automatycznych zmian na <#%s>", channelId))
+}
+
+func (h CsrvbotCommand) handleAuditChannelSet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	var channelId string
+	if options := i.ApplicationCommandData().Options[0].Options[0].Options; len(options) > 0 {
+		channelId = options[0].ChannelValue(s).ID
+	}
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("handleAuditChannelSet h.ServerRepo.GetServerConfigForGuild", err)
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić kanału")
+		return
+	}
+	serverConfig.AuditChannel = channelId
+	log.Debug("Updating server config with new audit channel")
 	err = h.ServerRepo.UpdateServerConfig(ctx, &serverConfig)
 	if err != nil {
-		log.WithError(err).Error("handleThxInfoChannelSet h.ServerRepo.UpdateServerConfig", err)
+		log.WithError(err).Error("handleAuditChannelSet h.ServerRepo.UpdateServerConfig", err)
 		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić kanału")
 		return
 	}
-	log.Infof("%s set thx info channel to %s (%s)", i.Member.User.Username, channel.Name, channel.ID)
-	discord.RespondWithMessage(ctx, s, i, "Ustawiono kanał do powiadomień o thx na "+channel.Mention())
+	if channelId == "" {
+		log.Infof("%s disabled audit channel", i.Member.User.Username)
+		discord.RespondWithMessage(ctx, s, i, "Wpisy z dziennika działań administracji nie będą wysyłane na kanał")
+		return
+	}
+	log.Infof("%s set audit channel to %s", i.Member.User.Username, channelId)
+	discord.RespondWithMessage(ctx, s, i, fmt.Sprintf("Ustawiono kanał na wpisy z dziennika działań administracji na <#%s>", channelId))
 }
 
 func (h CsrvbotCommand) handleAdminRoleSet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
@@ -782,63 +1827,107 @@ func (h CsrvbotCommand) handleAdminRoleSet(ctx context.Context, s *discordgo.Ses
 	discord.RespondWithMessage(ctx, s, i, "Ustawiono rolę admina na "+role.Name)
 }
 
-func (h CsrvbotCommand) handleHelperRoleSet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h CsrvbotCommand) handleHelperTierSet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log := logger.GetLoggerFromContext(ctx)
-	roleId := i.ApplicationCommandData().Options[0].Options[0].Options[0].RoleValue(s, i.GuildID).ID
-	role, err := s.State.Role(i.GuildID, roleId)
+	options := i.ApplicationCommandData().Options[0].Options[0].Options
+	role := options[0].RoleValue(s, i.GuildID)
+	amount := int(options[1].IntValue())
+
+	var previousTier *entities.HelperTier
+	previousTiers, err := h.ServerRepo.GetHelperTiers(ctx, i.GuildID)
 	if err != nil {
-		log.WithError(err).Error("handleHelperRoleSet s.State.Role", err)
-		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić roli")
+		log.WithError(err).Error("handleHelperTierSet h.ServerRepo.GetHelperTiers")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić roli helpera")
 		return
 	}
-	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
-	if err != nil {
-		log.WithError(err).Error("handleHelperRoleSet h.ServerRepo.GetServerConfigForGuild", err)
-		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić roli")
-		return
+	for n := range previousTiers {
+		if previousTiers[n].RoleId == role.ID {
+			previousTier = &previousTiers[n]
+		}
+	}
+
+	var tier *entities.HelperTier
+	if amount == 0 {
+		err = h.ServerRepo.RemoveHelperTier(ctx, i.GuildID, role.ID)
+	} else {
+		tier = &entities.HelperTier{GuildId: i.GuildID, RoleId: role.ID, ThxesNeeded: amount}
+		err = h.ServerRepo.SetHelperTier(ctx, tier)
 	}
-	if serverConfig.HelperRoleId == roleId {
-		log.Debug("Helper role is the same as current")
-		discord.RespondWithMessage(ctx, s, i, "Rola helpera jest już ustawiona na "+role.Name)
+	if err != nil {
+		log.WithError(err).Error("handleHelperTierSet h.ServerRepo.SetHelperTier")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić roli helpera")
 		return
 	}
-	serverConfig.HelperRoleId = roleId
-	log.Debug("Updating server config with new helper role")
-	err = h.ServerRepo.UpdateServerConfig(ctx, &serverConfig)
+	log.Infof("%s set helper tier %s to %d thx", i.Member.User.Username, role.ID, amount)
+	h.auditChange(ctx, s, i, entities.AuditActionSettings, HelperTierSubcommand, previousTier, tier)
+
+	tiers, err := h.ServerRepo.GetHelperTiers(ctx, i.GuildID)
 	if err != nil {
-		log.WithError(err).Error("handleHelperRoleSet h.ServerRepo.UpdateServerConfig", err)
-		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić roli")
+		log.WithError(err).Error("handleHelperTierSet h.ServerRepo.GetHelperTiers")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się pobrać ról helpera")
 		return
 	}
-	log.Infof("%s set helper role to %s (%s)", i.Member.User.Username, role.Name, role.ID)
-	discord.RespondWithMessage(ctx, s, i, "Ustawiono rolę helpera na "+role.Name)
+	message := "Role helpera nie są nadawane"
+	if len(tiers) > 0 {
+		message = "**Role helpera:**"
+		for _, tier := range tiers {
+			message += fmt.Sprintf("\n<@&%s> - powyżej %d thx", tier.RoleId, tier.ThxesNeeded)
+		}
+	}
+	discord.RespondWithMessage(ctx, s, i, message)
+
+	log.Debug("Checking helpers after helper tier set")
+	h.HelperService.CheckHelpers(ctx, s, i.GuildID)
 }
 
-func (h CsrvbotCommand) handleHelperThxAmountSet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (h CsrvbotCommand) handleHelpersSet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log := logger.GetLoggerFromContext(ctx)
-	amount := i.ApplicationCommandData().Options[0].Options[0].Options[0].UintValue()
 	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
 	if err != nil {
-		log.WithError(err).Error("handleHelperThxAmountSet h.ServerRepo.GetServerConfigForGuild", err)
-		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić ilości thx")
+		log.WithError(err).Error("handleHelpersSet h.ServerRepo.GetServerConfigForGuild")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić ról helpera")
 		return
 	}
-	if serverConfig.HelperRoleThxesNeeded == int(amount) {
-		log.Debug("Thx amount is the same as current")
-		discord.RespondWithMessage(ctx, s, i, "Ilość thx potrzebna do uzyskania rangi helpera jest już ustawiona na "+strconv.FormatUint(amount, 10))
-		return
+
+	announce := true
+	for _, option := range i.ApplicationCommandData().Options[0].Options[0].Options {
+		switch option.Name {
+		case "days":
+			serverConfig.HelperThxWindowDays = int(option.IntValue())
+		case "channel":
+			serverConfig.HelperAnnouncementChannel = option.ChannelValue(s).ID
+		case "announce":
+			announce = option.BoolValue()
+		}
+	}
+	if !announce {
+		serverConfig.HelperAnnouncementChannel = ""
 	}
-	serverConfig.HelperRoleThxesNeeded = int(amount)
-	log.Debug("Updating server config with new helper thx amount")
+
+	log.Debug("Updating server config with new helper settings")
 	err = h.ServerRepo.UpdateServerConfig(ctx, &serverConfig)
 	if err != nil {
-		log.WithError(err).Error("handleHelperThxAmountSet h.ServerRepo.UpdateServerConfig", err)
-		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić ilości thx")
+		log.WithError(err).Error("handleHelpersSet h.ServerRepo.UpdateServerConfig")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić ról helpera")
 		return
 	}
-	log.Infof("%s set helper thx amount to %d", i.Member.User.Username, amount)
-	discord.RespondWithMessage(ctx, s, i, "Ustawiono ilość thx potrzebną do uzyskania rangi helpera na "+strconv.FormatUint(amount, 10))
-	log.Debug("Checking helpers after helper thx amount set")
+	log.Infof("%s set helper thx window to %d days and announcement channel to %s", i.Member.User.Username, serverConfig.HelperThxWindowDays, serverConfig.HelperAnnouncementChannel)
+
+	message := "Do ról helpera liczą się wszystkie thx"
+	switch {
+	case serverConfig.HelperThxWindowDays == 1:
+		message = "Do ról helpera liczą się thx z ostatniego dnia"
+	case serverConfig.HelperThxWindowDays > 1:
+		message = fmt.Sprintf("Do ról helpera liczą się thx z ostatnich %d dni", serverConfig.HelperThxWindowDays)
+	}
+	if serverConfig.HelperAnnouncementChannel != "" {
+		message += fmt.Sprintf(", zmiany rang są ogłaszane na kanale <#%s>", serverConfig.HelperAnnouncementChannel)
+	} else {
+		message += ", zmiany rang nie są ogłaszane"
+	}
+	discord.RespondWithMessage(ctx, s, i, message)
+
+	log.Debug("Checking helpers after helper settings set")
 	h.HelperService.CheckHelpers(ctx, s, i.GuildID)
 }
 
@@ -1107,6 +2196,221 @@ func (h CsrvbotCommand) handleConditionalGiveawayLevelsSet(ctx context.Context,
 	h.GiveawayService.CreateJoinableGiveaway(ctx, s, guild, true)
 }
 
+func (h CsrvbotCommand) handleThxWeightingSet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	options := i.ApplicationCommandData().Options[0].Options[0].Options
+	strategy := options[0].StringValue()
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("handleThxWeightingSet h.ServerRepo.GetServerConfigForGuild")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić sposobu liczenia losów")
+		return
+	}
+
+	serverConfig.ThxWeighting = strategy
+	if len(options) > 1 {
+		weightingCap := options[1].IntValue()
+		if weightingCap < 1 || weightingCap > 100 {
+			log.Debug("Thx weighting cap is out of range")
+			discord.RespondWithMessage(ctx, s, i, "Maksymalna liczba losów musi być z przedziału od 1 do 100")
+			return
+		}
+		serverConfig.ThxWeightingCap = int(weightingCap)
+	}
+
+	log.Debug("Updating server config with new thx weighting")
+	err = h.ServerRepo.UpdateServerConfig(ctx, &serverConfig)
+	if err != nil {
+		log.WithError(err).Error("handleThxWeightingSet h.ServerRepo.UpdateServerConfig")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić sposobu liczenia losów")
+		return
+	}
+	log.Infof("%s set thx weighting to %s with cap %d", i.Member.User.Username, serverConfig.ThxWeighting, serverConfig.ThxWeightingCap)
+
+	var message string
+	switch strategy {
+	case entities.ThxWeightingPerParticipant:
+		message = "Od teraz każdy uczestnik giveawaya za podziękowania ma jeden los"
+	case entities.ThxWeightingDiminishing:
+		message = fmt.Sprintf("Od teraz kolejne podziękowania dają coraz mniej losów, maksymalnie %d", serverConfig.ThxWeightingCap)
+	default:
+		message = "Od teraz każde podziękowanie to jeden los"
+	}
+	discord.RespondWithMessage(ctx, s, i, message)
+}
+
+func (h CsrvbotCommand) handleExpiredVoucherPolicySet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	policy := i.ApplicationCommandData().Options[0].Options[0].Options[0].StringValue()
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("handleExpiredVoucherPolicySet h.ServerRepo.GetServerConfigForGuild")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić postępowania z wygasłymi kodami")
+		return
+	}
+
+	serverConfig.ExpiredVoucherPolicy = policy
+	log.Debug("Updating server config with new expired voucher policy")
+	err = h.ServerRepo.UpdateServerConfig(ctx, &serverConfig)
+	if err != nil {
+		log.WithError(err).Error("handleExpiredVoucherPolicySet h.ServerRepo.UpdateServerConfig")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić postępowania z wygasłymi kodami")
+		return
+	}
+	log.Infof("%s set expired voucher policy to %s", i.Member.User.Username, policy)
+
+	var message string
+	switch policy {
+	case entities.ExpiredVoucherPolicyReissue:
+		message = "Od teraz zwycięzca, który nie wykorzysta kodu przed jego wygaśnięciem, dostanie nowy kod"
+	case entities.ExpiredVoucherPolicyRedraw:
+		message = "Od teraz nagroda z kodu, który wygaśnie niewykorzystany, będzie rozlosowana ponownie wśród pozostałych uczestników"
+	default:
+		message = "Od teraz wygasłe kody nie będą zastępowane"
+	}
+	discord.RespondWithMessage(ctx, s, i, message)
+}
+
+func (h CsrvbotCommand) handleScheduleSet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	var giveawayType, hours, timezone string
+	for _, option := range i.ApplicationCommandData().Options[0].Options[0].Options {
+		switch option.Name {
+		case "giveaway":
+			giveawayType = option.StringValue()
+		case "hours":
+			hours = strings.TrimSpace(option.StringValue())
+		case "timezone":
+			timezone = strings.TrimSpace(option.StringValue())
+		}
+	}
+
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("handleScheduleSet h.ServerRepo.GetServerConfigForGuild")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić godzin giveawayów")
+		return
+	}
+
+	if hours != "" || timezone != "" {
+		if hours != "" {
+			if giveawayType == "" {
+				discord.RespondWithMessage(ctx, s, i, "Wybierz rodzaj giveawaya, którego godziny chcesz zmienić")
+				return
+			}
+			if hours == "-" {
+				hours = ""
+			}
+			daily, err := schedule.Parse(hours)
+			if err != nil {
+				log.WithError(err).Debug("Invalid giveaway schedule")
+				discord.RespondWithMessage(ctx, s, i, "Nieprawidłowe godziny, podaj je w formacie GG:MM oddzielone przecinkami, np. 6:00,12:00,18:00")
+				return
+			}
+			serverConfig.SetGiveawaySchedule(giveawayType, daily.String())
+		}
+		if timezone != "" {
+			if _, err := time.LoadLocation(timezone); err != nil {
+				log.WithError(err).Debug("Invalid timezone")
+				discord.RespondWithMessage(ctx, s, i, "Nieznana strefa czasowa, podaj ją w formacie IANA, np. Europe/Warsaw")
+				return
+			}
+			serverConfig.Timezone = timezone
+		}
+
+		log.Debug("Updating server config with new giveaway schedule")
+		err = h.ServerRepo.UpdateServerConfig(ctx, &serverConfig)
+		if err != nil {
+			log.WithError(err).Error("handleScheduleSet h.ServerRepo.UpdateServerConfig")
+			discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić godzin giveawayów")
+			return
+		}
+		log.Infof("%s set %s giveaway schedule to %q in %s", i.Member.User.Username, giveawayType, hours, serverConfig.Timezone)
+		h.GiveawayScheduler.ScheduleGuild(ctx, s, i.GuildID)
+	}
+
+	message := fmt.Sprintf("Godziny giveawayów (%s):", serverConfig.Location().String())
+	for _, scheduledType := range services.ScheduledGiveawayTypes {
+		daily, _ := schedule.Parse(serverConfig.GiveawaySchedule(scheduledType))
+		if len(daily) == 0 {
+			message += fmt.Sprintf("\n- %s: wyłączony", discord.GiveawayTypeNames[scheduledType])
+			continue
+		}
+		message += fmt.Sprintf("\n- %s: %s", discord.GiveawayTypeNames[scheduledType], discord.JoinWithAnd(daily.Times()))
+		if next, ok := h.GiveawayScheduler.NextRun(serverConfig, scheduledType); ok {
+			message += fmt.Sprintf(", najbliższy <t:%d:R>", next.Unix())
+		}
+	}
+	discord.RespondWithMessage(ctx, s, i, message)
+}
+
+var voucherPrefixRegexp = regexp.MustCompile(`^[A-Za-z0-9-]*$`)
+
+func (h CsrvbotCommand) handleVoucherSet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	options := i.ApplicationCommandData().Options[0].Options[0].Options
+	giveawayType := options[0].StringValue()
+	voucherConfig := h.VoucherService.GetVoucherConfig(ctx, i.GuildID, giveawayType)
+	previousVoucherConfig := voucherConfig
+
+	var value *float64
+	for _, option := range options[1:] {
+		switch option.Name {
+		case "value":
+			optionValue := option.FloatValue()
+			value = &optionValue
+		case "currency":
+			voucherConfig.Currency = strings.ToUpper(strings.TrimSpace(option.StringValue()))
+		case "expiration":
+			voucherConfig.ExpirationDays = int(option.IntValue())
+		case "prefix":
+			voucherConfig.Prefix = strings.TrimSpace(option.StringValue())
+		case "group":
+			voucherConfig.GroupId = strings.TrimSpace(option.StringValue())
+		}
+	}
+
+	if len(options) > 1 {
+		currency, err := monies.CurrencyByCode(monies.CurrencyCode(voucherConfig.Currency))
+		if err != nil {
+			discord.RespondWithMessage(ctx, s, i, "Nieznana waluta, podaj jej kod ISO 4217, np. PLN")
+			return
+		}
+		if value != nil {
+			// Values are stored in minor units of the currency, e.g. grosz
+			voucherConfig.Value = int(math.Round(*value * math.Pow10(currency.Fraction)))
+		}
+		if voucherConfig.Value <= 0 {
+			discord.RespondWithMessage(ctx, s, i, "Wartość kodu musi być większa od zera")
+			return
+		}
+		if voucherConfig.ExpirationDays < 1 || voucherConfig.ExpirationDays > 3650 {
+			discord.RespondWithMessage(ctx, s, i, "Ważność kodu musi być z przedziału od 1 do 3650 dni")
+			return
+		}
+		if !voucherPrefixRegexp.MatchString(voucherConfig.Prefix) {
+			discord.RespondWithMessage(ctx, s, i, "Prefiks kodu może zawierać tylko litery, cyfry i myślniki")
+			return
+		}
+		if voucherConfig.GroupId == "" {
+			discord.RespondWithMessage(ctx, s, i, "ID grupy kodów nie może być puste")
+			return
+		}
+
+		log.Debug("Updating voucher config")
+		err = h.ServerRepo.SetVoucherConfig(ctx, &voucherConfig)
+		if err != nil {
+			log.WithError(err).Error("handleVoucherSet h.ServerRepo.SetVoucherConfig")
+			discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić kodów giveawaya")
+			return
+		}
+		log.Infof("%s set %s giveaway vouchers to %s for %d days (%s, %s)", i.Member.User.Username, giveawayType, voucherConfig.FormatValue(), voucherConfig.ExpirationDays, voucherConfig.Prefix, voucherConfig.GroupId)
+		h.auditChange(ctx, s, i, entities.AuditActionSettings, VoucherSubcommand+" "+giveawayType, previousVoucherConfig, voucherConfig)
+	}
+
+	discord.RespondWithMessage(ctx, s, i, fmt.Sprintf("Giveaway %s: kody o wartości %s, ważne %d dni, prefiks `%s`, grupa `%s`", strings.ToLower(discord.GiveawayTypeNames[giveawayType]), voucherConfig.FormatValue(), voucherConfig.ExpirationDays, voucherConfig.Prefix, voucherConfig.GroupId))
+}
+
 func (h CsrvbotCommand) handleStatusChannelSet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log := logger.GetLoggerFromContext(ctx)
 	log.Debug("Got command")
@@ -1162,3 +2466,321 @@ func (h CsrvbotCommand) handleStatusChannelSet(ctx context.Context, s *discordgo
 	log.Infof("%s set status channel to %s (%s)", i.Member.User.Username, channel.Name, channel.ID)
 	discord.RespondWithMessage(ctx, s, i, fmt.Sprintf("Ustawiono kanał ze statusem (%s) na %s", language, channel.Mention()))
 }
+
+func (h CsrvbotCommand) handleCustomGiveaway(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	switch i.ApplicationCommandData().Options[0].Options[0].Name {
+	case CreateSubcommand:
+		h.handleCustomGiveawayCreate(ctx, s, i)
+	}
+}
+
+func (h CsrvbotCommand) handleCustomGiveawayCreate(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Options chosen in the command are carried to the modal submit in its custom id
+	level, roleId, voucher := "-", "-", "-"
+	for _, option := range i.ApplicationCommandData().Options[0].Options[0].Options {
+		switch option.Name {
+		case "level":
+			level = strconv.FormatInt(option.IntValue(), 10)
+		case "role":
+			roleId = option.RoleValue(s, i.GuildID).ID
+		case "voucher":
+			voucher = strconv.FormatInt(option.IntValue()*100, 10)
+		}
+	}
+
+	customId := fmt.Sprintf("customgiveaway_create_%s_%s_%s", level, roleId, voucher)
+	discord.RespondWithModal(ctx, s, i, discord.ConstructCustomGiveawayModalComponent(customId))
+}
+
+var customGiveawayDurationRegexp = regexp.MustCompile(`^(\d+)\s*([mhd])$`)
+
+const customGiveawayMaxDuration = 90 * 24 * time.Hour
+
+// parseCustomGiveawayEndTime reads a duration from now or a date in the guild time zone.
+func parseCustomGiveawayEndTime(value string, now time.Time, location *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if match := customGiveawayDurationRegexp.FindStringSubmatch(value); match != nil {
+		amount, err := strconv.Atoi(match[1])
+		if err != nil {
+			return time.Time{}, err
+		}
+		unit := map[string]time.Duration{"m": time.Minute, "h": time.Hour, "d": 24 * time.Hour}[match[2]]
+		return now.Add(time.Duration(amount) * unit), nil
+	}
+
+	return time.ParseInLocation("2006-01-02 15:04", value, location)
+}
+
+func parseOptionalInt(value string) (*int, error) {
+	if value == "-" {
+		return nil, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func (h CsrvbotCommand) HandleModalSubmit(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx).WithCommand(h.Name)
+	adminRole, err := h.ServerRepo.GetAdminRoleForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("Could not get admin role")
+	}
+	if !discord.HasAdminPermissions(ctx, s, i.Member, adminRole, i.GuildID) {
+		log.Debug("User is not an admin")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie masz uprawnień do tej komendy")
+		return
+	}
+
+	data := i.ModalSubmitData()
+	action := strings.Split(data.CustomID, "_")
+	if len(action) != 5 || action[1] != "create" {
+		log.Errorf("Unknown custom giveaway modal %s", data.CustomID)
+		return
+	}
+
+	requiredLevel, err := parseOptionalInt(action[2])
+	if err != nil {
+		log.WithError(err).Error("HandleModalSubmit#parseOptionalInt")
+		return
+	}
+	voucherValue, err := parseOptionalInt(action[4])
+	if err != nil {
+		log.WithError(err).Error("HandleModalSubmit#parseOptionalInt")
+		return
+	}
+	var requiredRoleId *string
+	if action[3] != "-" {
+		requiredRoleId = &action[3]
+	}
+
+	title := strings.TrimSpace(data.Components[0].(*discordgo.Label).Component.(*discordgo.TextInput).Value)
+	prize := strings.TrimSpace(data.Components[1].(*discordgo.Label).Component.(*discordgo.TextInput).Value)
+	winnersValue := strings.TrimSpace(data.Components[2].(*discordgo.Label).Component.(*discordgo.TextInput).Value)
+	endTimeValue := data.Components[3].(*discordgo.Label).Component.(*discordgo.TextInput).Value
+	channelValues := data.Components[4].(*discordgo.Label).Component.(*discordgo.SelectMenu).Values
+
+	if title == "" || prize == "" {
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Tytuł i nagroda nie mogą być puste.")
+		return
+	}
+
+	winnersCount, err := strconv.Atoi(winnersValue)
+	if err != nil || winnersCount < 1 || winnersCount > 50 {
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Liczba zwycięzców musi być liczbą od 1 do 50.")
+		return
+	}
+
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("HandleModalSubmit#h.ServerRepo.GetServerConfigForGuild")
+		return
+	}
+
+	now := time.Now()
+	endTime, err := parseCustomGiveawayEndTime(endTimeValue, now, serverConfig.Location())
+	if err != nil {
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nieprawidłowy koniec giveawayu. Podaj datę w formacie RRRR-MM-DD GG:MM lub czas trwania, np. 90m, 12h, 3d.")
+		return
+	}
+	if !endTime.After(now) || endTime.Sub(now) > customGiveawayMaxDuration {
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Giveaway musi zakończyć się w przyszłości, najpóźniej za 90 dni.")
+		return
+	}
+
+	if len(channelValues) == 0 {
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie wybrano kanału.")
+		return
+	}
+	channel, err := s.Channel(channelValues[0])
+	if err != nil || channel.GuildID != i.GuildID {
+		log.WithError(err).Debug("Could not get custom giveaway channel")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie znaleziono wybranego kanału.")
+		return
+	}
+
+	customGiveaway := &entities.CustomGiveaway{
+		GuildId:          i.GuildID,
+		Title:            title,
+		Prize:            prize,
+		WinnersCount:     winnersCount,
+		ScheduledEndTime: endTime,
+		ChannelId:        channel.ID,
+		RequiredLevel:    requiredLevel,
+		RequiredRoleId:   requiredRoleId,
+		VoucherValue:     voucherValue,
+		CreatedBy:        i.Member.User.ID,
+	}
+
+	var levelRoleId *string
+	if requiredLevel != nil {
+		levelRole, err := discord.GetRoleForLevel(ctx, s, i.GuildID, *requiredLevel)
+		if err != nil {
+			log.WithError(err).Error("HandleModalSubmit#discord.GetRoleForLevel")
+			discord.RespondWithEphemeralMessage(ctx, s, i, "Nie znaleziono roli dla podanego poziomu.")
+			return
+		}
+		levelRoleId = &levelRole.ID
+	}
+
+	embed := discord.ConstructCustomGiveawayEmbed(h.CraftserveUrl, customGiveaway, 0, levelRoleId, false)
+	message, err := s.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+		Embed:      embed,
+		Components: discord.ConstructJoinComponents(false),
+	})
+	if err != nil {
+		log.WithError(err).Error("HandleModalSubmit#s.ChannelMessageSendComplex")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się wysłać wiadomości z giveawayem na wybrany kanał.")
+		return
+	}
+
+	giveaway, err := h.GiveawaysRepo.InsertCustomGiveaway(ctx, customGiveaway, message.ID)
+	if err != nil {
+		log.WithError(err).Error("HandleModalSubmit#h.GiveawaysRepo.InsertCustomGiveaway")
+		err = s.ChannelMessageDelete(channel.ID, message.ID)
+		if err != nil {
+			log.WithError(err).Error("HandleModalSubmit#s.ChannelMessageDelete")
+		}
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się utworzyć giveawayu.")
+		return
+	}
+
+	discord.SetSeedHashFooter(embed, giveaway.Id, giveaway.SeedHash)
+	_, err = s.ChannelMessageEditEmbed(channel.ID, message.ID, embed)
+	if err != nil {
+		log.WithError(err).Error("HandleModalSubmit#s.ChannelMessageEditEmbed")
+	}
+
+	h.GiveawayService.ScheduleCustomGiveaway(ctx, s, giveaway.Id, endTime)
+	log.Infof("%s created custom giveaway #%d ending at %s", i.Member.User.Username, giveaway.Id, endTime.Format(time.DateTime))
+	h.audit(ctx, s, i, entities.AuditActionCustomGiveaway, strconv.Itoa(giveaway.Id))
+	discord.RespondWithEphemeralMessage(ctx, s, i, fmt.Sprintf("Utworzono giveaway #%d na kanale <#%s>, zakończy się <t:%d:f>.", giveaway.Id, channel.ID, endTime.Unix()))
+}
+
+func (h CsrvbotCommand) handleThxLimitsSet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("handleThxLimitsSet h.ServerRepo.GetServerConfigForGuild")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić limitów podziękowań")
+		return
+	}
+
+	for _, option := range i.ApplicationCommandData().Options[0].Options[0].Options {
+		switch option.Name {
+		case "cooldown":
+			serverConfig.ThxCooldownMinutes = int(option.IntValue())
+		case "daily":
+			serverConfig.ThxDailyLimit = int(option.IntValue())
+		case "pending":
+			serverConfig.ThxmePendingLimit = int(option.IntValue())
+		}
+	}
+
+	log.Debug("Updating server config with new thx limits")
+	err = h.ServerRepo.UpdateServerConfig(ctx, &serverConfig)
+	if err != nil {
+		log.WithError(err).Error("handleThxLimitsSet h.ServerRepo.UpdateServerConfig")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić limitów podziękowań")
+		return
+	}
+	log.Infof("%s set thx limits to cooldown %d, daily %d, pending %d", i.Member.User.Username, serverConfig.ThxCooldownMinutes, serverConfig.ThxDailyLimit, serverConfig.ThxmePendingLimit)
+
+	discord.RespondWithMessage(ctx, s, i, fmt.Sprintf("Limity podziękowań:\n%s: %s\n%s: %s\n%s: %s",
+		discord.ThxLimitNames[entities.ThxLimitCooldown], formatThxLimit(serverConfig.ThxCooldownMinutes, " min"),
+		discord.ThxLimitNames[entities.ThxLimitDaily], formatThxLimit(serverConfig.ThxDailyLimit, ""),
+		discord.ThxLimitNames[entities.ThxLimitPendingThxme], formatThxLimit(serverConfig.ThxmePendingLimit, "")))
+}
+
+func (h CsrvbotCommand) handleEligibilitySet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("handleEligibilitySet h.ServerRepo.GetServerConfigForGuild")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić wymagań udziału")
+		return
+	}
+
+	for _, option := range i.ApplicationCommandData().Options[0].Options[0].Options {
+		switch option.Name {
+		case "accountage":
+			serverConfig.MinAccountAgeDays = int(option.IntValue())
+		case "memberage":
+			serverConfig.MinMemberDays = int(option.IntValue())
+		case "role":
+			serverConfig.EligibilityRoleId = option.RoleValue(s, i.GuildID).ID
+		case "clearrole":
+			if option.BoolValue() {
+				serverConfig.EligibilityRoleId = ""
+			}
+		}
+	}
+
+	log.Debug("Updating server config with new eligibility requirements")
+	err = h.ServerRepo.UpdateServerConfig(ctx, &serverConfig)
+	if err != nil {
+		log.WithError(err).Error("handleEligibilitySet h.ServerRepo.UpdateServerConfig")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić wymagań udziału")
+		return
+	}
+	log.Infof("%s set eligibility to account age %d, member age %d, role %q", i.Member.User.Username, serverConfig.MinAccountAgeDays, serverConfig.MinMemberDays, serverConfig.EligibilityRoleId)
+
+	role := "brak"
+	if serverConfig.EligibilityRoleId != "" {
+		role = fmt.Sprintf("<@&%s>", serverConfig.EligibilityRoleId)
+	}
+	discord.RespondWithMessage(ctx, s, i, fmt.Sprintf("Wymagania udziału w giveawayach:\nWiek konta: %s\nCzas na serwerze: %s\nRola: %s",
+		formatEligibilityDays(serverConfig.MinAccountAgeDays), formatEligibilityDays(serverConfig.MinMemberDays), role))
+}
+
+func formatEligibilityDays(days int) string {
+	if days == 0 {
+		return "brak"
+	}
+
+	return fmt.Sprintf("co najmniej %d dni", days)
+}
+
+func (h CsrvbotCommand) handleThxExpirySet(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("handleThxExpirySet h.ServerRepo.GetServerConfigForGuild")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić wygasania podziękowań")
+		return
+	}
+
+	for _, option := range i.ApplicationCommandData().Options[0].Options[0].Options {
+		switch option.Name {
+		case "hours":
+			serverConfig.ThxmeExpiryHours = int(option.IntValue())
+		case "policy":
+			serverConfig.UnreviewedThxPolicy = option.StringValue()
+		}
+	}
+
+	log.Debug("Updating server config with new thx expiry")
+	err = h.ServerRepo.UpdateServerConfig(ctx, &serverConfig)
+	if err != nil {
+		log.WithError(err).Error("handleThxExpirySet h.ServerRepo.UpdateServerConfig")
+		discord.RespondWithMessage(ctx, s, i, "Nie udało się ustawić wygasania podziękowań")
+		return
+	}
+	log.Infof("%s set thxme expiry to %d hours and unreviewed thx policy to %s", i.Member.User.Username, serverConfig.ThxmeExpiryHours, serverConfig.UnreviewedThxPolicy)
+
+	policy := "są przenoszone do następnego giveawaya"
+	if serverConfig.UnreviewedThxPolicy == entities.UnreviewedThxPolicyReject {
+		policy = "są odrzucane"
+	}
+	discord.RespondWithMessage(ctx, s, i, fmt.Sprintf("Prośby /thxme wygasają po %d h, a nierozpatrzone podziękowania po zakończeniu giveawaya %s", serverConfig.ThxmeExpiryHours, policy))
+}
+
+func formatThxLimit(value int, unit string) string {
+	if value == 0 {
+		return "bez limitu"
+	}
+
+	return strconv.Itoa(value) + unit
+}
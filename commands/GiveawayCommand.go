@@ -3,30 +3,43 @@ package commands
 import (
 	"context"
 	"csrvbot/domain/entities"
+	"csrvbot/internal/services"
 	"csrvbot/pkg/discord"
+	"csrvbot/pkg/fairdraw"
 	"csrvbot/pkg/logger"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/bwmarrin/discordgo"
 )
 
 type GiveawayCommand struct {
-	Name          string
-	Description   string
-	DMPermission  bool
-	GiveawayHours string
-	GiveawaysRepo entities.GiveawaysRepo
-	CraftserveUrl string
-	VoucherValue  int
+	Name           string
+	Description    string
+	DMPermission   bool
+	GiveawaysRepo  entities.GiveawaysRepo
+	ServerRepo     entities.ServerRepo
+	CraftserveUrl  string
+	VoucherService *services.VoucherService
 }
 
-func NewGiveawayCommand(giveawaysRepo entities.GiveawaysRepo, giveawayHours, craftserveUrl string, voucherValue int) GiveawayCommand {
+const (
+	// GiveawayCommand Subcommands
+	InfoSubcommand   = "info"
+	VerifySubcommand = "verify"
+)
+
+func NewGiveawayCommand(giveawaysRepo entities.GiveawaysRepo, serverRepo entities.ServerRepo, craftserveUrl string, voucherService *services.VoucherService) GiveawayCommand {
 	return GiveawayCommand{
-		Name:          "giveaway",
-		Description:   "Wyświetla zasady giveawaya",
-		DMPermission:  false,
-		GiveawaysRepo: giveawaysRepo,
-		GiveawayHours: giveawayHours,
-		CraftserveUrl: craftserveUrl,
-		VoucherValue:  voucherValue,
+		Name:           "giveaway",
+		Description:    "Wyświetla zasady giveawaya",
+		DMPermission:   false,
+		GiveawaysRepo:  giveawaysRepo,
+		ServerRepo:     serverRepo,
+		CraftserveUrl:  craftserveUrl,
+		VoucherService: voucherService,
 	}
 }
 
@@ -37,6 +50,26 @@ func (h GiveawayCommand) Register(ctx context.Context, s *discordgo.Session) {
 		Name:         h.Name,
 		Description:  h.Description,
 		DMPermission: &h.DMPermission,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        InfoSubcommand,
+				Description: "Wyświetla zasady giveawaya",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        VerifySubcommand,
+				Description: "Sprawdza, czy zwycięzcy giveawaya zostali wylosowani uczciwie",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "id",
+						Description: "ID giveawaya",
+						Required:    true,
+					},
+				},
+			},
+		},
 	})
 	if err != nil {
 		log.WithError(err).Error("Could not register command")
@@ -44,6 +77,15 @@ func (h GiveawayCommand) Register(ctx context.Context, s *discordgo.Session) {
 }
 
 func (h GiveawayCommand) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	switch i.ApplicationCommandData().Options[0].Name {
+	case InfoSubcommand:
+		h.handleInfo(ctx, s, i)
+	case VerifySubcommand:
+		h.handleVerify(ctx, s, i)
+	}
+}
+
+func (h GiveawayCommand) handleInfo(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log := logger.GetLoggerFromContext(ctx)
 	giveaway, err := h.GiveawaysRepo.GetGiveawayForGuild(ctx, i.GuildID, entities.ThxGiveawayType)
 	if err != nil {
@@ -56,18 +98,20 @@ func (h GiveawayCommand) Handle(ctx context.Context, s *discordgo.Session, i *di
 		return
 	}
 
-	var participantsNames []string
-	for _, participant := range participants {
-		participantsNames = append(participantsNames, participant.UserName)
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("Could not get server config")
+		return
 	}
 
+	embed := discord.ConstructInfoEmbed(h.CraftserveUrl, serverConfig, participants, h.VoucherService.GetVoucherConfig(ctx, serverConfig.GuildId, entities.ThxGiveawayType))
+	discord.SetSeedHashFooter(embed, giveaway.Id, giveaway.SeedHash)
+
 	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Flags: discordgo.MessageFlagsEphemeral,
-			Embeds: []*discordgo.MessageEmbed{
-				discord.ConstructInfoEmbed(h.CraftserveUrl, participantsNames, h.GiveawayHours, h.VoucherValue),
-			},
+			Flags:  discordgo.MessageFlagsEphemeral,
+			Embeds: []*discordgo.MessageEmbed{embed},
 		},
 	})
 	if err != nil {
@@ -75,3 +119,94 @@ func (h GiveawayCommand) Handle(ctx context.Context, s *discordgo.Session, i *di
 		return
 	}
 }
+
+func (h GiveawayCommand) handleVerify(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	giveawayId := int(i.ApplicationCommandData().Options[0].Options[0].IntValue())
+
+	giveaway, err := h.GiveawaysRepo.GetGiveawayById(ctx, giveawayId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.WithError(err).Error("GiveawayCommand#h.GiveawaysRepo.GetGiveawayById")
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) || giveaway.GuildId != i.GuildID {
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie znaleziono giveawaya o podanym ID.")
+		return
+	}
+
+	if giveaway.SeedHash == "" {
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Ten giveaway został rozlosowany przed wprowadzeniem weryfikacji losowania.")
+		return
+	}
+
+	draw, err := h.GiveawaysRepo.GetDrawForGiveaway(ctx, giveaway.Id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.WithError(err).Error("GiveawayCommand#h.GiveawaysRepo.GetDrawForGiveaway")
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) || draw.State != entities.DrawCompletedState {
+		if giveaway.EndTime != nil {
+			discord.RespondWithEphemeralMessage(ctx, s, i, "Ten giveaway zakończył się bez losowania.")
+			return
+		}
+		discord.RespondWithEphemeralMessage(ctx, s, i, fmt.Sprintf("Ten giveaway jeszcze trwa. Ziarno zostanie ujawnione po losowaniu, jego SHA-256 to `%s`.", giveaway.SeedHash))
+		return
+	}
+
+	winners, err := h.GiveawaysRepo.GetDrawWinners(ctx, draw.Id)
+	if err != nil {
+		log.WithError(err).Error("GiveawayCommand#h.GiveawaysRepo.GetDrawWinners")
+		return
+	}
+	winnerIds := make([]string, len(winners))
+	for i, winner := range winners {
+		winnerIds[i] = winner.UserId
+	}
+
+	// Replaying the draw with the recorded skips gives the same picks as the original eligibility checks
+	skipped := make(map[string]bool)
+	for _, userId := range draw.Skipped {
+		skipped[userId] = true
+	}
+	expectedWinnerIds, _ := fairdraw.Pick(giveaway.Seed, draw.Participants, len(winners), entities.DrawsWithReplacement(giveaway.Type), func(userId string) bool {
+		return !skipped[userId]
+	})
+
+	// Replacements of expired codes are not part of the draw, the redraws are listed with their own seeds
+	giveawayWinners, err := h.GiveawaysRepo.GetGiveawayWinners(ctx, giveaway.Id)
+	if err != nil {
+		log.WithError(err).Error("GiveawayCommand#h.GiveawaysRepo.GetGiveawayWinners")
+		return
+	}
+	embed := discord.ConstructDrawVerificationEmbed(h.CraftserveUrl, giveaway.Id, giveaway.Seed, giveaway.SeedHash, len(draw.Participants), draw.Skipped, winnerIds, expectedWinnerIds)
+	discord.AddRedrawsField(embed, giveaway.Seed, giveawayWinners)
+
+	var participantsList strings.Builder
+	for i, userId := range draw.Participants {
+		participantsList.WriteString(fmt.Sprintf("%d. %s\n", i+1, userId))
+	}
+	if len(draw.Skipped) > 0 {
+		participantsList.WriteString("\nPominięci:\n")
+		for _, userId := range draw.Skipped {
+			participantsList.WriteString(userId + "\n")
+		}
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags:  discordgo.MessageFlagsEphemeral,
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Files: []*discordgo.File{
+				{
+					Name:        fmt.Sprintf("giveaway-%d.txt", giveaway.Id),
+					ContentType: "text/plain",
+					Reader:      strings.NewReader(participantsList.String()),
+				},
+			},
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("GiveawayCommand#s.InteractionRespond")
+	}
+}
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/logger"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const profileTopThankers = 5
+
+type ProfileCommand struct {
+	Name          string
+	Description   string
+	DMPermission  bool
+	CraftserveUrl string
+	GiveawaysRepo entities.GiveawaysRepo
+	ServerRepo    entities.ServerRepo
+}
+
+func NewProfileCommand(giveawaysRepo entities.GiveawaysRepo, serverRepo entities.ServerRepo, craftserveUrl string) ProfileCommand {
+	return ProfileCommand{
+		Name:          "profile",
+		Description:   "Statystyki podziękowań użytkownika",
+		DMPermission:  false,
+		CraftserveUrl: craftserveUrl,
+		GiveawaysRepo: giveawaysRepo,
+		ServerRepo:    serverRepo,
+	}
+}
+
+func (h ProfileCommand) Register(ctx context.Context, s *discordgo.Session) {
+	log := logger.GetLoggerFromContext(ctx).WithCommand(h.Name)
+	log.Debug("Registering command")
+	_, err := s.ApplicationCommandCreate(s.State.User.ID, "", &discordgo.ApplicationCommand{
+		Name:         h.Name,
+		Description:  h.Description,
+		DMPermission: &h.DMPermission,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user",
+				Description: "Użytkownik, którego statystyki chcesz zobaczyć, domyślnie Ty",
+				Required:    false,
+			},
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("Could not register command")
+	}
+}
+
+func (h ProfileCommand) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	user := i.Member.User
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "user" {
+			user = option.UserValue(s)
+		}
+	}
+
+	stats, err := h.GiveawaysRepo.GetThxStats(ctx, i.GuildID, user.ID)
+	if err != nil {
+		log.WithError(err).Error("ProfileCommand#h.GiveawaysRepo.GetThxStats")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać statystyk użytkownika")
+		return
+	}
+
+	rank, err := h.GiveawaysRepo.GetThxRank(ctx, i.GuildID, user.ID)
+	if err != nil {
+		log.WithError(err).Error("ProfileCommand#h.GiveawaysRepo.GetThxRank")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać statystyk użytkownika")
+		return
+	}
+
+	thankers, err := h.GiveawaysRepo.GetTopThankers(ctx, i.GuildID, user.ID, profileTopThankers)
+	if err != nil {
+		log.WithError(err).Error("ProfileCommand#h.GiveawaysRepo.GetTopThankers")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać statystyk użytkownika")
+		return
+	}
+
+	thanked, err := h.GiveawaysRepo.GetTopThanked(ctx, i.GuildID, user.ID, profileTopThankers)
+	if err != nil {
+		log.WithError(err).Error("ProfileCommand#h.GiveawaysRepo.GetTopThanked")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać statystyk użytkownika")
+		return
+	}
+
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("ProfileCommand#h.ServerRepo.GetServerConfigForGuild")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać statystyk użytkownika")
+		return
+	}
+	helperTiers, err := h.ServerRepo.GetHelperTiers(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("ProfileCommand#h.ServerRepo.GetHelperTiers")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać statystyk użytkownika")
+		return
+	}
+	helperThxAmount := stats.Accepted
+	if serverConfig.HelperThxWindowDays > 0 {
+		helperThxAmount, err = h.GiveawaysRepo.GetThxAmount(ctx, i.GuildID, user.ID, serverConfig.HelperThxSince(time.Now()))
+		if err != nil {
+			log.WithError(err).Error("ProfileCommand#h.GiveawaysRepo.GetThxAmount")
+			discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać statystyk użytkownika")
+			return
+		}
+	}
+
+	embed := discord.ConstructThxProfileEmbed(h.CraftserveUrl, user, stats, rank, helperTiers, helperThxAmount, thankers, thanked)
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("ProfileCommand#session.InteractionRespond")
+	}
+}
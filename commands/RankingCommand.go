@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	rankingPageSize = 10
+
+	// RankingCommand periods
+	WeekRankingPeriod    = "week"
+	MonthRankingPeriod   = "month"
+	AllTimeRankingPeriod = "all"
+)
+
+var rankingPeriodNames = map[string]string{
+	WeekRankingPeriod:    "ostatnie 7 dni",
+	MonthRankingPeriod:   "ostatnie 30 dni",
+	AllTimeRankingPeriod: "od początku",
+}
+
+type RankingCommand struct {
+	Name          string
+	Description   string
+	DMPermission  bool
+	CraftserveUrl string
+	GiveawaysRepo entities.GiveawaysRepo
+}
+
+func NewRankingCommand(giveawaysRepo entities.GiveawaysRepo, craftserveUrl string) RankingCommand {
+	return RankingCommand{
+		Name:          "ranking",
+		Description:   "Ranking użytkowników z największą liczbą podziękowań",
+		DMPermission:  false,
+		CraftserveUrl: craftserveUrl,
+		GiveawaysRepo: giveawaysRepo,
+	}
+}
+
+func (h RankingCommand) Register(ctx context.Context, s *discordgo.Session) {
+	log := logger.GetLoggerFromContext(ctx).WithCommand(h.Name)
+	log.Debug("Registering command")
+	_, err := s.ApplicationCommandCreate(s.State.User.ID, "", &discordgo.ApplicationCommand{
+		Name:         h.Name,
+		Description:  h.Description,
+		DMPermission: &h.DMPermission,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "period",
+				Description: "Okres, z którego liczone są podziękowania",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{
+						Name:  "Ostatnie 7 dni",
+						Value: WeekRankingPeriod,
+					},
+					{
+						Name:  "Ostatnie 30 dni",
+						Value: MonthRankingPeriod,
+					},
+					{
+						Name:  "Od początku",
+						Value: AllTimeRankingPeriod,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("Could not register command")
+	}
+}
+
+func (h RankingCommand) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	period := AllTimeRankingPeriod
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "period" {
+			period = option.StringValue()
+		}
+	}
+
+	embed, components, err := h.page(ctx, i.GuildID, period, 0)
+	if err != nil {
+		log.WithError(err).Error("RankingCommand#h.page")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać rankingu")
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed},
+			Components:      components,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("RankingCommand#session.InteractionRespond")
+	}
+}
+
+// HandleMessageComponents switches the page of the ranking, the custom ID is ranking_<period>_<page>.
+func (h RankingCommand) HandleMessageComponents(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx).WithCommand(h.Name)
+	action := strings.Split(i.MessageComponentData().CustomID, "_")
+	if len(action) != 3 || rankingPeriodNames[action[1]] == "" {
+		log.Errorf("Invalid ranking button %s", i.MessageComponentData().CustomID)
+		return
+	}
+	page, err := strconv.Atoi(action[2])
+	if err != nil || page < 0 {
+		log.Errorf("Invalid ranking page %s", i.MessageComponentData().CustomID)
+		return
+	}
+
+	embed, components, err := h.page(ctx, i.GuildID, action[1], page)
+	if err != nil {
+		log.WithError(err).Error("RankingCommand.HandleMessageComponents#h.page")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać rankingu")
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("RankingCommand.HandleMessageComponents#session.InteractionRespond")
+	}
+}
+
+func (h RankingCommand) page(ctx context.Context, guildId, period string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	var since time.Time
+	switch period {
+	case WeekRankingPeriod:
+		since = time.Now().AddDate(0, 0, -7)
+	case MonthRankingPeriod:
+		since = time.Now().AddDate(0, 0, -30)
+	}
+
+	total, err := h.GiveawaysRepo.CountThxRanking(ctx, guildId, since)
+	if err != nil {
+		return nil, nil, err
+	}
+	pages := (total + rankingPageSize - 1) / rankingPageSize
+	if page >= pages {
+		page = max(pages-1, 0)
+	}
+
+	ranking, err := h.GiveawaysRepo.GetThxRanking(ctx, guildId, since, page*rankingPageSize, rankingPageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	embed := discord.ConstructThxRankingEmbed(h.CraftserveUrl, rankingPeriodNames[period], ranking, page*rankingPageSize, page, pages)
+	return embed, discord.ConstructPaginationComponents("ranking_"+period, page, pages), nil
+}
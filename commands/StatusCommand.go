@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"csrvbot/domain/entities"
+	"csrvbot/internal/services"
 	"csrvbot/pkg/discord"
 	"csrvbot/pkg/logger"
 	"encoding/json"
@@ -19,17 +20,19 @@ type StatusCommand struct {
 	DMPermission bool
 	ServerRepo   entities.ServerRepo
 	StatusRepo   entities.StatusRepo
+	AuditService *services.AuditService
 }
 
 var statusCache = make(map[string]*entities.Status)
 
-func NewStatusCommand(serverRepo entities.ServerRepo, statusRepo entities.StatusRepo) StatusCommand {
+func NewStatusCommand(serverRepo entities.ServerRepo, statusRepo entities.StatusRepo, auditService *services.AuditService) StatusCommand {
 	return StatusCommand{
 		Name:         "status",
 		Description:  "Zarządza kanałem statusowym",
 		DMPermission: false,
 		ServerRepo:   serverRepo,
 		StatusRepo:   statusRepo,
+		AuditService: auditService,
 	}
 }
 
@@ -359,6 +362,14 @@ func (h StatusCommand) HandleMessageComponents(ctx context.Context, s *discordgo
 			}(channelId, channelName)
 		}
 
+		h.AuditService.Record(ctx, s, &entities.AuditLogEntry{
+			GuildId:   i.GuildID,
+			ActorId:   i.Member.User.ID,
+			ActorName: i.Member.User.Username,
+			Action:    entities.AuditActionStatusPublish,
+			Target:    statusCache[interactionID].ShortName,
+			After:     string(statusCache[interactionID].Content),
+		})
 		delete(statusCache, interactionID)
 
 		discord.RespondFollowUpEphemeralMessage(ctx, s, i, "Kanały statusowe zostały pomyślnie zaktualizowane.")
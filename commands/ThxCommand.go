@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"csrvbot/domain/entities"
+	"csrvbot/internal/services"
 	"csrvbot/pkg/discord"
 	"csrvbot/pkg/logger"
 	"database/sql"
@@ -11,28 +12,30 @@ import (
 )
 
 type ThxCommand struct {
-	Name          string
-	Description   string
-	DMPermission  bool
-	GiveawayHours string
-	CraftserveUrl string
-	VoucherValue  int
-	GiveawaysRepo entities.GiveawaysRepo
-	UserRepo      entities.UserRepo
-	ServerRepo    entities.ServerRepo
+	Name               string
+	Description        string
+	DMPermission       bool
+	CraftserveUrl      string
+	VoucherService     *services.VoucherService
+	ThxAbuseService    *services.ThxAbuseService
+	EligibilityService *services.EligibilityService
+	GiveawaysRepo      entities.GiveawaysRepo
+	UserRepo           entities.UserRepo
+	ServerRepo         entities.ServerRepo
 }
 
-func NewThxCommand(giveawaysRepo entities.GiveawaysRepo, userRepo entities.UserRepo, serverRepo entities.ServerRepo, giveawayHours, craftserveUrl string, voucherValue int) ThxCommand {
+func NewThxCommand(giveawaysRepo entities.GiveawaysRepo, userRepo entities.UserRepo, serverRepo entities.ServerRepo, craftserveUrl string, voucherService *services.VoucherService, thxAbuseService *services.ThxAbuseService, eligibilityService *services.EligibilityService) ThxCommand {
 	return ThxCommand{
-		Name:          "thx",
-		Description:   "Podziękowanie innemu użytkownikowi",
-		DMPermission:  false,
-		GiveawaysRepo: giveawaysRepo,
-		UserRepo:      userRepo,
-		ServerRepo:    serverRepo,
-		GiveawayHours: giveawayHours,
-		CraftserveUrl: craftserveUrl,
-		VoucherValue:  voucherValue,
+		Name:               "thx",
+		Description:        "Podziękowanie innemu użytkownikowi",
+		DMPermission:       false,
+		GiveawaysRepo:      giveawaysRepo,
+		UserRepo:           userRepo,
+		ServerRepo:         serverRepo,
+		CraftserveUrl:      craftserveUrl,
+		VoucherService:     voucherService,
+		ThxAbuseService:    thxAbuseService,
+		EligibilityService: eligibilityService,
 	}
 }
 
@@ -109,16 +112,38 @@ func (h ThxCommand) Handle(ctx context.Context, s *discordgo.Session, i *discord
 		discord.RespondWithMessage(ctx, s, i, "Nie można dziękować botom!")
 		return
 	}
-	isUserBlacklisted, err := h.UserRepo.IsUserBlacklisted(ctx, selectedUser.ID, i.GuildID)
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+	if err != nil {
+		log.WithError(err).Error("handleThxCommand#ServerRepo.GetServerConfigForGuild")
+		return
+	}
+	selectedMember, err := s.GuildMember(i.GuildID, selectedUser.ID)
 	if err != nil {
-		log.WithError(err).Error("handleThxCommand#UserRepo.IsUserBlacklisted")
+		log.WithError(err).Error("handleThxCommand#session.GuildMember")
 		return
 	}
-	if isUserBlacklisted {
-		log.Debug("User is blacklisted")
-		discord.RespondWithMessage(ctx, s, i, "Ten użytkownik jest na czarnej liście i nie może brać udziału :(")
+	ineligibility, err := h.EligibilityService.CheckEligibility(ctx, s, serverConfig, selectedMember, entities.EligibilityRequirements{})
+	if err != nil {
+		log.WithError(err).Error("handleThxCommand#EligibilityService.CheckEligibility")
+		return
+	}
+	if ineligibility != nil {
+		log.Debugf("User is not eligible: %s", ineligibility.Reason)
+		discord.RespondWithEphemeralMessage(ctx, s, i, discord.IneligibilityMessage(ineligibility, i.Locale, false))
+		return
+	}
+	limit, releaseLimits, err := h.ThxAbuseService.CheckThxLimits(ctx, serverConfig, author.ID, selectedUser.ID)
+	if err != nil {
+		log.WithError(err).Error("handleThxCommand#ThxAbuseService.CheckThxLimits")
 		return
 	}
+	defer releaseLimits()
+	if limit != "" {
+		log.Debugf("Thx limit %s hit", limit)
+		discord.RespondWithEphemeralMessage(ctx, s, i, discord.ThxLimitMessage(limit, serverConfig))
+		return
+	}
+
 	giveaway, err := h.GiveawaysRepo.GetGiveawayForGuild(ctx, i.GuildID, entities.ThxGiveawayType)
 	if err != nil {
 		log.WithError(err).Error("handleThxCommand#GiveawaysRepo.GetGiveawayForGuild")
@@ -130,12 +155,7 @@ func (h ThxCommand) Handle(ctx context.Context, s *discordgo.Session, i *discord
 		return
 	}
 
-	var participantsNames []string
-	for _, participant := range participants {
-		participantsNames = append(participantsNames, participant.UserName)
-	}
-
-	embed := discord.ConstructThxEmbed(h.CraftserveUrl, participantsNames, h.GiveawayHours, selectedUser.ID, "", "wait", h.VoucherValue)
+	embed := discord.ConstructThxEmbed(h.CraftserveUrl, serverConfig, participants, selectedUser.ID, "", "wait", h.VoucherService.GetVoucherConfig(ctx, serverConfig.GuildId, entities.ThxGiveawayType))
 
 	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -162,7 +182,7 @@ func (h ThxCommand) Handle(ctx context.Context, s *discordgo.Session, i *discord
 		return
 	}
 
-	err = h.GiveawaysRepo.InsertParticipant(ctx, giveaway.Id, level, guild.ID, selectedUser.ID, selectedUser.Username, &response.ID, &i.ChannelID)
+	err = h.GiveawaysRepo.InsertParticipant(ctx, giveaway.Id, level, guild.ID, selectedUser.ID, selectedUser.Username, &response.ID, &i.ChannelID, &author.ID)
 	if err != nil {
 		log.WithError(err).Error("handleThxCommand#GiveawaysRepo.InsertParticipant")
 		str := "Coś poszło nie tak przy dodawaniu podziękowania :("
@@ -176,11 +196,7 @@ func (h ThxCommand) Handle(ctx context.Context, s *discordgo.Session, i *discord
 	}
 	log.Infof("%s has thanked %s", author.Username, selectedUser.Username)
 
-	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
-	if err != nil {
-		log.WithError(err).Error("handleThxCommand#ServerRepo.GetServerConfigForGuild")
-		return
-	}
+	flags := h.ThxAbuseService.Check(ctx, s, i.GuildID, i.ChannelID, response.ID, author.ID, selectedUser.ID)
 
 	if serverConfig.ThxInfoChannel == "" {
 		log.Warn("ThxInfoChannel is empty")
@@ -194,7 +210,7 @@ func (h ThxCommand) Handle(ctx context.Context, s *discordgo.Session, i *discord
 	}
 
 	if errors.Is(err, sql.ErrNoRows) {
-		notificationMessageId, err := discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, "", i.GuildID, i.ChannelID, response.ID, selectedUser.ID, "", "wait", h.CraftserveUrl)
+		notificationMessageId, err := discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, "", i.GuildID, i.ChannelID, response.ID, selectedUser.ID, "", "wait", h.CraftserveUrl, flags)
 		if err != nil {
 			log.WithError(err).Error("handleThxCommand#discord.NotifyThxOnThxInfoChannel")
 			return
@@ -207,7 +223,7 @@ func (h ThxCommand) Handle(ctx context.Context, s *discordgo.Session, i *discord
 			return
 		}
 	} else {
-		_, err = discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, thxNotification.NotificationMessageId, i.GuildID, i.ChannelID, response.ID, selectedUser.ID, "", "wait", h.CraftserveUrl)
+		_, err = discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, thxNotification.NotificationMessageId, i.GuildID, i.ChannelID, response.ID, selectedUser.ID, "", "wait", h.CraftserveUrl, flags)
 		if err != nil {
 			log.WithError(err).Error("handleThxCommand#discord.NotifyThxOnThxInfoChannel")
 			return
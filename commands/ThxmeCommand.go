@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"csrvbot/domain/entities"
+	"csrvbot/internal/services"
 	"csrvbot/pkg/discord"
 	"csrvbot/pkg/logger"
 	"errors"
@@ -11,24 +12,26 @@ import (
 )
 
 type ThxmeCommand struct {
-	Name          string
-	Description   string
-	DMPermission  bool
-	GiveawayHours string
-	GiveawaysRepo entities.GiveawaysRepo
-	UserRepo      entities.UserRepo
-	ServerRepo    entities.ServerRepo
+	Name               string
+	Description        string
+	DMPermission       bool
+	GiveawaysRepo      entities.GiveawaysRepo
+	UserRepo           entities.UserRepo
+	ServerRepo         entities.ServerRepo
+	ThxAbuseService    *services.ThxAbuseService
+	EligibilityService *services.EligibilityService
 }
 
-func NewThxmeCommand(giveawaysRepo entities.GiveawaysRepo, userRepo entities.UserRepo, serverRepo entities.ServerRepo, giveawayHours string) ThxmeCommand {
+func NewThxmeCommand(giveawaysRepo entities.GiveawaysRepo, userRepo entities.UserRepo, serverRepo entities.ServerRepo, thxAbuseService *services.ThxAbuseService, eligibilityService *services.EligibilityService) ThxmeCommand {
 	return ThxmeCommand{
-		Name:          "thxme",
-		Description:   "Poproszenie użytkownika o podziękowanie",
-		DMPermission:  false,
-		GiveawaysRepo: giveawaysRepo,
-		UserRepo:      userRepo,
-		ServerRepo:    serverRepo,
-		GiveawayHours: giveawayHours,
+		Name:               "thxme",
+		Description:        "Poproszenie użytkownika o podziękowanie",
+		DMPermission:       false,
+		GiveawaysRepo:      giveawaysRepo,
+		UserRepo:           userRepo,
+		ServerRepo:         serverRepo,
+		ThxAbuseService:    thxAbuseService,
+		EligibilityService: eligibilityService,
 	}
 }
 
@@ -105,14 +108,29 @@ func (h ThxmeCommand) Handle(ctx context.Context, s *discordgo.Session, i *disco
 		discord.RespondWithMessage(ctx, s, i, "Nie można prosić o podziękowanie bota!")
 		return
 	}
-	isUserBlacklisted, err := h.UserRepo.IsUserBlacklisted(ctx, author.ID, i.GuildID)
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
 	if err != nil {
-		log.WithError(err).Error("handleThxmeCommand#UserRepo.IsUserBlacklisted")
+		log.WithError(err).Error("handleThxmeCommand#ServerRepo.GetServerConfigForGuild")
 		return
 	}
-	if isUserBlacklisted {
-		log.Debug("Author is blacklisted")
-		discord.RespondWithMessage(ctx, s, i, "Nie możesz poprosić o podziękowanie, gdyż jesteś na czarnej liście!")
+	ineligibility, err := h.EligibilityService.CheckEligibility(ctx, s, serverConfig, i.Member, entities.EligibilityRequirements{})
+	if err != nil {
+		log.WithError(err).Error("handleThxmeCommand#EligibilityService.CheckEligibility")
+		return
+	}
+	if ineligibility != nil {
+		log.Debugf("Author is not eligible: %s", ineligibility.Reason)
+		discord.RespondWithEphemeralMessage(ctx, s, i, discord.IneligibilityMessage(ineligibility, i.Locale, true))
+		return
+	}
+	limit, err := h.ThxAbuseService.CheckThxmeLimits(ctx, serverConfig, author.ID, selectedUser.ID)
+	if err != nil {
+		log.WithError(err).Error("handleThxmeCommand#ThxAbuseService.CheckThxmeLimits")
+		return
+	}
+	if limit != "" {
+		log.Debugf("Thxme limit %s hit", limit)
+		discord.RespondWithEphemeralMessage(ctx, s, i, discord.ThxLimitMessage(limit, serverConfig))
 		return
 	}
 
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/logger"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const winsPageSize = 10
+
+type WinsCommand struct {
+	Name          string
+	Description   string
+	DMPermission  bool
+	CraftserveUrl string
+	GiveawaysRepo entities.GiveawaysRepo
+}
+
+func NewWinsCommand(giveawaysRepo entities.GiveawaysRepo, craftserveUrl string) WinsCommand {
+	return WinsCommand{
+		Name:          "wins",
+		Description:   "Wyświetla historię wygranych we wszystkich giveawayach",
+		DMPermission:  false,
+		CraftserveUrl: craftserveUrl,
+		GiveawaysRepo: giveawaysRepo,
+	}
+}
+
+func (h WinsCommand) Register(ctx context.Context, s *discordgo.Session) {
+	log := logger.GetLoggerFromContext(ctx).WithCommand(h.Name)
+	log.Debug("Registering command")
+	_, err := s.ApplicationCommandCreate(s.State.User.ID, "", &discordgo.ApplicationCommand{
+		Name:         h.Name,
+		Description:  h.Description,
+		DMPermission: &h.DMPermission,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "dm",
+				Description: "Wyślij historię w wiadomości prywatnej",
+				Required:    false,
+			},
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("Could not register command")
+	}
+
+	// /wins replaced /resend, which stays registered until it is removed
+	registered, err := s.ApplicationCommands(s.State.User.ID, "")
+	if err != nil {
+		log.WithError(err).Error("Could not list registered commands")
+		return
+	}
+	for _, command := range registered {
+		if command.Name != "resend" {
+			continue
+		}
+		log.Debug("Removing replaced resend command")
+		err = s.ApplicationCommandDelete(s.State.User.ID, "", command.ID)
+		if err != nil {
+			log.WithError(err).Error("Could not remove resend command")
+		}
+	}
+}
+
+func (h WinsCommand) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx)
+	sendDM := false
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "dm" {
+			sendDM = option.BoolValue()
+		}
+	}
+
+	embed, components, err := h.page(ctx, s, i.Member.User.ID, 0)
+	if err != nil {
+		log.WithError(err).Error("WinsCommand#h.page")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać historii wygranych")
+		return
+	}
+
+	content := ""
+	if sendDM {
+		log.Debug("Trying to create DM channel")
+		dm, err := s.UserChannelCreate(i.Member.User.ID)
+		if err == nil {
+			_, err = s.ChannelMessageSendComplex(dm.ID, &discordgo.MessageSend{
+				Embeds:     []*discordgo.MessageEmbed{embed},
+				Components: components,
+			})
+		}
+		if err == nil {
+			discord.RespondWithEphemeralMessage(ctx, s, i, "Wysłano historię wygranych w wiadomości prywatnej")
+			return
+		}
+		log.WithError(err).Debug("Could not send wins in DM")
+		content = "Nie udało się wysłać historii wygranych, ponieważ masz wyłączone wiadomości prywatne, oto kopia wiadomości:"
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("WinsCommand#session.InteractionRespond")
+	}
+}
+
+// HandleMessageComponents switches the page of the wins list, the buttons are only shown to the user in a DM
+// or an ephemeral message.
+func (h WinsCommand) HandleMessageComponents(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log := logger.GetLoggerFromContext(ctx).WithCommand(h.Name)
+	page, err := strconv.Atoi(strings.TrimPrefix(i.MessageComponentData().CustomID, "wins_"))
+	if err != nil || page < 0 {
+		log.Errorf("Invalid wins page %s", i.MessageComponentData().CustomID)
+		return
+	}
+
+	user := i.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+
+	embed, components, err := h.page(ctx, s, user.ID, page)
+	if err != nil {
+		log.WithError(err).Error("WinsCommand.HandleMessageComponents#h.page")
+		discord.RespondWithEphemeralMessage(ctx, s, i, "Nie udało się pobrać historii wygranych")
+		return
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("WinsCommand.HandleMessageComponents#session.InteractionRespond")
+	}
+}
+
+func (h WinsCommand) page(ctx context.Context, s *discordgo.Session, userId string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	total, err := h.GiveawaysRepo.CountWinsForUser(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+	pages := (total + winsPageSize - 1) / winsPageSize
+	if page >= pages {
+		page = max(pages-1, 0)
+	}
+
+	wins, err := h.GiveawaysRepo.GetWinsForUser(ctx, userId, page*winsPageSize, winsPageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	guildNames := make(map[string]string)
+	for _, win := range wins {
+		if guild, err := s.State.Guild(win.GuildId); err == nil {
+			guildNames[win.GuildId] = guild.Name
+		}
+	}
+
+	return discord.ConstructWinsEmbed(h.CraftserveUrl, wins, guildNames, page, pages, total), discord.ConstructPaginationComponents("wins", page, pages), nil
+}
@@ -0,0 +1,88 @@
+package entities
+
+import (
+	"context"
+	"time"
+)
+
+// Administrative actions recorded in the audit log.
+const (
+	AuditActionBlacklist         = "blacklist"
+	AuditActionUnblacklist       = "unblacklist"
+	AuditActionHelperBlacklist   = "helper_blacklist"
+	AuditActionHelperUnblacklist = "helper_unblacklist"
+	AuditActionSettings          = "settings"        // Target is the name of the setting
+	AuditActionGiveawayStart     = "giveaway_start"  // Target is the giveaway type
+	AuditActionCustomGiveaway    = "custom_giveaway" // Target is the giveaway id
+	AuditActionThxDelete         = "thx_delete"
+	AuditActionThxAccept         = "thx_accept"
+	AuditActionThxReject         = "thx_reject"
+	AuditActionStatusPublish     = "status_publish" // Target is the short name of the status
+)
+
+// AuditActions lists the audit log actions in the order they are offered as filters.
+var AuditActions = []string{
+	AuditActionBlacklist,
+	AuditActionUnblacklist,
+	AuditActionHelperBlacklist,
+	AuditActionHelperUnblacklist,
+	AuditActionSettings,
+	AuditActionGiveawayStart,
+	AuditActionCustomGiveaway,
+	AuditActionThxDelete,
+	AuditActionThxAccept,
+	AuditActionThxReject,
+	AuditActionStatusPublish,
+}
+
+// AuditLogEntry records an administrative action, Target is a user id unless the action says otherwise. Before and
+// After hold the changed values, if any. Actions taken by the bot on its own, like lifting an expired blacklist, have
+// no ActorId.
+type AuditLogEntry struct {
+	Id        int       `json:"id"`
+	GuildId   string    `json:"guildId"`
+	ActorId   string    `json:"actorId"`
+	ActorName string    `json:"actorName"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target"`
+	Before    string    `json:"before"`
+	After     string    `json:"after"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AuditLogFilter selects the audit log entries of a guild, empty fields match every entry. UserId matches both the
+// actor and the target.
+type AuditLogFilter struct {
+	GuildId string
+	UserId  string
+	Action  string
+	Since   time.Time
+	Until   time.Time
+}
+
+// Matches reports whether the entry is selected by the filter.
+func (f AuditLogFilter) Matches(entry AuditLogEntry) bool {
+	if entry.GuildId != f.GuildId {
+		return false
+	}
+	if f.UserId != "" && entry.ActorId != f.UserId && entry.Target != f.UserId {
+		return false
+	}
+	if f.Action != "" && entry.Action != f.Action {
+		return false
+	}
+	if !f.Since.IsZero() && entry.CreatedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.CreatedAt.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+type AuditLogRepo interface {
+	InsertAuditLogEntry(ctx context.Context, entry *AuditLogEntry) error
+	// GetAuditLogEntries returns the entries selected by the filter, newest first.
+	GetAuditLogEntries(ctx context.Context, filter AuditLogFilter, offset, limit int) ([]AuditLogEntry, error)
+	CountAuditLogEntries(ctx context.Context, filter AuditLogFilter) (int, error)
+}
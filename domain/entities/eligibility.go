@@ -0,0 +1,24 @@
+package entities
+
+// Reasons for which a member cannot take part in the giveaways of a guild.
+const (
+	IneligibleBlacklisted = "blacklisted" // The member is on the blacklist of the guild
+	IneligibleAccountAge  = "account_age" // The Discord account of the member is too new
+	IneligibleMemberAge   = "member_age"  // The member joined the guild too recently
+	IneligibleRole        = "role"        // The member is missing a required role
+	IneligibleLevel       = "level"       // The member has a lower level than required
+)
+
+// Ineligibility explains why a member cannot take part in a giveaway.
+type Ineligibility struct {
+	Reason string `json:"reason"`
+	Days   int    `json:"days"`   // the required age, for IneligibleAccountAge and IneligibleMemberAge
+	RoleId string `json:"roleId"` // the missing role, for IneligibleRole
+	Level  int    `json:"level"`  // the required level, for IneligibleLevel
+}
+
+// EligibilityRequirements are the requirements of a single giveaway, checked on top of the ones of the guild.
+type EligibilityRequirements struct {
+	RoleId *string `json:"roleId"`
+	Level  *int    `json:"level"`
+}
@@ -3,6 +3,7 @@ package entities
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 )
 
@@ -11,8 +12,30 @@ const (
 	LevelGiveawayType   = "level"
 	MessageGiveawayType = "message"
 	ThxGiveawayType     = "thx"
+	CustomGiveawayType  = "custom"
 )
 
+const (
+	DrawInProgressState = "in_progress"
+	DrawCompletedState  = "completed"
+)
+
+// Redemption statuses of the voucher given to a winner, checked with the Craftserve admin API.
+const (
+	VoucherStatusUnknown   = "unknown" // not checked yet
+	VoucherStatusUnclaimed = "unclaimed"
+	VoucherStatusRedeemed  = "redeemed"
+	VoucherStatusExpired   = "expired"  // expired without being redeemed
+	VoucherStatusReplaced  = "replaced" // expired and reissued or redrawn by the guild policy
+)
+
+var ErrWinnerCodeAlreadySet = errors.New("winner code already set")
+
+// DrawsWithReplacement reports whether a user can win more than once in a single draw of the giveaway type.
+func DrawsWithReplacement(giveawayType string) bool {
+	return giveawayType == ThxGiveawayType || giveawayType == MessageGiveawayType
+}
+
 type Giveaway struct {
 	Id            int        `json:"id"`
 	Type          string     `json:"type"`
@@ -21,6 +44,8 @@ type Giveaway struct {
 	GuildId       string     `json:"guildId"`
 	InfoMessageId *string    `json:"infoMessageId"`
 	Level         *int       `json:"level"`
+	Seed          string     `json:"-"` // secret until the giveaway ends
+	SeedHash      string     `json:"seedHash"`
 }
 
 type GiveawayParticipant struct {
@@ -37,13 +62,75 @@ type GiveawayParticipant struct {
 	AcceptTime   *time.Time     `json:"acceptTime"`
 	AcceptUser   sql.NullString `json:"acceptUser"`
 	AcceptUserId sql.NullString `json:"acceptUserId"`
+	ThankerId    *string        `json:"thankerId"`
 }
 
 type GiveawayWinner struct {
 	Id         int    `json:"id"`
 	GiveawayId int    `json:"giveawayId"`
+	DrawId     *int   `json:"drawId"`
 	UserId     string `json:"userId"`
-	Code       string `json:"code"`
+	UserName   string `json:"userName"`
+	Code       string `json:"code"` // empty until the code is issued
+	Notified   bool   `json:"notified"`
+
+	RedemptionStatus string     `json:"redemptionStatus"`
+	ExpiresAt        *time.Time `json:"expiresAt"`
+	RedeemedAt       *time.Time `json:"redeemedAt"`
+	StatusCheckedAt  *time.Time `json:"statusCheckedAt"`
+	ReplacesWinnerId *int       `json:"replacesWinnerId"` // set on winners created by the expired voucher policy
+
+	// Value of the voucher, nil for codes issued before it was recorded and prizes handed out by the admins
+	VoucherValue    *int    `json:"voucherValue"`
+	VoucherCurrency *string `json:"voucherCurrency"`
+}
+
+// FormatVoucherValue returns the value of the voucher for display, or an empty string if it is unknown.
+func (w GiveawayWinner) FormatVoucherValue() string {
+	if w.VoucherValue == nil || w.VoucherCurrency == nil {
+		return ""
+	}
+
+	return VoucherConfig{Value: *w.VoucherValue, Currency: *w.VoucherCurrency}.FormatValue()
+}
+
+// GiveawayWin is a winner with the giveaway it was won in.
+type GiveawayWin struct {
+	GiveawayWinner
+	GuildId         string     `json:"guildId"`
+	GiveawayType    string     `json:"giveawayType"`
+	GiveawayEndTime *time.Time `json:"giveawayEndTime"`
+}
+
+// GiveawayDraw is a persisted draw with its winners chosen up front, so an interrupted finish can be resumed.
+type GiveawayDraw struct {
+	Id         int        `json:"id"`
+	GiveawayId int        `json:"giveawayId"`
+	GuildId    string     `json:"guildId"`
+	State      string     `json:"state"`
+	StartTime  time.Time  `json:"startTime"`
+	EndTime    *time.Time `json:"endTime"`
+
+	// Ordered participants the winners were picked from and the picked users skipped as ineligible
+	Participants []string `json:"participants"`
+	Skipped      []string `json:"skipped"`
+
+	AnnouncementMessageId *string `json:"announcementMessageId"` // nil until the winners are announced
+}
+
+// CustomGiveaway holds the details of a one-off giveaway created by an admin, which ends on its own timer.
+type CustomGiveaway struct {
+	GiveawayId       int       `json:"giveawayId"`
+	GuildId          string    `json:"guildId"`
+	Title            string    `json:"title"`
+	Prize            string    `json:"prize"`
+	WinnersCount     int       `json:"winnersCount"`
+	ScheduledEndTime time.Time `json:"scheduledEndTime"`
+	ChannelId        string    `json:"channelId"`
+	RequiredLevel    *int      `json:"requiredLevel"`
+	RequiredRoleId   *string   `json:"requiredRoleId"`
+	VoucherValue     *int      `json:"voucherValue"` // nil if the prize is not a Craftserve voucher
+	CreatedBy        string    `json:"createdBy"`
 }
 
 type ThxParticipantCandidate struct {
@@ -59,6 +146,7 @@ type ThxParticipantCandidate struct {
 	ChannelId             string       `json:"channelId"`
 	IsAccepted            sql.NullBool `json:"isAccepted"`
 	AcceptTime            *time.Time   `json:"acceptTime"`
+	CreatedAt             time.Time    `json:"createdAt"`
 }
 
 type ThxNotification struct {
@@ -67,11 +155,62 @@ type ThxNotification struct {
 	NotificationMessageId string `json:"notificationMessageId"`
 }
 
+// ThxAbuseFlag marks a thx which looks farmed, the flags are shown to the admins on the thx notification.
+type ThxAbuseFlag string
+
+const (
+	ThxAbuseFlagReciprocal     ThxAbuseFlag = "reciprocal"      // The recipient recently thanked the thanker
+	ThxAbuseFlagBurst          ThxAbuseFlag = "burst"           // The thanker gave many thanks in a short time
+	ThxAbuseFlagNewMember      ThxAbuseFlag = "new_member"      // The thanker or the recipient joined the guild recently
+	ThxAbuseFlagNoConversation ThxAbuseFlag = "no_conversation" // The users did not write in the channel recently
+)
+
+// ThxAbuseCheck records who thanked whom with a thx message and the abuse flags raised for it.
+type ThxAbuseCheck struct {
+	Id          int            `json:"id"`
+	GuildId     string         `json:"guildId"`
+	MessageId   string         `json:"messageId"`
+	ChannelId   string         `json:"channelId"`
+	ThankerId   string         `json:"thankerId"`
+	RecipientId string         `json:"recipientId"`
+	Flags       []ThxAbuseFlag `json:"flags"`
+	CreatedAt   time.Time      `json:"createdAt"`
+}
+
+// Thx limits of a guild, a thx over a limit is refused and recorded as a ThxLimitHit.
+const (
+	ThxLimitCooldown     = "cooldown"      // The thanker thanked the same user too recently
+	ThxLimitDaily        = "daily"         // The thanker gave the maximum number of thanks today
+	ThxLimitPendingThxme = "pending_thxme" // The requester has too many unanswered /thxme requests
+)
+
+// ThxLimitHit records a thx or /thxme request refused by the thx limits of the guild.
+type ThxLimitHit struct {
+	Id           int       `json:"id"`
+	GuildId      string    `json:"guildId"`
+	UserId       string    `json:"userId"`
+	TargetUserId string    `json:"targetUserId"`
+	Limit        string    `json:"limit"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
 type ThxParticipantWithThxAmount struct {
 	UserId    string `json:"userId"`
 	ThxAmount int    `json:"thxAmount"`
 }
 
+// ThxStats counts the thanks received by a user in a guild, pending ones are not reviewed by an admin yet.
+type ThxStats struct {
+	Total    int `json:"total"`
+	Accepted int `json:"accepted"`
+	Rejected int `json:"rejected"`
+	Pending  int `json:"pending"`
+	// Thankers counts the distinct members whose thanks for the user were accepted.
+	Thankers int `json:"thankers"`
+	// Given counts the accepted thanks the user gave to others.
+	Given int `json:"given"`
+}
+
 type DailyUserMessages struct {
 	Id      int    `json:"id"`
 	UserId  string `json:"userId"`
@@ -87,17 +226,54 @@ type GiveawaysRepo interface {
 	GetParticipantsForGiveaway(ctx context.Context, giveawayId int, accepted *bool) ([]GiveawayParticipant, error)
 	CountParticipantsForGiveaway(ctx context.Context, giveawayId int) (int, error)
 	InsertGiveaway(ctx context.Context, guildId string, messageId *string, giveawayType string, level *int) error
-	InsertParticipant(ctx context.Context, giveawayId, level int, guildId, userId, userName string, messageId, channelId *string) error
+	InsertParticipant(ctx context.Context, giveawayId, level int, guildId, userId, userName string, messageId, channelId, thankerId *string) error
 	InsertWinner(ctx context.Context, giveawayId int, userId, code string) error
 	FinishGiveaway(ctx context.Context, giveaway *Giveaway, messageId *string) error
 	HasWonGiveawayByMessageId(ctx context.Context, messageId, userId string) (bool, error)
 	GetCodeForInfoMessage(ctx context.Context, messageId, userId string) (string, error)
 	GetGiveawayByMessageId(ctx context.Context, messageId string) (*Giveaway, error)
 
+	// Draws
+	StartDraw(ctx context.Context, giveaway *Giveaway, participants, skipped []string, winners []GiveawayWinner) (*GiveawayDraw, error)
+	GetDrawForGiveaway(ctx context.Context, giveawayId int) (*GiveawayDraw, error)
+	GetInProgressDraws(ctx context.Context) ([]GiveawayDraw, error)
+	// GetDrawWinners returns the winners picked by the draw, without the winners replacing them after their codes expired.
+	GetDrawWinners(ctx context.Context, drawId int) ([]GiveawayWinner, error)
+	GetWinner(ctx context.Context, winnerId int) (*GiveawayWinner, error)
+	// SetWinnerCode stores the code with the value and expiry of the voucher, or returns ErrWinnerCodeAlreadySet.
+	SetWinnerCode(ctx context.Context, winnerId int, code string, voucherConfig VoucherConfig, expiresAt time.Time) error
+	SetWinnerNotified(ctx context.Context, winnerId int) error
+	SetDrawAnnouncement(ctx context.Context, drawId int, messageId string) error
+	GetGiveawayWinners(ctx context.Context, giveawayId int) ([]GiveawayWinner, error)
+	CompleteDraw(ctx context.Context, draw *GiveawayDraw, giveaway *Giveaway, messageId *string) error
+	RollbackDraw(ctx context.Context, draw *GiveawayDraw) error
+
+	// Redemption
+	// GetWinnersToCheck returns winners with an issued, not yet redeemed or expired code, last checked before checkedBefore
+	// or past their expiry.
+	GetWinnersToCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]GiveawayWinner, error)
+	UpdateWinnerRedemption(ctx context.Context, winner *GiveawayWinner) error
+	// ReplaceWinner marks the winner as replaced and inserts the replacement winner in the same draw.
+	ReplaceWinner(ctx context.Context, winner *GiveawayWinner, replacement *GiveawayWinner) error
+	GetUnclaimedWinners(ctx context.Context, guildId string) ([]GiveawayWin, error)
+
+	// Pending vouchers
+	AddPendingVoucher(ctx context.Context, pendingVoucher *PendingVoucher) error
+	GetDuePendingVouchers(ctx context.Context, now time.Time, limit int) ([]PendingVoucher, error)
+	UpdatePendingVoucher(ctx context.Context, pendingVoucher *PendingVoucher) error
+
+	// Custom
+	InsertCustomGiveaway(ctx context.Context, customGiveaway *CustomGiveaway, messageId string) (*Giveaway, error)
+	GetCustomGiveaway(ctx context.Context, giveawayId int) (*CustomGiveaway, error)
+	GetUnfinishedCustomGiveaways(ctx context.Context) ([]CustomGiveaway, error)
+
 	// Thx
 	InsertParticipantCandidate(ctx context.Context, guildId, guildName, candidateId, candidateName, approverId, approverName, channelId, messageId string, giveawayId int) error
-	GetParticipantsWithThxAmount(ctx context.Context, guildId string, minThxAmount int) ([]ThxParticipantWithThxAmount, error)
-	HasThxAmount(ctx context.Context, guildId, memberId string, minThxAmount int) (bool, error)
+	// GetParticipantsWithThxAmount returns the users with more than minThxAmount accepted thanks since the given time,
+	// zero for all time.
+	GetParticipantsWithThxAmount(ctx context.Context, guildId string, minThxAmount int, since time.Time) ([]ThxParticipantWithThxAmount, error)
+	// GetThxAmount counts the accepted thanks of the member since the given time, zero for all time.
+	GetThxAmount(ctx context.Context, guildId, memberId string, since time.Time) (int, error)
 	GetThxNotification(ctx context.Context, messageId string) (ThxNotification, error)
 	InsertThxNotification(ctx context.Context, thxMessageId, notificationMessageId string) error
 	IsThxMessage(ctx context.Context, messageId string) (bool, error)
@@ -105,11 +281,41 @@ type GiveawaysRepo interface {
 	GetParticipant(ctx context.Context, messageId string) (*GiveawayParticipant, error)
 	GetParticipantCandidate(ctx context.Context, messageId string) (ThxParticipantCandidate, error)
 	UpdateParticipantCandidate(ctx context.Context, participantCandidate *ThxParticipantCandidate, isAccepted bool) error
+	// GetExpiredParticipantCandidates returns the unanswered /thxme requests created before the given time, oldest first.
+	GetExpiredParticipantCandidates(ctx context.Context, guildId string, createdBefore time.Time, limit int) ([]ThxParticipantCandidate, error)
+	// GetUnreviewedParticipants returns the thx still awaiting review in finished thx giveaways of the guild.
+	GetUnreviewedParticipants(ctx context.Context, guildId string, limit int) ([]GiveawayParticipant, error)
+	MoveParticipant(ctx context.Context, participant *GiveawayParticipant, giveawayId int) error
+	// GetPendingParticipants returns the thx of the giveaway still awaiting review, oldest first.
+	GetPendingParticipants(ctx context.Context, giveawayId int) ([]GiveawayParticipant, error)
 	IsGiveawayEnded(ctx context.Context, giveawayId int) (bool, error)
 	GetGiveawayById(ctx context.Context, giveawayId int) (*Giveaway, error)
-	GetLastCodesForUser(ctx context.Context, userId, giveawayType string, limit int) ([]string, error)
+	// GetWinsForUser returns the wins of the user in all guilds, newest first.
+	GetWinsForUser(ctx context.Context, userId string, offset, limit int) ([]GiveawayWin, error)
+	CountWinsForUser(ctx context.Context, userId string) (int, error)
 	RemoveAllThxParticipantEntries(ctx context.Context, giveawayId int, participantId string) error
 	UpdateParticipant(ctx context.Context, participant *GiveawayParticipant, acceptUserId, acceptUsername string, isAccepted bool) error
+	// GetThxRanking returns the users with the most accepted thanks received since the given time, zero for all time.
+	GetThxRanking(ctx context.Context, guildId string, since time.Time, offset, limit int) ([]ThxParticipantWithThxAmount, error)
+	CountThxRanking(ctx context.Context, guildId string, since time.Time) (int, error)
+	GetThxStats(ctx context.Context, guildId, userId string) (ThxStats, error)
+	// GetThxRank returns the position of the user in the all-time ranking, or 0 without accepted thanks.
+	GetThxRank(ctx context.Context, guildId, userId string) (int, error)
+	// GetTopThankers returns who thanked the user most. Thanks given before the thanker was recorded are not counted.
+	GetTopThankers(ctx context.Context, guildId, userId string, limit int) ([]ThxParticipantWithThxAmount, error)
+	// GetTopThanked returns who the thanker thanked most.
+	GetTopThanked(ctx context.Context, guildId, thankerId string, limit int) ([]ThxParticipantWithThxAmount, error)
+	InsertThxAbuseCheck(ctx context.Context, check *ThxAbuseCheck) error
+	GetThxAbuseCheck(ctx context.Context, messageId string) (*ThxAbuseCheck, error)
+	// CountThanksGiven counts the thanks given by the thanker since the given time. Thanks given before the thanker was
+	// recorded are not counted.
+	CountThanksGiven(ctx context.Context, guildId, thankerId string, since time.Time) (int, error)
+	// CountThanksBetween counts the thanks given by the thanker to the recipient since the given time.
+	CountThanksBetween(ctx context.Context, guildId, thankerId, recipientId string, since time.Time) (int, error)
+	CountPendingParticipantCandidates(ctx context.Context, guildId, candidateId string) (int, error)
+	InsertThxLimitHit(ctx context.Context, hit *ThxLimitHit) error
+	// GetThxLimitHits returns the latest limit hits in the guild, newest first.
+	GetThxLimitHits(ctx context.Context, guildId string, limit int) ([]ThxLimitHit, error)
 
 	// Message
 	UpdateUserDailyMessageCount(ctx context.Context, userId string, guildId string) error
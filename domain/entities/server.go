@@ -3,23 +3,214 @@ package entities
 import (
 	"context"
 	"encoding/json"
+	"math/bits"
+	"sort"
+	"time"
 )
 
 type ServerConfig struct {
-	Id                           int             `json:"id"`
-	GuildId                      string          `json:"guildId"`
-	AdminRoleId                  string          `json:"adminRoleId"`
-	StatusChannelsId             json.RawMessage `json:"statusChannelsId"`
-	MainChannel                  string          `json:"mainChannel"`
-	ThxInfoChannel               string          `json:"thxInfoChannel"`
-	HelperRoleId                 string          `json:"helperRoleId"`
-	HelperRoleThxesNeeded        int             `json:"helperRoleThxesNeeded"`
-	MessageGiveawayWinners       int             `json:"messageGiveawayWinners"`
-	UnconditionalGiveawayChannel string          `json:"unconditionalGiveawayChannel"`
-	UnconditionalGiveawayWinners int             `json:"unconditionalGiveawayWinners"`
-	ConditionalGiveawayChannel   string          `json:"conditionalGiveawayChannel"`
-	ConditionalGiveawayWinners   int             `json:"conditionalGiveawayWinners"`
-	ConditionalGiveawayLevels    json.RawMessage `json:"conditionalGiveawayLevels"`
+	Id                            int             `json:"id"`
+	GuildId                       string          `json:"guildId"`
+	AdminRoleId                   string          `json:"adminRoleId"`
+	StatusChannelsId              json.RawMessage `json:"statusChannelsId"`
+	MainChannel                   string          `json:"mainChannel"`
+	ThxInfoChannel                string          `json:"thxInfoChannel"`
+	MessageGiveawayWinners        int             `json:"messageGiveawayWinners"`
+	UnconditionalGiveawayChannel  string          `json:"unconditionalGiveawayChannel"`
+	UnconditionalGiveawayWinners  int             `json:"unconditionalGiveawayWinners"`
+	ConditionalGiveawayChannel    string          `json:"conditionalGiveawayChannel"`
+	ConditionalGiveawayWinners    int             `json:"conditionalGiveawayWinners"`
+	ConditionalGiveawayLevels     json.RawMessage `json:"conditionalGiveawayLevels"`
+	ThxWeighting                  string          `json:"thxWeighting"`
+	ThxWeightingCap               int             `json:"thxWeightingCap"`
+	Timezone                      string          `json:"timezone"`
+	ThxGiveawaySchedule           string          `json:"thxGiveawaySchedule"`
+	MessageGiveawaySchedule       string          `json:"messageGiveawaySchedule"`
+	UnconditionalGiveawaySchedule string          `json:"unconditionalGiveawaySchedule"`
+	ConditionalGiveawaySchedule   string          `json:"conditionalGiveawaySchedule"`
+	ExpiredVoucherPolicy          string          `json:"expiredVoucherPolicy"`
+	// The thx limits are disabled when set to 0
+	ThxCooldownMinutes        int    `json:"thxCooldownMinutes"`
+	ThxDailyLimit             int    `json:"thxDailyLimit"`
+	ThxmePendingLimit         int    `json:"thxmePendingLimit"`
+	ThxmeExpiryHours          int    `json:"thxmeExpiryHours"`
+	UnreviewedThxPolicy       string `json:"unreviewedThxPolicy"`
+	HelperThxWindowDays       int    `json:"helperThxWindowDays"` // 0 counts the thanks of all time
+	HelperAnnouncementChannel string `json:"helperAnnouncementChannel"`
+	AdminLogChannel           string `json:"adminLogChannel"`
+	AuditChannel              string `json:"auditChannel"`
+	// The eligibility requirements are disabled when left unset
+	MinAccountAgeDays int    `json:"minAccountAgeDays"`
+	MinMemberDays     int    `json:"minMemberDays"`
+	EligibilityRoleId string `json:"eligibilityRoleId"`
+}
+
+const (
+	ThxWeightingPerParticipant = "participant" // one entry per participant
+	ThxWeightingPerThx         = "thx"         // one entry per accepted thx
+	ThxWeightingDiminishing    = "diminishing" // 1 + log2(thx) entries, up to ThxWeightingCap
+
+	DefaultThxWeightingCap = 5
+
+	DefaultTimezone                      = "Europe/Warsaw"
+	DefaultThxGiveawaySchedule           = "06:00,12:00,18:00"
+	DefaultMessageGiveawaySchedule       = "06:00,12:00,18:00"
+	DefaultUnconditionalGiveawaySchedule = "12:00"
+	DefaultConditionalGiveawaySchedule   = "18:00"
+)
+
+// Policies applied when a winner's voucher expires without being redeemed.
+const (
+	ExpiredVoucherPolicyNone    = "none"
+	ExpiredVoucherPolicyReissue = "reissue" // a new voucher for the same winner
+	ExpiredVoucherPolicyRedraw  = "redraw"  // a new winner among the participants of the draw
+)
+
+// Policies applied to the thx still awaiting review when their giveaway finishes.
+const (
+	UnreviewedThxPolicyReject    = "reject"    // the thx are rejected
+	UnreviewedThxPolicyCarryOver = "carryover" // the thx of the giveaway which just finished are moved to the next one
+
+	DefaultThxmeExpiryHours = 24
+)
+
+// Location returns the time zone in which the guild giveaway schedules are set.
+func (c ServerConfig) Location() *time.Location {
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil || c.Timezone == "" {
+		location, err = time.LoadLocation(DefaultTimezone)
+		if err != nil {
+			return time.UTC
+		}
+	}
+
+	return location
+}
+
+// GiveawaySchedule returns the daily schedule of the given giveaway type as a comma separated list of HH:MM times.
+func (c ServerConfig) GiveawaySchedule(giveawayType string) string {
+	switch giveawayType {
+	case ThxGiveawayType:
+		return c.ThxGiveawaySchedule
+	case MessageGiveawayType:
+		return c.MessageGiveawaySchedule
+	case JoinedGiveawayType:
+		return c.UnconditionalGiveawaySchedule
+	case LevelGiveawayType:
+		return c.ConditionalGiveawaySchedule
+	default:
+		return ""
+	}
+}
+
+// SetGiveawaySchedule sets the daily schedule of the given giveaway type.
+func (c *ServerConfig) SetGiveawaySchedule(giveawayType string, schedule string) {
+	switch giveawayType {
+	case ThxGiveawayType:
+		c.ThxGiveawaySchedule = schedule
+	case MessageGiveawayType:
+		c.MessageGiveawaySchedule = schedule
+	case JoinedGiveawayType:
+		c.UnconditionalGiveawaySchedule = schedule
+	case LevelGiveawayType:
+		c.ConditionalGiveawaySchedule = schedule
+	}
+}
+
+// ThxEntries returns the number of entries in the thx giveaway draw for a participant with thxCount accepted thx.
+func (c ServerConfig) ThxEntries(thxCount int) int {
+	if thxCount <= 0 {
+		return 0
+	}
+
+	switch c.ThxWeighting {
+	case ThxWeightingPerParticipant:
+		return 1
+	case ThxWeightingDiminishing:
+		entries := bits.Len(uint(thxCount))
+		if c.ThxWeightingCap > 0 && entries > c.ThxWeightingCap {
+			entries = c.ThxWeightingCap
+		}
+		return entries
+	default:
+		return thxCount
+	}
+}
+
+// ThxGiveawayEntries returns the ordered entries of the thx giveaway draw from its accepted participants.
+// Users are ordered by their first thx and every user appears as many times as ThxEntries allows.
+func (c ServerConfig) ThxGiveawayEntries(participants []GiveawayParticipant) []string {
+	sorted := append([]GiveawayParticipant(nil), participants...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Id < sorted[j].Id
+	})
+
+	var userIds []string
+	thxCounts := make(map[string]int)
+	for _, participant := range sorted {
+		if !participant.IsAccepted.Valid || !participant.IsAccepted.Bool {
+			continue
+		}
+		if thxCounts[participant.UserId] == 0 {
+			userIds = append(userIds, participant.UserId)
+		}
+		thxCounts[participant.UserId]++
+	}
+
+	var entries []string
+	for _, userId := range userIds {
+		for i := 0; i < c.ThxEntries(thxCounts[userId]); i++ {
+			entries = append(entries, userId)
+		}
+	}
+
+	return entries
+}
+
+// HelperThxSince returns since when the accepted thanks count for the helper tiers, zero for all time.
+func (c ServerConfig) HelperThxSince(now time.Time) time.Time {
+	if c.HelperThxWindowDays <= 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, -c.HelperThxWindowDays)
+}
+
+// HelperTier is a step of the helper role ladder, its role is given for more than ThxesNeeded accepted thanks.
+type HelperTier struct {
+	GuildId     string `json:"guildId"`
+	RoleId      string `json:"roleId"`
+	ThxesNeeded int    `json:"thxesNeeded"`
+}
+
+// HelperTierChange is a change of the helper tier of a member, a nil tier means no helper role.
+type HelperTierChange struct {
+	UserId       string
+	UserName     string
+	PreviousTier *HelperTier
+	Tier         *HelperTier
+}
+
+// HelperTierFor returns the highest of the tiers, ordered by ThxesNeeded, reached with thxAmount accepted thanks,
+// or nil if none is reached.
+func HelperTierFor(tiers []HelperTier, thxAmount int) *HelperTier {
+	var reached *HelperTier
+	for i := range tiers {
+		if thxAmount > tiers[i].ThxesNeeded {
+			reached = &tiers[i]
+		}
+	}
+	return reached
+}
+
+// NextHelperTier returns the lowest of the tiers, ordered by ThxesNeeded, not reached with thxAmount accepted
+// thanks yet, or nil if all of them are reached.
+func NextHelperTier(tiers []HelperTier, thxAmount int) *HelperTier {
+	for i := range tiers {
+		if thxAmount <= tiers[i].ThxesNeeded {
+			return &tiers[i]
+		}
+	}
+	return nil
 }
 
 type ServerRepo interface {
@@ -29,5 +220,13 @@ type ServerRepo interface {
 	GetAdminRoleForGuild(ctx context.Context, guildId string) (string, error)
 	GetMainChannelForGuild(ctx context.Context, guildId string) (string, error)
 	GetGuildsWithMessageGiveawaysEnabled(ctx context.Context) ([]string, error)
+	GetGuildsWithHelperTiers(ctx context.Context) ([]string, error)
 	GetConditionalGiveawayLevels(ctx context.Context, guildId string) ([]int, error)
+	GetVoucherConfig(ctx context.Context, guildId, giveawayType string) (VoucherConfig, error)
+	GetVoucherConfigs(ctx context.Context) ([]VoucherConfig, error)
+	SetVoucherConfig(ctx context.Context, voucherConfig *VoucherConfig) error
+	// GetHelperTiers returns the helper ladder of the guild, ordered by ThxesNeeded.
+	GetHelperTiers(ctx context.Context, guildId string) ([]HelperTier, error)
+	SetHelperTier(ctx context.Context, tier *HelperTier) error
+	RemoveHelperTier(ctx context.Context, guildId, roleId string) error
 }
@@ -1,12 +1,19 @@
 package entities
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
+// Blacklist keeps the user out of the giveaways of the guild until it expires, a nil ExpiresAt never expires.
 type Blacklist struct {
-	Id            int    `json:"id"`
-	GuildId       string `json:"guildId"`
-	UserId        string `json:"userId"`
-	BlacklisterId string `json:"blacklisterId"`
+	Id            int        `json:"id"`
+	GuildId       string     `json:"guildId"`
+	UserId        string     `json:"userId"`
+	BlacklisterId string     `json:"blacklisterId"`
+	Reason        string     `json:"reason"`
+	CreatedAt     *time.Time `json:"createdAt"` // nil for the entries added before reasons were recorded
+	ExpiresAt     *time.Time `json:"expiresAt"`
 }
 
 type MemberRole struct {
@@ -16,21 +23,34 @@ type MemberRole struct {
 	RoleId   string `json:"roleId"`
 }
 
+// HelperBlacklist keeps the user out of the helper roles of the guild until it expires, a nil ExpiresAt never
+// expires.
 type HelperBlacklist struct {
-	Id            int    `json:"id"`
-	GuildId       string `json:"guildId"`
-	UserId        string `json:"userId"`
-	BlacklisterId string `json:"blacklisterId"`
+	Id            int        `json:"id"`
+	GuildId       string     `json:"guildId"`
+	UserId        string     `json:"userId"`
+	BlacklisterId string     `json:"blacklisterId"`
+	Reason        string     `json:"reason"`
+	CreatedAt     *time.Time `json:"createdAt"`
+	ExpiresAt     *time.Time `json:"expiresAt"`
 }
 
 type UserRepo interface {
 	GetRolesForMember(ctx context.Context, guildId, memberId string) ([]MemberRole, error)
 	AddRoleForMember(ctx context.Context, guildId, memberId, roleId string) error
 	RemoveRoleForMember(ctx context.Context, guildId, memberId, roleId string) error
+	// IsUserHelperBlacklisted and IsUserBlacklisted ignore the expired entries not removed yet.
 	IsUserHelperBlacklisted(ctx context.Context, userId, guildId string) (bool, error)
 	IsUserBlacklisted(ctx context.Context, userId, guildId string) (bool, error)
-	AddBlacklistForUser(ctx context.Context, userId, guildId, blacklisterId string) error
+	// AddBlacklistForUser blacklists the user, replacing an expired entry, expiresAt is nil for a permanent entry.
+	AddBlacklistForUser(ctx context.Context, userId, guildId, blacklisterId, reason string, expiresAt *time.Time) error
 	RemoveBlacklistForUser(ctx context.Context, userId, guildId string) error
-	AddHelperBlacklistForUser(ctx context.Context, userId, guildId, blacklisterId string) error
+	// GetBlacklists returns the active entries of the guild, the ones expiring first first and the permanent ones last.
+	GetBlacklists(ctx context.Context, guildId string) ([]Blacklist, error)
+	// GetExpiredBlacklists returns the entries of all guilds which expired before now.
+	GetExpiredBlacklists(ctx context.Context, now time.Time) ([]Blacklist, error)
+	AddHelperBlacklistForUser(ctx context.Context, userId, guildId, blacklisterId, reason string, expiresAt *time.Time) error
 	RemoveHelperBlacklistForUser(ctx context.Context, userId, guildId string) error
+	GetHelperBlacklists(ctx context.Context, guildId string) ([]HelperBlacklist, error)
+	GetExpiredHelperBlacklists(ctx context.Context, now time.Time) ([]HelperBlacklist, error)
 }
@@ -1,7 +1,10 @@
 package entities
 
 import (
+	"context"
+	"fmt"
 	"github.com/Craftserve/monies"
+	"strconv"
 	"time"
 )
 
@@ -14,8 +17,114 @@ type VoucherAction struct {
 }
 
 type Voucher struct {
-	Id        string          `json:"id"`
-	CreatedAt time.Time       `json:"created_at"`
-	Expires   *time.Time      `json:"expires"`
-	Data      []VoucherAction `json:"data"`
+	Id         string          `json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Expires    *time.Time      `json:"expires"`
+	Data       []VoucherAction `json:"data"`
+	RedeemedAt *time.Time      `json:"redeemed_at,omitempty"` // nil until the voucher is used
+}
+
+// VoucherConfig describes the vouchers given to the winners of one giveaway type in a guild.
+type VoucherConfig struct {
+	GuildId        string `json:"guildId"`
+	GiveawayType   string `json:"giveawayType"`
+	Value          int    `json:"value"` // in minor units of Currency
+	Currency       string `json:"currency"`
+	ExpirationDays int    `json:"expirationDays"`
+	Prefix         string `json:"prefix"`
+	GroupId        string `json:"groupId"`
+}
+
+const (
+	DefaultVoucherCurrency = "PLN"
+	DefaultVoucherPrefix   = "discord"
+	DefaultVoucherGroupId  = "discord-giveaway"
+)
+
+// Money returns the value of a single voucher.
+func (c VoucherConfig) Money() (monies.Money, error) {
+	return monies.New(int64(c.Value), monies.CurrencyCode(c.Currency))
+}
+
+// FormatValue returns the value of a single voucher for display, e.g. "10 PLN" or "12.5 EUR".
+func (c VoucherConfig) FormatValue() string {
+	money, err := c.Money()
+	if err != nil {
+		return fmt.Sprintf("%d %s", c.Value, c.Currency)
+	}
+
+	return strconv.FormatFloat(money.AsMajorUnits(), 'f', -1, 64) + " " + c.Currency
+}
+
+// ExpiresAt returns when a voucher issued at the given time expires.
+func (c VoucherConfig) ExpiresAt(issuedAt time.Time) time.Time {
+	return issuedAt.Add(24 * time.Duration(c.ExpirationDays) * time.Hour)
+}
+
+// Profile returns the settings which make vouchers interchangeable, the guild and giveaway type do not matter.
+func (c VoucherConfig) Profile() VoucherProfile {
+	return VoucherProfile{
+		Value:          c.Value,
+		Currency:       c.Currency,
+		ExpirationDays: c.ExpirationDays,
+		Prefix:         c.Prefix,
+		GroupId:        c.GroupId,
+	}
+}
+
+// VoucherProfile groups the pre-generated vouchers in the pool, a pooled code is only handed out for the same profile.
+type VoucherProfile struct {
+	Value          int    `json:"value"`
+	Currency       string `json:"currency"`
+	ExpirationDays int    `json:"expirationDays"`
+	Prefix         string `json:"prefix"`
+	GroupId        string `json:"groupId"`
+}
+
+func (p VoucherProfile) String() string {
+	return fmt.Sprintf("%s, %d dni, %s/%s", VoucherConfig{Value: p.Value, Currency: p.Currency}.FormatValue(), p.ExpirationDays, p.Prefix, p.GroupId)
+}
+
+// PooledVoucher is a pre-generated voucher waiting in the pool for a winner.
+type PooledVoucher struct {
+	Id        int            `json:"id"`
+	Code      string         `json:"code"`
+	Profile   VoucherProfile `json:"profile"`
+	CreatedAt time.Time      `json:"createdAt"`
+	ExpiresAt time.Time      `json:"expiresAt"`
+	WinnerId  *int           `json:"winnerId"` // set while the voucher is reserved for a winner
+}
+
+type VoucherRepo interface {
+	AddPooledVouchers(ctx context.Context, vouchers []PooledVoucher) error
+	// TakePooledVoucher removes and returns the oldest voucher of the profile created after createdAfter and expiring after
+	// expiresAfter, or sql.ErrNoRows.
+	TakePooledVoucher(ctx context.Context, profile VoucherProfile, createdAfter, expiresAfter time.Time) (*PooledVoucher, error)
+	// ReservePooledVoucher reserves the oldest voucher of the profile created after createdAfter and expiring after
+	// expiresAfter for the winner, or returns sql.ErrNoRows.
+	ReservePooledVoucher(ctx context.Context, profile VoucherProfile, createdAfter, expiresAfter time.Time, winnerId int) (*PooledVoucher, error)
+	// GetReservedVoucher returns the voucher reserved for the winner, or sql.ErrNoRows.
+	GetReservedVoucher(ctx context.Context, winnerId int) (*PooledVoucher, error)
+	RemoveReservedVoucher(ctx context.Context, winnerId int) error
+	CountPooledVouchers(ctx context.Context, profile VoucherProfile, createdAfter, expiresAfter time.Time) (int, error)
+	// RemoveExpiredPooledVouchers removes the unreserved vouchers expiring before expiresBefore.
+	RemoveExpiredPooledVouchers(ctx context.Context, expiresBefore time.Time) (int, error)
+	// CountAgedPooledVouchers counts the unreserved vouchers created before createdBefore, which are no longer handed out.
+	CountAgedPooledVouchers(ctx context.Context, createdBefore time.Time) (int, error)
+}
+
+// PendingVoucher is a voucher of a giveaway winner which could not be generated when the giveaway ended.
+// It is retried in the background until the code is issued and sent to the winner.
+type PendingVoucher struct {
+	Id            int           `json:"id"`
+	WinnerId      int           `json:"winnerId"`
+	GiveawayId    int           `json:"giveawayId"`
+	GuildId       string        `json:"guildId"`
+	UserId        string        `json:"userId"`
+	VoucherConfig VoucherConfig `json:"voucherConfig"` // settings at the time of the draw
+	Attempts      int           `json:"attempts"`
+	LastError     string        `json:"lastError"`
+	NextAttemptAt time.Time     `json:"nextAttemptAt"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	ResolvedAt    *time.Time    `json:"resolvedAt"` // nil while the voucher is still pending
 }
@@ -10,7 +10,6 @@ require (
 	github.com/getsentry/sentry-go v0.27.0
 	github.com/go-gorp/gorp v2.2.0+incompatible
 	github.com/go-sql-driver/mysql v1.7.0
-	github.com/robfig/cron v1.2.0
 	github.com/sirupsen/logrus v1.9.0
 )
 
@@ -0,0 +1,61 @@
+package repos
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"sort"
+	"sync"
+)
+
+// MemoryAuditLogRepo is an in-memory implementation of entities.AuditLogRepo.
+type MemoryAuditLogRepo struct {
+	mu      sync.Mutex
+	entries []entities.AuditLogEntry
+	lastId  int
+}
+
+var _ entities.AuditLogRepo = (*MemoryAuditLogRepo)(nil)
+
+func NewMemoryAuditLogRepo() *MemoryAuditLogRepo {
+	return &MemoryAuditLogRepo{}
+}
+
+func (repo *MemoryAuditLogRepo) InsertAuditLogEntry(ctx context.Context, entry *entities.AuditLogEntry) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.lastId++
+	entry.Id = repo.lastId
+	repo.entries = append(repo.entries, *entry)
+	return nil
+}
+
+func (repo *MemoryAuditLogRepo) GetAuditLogEntries(ctx context.Context, filter entities.AuditLogFilter, offset, limit int) ([]entities.AuditLogEntry, error) {
+	entries := repo.filterEntries(filter)
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.After(entries[j].CreatedAt)
+		}
+		return entries[i].Id > entries[j].Id
+	})
+	if offset >= len(entries) {
+		return nil, nil
+	}
+
+	return entries[offset:min(offset+limit, len(entries))], nil
+}
+
+func (repo *MemoryAuditLogRepo) CountAuditLogEntries(ctx context.Context, filter entities.AuditLogFilter) (int, error) {
+	return len(repo.filterEntries(filter)), nil
+}
+
+func (repo *MemoryAuditLogRepo) filterEntries(filter entities.AuditLogFilter) []entities.AuditLogEntry {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var entries []entities.AuditLogEntry
+	for _, entry := range repo.entries {
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,1197 @@
+package repos
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/fairdraw"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryGiveawaysRepo is an in-memory implementation of entities.GiveawaysRepo.
+// It mirrors the queries of GiveawaysRepo and is meant for tests and local development.
+type MemoryGiveawaysRepo struct {
+	mu            sync.Mutex
+	giveaways     []entities.Giveaway
+	participants  []entities.GiveawayParticipant
+	winners       []entities.GiveawayWinner
+	draws         []entities.GiveawayDraw
+	custom        []entities.CustomGiveaway
+	pending       []entities.PendingVoucher
+	candidates    []entities.ThxParticipantCandidate
+	notifications []entities.ThxNotification
+	abuseChecks   []entities.ThxAbuseCheck
+	limitHits     []entities.ThxLimitHit
+	dailyMessages []entities.DailyUserMessages
+	lastId        int
+}
+
+var _ entities.GiveawaysRepo = (*MemoryGiveawaysRepo)(nil)
+
+func NewMemoryGiveawaysRepo() *MemoryGiveawaysRepo {
+	return &MemoryGiveawaysRepo{}
+}
+
+func (repo *MemoryGiveawaysRepo) nextId() int {
+	repo.lastId++
+	return repo.lastId
+}
+
+func (repo *MemoryGiveawaysRepo) findGiveaway(giveawayId int) *entities.Giveaway {
+	for i := range repo.giveaways {
+		if repo.giveaways[i].Id == giveawayId {
+			return &repo.giveaways[i]
+		}
+	}
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) findGiveawayByInfoMessage(messageId string) *entities.Giveaway {
+	for i := range repo.giveaways {
+		if repo.giveaways[i].InfoMessageId != nil && *repo.giveaways[i].InfoMessageId == messageId {
+			return &repo.giveaways[i]
+		}
+	}
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) winnersForInfoMessage(messageId, userId string) []entities.GiveawayWinner {
+	var result []entities.GiveawayWinner
+	for _, giveaway := range repo.giveaways {
+		if giveaway.InfoMessageId == nil || *giveaway.InfoMessageId != messageId {
+			continue
+		}
+		for _, winner := range repo.winners {
+			if winner.GiveawayId == giveaway.Id && winner.UserId == userId && winner.RedemptionStatus != entities.VoucherStatusReplaced {
+				result = append(result, winner)
+			}
+		}
+	}
+	return result
+}
+
+func (repo *MemoryGiveawaysRepo) acceptedThxAmounts(guildId string, since time.Time) map[string]int {
+	amounts := make(map[string]int)
+	for _, participant := range repo.participants {
+		if participant.GuildId == guildId && participant.IsAccepted.Valid && participant.IsAccepted.Bool && !participant.JoinTime.Before(since) {
+			amounts[participant.UserId]++
+		}
+	}
+	return amounts
+}
+
+func (repo *MemoryGiveawaysRepo) GetGiveawayForGuild(ctx context.Context, guildId, giveawayType string) (*entities.Giveaway, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, giveaway := range repo.giveaways {
+		if giveaway.EndTime == nil && giveaway.GuildId == guildId && giveaway.Type == giveawayType {
+			return &giveaway, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (repo *MemoryGiveawaysRepo) GetUnfinishedGiveaways(ctx context.Context, giveawayType string) (result []entities.Giveaway, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, giveaway := range repo.giveaways {
+		if giveaway.EndTime == nil && giveaway.Type == giveawayType {
+			result = append(result, giveaway)
+		}
+	}
+
+	return result, nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetParticipantsForGiveaway(ctx context.Context, giveawayId int, accepted *bool) (result []entities.GiveawayParticipant, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, participant := range repo.participants {
+		if participant.GiveawayId != giveawayId {
+			continue
+		}
+		if accepted != nil && (!participant.IsAccepted.Valid || participant.IsAccepted.Bool != *accepted) {
+			continue
+		}
+		result = append(result, participant)
+	}
+
+	return result, nil
+}
+
+func (repo *MemoryGiveawaysRepo) CountParticipantsForGiveaway(ctx context.Context, giveawayId int) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	count := 0
+	for _, participant := range repo.participants {
+		if participant.GiveawayId == giveawayId {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (repo *MemoryGiveawaysRepo) InsertGiveaway(ctx context.Context, guildId string, messageId *string, giveawayType string, level *int) error {
+	seed, err := fairdraw.NewSeed()
+	if err != nil {
+		return err
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.giveaways = append(repo.giveaways, entities.Giveaway{
+		Id:            repo.nextId(),
+		Type:          giveawayType,
+		StartTime:     time.Now(),
+		GuildId:       guildId,
+		InfoMessageId: messageId,
+		Level:         level,
+		Seed:          seed,
+		SeedHash:      fairdraw.HashSeed(seed),
+	})
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) InsertParticipant(ctx context.Context, giveawayId, level int, guildId, userId, userName string, messageId, channelId, thankerId *string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.participants = append(repo.participants, entities.GiveawayParticipant{
+		Id:         repo.nextId(),
+		GiveawayId: giveawayId,
+		GuildId:    guildId,
+		UserId:     userId,
+		UserName:   userName,
+		JoinTime:   time.Now(),
+		UserLevel:  &level,
+		MessageId:  messageId,
+		ChannelId:  channelId,
+		ThankerId:  thankerId,
+	})
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) InsertWinner(ctx context.Context, giveawayId int, userId, code string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.winners = append(repo.winners, entities.GiveawayWinner{
+		Id:         repo.nextId(),
+		GiveawayId: giveawayId,
+		UserId:     userId,
+		Code:       code,
+		Notified:   true,
+
+		RedemptionStatus: entities.VoucherStatusUnknown,
+	})
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) FinishGiveaway(ctx context.Context, giveaway *entities.Giveaway, messageId *string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	now := time.Now()
+	giveaway.EndTime = &now
+	giveaway.InfoMessageId = messageId
+	if stored := repo.findGiveaway(giveaway.Id); stored != nil {
+		*stored = *giveaway
+	}
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) HasWonGiveawayByMessageId(ctx context.Context, messageId, userId string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return len(repo.winnersForInfoMessage(messageId, userId)) > 0, nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetCodeForInfoMessage(ctx context.Context, messageId, userId string) (string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	winners := repo.winnersForInfoMessage(messageId, userId)
+	if len(winners) == 0 {
+		return "", nil
+	}
+
+	return winners[len(winners)-1].Code, nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetGiveawayByMessageId(ctx context.Context, messageId string) (*entities.Giveaway, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	giveaway := repo.findGiveawayByInfoMessage(messageId)
+	if giveaway == nil {
+		return nil, sql.ErrNoRows
+	}
+	result := *giveaway
+
+	return &result, nil
+}
+
+func (repo *MemoryGiveawaysRepo) StartDraw(ctx context.Context, giveaway *entities.Giveaway, participants, skipped []string, winners []entities.GiveawayWinner) (*entities.GiveawayDraw, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, draw := range repo.draws {
+		if draw.GiveawayId == giveaway.Id {
+			return nil, fmt.Errorf("draw for giveaway %d already exists", giveaway.Id)
+		}
+	}
+
+	draw := entities.GiveawayDraw{
+		Id:           repo.nextId(),
+		GiveawayId:   giveaway.Id,
+		GuildId:      giveaway.GuildId,
+		State:        entities.DrawInProgressState,
+		StartTime:    time.Now(),
+		Participants: participants,
+		Skipped:      skipped,
+	}
+	repo.draws = append(repo.draws, draw)
+	for _, winner := range winners {
+		drawId := draw.Id
+		repo.winners = append(repo.winners, entities.GiveawayWinner{
+			Id:         repo.nextId(),
+			GiveawayId: giveaway.Id,
+			DrawId:     &drawId,
+			UserId:     winner.UserId,
+			UserName:   winner.UserName,
+
+			RedemptionStatus: entities.VoucherStatusUnknown,
+		})
+	}
+
+	return &draw, nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetDrawForGiveaway(ctx context.Context, giveawayId int) (*entities.GiveawayDraw, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, draw := range repo.draws {
+		if draw.GiveawayId == giveawayId {
+			return &draw, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (repo *MemoryGiveawaysRepo) GetInProgressDraws(ctx context.Context) (result []entities.GiveawayDraw, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, draw := range repo.draws {
+		if draw.State == entities.DrawInProgressState {
+			result = append(result, draw)
+		}
+	}
+
+	return result, nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetDrawWinners(ctx context.Context, drawId int) (result []entities.GiveawayWinner, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, winner := range repo.winners {
+		if winner.DrawId != nil && *winner.DrawId == drawId && winner.ReplacesWinnerId == nil {
+			result = append(result, winner)
+		}
+	}
+
+	return result, nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetWinner(ctx context.Context, winnerId int) (*entities.GiveawayWinner, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, winner := range repo.winners {
+		if winner.Id == winnerId {
+			return &winner, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (repo *MemoryGiveawaysRepo) GetGiveawayWinners(ctx context.Context, giveawayId int) (result []entities.GiveawayWinner, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, winner := range repo.winners {
+		if winner.GiveawayId == giveawayId {
+			result = append(result, winner)
+		}
+	}
+
+	return result, nil
+}
+
+func (repo *MemoryGiveawaysRepo) SetWinnerCode(ctx context.Context, winnerId int, code string, voucherConfig entities.VoucherConfig, expiresAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.winners {
+		if repo.winners[i].Id != winnerId {
+			continue
+		}
+		if repo.winners[i].Code != "" {
+			return entities.ErrWinnerCodeAlreadySet
+		}
+		value, currency := voucherConfig.Value, voucherConfig.Currency
+		repo.winners[i].Code = code
+		repo.winners[i].VoucherValue = &value
+		repo.winners[i].VoucherCurrency = &currency
+		repo.winners[i].ExpiresAt = &expiresAt
+		return nil
+	}
+
+	return entities.ErrWinnerCodeAlreadySet
+}
+
+func (repo *MemoryGiveawaysRepo) SetWinnerNotified(ctx context.Context, winnerId int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.winners {
+		if repo.winners[i].Id == winnerId {
+			repo.winners[i].Notified = true
+		}
+	}
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) SetDrawAnnouncement(ctx context.Context, drawId int, messageId string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.draws {
+		if repo.draws[i].Id == drawId {
+			repo.draws[i].AnnouncementMessageId = &messageId
+		}
+	}
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) CompleteDraw(ctx context.Context, draw *entities.GiveawayDraw, giveaway *entities.Giveaway, messageId *string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	now := time.Now()
+	draw.State = entities.DrawCompletedState
+	draw.EndTime = &now
+	giveaway.EndTime = &now
+	giveaway.InfoMessageId = messageId
+	for i := range repo.draws {
+		if repo.draws[i].Id == draw.Id {
+			repo.draws[i] = *draw
+		}
+	}
+	if stored := repo.findGiveaway(giveaway.Id); stored != nil {
+		*stored = *giveaway
+	}
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) RollbackDraw(ctx context.Context, draw *entities.GiveawayDraw) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.draws {
+		if repo.draws[i].Id != draw.Id || repo.draws[i].State != entities.DrawInProgressState {
+			continue
+		}
+		repo.draws = append(repo.draws[:i], repo.draws[i+1:]...)
+
+		var winners []entities.GiveawayWinner
+		for _, winner := range repo.winners {
+			if winner.DrawId == nil || *winner.DrawId != draw.Id {
+				winners = append(winners, winner)
+			}
+		}
+		repo.winners = winners
+		break
+	}
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) InsertCustomGiveaway(ctx context.Context, customGiveaway *entities.CustomGiveaway, messageId string) (*entities.Giveaway, error) {
+	seed, err := fairdraw.NewSeed()
+	if err != nil {
+		return nil, err
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	giveaway := entities.Giveaway{
+		Id:            repo.nextId(),
+		Type:          entities.CustomGiveawayType,
+		StartTime:     time.Now(),
+		GuildId:       customGiveaway.GuildId,
+		InfoMessageId: &messageId,
+		Level:         customGiveaway.RequiredLevel,
+		Seed:          seed,
+		SeedHash:      fairdraw.HashSeed(seed),
+	}
+	repo.giveaways = append(repo.giveaways, giveaway)
+
+	customGiveaway.GiveawayId = giveaway.Id
+	repo.custom = append(repo.custom, *customGiveaway)
+
+	return &giveaway, nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetWinnersToCheck(ctx context.Context, checkedBefore time.Time, limit int) (result []entities.GiveawayWinner, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	now := time.Now()
+	for _, winner := range repo.winners {
+		if winner.Code == "" || (winner.RedemptionStatus != entities.VoucherStatusUnknown && winner.RedemptionStatus != entities.VoucherStatusUnclaimed) {
+			continue
+		}
+		due := winner.StatusCheckedAt == nil || winner.StatusCheckedAt.Before(checkedBefore) || (winner.ExpiresAt != nil && winner.ExpiresAt.Before(now))
+		if due {
+			result = append(result, winner)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].StatusCheckedAt == nil || result[j].StatusCheckedAt == nil {
+			return result[i].StatusCheckedAt == nil && result[j].StatusCheckedAt != nil
+		}
+		return result[i].StatusCheckedAt.Before(*result[j].StatusCheckedAt)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+func (repo *MemoryGiveawaysRepo) UpdateWinnerRedemption(ctx context.Context, winner *entities.GiveawayWinner) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.winners {
+		if repo.winners[i].Id == winner.Id {
+			repo.winners[i].RedemptionStatus = winner.RedemptionStatus
+			repo.winners[i].ExpiresAt = winner.ExpiresAt
+			repo.winners[i].RedeemedAt = winner.RedeemedAt
+			repo.winners[i].StatusCheckedAt = winner.StatusCheckedAt
+			return nil
+		}
+	}
+
+	return sql.ErrNoRows
+}
+
+func (repo *MemoryGiveawaysRepo) ReplaceWinner(ctx context.Context, winner *entities.GiveawayWinner, replacement *entities.GiveawayWinner) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.winners {
+		if repo.winners[i].Id != winner.Id {
+			continue
+		}
+		repo.winners[i].RedemptionStatus = entities.VoucherStatusReplaced
+		repo.winners[i].ExpiresAt = winner.ExpiresAt
+		repo.winners[i].StatusCheckedAt = winner.StatusCheckedAt
+
+		replacesWinnerId := winner.Id
+		replacement.Id = repo.nextId()
+		replacement.GiveawayId = winner.GiveawayId
+		replacement.DrawId = winner.DrawId
+		replacement.ReplacesWinnerId = &replacesWinnerId
+		replacement.RedemptionStatus = entities.VoucherStatusUnknown
+		repo.winners = append(repo.winners, *replacement)
+		winner.RedemptionStatus = entities.VoucherStatusReplaced
+		return nil
+	}
+
+	return sql.ErrNoRows
+}
+
+func (repo *MemoryGiveawaysRepo) GetUnclaimedWinners(ctx context.Context, guildId string) (result []entities.GiveawayWin, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, winner := range repo.winners {
+		if winner.Code == "" || (winner.RedemptionStatus != entities.VoucherStatusUnclaimed && winner.RedemptionStatus != entities.VoucherStatusExpired) {
+			continue
+		}
+		giveaway := repo.findGiveaway(winner.GiveawayId)
+		if giveaway == nil || giveaway.GuildId != guildId {
+			continue
+		}
+		result = append(result, entities.GiveawayWin{GiveawayWinner: winner, GuildId: giveaway.GuildId, GiveawayType: giveaway.Type, GiveawayEndTime: giveaway.EndTime})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].ExpiresAt == nil || result[j].ExpiresAt == nil {
+			return result[i].ExpiresAt != nil && result[j].ExpiresAt == nil
+		}
+		return result[i].ExpiresAt.Before(*result[j].ExpiresAt)
+	})
+
+	return result, nil
+}
+
+func (repo *MemoryGiveawaysRepo) AddPendingVoucher(ctx context.Context, pendingVoucher *entities.PendingVoucher) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, existing := range repo.pending {
+		if existing.WinnerId == pendingVoucher.WinnerId {
+			return nil
+		}
+	}
+
+	pendingVoucher.Id = repo.nextId()
+	repo.pending = append(repo.pending, *pendingVoucher)
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetDuePendingVouchers(ctx context.Context, now time.Time, limit int) (result []entities.PendingVoucher, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, pendingVoucher := range repo.pending {
+		if pendingVoucher.ResolvedAt == nil && !pendingVoucher.NextAttemptAt.After(now) {
+			result = append(result, pendingVoucher)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].NextAttemptAt.Before(result[j].NextAttemptAt)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+func (repo *MemoryGiveawaysRepo) UpdatePendingVoucher(ctx context.Context, pendingVoucher *entities.PendingVoucher) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.pending {
+		if repo.pending[i].Id == pendingVoucher.Id {
+			repo.pending[i].Attempts = pendingVoucher.Attempts
+			repo.pending[i].LastError = pendingVoucher.LastError
+			repo.pending[i].NextAttemptAt = pendingVoucher.NextAttemptAt
+			repo.pending[i].ResolvedAt = pendingVoucher.ResolvedAt
+			return nil
+		}
+	}
+
+	return sql.ErrNoRows
+}
+
+func (repo *MemoryGiveawaysRepo) GetCustomGiveaway(ctx context.Context, giveawayId int) (*entities.CustomGiveaway, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, customGiveaway := range repo.custom {
+		if customGiveaway.GiveawayId == giveawayId {
+			return &customGiveaway, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (repo *MemoryGiveawaysRepo) GetUnfinishedCustomGiveaways(ctx context.Context) (result []entities.CustomGiveaway, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, customGiveaway := range repo.custom {
+		giveaway := repo.findGiveaway(customGiveaway.GiveawayId)
+		if giveaway != nil && giveaway.EndTime == nil {
+			result = append(result, customGiveaway)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ScheduledEndTime.Before(result[j].ScheduledEndTime)
+	})
+
+	return result, nil
+}
+
+func (repo *MemoryGiveawaysRepo) InsertParticipantCandidate(ctx context.Context, guildId, guildName, candidateId, candidateName, approverId, approverName, channelId, messageId string, giveawayId int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.candidates = append(repo.candidates, entities.ThxParticipantCandidate{
+		Id:                    repo.nextId(),
+		CandidateId:           candidateId,
+		CandidateName:         candidateName,
+		CandidateApproverId:   approverId,
+		CandidateApproverName: approverName,
+		GiveawayId:            giveawayId,
+		GuildId:               guildId,
+		GuildName:             guildName,
+		MessageId:             messageId,
+		ChannelId:             channelId,
+		CreatedAt:             time.Now(),
+	})
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetParticipantsWithThxAmount(ctx context.Context, guildId string, minThxAmount int, since time.Time) (result []entities.ThxParticipantWithThxAmount, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for userId, amount := range repo.acceptedThxAmounts(guildId, since) {
+		if amount > minThxAmount {
+			result = append(result, entities.ThxParticipantWithThxAmount{UserId: userId, ThxAmount: amount})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].UserId < result[j].UserId
+	})
+
+	return result, nil
+}
+
+// thxRanking must be called with repo.mu held.
+func (repo *MemoryGiveawaysRepo) thxRanking(guildId string, since time.Time) []entities.ThxParticipantWithThxAmount {
+	amounts := make(map[string]int)
+	for _, participant := range repo.participants {
+		if participant.GuildId != guildId || !participant.IsAccepted.Valid || !participant.IsAccepted.Bool || participant.JoinTime.Before(since) {
+			continue
+		}
+		giveaway := repo.findGiveaway(participant.GiveawayId)
+		if giveaway == nil || giveaway.Type != entities.ThxGiveawayType {
+			continue
+		}
+		amounts[participant.UserId]++
+	}
+
+	ranking := make([]entities.ThxParticipantWithThxAmount, 0, len(amounts))
+	for userId, amount := range amounts {
+		ranking = append(ranking, entities.ThxParticipantWithThxAmount{UserId: userId, ThxAmount: amount})
+	}
+	sort.Slice(ranking, func(i, j int) bool {
+		if ranking[i].ThxAmount == ranking[j].ThxAmount {
+			return ranking[i].UserId < ranking[j].UserId
+		}
+		return ranking[i].ThxAmount > ranking[j].ThxAmount
+	})
+
+	return ranking
+}
+
+func (repo *MemoryGiveawaysRepo) GetThxRanking(ctx context.Context, guildId string, since time.Time, offset, limit int) ([]entities.ThxParticipantWithThxAmount, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	ranking := repo.thxRanking(guildId, since)
+	if offset >= len(ranking) {
+		return nil, nil
+	}
+
+	return ranking[offset:min(offset+limit, len(ranking))], nil
+}
+
+func (repo *MemoryGiveawaysRepo) CountThxRanking(ctx context.Context, guildId string, since time.Time) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	return len(repo.thxRanking(guildId, since)), nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetThxStats(ctx context.Context, guildId, userId string) (stats entities.ThxStats, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, participant := range repo.participants {
+		if participant.GuildId != guildId || participant.UserId != userId {
+			continue
+		}
+		giveaway := repo.findGiveaway(participant.GiveawayId)
+		if giveaway == nil || giveaway.Type != entities.ThxGiveawayType {
+			continue
+		}
+		stats.Total++
+		switch {
+		case !participant.IsAccepted.Valid:
+			stats.Pending++
+		case participant.IsAccepted.Bool:
+			stats.Accepted++
+		default:
+			stats.Rejected++
+		}
+	}
+	thankers := make(map[string]bool)
+	for _, thanker := range repo.countAcceptedThanks(guildId, func(participant entities.GiveawayParticipant) string {
+		if participant.UserId == userId && participant.ThankerId != nil {
+			return *participant.ThankerId
+		}
+		return ""
+	}) {
+		thankers[thanker.UserId] = true
+	}
+	stats.Thankers = len(thankers)
+	for _, thanked := range repo.countAcceptedThanks(guildId, func(participant entities.GiveawayParticipant) string {
+		if participant.ThankerId != nil && *participant.ThankerId == userId {
+			return participant.UserId
+		}
+		return ""
+	}) {
+		stats.Given += thanked.ThxAmount
+	}
+
+	return stats, nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetThxRank(ctx context.Context, guildId, userId string) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	ranking := repo.thxRanking(guildId, time.Time{})
+	for i, participant := range ranking {
+		if participant.UserId != userId {
+			continue
+		}
+		// Users with as many thanks share the rank
+		for i > 0 && ranking[i-1].ThxAmount == participant.ThxAmount {
+			i--
+		}
+		return i + 1, nil
+	}
+
+	return 0, nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetTopThankers(ctx context.Context, guildId, userId string, limit int) ([]entities.ThxParticipantWithThxAmount, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	thankers := repo.countAcceptedThanks(guildId, func(participant entities.GiveawayParticipant) string {
+		if participant.UserId == userId && participant.ThankerId != nil {
+			return *participant.ThankerId
+		}
+		return ""
+	})
+
+	return thankers[:min(limit, len(thankers))], nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetTopThanked(ctx context.Context, guildId, thankerId string, limit int) ([]entities.ThxParticipantWithThxAmount, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	thanked := repo.countAcceptedThanks(guildId, func(participant entities.GiveawayParticipant) string {
+		if participant.ThankerId != nil && *participant.ThankerId == thankerId {
+			return participant.UserId
+		}
+		return ""
+	})
+
+	return thanked[:min(limit, len(thanked))], nil
+}
+
+// countAcceptedThanks counts the accepted thanks of the guild by the user key returns for them, skipping the thanks
+// with an empty key, most counted first.
+func (repo *MemoryGiveawaysRepo) countAcceptedThanks(guildId string, key func(participant entities.GiveawayParticipant) string) []entities.ThxParticipantWithThxAmount {
+	amounts := make(map[string]int)
+	for _, participant := range repo.participants {
+		if participant.GuildId != guildId || !participant.IsAccepted.Valid || !participant.IsAccepted.Bool {
+			continue
+		}
+		if giveaway := repo.findGiveaway(participant.GiveawayId); giveaway == nil || giveaway.Type != entities.ThxGiveawayType {
+			continue
+		}
+		if userId := key(participant); userId != "" {
+			amounts[userId]++
+		}
+	}
+
+	result := make([]entities.ThxParticipantWithThxAmount, 0, len(amounts))
+	for userId, amount := range amounts {
+		result = append(result, entities.ThxParticipantWithThxAmount{UserId: userId, ThxAmount: amount})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ThxAmount == result[j].ThxAmount {
+			return result[i].UserId < result[j].UserId
+		}
+		return result[i].ThxAmount > result[j].ThxAmount
+	})
+
+	return result
+}
+
+func (repo *MemoryGiveawaysRepo) InsertThxAbuseCheck(ctx context.Context, check *entities.ThxAbuseCheck) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, existing := range repo.abuseChecks {
+		if existing.MessageId == check.MessageId {
+			return fmt.Errorf("duplicate thx abuse check for message %s", check.MessageId)
+		}
+	}
+
+	check.Id = repo.nextId()
+	repo.abuseChecks = append(repo.abuseChecks, *check)
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetThxAbuseCheck(ctx context.Context, messageId string) (*entities.ThxAbuseCheck, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, check := range repo.abuseChecks {
+		if check.MessageId == messageId {
+			return &check, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (repo *MemoryGiveawaysRepo) CountThanksGiven(ctx context.Context, guildId, thankerId string, since time.Time) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	count := 0
+	for _, participant := range repo.participants {
+		if participant.GuildId == guildId && participant.ThankerId != nil && *participant.ThankerId == thankerId && !participant.JoinTime.Before(since) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (repo *MemoryGiveawaysRepo) CountThanksBetween(ctx context.Context, guildId, thankerId, recipientId string, since time.Time) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	count := 0
+	for _, participant := range repo.participants {
+		if participant.GuildId == guildId && participant.ThankerId != nil && *participant.ThankerId == thankerId && participant.UserId == recipientId && !participant.JoinTime.Before(since) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (repo *MemoryGiveawaysRepo) CountPendingParticipantCandidates(ctx context.Context, guildId, candidateId string) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	count := 0
+	for _, candidate := range repo.candidates {
+		if candidate.GuildId == guildId && candidate.CandidateId == candidateId && !candidate.IsAccepted.Valid {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (repo *MemoryGiveawaysRepo) InsertThxLimitHit(ctx context.Context, hit *entities.ThxLimitHit) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	hit.Id = repo.nextId()
+	repo.limitHits = append(repo.limitHits, *hit)
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetThxLimitHits(ctx context.Context, guildId string, limit int) (result []entities.ThxLimitHit, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := len(repo.limitHits) - 1; i >= 0 && len(result) < limit; i-- {
+		if repo.limitHits[i].GuildId == guildId {
+			result = append(result, repo.limitHits[i])
+		}
+	}
+
+	return result, nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetThxAmount(ctx context.Context, guildId, memberId string, since time.Time) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.acceptedThxAmounts(guildId, since)[memberId], nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetThxNotification(ctx context.Context, messageId string) (entities.ThxNotification, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, notification := range repo.notifications {
+		if notification.ThxMessageId == messageId {
+			return notification, nil
+		}
+	}
+
+	return entities.ThxNotification{}, sql.ErrNoRows
+}
+
+func (repo *MemoryGiveawaysRepo) InsertThxNotification(ctx context.Context, thxMessageId, notificationMessageId string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.notifications = append(repo.notifications, entities.ThxNotification{
+		Id:                    repo.nextId(),
+		ThxMessageId:          thxMessageId,
+		NotificationMessageId: notificationMessageId,
+	})
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) IsThxMessage(ctx context.Context, messageId string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, participant := range repo.participants {
+		if participant.MessageId == nil || *participant.MessageId != messageId {
+			continue
+		}
+		if giveaway := repo.findGiveaway(participant.GiveawayId); giveaway != nil && giveaway.Type == entities.ThxGiveawayType {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (repo *MemoryGiveawaysRepo) IsThxmeMessage(ctx context.Context, messageId string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, candidate := range repo.candidates {
+		if candidate.MessageId == messageId {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetParticipant(ctx context.Context, messageId string) (*entities.GiveawayParticipant, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, participant := range repo.participants {
+		if participant.MessageId != nil && *participant.MessageId == messageId {
+			return &participant, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (repo *MemoryGiveawaysRepo) GetParticipantCandidate(ctx context.Context, messageId string) (entities.ThxParticipantCandidate, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, candidate := range repo.candidates {
+		if candidate.MessageId == messageId {
+			return candidate, nil
+		}
+	}
+
+	return entities.ThxParticipantCandidate{}, sql.ErrNoRows
+}
+
+func (repo *MemoryGiveawaysRepo) UpdateParticipantCandidate(ctx context.Context, participantCandidate *entities.ThxParticipantCandidate, isAccepted bool) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	now := time.Now()
+	participantCandidate.AcceptTime = &now
+	participantCandidate.IsAccepted = sql.NullBool{Bool: isAccepted, Valid: true}
+	for i := range repo.candidates {
+		if repo.candidates[i].Id == participantCandidate.Id {
+			repo.candidates[i] = *participantCandidate
+		}
+	}
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetExpiredParticipantCandidates(ctx context.Context, guildId string, createdBefore time.Time, limit int) (result []entities.ThxParticipantCandidate, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, candidate := range repo.candidates {
+		if candidate.GuildId == guildId && !candidate.IsAccepted.Valid && candidate.CreatedAt.Before(createdBefore) {
+			result = append(result, candidate)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result[:min(limit, len(result))], nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetUnreviewedParticipants(ctx context.Context, guildId string, limit int) (result []entities.GiveawayParticipant, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, participant := range repo.participants {
+		if participant.GuildId != guildId || participant.IsAccepted.Valid || len(result) >= limit {
+			continue
+		}
+		if giveaway := repo.findGiveaway(participant.GiveawayId); giveaway != nil && giveaway.Type == entities.ThxGiveawayType && giveaway.EndTime != nil {
+			result = append(result, participant)
+		}
+	}
+
+	return result, nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetPendingParticipants(ctx context.Context, giveawayId int) (result []entities.GiveawayParticipant, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, participant := range repo.participants {
+		if participant.GiveawayId == giveawayId && !participant.IsAccepted.Valid {
+			result = append(result, participant)
+		}
+	}
+
+	return result, nil
+}
+
+func (repo *MemoryGiveawaysRepo) MoveParticipant(ctx context.Context, participant *entities.GiveawayParticipant, giveawayId int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.participants {
+		if repo.participants[i].Id == participant.Id {
+			repo.participants[i].GiveawayId = giveawayId
+		}
+	}
+	participant.GiveawayId = giveawayId
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) IsGiveawayEnded(ctx context.Context, giveawayId int) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	giveaway := repo.findGiveaway(giveawayId)
+
+	return giveaway != nil && giveaway.EndTime != nil, nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetGiveawayById(ctx context.Context, giveawayId int) (*entities.Giveaway, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	giveaway := repo.findGiveaway(giveawayId)
+	if giveaway == nil {
+		return nil, sql.ErrNoRows
+	}
+	result := *giveaway
+
+	return &result, nil
+}
+
+func (repo *MemoryGiveawaysRepo) winsForUser(userId string) []entities.GiveawayWin {
+	var wins []entities.GiveawayWin
+	for _, winner := range repo.winners {
+		if winner.UserId != userId {
+			continue
+		}
+		giveaway := repo.findGiveaway(winner.GiveawayId)
+		if giveaway == nil {
+			continue
+		}
+		wins = append(wins, entities.GiveawayWin{GiveawayWinner: winner, GuildId: giveaway.GuildId, GiveawayType: giveaway.Type, GiveawayEndTime: giveaway.EndTime})
+	}
+	sort.SliceStable(wins, func(i, j int) bool {
+		if wins[i].GiveawayEndTime == nil || wins[j].GiveawayEndTime == nil {
+			if wins[i].GiveawayEndTime == nil && wins[j].GiveawayEndTime == nil {
+				return wins[i].Id > wins[j].Id
+			}
+			return wins[i].GiveawayEndTime != nil
+		}
+		if wins[i].GiveawayEndTime.Equal(*wins[j].GiveawayEndTime) {
+			return wins[i].Id > wins[j].Id
+		}
+		return wins[i].GiveawayEndTime.After(*wins[j].GiveawayEndTime)
+	})
+
+	return wins
+}
+
+func (repo *MemoryGiveawaysRepo) GetWinsForUser(ctx context.Context, userId string, offset, limit int) ([]entities.GiveawayWin, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	wins := repo.winsForUser(userId)
+	if offset >= len(wins) {
+		return nil, nil
+	}
+
+	return wins[offset:min(offset+limit, len(wins))], nil
+}
+
+func (repo *MemoryGiveawaysRepo) CountWinsForUser(ctx context.Context, userId string) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	return len(repo.winsForUser(userId)), nil
+}
+
+func (repo *MemoryGiveawaysRepo) RemoveAllThxParticipantEntries(ctx context.Context, giveawayId int, participantId string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.candidates {
+		if repo.candidates[i].CandidateId == participantId && repo.candidates[i].GiveawayId == giveawayId {
+			repo.candidates[i].IsAccepted = sql.NullBool{Bool: false, Valid: true}
+		}
+	}
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) UpdateParticipant(ctx context.Context, participant *entities.GiveawayParticipant, acceptUserId, acceptUsername string, isAccepted bool) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	now := time.Now()
+	participant.AcceptTime = &now
+	participant.IsAccepted = sql.NullBool{Bool: isAccepted, Valid: true}
+	participant.AcceptUserId = sql.NullString{String: acceptUserId, Valid: true}
+	participant.AcceptUser = sql.NullString{String: acceptUsername, Valid: true}
+	for i := range repo.participants {
+		if repo.participants[i].Id == participant.Id {
+			repo.participants[i] = *participant
+		}
+	}
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) UpdateUserDailyMessageCount(ctx context.Context, userId string, guildId string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	day := time.Now().Format(time.DateOnly)
+	for i := range repo.dailyMessages {
+		if repo.dailyMessages[i].Day == day && repo.dailyMessages[i].UserId == userId && repo.dailyMessages[i].GuildId == guildId {
+			repo.dailyMessages[i].Count++
+			return nil
+		}
+	}
+	repo.dailyMessages = append(repo.dailyMessages, entities.DailyUserMessages{
+		Id:      repo.nextId(),
+		UserId:  userId,
+		Day:     day,
+		GuildId: guildId,
+		Count:   1,
+	})
+
+	return nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetUsersWithMessagesFromLastDays(ctx context.Context, dayCount int, guildId string) ([]string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	since := time.Now().AddDate(0, 0, -dayCount)
+	seen := make(map[string]bool)
+	var users []string
+	for _, messages := range repo.dailyMessages {
+		if messages.GuildId != guildId || seen[messages.UserId] {
+			continue
+		}
+		day, err := time.ParseInLocation(time.DateOnly, messages.Day, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		if !day.After(since) {
+			continue
+		}
+		seen[messages.UserId] = true
+		users = append(users, messages.UserId)
+	}
+
+	return users, nil
+}
+
+func (repo *MemoryGiveawaysRepo) GetCodesForInfoMessage(ctx context.Context, messageId, userId string) ([]string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var codes []string
+	for _, winner := range repo.winnersForInfoMessage(messageId, userId) {
+		if winner.Code != "" {
+			codes = append(codes, winner.Code)
+		}
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,128 @@
+package repos
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func acceptThx(t *testing.T, repo *MemoryGiveawaysRepo, guildId, userId string, joinTime time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	err := repo.InsertParticipant(ctx, 1, 0, guildId, userId, userId, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("InsertParticipant: %v", err)
+	}
+	participant := repo.participants[len(repo.participants)-1]
+	err = repo.UpdateParticipant(ctx, &participant, "admin", "admin", true)
+	if err != nil {
+		t.Fatalf("UpdateParticipant: %v", err)
+	}
+	repo.participants[len(repo.participants)-1].JoinTime = joinTime
+}
+
+func TestMemoryGiveawaysRepo_GetParticipantsWithThxAmount(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := NewMemoryGiveawaysRepo()
+	acceptThx(t, repo, "guild", "once", now)
+	acceptThx(t, repo, "guild", "twice", now)
+	acceptThx(t, repo, "guild", "twice", now)
+	acceptThx(t, repo, "guild", "old", now.AddDate(0, 0, -60))
+	acceptThx(t, repo, "guild", "old", now.AddDate(0, 0, -60))
+	acceptThx(t, repo, "other", "twice", now)
+
+	// A pending thx does not count
+	err := repo.InsertParticipant(ctx, 1, 0, "guild", "once", "once", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("InsertParticipant: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		minThxAmount int
+		since        time.Time
+		want         []entities.ThxParticipantWithThxAmount
+	}{
+		{
+			name:         "more than the minimum of all time",
+			minThxAmount: 1,
+			want:         []entities.ThxParticipantWithThxAmount{{UserId: "old", ThxAmount: 2}, {UserId: "twice", ThxAmount: 2}},
+		},
+		{
+			name:         "exactly the minimum is not enough",
+			minThxAmount: 2,
+		},
+		{
+			name:         "thanks outside the window do not count",
+			minThxAmount: 1,
+			since:        now.AddDate(0, 0, -30),
+			want:         []entities.ThxParticipantWithThxAmount{{UserId: "twice", ThxAmount: 2}},
+		},
+		{
+			name:         "zero minimum counts every accepted thx",
+			minThxAmount: 0,
+			since:        now.AddDate(0, 0, -30),
+			want:         []entities.ThxParticipantWithThxAmount{{UserId: "once", ThxAmount: 1}, {UserId: "twice", ThxAmount: 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.GetParticipantsWithThxAmount(ctx, "guild", tt.minThxAmount, tt.since)
+			if err != nil {
+				t.Fatalf("GetParticipantsWithThxAmount: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetParticipantsWithThxAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryGiveawaysRepo_GetUsersWithMessagesFromLastDays(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryGiveawaysRepo()
+	for _, message := range []struct{ userId, guildId string }{{"today", "guild"}, {"today", "guild"}, {"elsewhere", "other"}} {
+		err := repo.UpdateUserDailyMessageCount(ctx, message.userId, message.guildId)
+		if err != nil {
+			t.Fatalf("UpdateUserDailyMessageCount: %v", err)
+		}
+	}
+	if repo.dailyMessages[0].Count != 2 {
+		t.Errorf("messages of the same day counted as %d, want 2", repo.dailyMessages[0].Count)
+	}
+
+	day := func(daysAgo int) string {
+		return time.Now().AddDate(0, 0, -daysAgo).Format(time.DateOnly)
+	}
+	repo.dailyMessages = append(repo.dailyMessages,
+		entities.DailyUserMessages{UserId: "recent", Day: day(29), GuildId: "guild", Count: 1},
+		entities.DailyUserMessages{UserId: "expired", Day: day(30), GuildId: "guild", Count: 1},
+		entities.DailyUserMessages{UserId: "ancient", Day: day(90), GuildId: "guild", Count: 5},
+	)
+
+	tests := []struct {
+		name     string
+		dayCount int
+		want     []string
+	}{
+		{name: "last 30 days", dayCount: 30, want: []string{"recent", "today"}},
+		{name: "last day", dayCount: 1, want: []string{"today"}},
+		{name: "last 100 days", dayCount: 100, want: []string{"ancient", "expired", "recent", "today"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.GetUsersWithMessagesFromLastDays(ctx, tt.dayCount, "guild")
+			if err != nil {
+				t.Fatalf("GetUsersWithMessagesFromLastDays: %v", err)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetUsersWithMessagesFromLastDays() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package repos
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// MemoryServerRepo is an in-memory implementation of entities.ServerRepo.
+type MemoryServerRepo struct {
+	mu             sync.Mutex
+	serverConfigs  []entities.ServerConfig
+	voucherConfigs []entities.VoucherConfig
+	helperTiers    []entities.HelperTier
+	lastId         int
+}
+
+var _ entities.ServerRepo = (*MemoryServerRepo)(nil)
+
+func NewMemoryServerRepo() *MemoryServerRepo {
+	return &MemoryServerRepo{}
+}
+
+func (repo *MemoryServerRepo) findServerConfig(guildId string) *entities.ServerConfig {
+	for i := range repo.serverConfigs {
+		if repo.serverConfigs[i].GuildId == guildId {
+			return &repo.serverConfigs[i]
+		}
+	}
+	return nil
+}
+
+func (repo *MemoryServerRepo) GetServerConfigForGuild(ctx context.Context, guildId string) (entities.ServerConfig, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	serverConfig := repo.findServerConfig(guildId)
+	if serverConfig == nil {
+		return entities.ServerConfig{}, sql.ErrNoRows
+	}
+	return *serverConfig, nil
+}
+
+func (repo *MemoryServerRepo) InsertServerConfig(ctx context.Context, guildId, giveawayChannel, adminRole string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.lastId++
+	repo.serverConfigs = append(repo.serverConfigs, entities.ServerConfig{
+		Id:                            repo.lastId,
+		GuildId:                       guildId,
+		AdminRoleId:                   adminRole,
+		StatusChannelsId:              json.RawMessage("{}"),
+		MainChannel:                   giveawayChannel,
+		UnconditionalGiveawayChannel:  giveawayChannel,
+		ConditionalGiveawayChannel:    giveawayChannel,
+		ConditionalGiveawayLevels:     json.RawMessage("[]"),
+		ThxWeighting:                  entities.ThxWeightingPerThx,
+		ThxWeightingCap:               entities.DefaultThxWeightingCap,
+		Timezone:                      entities.DefaultTimezone,
+		ThxGiveawaySchedule:           entities.DefaultThxGiveawaySchedule,
+		MessageGiveawaySchedule:       entities.DefaultMessageGiveawaySchedule,
+		UnconditionalGiveawaySchedule: entities.DefaultUnconditionalGiveawaySchedule,
+		ConditionalGiveawaySchedule:   entities.DefaultConditionalGiveawaySchedule,
+		ExpiredVoucherPolicy:          entities.ExpiredVoucherPolicyNone,
+		ThxmeExpiryHours:              entities.DefaultThxmeExpiryHours,
+		UnreviewedThxPolicy:           entities.UnreviewedThxPolicyReject,
+	})
+	return nil
+}
+
+func (repo *MemoryServerRepo) UpdateServerConfig(ctx context.Context, serverConfig *entities.ServerConfig) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.serverConfigs {
+		if repo.serverConfigs[i].Id == serverConfig.Id {
+			repo.serverConfigs[i] = *serverConfig
+		}
+	}
+	return nil
+}
+
+func (repo *MemoryServerRepo) GetAdminRoleForGuild(ctx context.Context, guildId string) (string, error) {
+	serverConfig, err := repo.GetServerConfigForGuild(ctx, guildId)
+	if err != nil {
+		return "", err
+	}
+	return serverConfig.AdminRoleId, nil
+}
+
+func (repo *MemoryServerRepo) GetMainChannelForGuild(ctx context.Context, guildId string) (string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	serverConfig := repo.findServerConfig(guildId)
+	if serverConfig == nil {
+		return "", nil
+	}
+	return serverConfig.MainChannel, nil
+}
+
+func (repo *MemoryServerRepo) GetGuildsWithMessageGiveawaysEnabled(ctx context.Context) ([]string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var guilds []string
+	for _, serverConfig := range repo.serverConfigs {
+		if serverConfig.MessageGiveawayWinners > 0 {
+			guilds = append(guilds, serverConfig.GuildId)
+		}
+	}
+	return guilds, nil
+}
+
+func (repo *MemoryServerRepo) GetGuildsWithHelperTiers(ctx context.Context) ([]string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var guilds []string
+	seen := make(map[string]bool)
+	for _, tier := range repo.helperTiers {
+		if !seen[tier.GuildId] {
+			seen[tier.GuildId] = true
+			guilds = append(guilds, tier.GuildId)
+		}
+	}
+	return guilds, nil
+}
+
+func (repo *MemoryServerRepo) GetConditionalGiveawayLevels(ctx context.Context, guildId string) ([]int, error) {
+	serverConfig, err := repo.GetServerConfigForGuild(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	var levels []int
+	err = json.Unmarshal(serverConfig.ConditionalGiveawayLevels, &levels)
+	if err != nil {
+		return nil, err
+	}
+
+	return levels, nil
+}
+
+func (repo *MemoryServerRepo) GetVoucherConfig(ctx context.Context, guildId, giveawayType string) (entities.VoucherConfig, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, voucherConfig := range repo.voucherConfigs {
+		if voucherConfig.GuildId == guildId && voucherConfig.GiveawayType == giveawayType {
+			return voucherConfig, nil
+		}
+	}
+	return entities.VoucherConfig{}, sql.ErrNoRows
+}
+
+func (repo *MemoryServerRepo) GetVoucherConfigs(ctx context.Context) ([]entities.VoucherConfig, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	return append([]entities.VoucherConfig{}, repo.voucherConfigs...), nil
+}
+
+func (repo *MemoryServerRepo) SetVoucherConfig(ctx context.Context, voucherConfig *entities.VoucherConfig) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.voucherConfigs {
+		if repo.voucherConfigs[i].GuildId == voucherConfig.GuildId && repo.voucherConfigs[i].GiveawayType == voucherConfig.GiveawayType {
+			repo.voucherConfigs[i] = *voucherConfig
+			return nil
+		}
+	}
+	repo.voucherConfigs = append(repo.voucherConfigs, *voucherConfig)
+	return nil
+}
+
+func (repo *MemoryServerRepo) GetHelperTiers(ctx context.Context, guildId string) (result []entities.HelperTier, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, tier := range repo.helperTiers {
+		if tier.GuildId == guildId {
+			result = append(result, tier)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].ThxesNeeded < result[j].ThxesNeeded
+	})
+
+	return result, nil
+}
+
+func (repo *MemoryServerRepo) SetHelperTier(ctx context.Context, tier *entities.HelperTier) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.helperTiers {
+		if repo.helperTiers[i].GuildId == tier.GuildId && repo.helperTiers[i].RoleId == tier.RoleId {
+			repo.helperTiers[i] = *tier
+			return nil
+		}
+	}
+	repo.helperTiers = append(repo.helperTiers, *tier)
+	return nil
+}
+
+func (repo *MemoryServerRepo) RemoveHelperTier(ctx context.Context, guildId, roleId string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.helperTiers {
+		if repo.helperTiers[i].GuildId == guildId && repo.helperTiers[i].RoleId == roleId {
+			repo.helperTiers = append(repo.helperTiers[:i], repo.helperTiers[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package repos
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"database/sql"
+	"sync"
+)
+
+// MemoryStatusRepo is an in-memory implementation of entities.StatusRepo.
+type MemoryStatusRepo struct {
+	mu       sync.Mutex
+	statuses []entities.Status
+	lastId   int
+}
+
+var _ entities.StatusRepo = (*MemoryStatusRepo)(nil)
+
+func NewMemoryStatusRepo() *MemoryStatusRepo {
+	return &MemoryStatusRepo{}
+}
+
+func (repo *MemoryStatusRepo) GetAllStatuses(ctx context.Context, guildId string) ([]entities.Status, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var statuses []entities.Status
+	for _, status := range repo.statuses {
+		if status.GuildId == guildId {
+			statuses = append(statuses, status)
+		}
+	}
+
+	return statuses, nil
+}
+
+func (repo *MemoryStatusRepo) GetStatusById(ctx context.Context, id int64) (*entities.Status, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, status := range repo.statuses {
+		if int64(status.Id) == id {
+			return &status, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (repo *MemoryStatusRepo) UpdateStatus(ctx context.Context, status *entities.Status) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.statuses {
+		if repo.statuses[i].Id == status.Id {
+			repo.statuses[i] = *status
+		}
+	}
+	return nil
+}
+
+func (repo *MemoryStatusRepo) CreateStatus(ctx context.Context, status *entities.Status) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.lastId++
+	created := *status
+	created.Id = repo.lastId
+	repo.statuses = append(repo.statuses, created)
+	return nil
+}
+
+func (repo *MemoryStatusRepo) RemoveStatus(ctx context.Context, id int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	statuses := repo.statuses[:0]
+	for _, status := range repo.statuses {
+		if status.Id != id {
+			statuses = append(statuses, status)
+		}
+	}
+	repo.statuses = statuses
+	return nil
+}
@@ -0,0 +1,224 @@
+package repos
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryUserRepo is an in-memory implementation of entities.UserRepo.
+type MemoryUserRepo struct {
+	mu               sync.Mutex
+	blacklists       []entities.Blacklist
+	memberRoles      []entities.MemberRole
+	helperBlacklists []entities.HelperBlacklist
+	lastId           int
+}
+
+var _ entities.UserRepo = (*MemoryUserRepo)(nil)
+
+func NewMemoryUserRepo() *MemoryUserRepo {
+	return &MemoryUserRepo{}
+}
+
+func (repo *MemoryUserRepo) nextId() int {
+	repo.lastId++
+	return repo.lastId
+}
+
+func (repo *MemoryUserRepo) GetRolesForMember(ctx context.Context, guildId, memberId string) ([]entities.MemberRole, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var result []entities.MemberRole
+	for _, memberRole := range repo.memberRoles {
+		if memberRole.GuildId == guildId && memberRole.MemberId == memberId {
+			result = append(result, memberRole)
+		}
+	}
+
+	return result, nil
+}
+
+func (repo *MemoryUserRepo) AddRoleForMember(ctx context.Context, guildId, memberId, roleId string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.memberRoles = append(repo.memberRoles, entities.MemberRole{Id: repo.nextId(), GuildId: guildId, MemberId: memberId, RoleId: roleId})
+	return nil
+}
+
+func (repo *MemoryUserRepo) RemoveRoleForMember(ctx context.Context, guildId, memberId, roleId string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	memberRoles := repo.memberRoles[:0]
+	for _, memberRole := range repo.memberRoles {
+		if memberRole.GuildId == guildId && memberRole.MemberId == memberId && memberRole.RoleId == roleId {
+			continue
+		}
+		memberRoles = append(memberRoles, memberRole)
+	}
+	repo.memberRoles = memberRoles
+	return nil
+}
+
+func (repo *MemoryUserRepo) IsUserHelperBlacklisted(ctx context.Context, userId, guildId string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, blacklist := range repo.helperBlacklists {
+		if blacklist.GuildId == guildId && blacklist.UserId == userId && isBlacklistActive(blacklist.ExpiresAt, time.Now()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (repo *MemoryUserRepo) IsUserBlacklisted(ctx context.Context, userId, guildId string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, blacklist := range repo.blacklists {
+		if blacklist.GuildId == guildId && blacklist.UserId == userId && isBlacklistActive(blacklist.ExpiresAt, time.Now()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (repo *MemoryUserRepo) AddBlacklistForUser(ctx context.Context, userId, guildId, blacklisterId, reason string, expiresAt *time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	now := time.Now()
+	blacklist := entities.Blacklist{Id: repo.nextId(), GuildId: guildId, UserId: userId, BlacklisterId: blacklisterId, Reason: reason, CreatedAt: &now, ExpiresAt: expiresAt}
+	for i := range repo.blacklists {
+		if repo.blacklists[i].GuildId == guildId && repo.blacklists[i].UserId == userId {
+			if isBlacklistActive(repo.blacklists[i].ExpiresAt, now) {
+				return fmt.Errorf("duplicate blacklist entry for user %s in guild %s", userId, guildId)
+			}
+			blacklist.Id = repo.blacklists[i].Id
+			repo.blacklists[i] = blacklist
+			return nil
+		}
+	}
+	repo.blacklists = append(repo.blacklists, blacklist)
+	return nil
+}
+
+func (repo *MemoryUserRepo) RemoveBlacklistForUser(ctx context.Context, userId, guildId string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	blacklists := repo.blacklists[:0]
+	for _, blacklist := range repo.blacklists {
+		if blacklist.GuildId == guildId && blacklist.UserId == userId {
+			continue
+		}
+		blacklists = append(blacklists, blacklist)
+	}
+	repo.blacklists = blacklists
+	return nil
+}
+
+func (repo *MemoryUserRepo) AddHelperBlacklistForUser(ctx context.Context, userId, guildId, blacklisterId, reason string, expiresAt *time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	now := time.Now()
+	blacklist := entities.HelperBlacklist{Id: repo.nextId(), GuildId: guildId, UserId: userId, BlacklisterId: blacklisterId, Reason: reason, CreatedAt: &now, ExpiresAt: expiresAt}
+	for i := range repo.helperBlacklists {
+		if repo.helperBlacklists[i].GuildId == guildId && repo.helperBlacklists[i].UserId == userId {
+			if isBlacklistActive(repo.helperBlacklists[i].ExpiresAt, now) {
+				return fmt.Errorf("duplicate helper blacklist entry for user %s in guild %s", userId, guildId)
+			}
+			blacklist.Id = repo.helperBlacklists[i].Id
+			repo.helperBlacklists[i] = blacklist
+			return nil
+		}
+	}
+	repo.helperBlacklists = append(repo.helperBlacklists, blacklist)
+	return nil
+}
+
+func (repo *MemoryUserRepo) RemoveHelperBlacklistForUser(ctx context.Context, userId, guildId string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	helperBlacklists := repo.helperBlacklists[:0]
+	for _, blacklist := range repo.helperBlacklists {
+		if blacklist.GuildId == guildId && blacklist.UserId == userId {
+			continue
+		}
+		helperBlacklists = append(helperBlacklists, blacklist)
+	}
+	repo.helperBlacklists = helperBlacklists
+	return nil
+}
+
+func (repo *MemoryUserRepo) GetBlacklists(ctx context.Context, guildId string) ([]entities.Blacklist, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var blacklists []entities.Blacklist
+	for _, blacklist := range repo.blacklists {
+		if blacklist.GuildId == guildId && isBlacklistActive(blacklist.ExpiresAt, time.Now()) {
+			blacklists = append(blacklists, blacklist)
+		}
+	}
+	sort.SliceStable(blacklists, func(i, j int) bool {
+		return expiresBefore(blacklists[i].ExpiresAt, blacklists[j].ExpiresAt)
+	})
+	return blacklists, nil
+}
+
+func (repo *MemoryUserRepo) GetExpiredBlacklists(ctx context.Context, now time.Time) ([]entities.Blacklist, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var blacklists []entities.Blacklist
+	for _, blacklist := range repo.blacklists {
+		if !isBlacklistActive(blacklist.ExpiresAt, now) {
+			blacklists = append(blacklists, blacklist)
+		}
+	}
+	sort.SliceStable(blacklists, func(i, j int) bool {
+		return expiresBefore(blacklists[i].ExpiresAt, blacklists[j].ExpiresAt)
+	})
+	return blacklists, nil
+}
+
+func (repo *MemoryUserRepo) GetHelperBlacklists(ctx context.Context, guildId string) ([]entities.HelperBlacklist, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var blacklists []entities.HelperBlacklist
+	for _, blacklist := range repo.helperBlacklists {
+		if blacklist.GuildId == guildId && isBlacklistActive(blacklist.ExpiresAt, time.Now()) {
+			blacklists = append(blacklists, blacklist)
+		}
+	}
+	sort.SliceStable(blacklists, func(i, j int) bool {
+		return expiresBefore(blacklists[i].ExpiresAt, blacklists[j].ExpiresAt)
+	})
+	return blacklists, nil
+}
+
+func (repo *MemoryUserRepo) GetExpiredHelperBlacklists(ctx context.Context, now time.Time) ([]entities.HelperBlacklist, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var blacklists []entities.HelperBlacklist
+	for _, blacklist := range repo.helperBlacklists {
+		if !isBlacklistActive(blacklist.ExpiresAt, now) {
+			blacklists = append(blacklists, blacklist)
+		}
+	}
+	sort.SliceStable(blacklists, func(i, j int) bool {
+		return expiresBefore(blacklists[i].ExpiresAt, blacklists[j].ExpiresAt)
+	})
+	return blacklists, nil
+}
+
+func isBlacklistActive(expiresAt *time.Time, now time.Time) bool {
+	return expiresAt == nil || expiresAt.After(now)
+}
+
+// expiresBefore orders the blacklist entries by expiry, the permanent ones last.
+func expiresBefore(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a != nil && b == nil
+	}
+	return a.Before(*b)
+}
@@ -0,0 +1,122 @@
+package repos
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// MemoryVoucherRepo is an in-memory implementation of entities.VoucherRepo.
+type MemoryVoucherRepo struct {
+	mu       sync.Mutex
+	vouchers []entities.PooledVoucher
+	lastId   int
+}
+
+var _ entities.VoucherRepo = (*MemoryVoucherRepo)(nil)
+
+func NewMemoryVoucherRepo() *MemoryVoucherRepo {
+	return &MemoryVoucherRepo{}
+}
+
+func (repo *MemoryVoucherRepo) AddPooledVouchers(ctx context.Context, vouchers []entities.PooledVoucher) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range vouchers {
+		repo.lastId++
+		vouchers[i].Id = repo.lastId
+		repo.vouchers = append(repo.vouchers, vouchers[i])
+	}
+	return nil
+}
+
+func (repo *MemoryVoucherRepo) TakePooledVoucher(ctx context.Context, profile entities.VoucherProfile, createdAfter, expiresAfter time.Time) (*entities.PooledVoucher, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	// Vouchers are appended in creation order, so the first match is the oldest one
+	for i, voucher := range repo.vouchers {
+		if voucher.WinnerId == nil && voucher.Profile == profile && voucher.CreatedAt.After(createdAfter) && voucher.ExpiresAt.After(expiresAfter) {
+			repo.vouchers = append(repo.vouchers[:i], repo.vouchers[i+1:]...)
+			return &voucher, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (repo *MemoryVoucherRepo) ReservePooledVoucher(ctx context.Context, profile entities.VoucherProfile, createdAfter, expiresAfter time.Time, winnerId int) (*entities.PooledVoucher, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for i := range repo.vouchers {
+		voucher := &repo.vouchers[i]
+		if voucher.WinnerId == nil && voucher.Profile == profile && voucher.CreatedAt.After(createdAfter) && voucher.ExpiresAt.After(expiresAfter) {
+			voucher.WinnerId = &winnerId
+			reserved := *voucher
+			return &reserved, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (repo *MemoryVoucherRepo) GetReservedVoucher(ctx context.Context, winnerId int) (*entities.PooledVoucher, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	for _, voucher := range repo.vouchers {
+		if voucher.WinnerId != nil && *voucher.WinnerId == winnerId {
+			return &voucher, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (repo *MemoryVoucherRepo) RemoveReservedVoucher(ctx context.Context, winnerId int) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	kept := repo.vouchers[:0]
+	for _, voucher := range repo.vouchers {
+		if voucher.WinnerId == nil || *voucher.WinnerId != winnerId {
+			kept = append(kept, voucher)
+		}
+	}
+	repo.vouchers = kept
+	return nil
+}
+
+func (repo *MemoryVoucherRepo) CountPooledVouchers(ctx context.Context, profile entities.VoucherProfile, createdAfter, expiresAfter time.Time) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	count := 0
+	for _, voucher := range repo.vouchers {
+		if voucher.WinnerId == nil && voucher.Profile == profile && voucher.CreatedAt.After(createdAfter) && voucher.ExpiresAt.After(expiresAfter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (repo *MemoryVoucherRepo) RemoveExpiredPooledVouchers(ctx context.Context, expiresBefore time.Time) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	kept := repo.vouchers[:0]
+	for _, voucher := range repo.vouchers {
+		if voucher.WinnerId != nil || voucher.ExpiresAt.After(expiresBefore) {
+			kept = append(kept, voucher)
+		}
+	}
+	removed := len(repo.vouchers) - len(kept)
+	repo.vouchers = kept
+	return removed, nil
+}
+
+func (repo *MemoryVoucherRepo) CountAgedPooledVouchers(ctx context.Context, createdBefore time.Time) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	count := 0
+	for _, voucher := range repo.vouchers {
+		if voucher.WinnerId == nil && !voucher.CreatedAt.After(createdBefore) {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,119 @@
+package repos
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"strings"
+	"time"
+
+	"github.com/go-gorp/gorp"
+)
+
+type AuditLogRepo struct {
+	mysql *gorp.DbMap
+}
+
+func NewAuditLogRepo(mysql *gorp.DbMap) *AuditLogRepo {
+	mysql.AddTableWithName(SqlAuditLogEntry{}, "audit_log").SetKeys(true, "id")
+
+	return &AuditLogRepo{mysql: mysql}
+}
+
+type SqlAuditLogEntry struct {
+	Id        int       `db:"id,primarykey,autoincrement"`
+	GuildId   string    `db:"guild_id,size:255"`
+	ActorId   string    `db:"actor_id,size:255"`
+	ActorName string    `db:"actor_name,size:255"`
+	Action    string    `db:"action,size:50"`
+	Target    string    `db:"target,size:255"`
+	Before    string    `db:"before_value"`
+	After     string    `db:"after_value"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func FromSqlAuditLogEntry(entry *SqlAuditLogEntry) *entities.AuditLogEntry {
+	return &entities.AuditLogEntry{
+		Id:        entry.Id,
+		GuildId:   entry.GuildId,
+		ActorId:   entry.ActorId,
+		ActorName: entry.ActorName,
+		Action:    entry.Action,
+		Target:    entry.Target,
+		Before:    entry.Before,
+		After:     entry.After,
+		CreatedAt: entry.CreatedAt,
+	}
+}
+
+func ToSqlAuditLogEntry(entry *entities.AuditLogEntry) *SqlAuditLogEntry {
+	return &SqlAuditLogEntry{
+		Id:        entry.Id,
+		GuildId:   entry.GuildId,
+		ActorId:   entry.ActorId,
+		ActorName: entry.ActorName,
+		Action:    entry.Action,
+		Target:    entry.Target,
+		Before:    entry.Before,
+		After:     entry.After,
+		CreatedAt: entry.CreatedAt,
+	}
+}
+
+// auditLogWhere builds the WHERE clause of the audit log queries selecting the entries matched by the filter.
+func auditLogWhere(filter entities.AuditLogFilter) (string, []interface{}) {
+	conditions := []string{"guild_id = ?"}
+	args := []interface{}{filter.GuildId}
+	if filter.UserId != "" {
+		conditions = append(conditions, "(actor_id = ? OR target = ?)")
+		args = append(args, filter.UserId, filter.UserId)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.Until)
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (repo *AuditLogRepo) InsertAuditLogEntry(ctx context.Context, entry *entities.AuditLogEntry) error {
+	sqlEntry := ToSqlAuditLogEntry(entry)
+	if err := repo.mysql.WithContext(ctx).Insert(sqlEntry); err != nil {
+		return err
+	}
+	entry.Id = sqlEntry.Id
+
+	return nil
+}
+
+func (repo *AuditLogRepo) GetAuditLogEntries(ctx context.Context, filter entities.AuditLogFilter, offset, limit int) (result []entities.AuditLogEntry, err error) {
+	where, args := auditLogWhere(filter)
+	var entries []SqlAuditLogEntry
+	_, err = repo.mysql.WithContext(ctx).Select(&entries, "SELECT id, guild_id, actor_id, actor_name, action, target, before_value, after_value, created_at FROM audit_log "+where+" ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?", append(args, limit, offset)...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		result = append(result, *FromSqlAuditLogEntry(&entry))
+	}
+
+	return result, nil
+}
+
+func (repo *AuditLogRepo) CountAuditLogEntries(ctx context.Context, filter entities.AuditLogFilter) (int, error) {
+	where, args := auditLogWhere(filter)
+	count, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM audit_log "+where, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
@@ -3,8 +3,11 @@ package repos
 import (
 	"context"
 	"csrvbot/domain/entities"
+	"csrvbot/pkg/fairdraw"
 	"database/sql"
+	"encoding/json"
 	"github.com/go-gorp/gorp"
+	"strings"
 	"time"
 )
 
@@ -20,6 +23,41 @@ type SqlGiveaways struct {
 	GuildId       string     `db:"guild_id, size:255"`
 	InfoMessageId *string    `db:"info_message_id, size:255"`
 	Level         *int       `db:"level"`
+	Seed          *string    `db:"seed, size:64"`
+	SeedHash      *string    `db:"seed_hash, size:64"`
+}
+
+type SqlCustomGiveaway struct {
+	GiveawayId       int       `db:"giveaway_id, primarykey"`
+	GuildId          string    `db:"guild_id, size:255"`
+	Title            string    `db:"title, size:255"`
+	Prize            string    `db:"prize"`
+	WinnersCount     int       `db:"winners_count"`
+	ScheduledEndTime time.Time `db:"scheduled_end_time"`
+	ChannelId        string    `db:"channel_id, size:255"`
+	RequiredLevel    *int      `db:"required_level"`
+	RequiredRoleId   *string   `db:"required_role_id, size:255"`
+	VoucherValue     *int      `db:"voucher_value"`
+	CreatedBy        string    `db:"created_by, size:255"`
+}
+
+type SqlPendingVoucher struct {
+	Id             int        `db:"id, primarykey, autoincrement"`
+	WinnerId       int        `db:"winner_id"`
+	GiveawayId     int        `db:"giveaway_id"`
+	GuildId        string     `db:"guild_id, size:255"`
+	UserId         string     `db:"user_id, size:255"`
+	GiveawayType   string     `db:"giveaway_type, size:20"`
+	Value          int        `db:"value"`
+	Currency       string     `db:"currency, size:3"`
+	ExpirationDays int        `db:"expiration_days"`
+	Prefix         string     `db:"prefix, size:32"`
+	GroupId        string     `db:"group_id, size:64"`
+	Attempts       int        `db:"attempts"`
+	LastError      string     `db:"last_error"`
+	NextAttemptAt  time.Time  `db:"next_attempt_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+	ResolvedAt     *time.Time `db:"resolved_at"`
 }
 
 type SqlGiveawaysParticipant struct {
@@ -36,13 +74,45 @@ type SqlGiveawaysParticipant struct {
 	AcceptTime   *time.Time     `db:"accept_time"`
 	AcceptUser   sql.NullString `db:"accept_user,size:255"`
 	AcceptUserId sql.NullString `db:"accept_user_id,size:255"`
+	ThankerId    *string        `db:"thanker_id,size:255"`
 }
 
 type SqlGiveawaysWinner struct {
 	Id         int    `db:"id,primarykey, autoincrement"`
 	GiveawayId int    `db:"giveaway_id"`
+	DrawId     *int   `db:"draw_id"`
 	UserId     string `db:"user_id, size:255"`
+	UserName   string `db:"user_name, size:255"`
 	Code       string `db:"code, size:255"`
+	Notified   bool   `db:"notified"`
+
+	RedemptionStatus string     `db:"redemption_status, size:20"`
+	ExpiresAt        *time.Time `db:"expires_at"`
+	RedeemedAt       *time.Time `db:"redeemed_at"`
+	StatusCheckedAt  *time.Time `db:"status_checked_at"`
+	ReplacesWinnerId *int       `db:"replaces_winner_id"`
+	VoucherValue     *int       `db:"voucher_value"`
+	VoucherCurrency  *string    `db:"voucher_currency, size:3"`
+}
+
+type SqlGiveawayWin struct {
+	SqlGiveawaysWinner
+	GuildId         string     `db:"guild_id"`
+	GiveawayType    string     `db:"giveaway_type"`
+	GiveawayEndTime *time.Time `db:"giveaway_end_time"`
+}
+
+type SqlGiveawayDraw struct {
+	Id           int             `db:"id, primarykey, autoincrement"`
+	GiveawayId   int             `db:"giveaway_id"`
+	GuildId      string          `db:"guild_id, size:255"`
+	State        string          `db:"state, size:20"`
+	StartTime    time.Time       `db:"start_time"`
+	EndTime      *time.Time      `db:"end_time"`
+	Participants json.RawMessage `db:"participants"`
+	Skipped      json.RawMessage `db:"skipped"`
+
+	AnnouncementMessageId *string `db:"announcement_message_id, size:255"`
 }
 
 type SqlThxParticipantCandidate struct {
@@ -58,6 +128,7 @@ type SqlThxParticipantCandidate struct {
 	ChannelId             string       `db:"channel_id, size:255"`
 	IsAccepted            sql.NullBool `db:"is_accepted"`
 	AcceptTime            *time.Time   `db:"accept_time"`
+	CreatedAt             time.Time    `db:"created_at"`
 }
 
 type SqlThxNotification struct {
@@ -66,21 +137,49 @@ type SqlThxNotification struct {
 	NotificationMessageId string `db:"notification_message_id, size:255"`
 }
 
+type SqlThxAbuseCheck struct {
+	Id          int       `db:"id, primarykey, autoincrement"`
+	GuildId     string    `db:"guild_id, size:255"`
+	MessageId   string    `db:"message_id, size:255"`
+	ChannelId   string    `db:"channel_id, size:255"`
+	ThankerId   string    `db:"thanker_id, size:255"`
+	RecipientId string    `db:"recipient_id, size:255"`
+	Flags       string    `db:"flags, size:255"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+type SqlThxLimitHit struct {
+	Id           int       `db:"id, primarykey, autoincrement"`
+	GuildId      string    `db:"guild_id, size:255"`
+	UserId       string    `db:"user_id, size:255"`
+	TargetUserId string    `db:"target_user_id, size:255"`
+	Limit        string    `db:"limit_type, size:20"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
 type SqlThxParticipantWithThxAmount struct {
 	UserId    string `db:"user_id, size:255"`
 	ThxAmount int    `db:"amount"`
 }
 
+type SqlThxStats struct {
+	Total    int `db:"total"`
+	Accepted int `db:"accepted"`
+	Rejected int `db:"rejected"`
+	Pending  int `db:"pending"`
+	Thankers int `db:"thankers"`
+}
+
 type SqlDailyUserMessages struct {
-	Id      int    `db:"id, primarykey, autoincrement"`
-	UserId  string `db:"user_id,size:255"`
-	Day     string `db:"day"` // fixme should be date
-	GuildId string `db:"guild_id,size:255"`
-	Count   int    `db:"count"`
+	Id      int       `db:"id, primarykey, autoincrement"`
+	UserId  string    `db:"user_id,size:255"`
+	Day     time.Time `db:"day"`
+	GuildId string    `db:"guild_id,size:255"`
+	Count   int       `db:"count"`
 }
 
 func FromSqlGiveaways(giveaway *SqlGiveaways) *entities.Giveaway {
-	return &entities.Giveaway{
+	result := &entities.Giveaway{
 		Id:            giveaway.Id,
 		Type:          giveaway.Type,
 		StartTime:     giveaway.StartTime,
@@ -89,10 +188,18 @@ func FromSqlGiveaways(giveaway *SqlGiveaways) *entities.Giveaway {
 		InfoMessageId: giveaway.InfoMessageId,
 		Level:         giveaway.Level,
 	}
+	if giveaway.Seed != nil {
+		result.Seed = *giveaway.Seed
+	}
+	if giveaway.SeedHash != nil {
+		result.SeedHash = *giveaway.SeedHash
+	}
+
+	return result
 }
 
 func ToSqlGiveaways(giveaway *entities.Giveaway) *SqlGiveaways {
-	return &SqlGiveaways{
+	result := &SqlGiveaways{
 		Id:            giveaway.Id,
 		Type:          giveaway.Type,
 		StartTime:     giveaway.StartTime,
@@ -101,6 +208,89 @@ func ToSqlGiveaways(giveaway *entities.Giveaway) *SqlGiveaways {
 		InfoMessageId: giveaway.InfoMessageId,
 		Level:         giveaway.Level,
 	}
+	if giveaway.Seed != "" {
+		result.Seed = &giveaway.Seed
+		result.SeedHash = &giveaway.SeedHash
+	}
+
+	return result
+}
+
+func FromSqlPendingVoucher(pendingVoucher *SqlPendingVoucher) *entities.PendingVoucher {
+	return &entities.PendingVoucher{
+		Id:         pendingVoucher.Id,
+		WinnerId:   pendingVoucher.WinnerId,
+		GiveawayId: pendingVoucher.GiveawayId,
+		GuildId:    pendingVoucher.GuildId,
+		UserId:     pendingVoucher.UserId,
+		VoucherConfig: entities.VoucherConfig{
+			GuildId:        pendingVoucher.GuildId,
+			GiveawayType:   pendingVoucher.GiveawayType,
+			Value:          pendingVoucher.Value,
+			Currency:       pendingVoucher.Currency,
+			ExpirationDays: pendingVoucher.ExpirationDays,
+			Prefix:         pendingVoucher.Prefix,
+			GroupId:        pendingVoucher.GroupId,
+		},
+		Attempts:      pendingVoucher.Attempts,
+		LastError:     pendingVoucher.LastError,
+		NextAttemptAt: pendingVoucher.NextAttemptAt,
+		CreatedAt:     pendingVoucher.CreatedAt,
+		ResolvedAt:    pendingVoucher.ResolvedAt,
+	}
+}
+
+func ToSqlPendingVoucher(pendingVoucher *entities.PendingVoucher) *SqlPendingVoucher {
+	return &SqlPendingVoucher{
+		Id:             pendingVoucher.Id,
+		WinnerId:       pendingVoucher.WinnerId,
+		GiveawayId:     pendingVoucher.GiveawayId,
+		GuildId:        pendingVoucher.GuildId,
+		UserId:         pendingVoucher.UserId,
+		GiveawayType:   pendingVoucher.VoucherConfig.GiveawayType,
+		Value:          pendingVoucher.VoucherConfig.Value,
+		Currency:       pendingVoucher.VoucherConfig.Currency,
+		ExpirationDays: pendingVoucher.VoucherConfig.ExpirationDays,
+		Prefix:         pendingVoucher.VoucherConfig.Prefix,
+		GroupId:        pendingVoucher.VoucherConfig.GroupId,
+		Attempts:       pendingVoucher.Attempts,
+		LastError:      pendingVoucher.LastError,
+		NextAttemptAt:  pendingVoucher.NextAttemptAt,
+		CreatedAt:      pendingVoucher.CreatedAt,
+		ResolvedAt:     pendingVoucher.ResolvedAt,
+	}
+}
+
+func FromSqlCustomGiveaway(customGiveaway *SqlCustomGiveaway) *entities.CustomGiveaway {
+	return &entities.CustomGiveaway{
+		GiveawayId:       customGiveaway.GiveawayId,
+		GuildId:          customGiveaway.GuildId,
+		Title:            customGiveaway.Title,
+		Prize:            customGiveaway.Prize,
+		WinnersCount:     customGiveaway.WinnersCount,
+		ScheduledEndTime: customGiveaway.ScheduledEndTime,
+		ChannelId:        customGiveaway.ChannelId,
+		RequiredLevel:    customGiveaway.RequiredLevel,
+		RequiredRoleId:   customGiveaway.RequiredRoleId,
+		VoucherValue:     customGiveaway.VoucherValue,
+		CreatedBy:        customGiveaway.CreatedBy,
+	}
+}
+
+func ToSqlCustomGiveaway(customGiveaway *entities.CustomGiveaway) *SqlCustomGiveaway {
+	return &SqlCustomGiveaway{
+		GiveawayId:       customGiveaway.GiveawayId,
+		GuildId:          customGiveaway.GuildId,
+		Title:            customGiveaway.Title,
+		Prize:            customGiveaway.Prize,
+		WinnersCount:     customGiveaway.WinnersCount,
+		ScheduledEndTime: customGiveaway.ScheduledEndTime,
+		ChannelId:        customGiveaway.ChannelId,
+		RequiredLevel:    customGiveaway.RequiredLevel,
+		RequiredRoleId:   customGiveaway.RequiredRoleId,
+		VoucherValue:     customGiveaway.VoucherValue,
+		CreatedBy:        customGiveaway.CreatedBy,
+	}
 }
 
 func FromSqlGiveawaysParticipant(participant *SqlGiveawaysParticipant) *entities.GiveawayParticipant {
@@ -118,6 +308,7 @@ func FromSqlGiveawaysParticipant(participant *SqlGiveawaysParticipant) *entities
 		AcceptTime:   participant.AcceptTime,
 		AcceptUser:   participant.AcceptUser,
 		AcceptUserId: participant.AcceptUserId,
+		ThankerId:    participant.ThankerId,
 	}
 }
 
@@ -136,6 +327,7 @@ func ToSqlGiveawaysParticipant(participant *entities.GiveawayParticipant) *SqlGi
 		AcceptTime:   participant.AcceptTime,
 		AcceptUser:   participant.AcceptUser,
 		AcceptUserId: participant.AcceptUserId,
+		ThankerId:    participant.ThankerId,
 	}
 }
 
@@ -143,8 +335,19 @@ func FromSqlGiveawaysWinner(winner *SqlGiveawaysWinner) *entities.GiveawayWinner
 	return &entities.GiveawayWinner{
 		Id:         winner.Id,
 		GiveawayId: winner.GiveawayId,
+		DrawId:     winner.DrawId,
 		UserId:     winner.UserId,
+		UserName:   winner.UserName,
 		Code:       winner.Code,
+		Notified:   winner.Notified,
+
+		RedemptionStatus: winner.RedemptionStatus,
+		ExpiresAt:        winner.ExpiresAt,
+		RedeemedAt:       winner.RedeemedAt,
+		StatusCheckedAt:  winner.StatusCheckedAt,
+		ReplacesWinnerId: winner.ReplacesWinnerId,
+		VoucherValue:     winner.VoucherValue,
+		VoucherCurrency:  winner.VoucherCurrency,
 	}
 }
 
@@ -152,11 +355,88 @@ func ToSqlGiveawaysWinner(winner *entities.GiveawayWinner) *SqlGiveawaysWinner {
 	return &SqlGiveawaysWinner{
 		Id:         winner.Id,
 		GiveawayId: winner.GiveawayId,
+		DrawId:     winner.DrawId,
 		UserId:     winner.UserId,
+		UserName:   winner.UserName,
 		Code:       winner.Code,
+		Notified:   winner.Notified,
+
+		RedemptionStatus: winner.RedemptionStatus,
+		ExpiresAt:        winner.ExpiresAt,
+		RedeemedAt:       winner.RedeemedAt,
+		StatusCheckedAt:  winner.StatusCheckedAt,
+		ReplacesWinnerId: winner.ReplacesWinnerId,
+		VoucherValue:     winner.VoucherValue,
+		VoucherCurrency:  winner.VoucherCurrency,
+	}
+}
+
+func FromSqlGiveawayWin(win *SqlGiveawayWin) *entities.GiveawayWin {
+	return &entities.GiveawayWin{
+		GiveawayWinner:  *FromSqlGiveawaysWinner(&win.SqlGiveawaysWinner),
+		GuildId:         win.GuildId,
+		GiveawayType:    win.GiveawayType,
+		GiveawayEndTime: win.GiveawayEndTime,
 	}
 }
 
+func FromSqlGiveawayDraw(draw *SqlGiveawayDraw) *entities.GiveawayDraw {
+	return &entities.GiveawayDraw{
+		Id:           draw.Id,
+		GiveawayId:   draw.GiveawayId,
+		GuildId:      draw.GuildId,
+		State:        draw.State,
+		StartTime:    draw.StartTime,
+		EndTime:      draw.EndTime,
+		Participants: parseJsonToStrings(draw.Participants),
+		Skipped:      parseJsonToStrings(draw.Skipped),
+
+		AnnouncementMessageId: draw.AnnouncementMessageId,
+	}
+}
+
+func ToSqlGiveawayDraw(draw *entities.GiveawayDraw) *SqlGiveawayDraw {
+	return &SqlGiveawayDraw{
+		Id:           draw.Id,
+		GiveawayId:   draw.GiveawayId,
+		GuildId:      draw.GuildId,
+		State:        draw.State,
+		StartTime:    draw.StartTime,
+		EndTime:      draw.EndTime,
+		Participants: parseStringsToJson(draw.Participants),
+		Skipped:      parseStringsToJson(draw.Skipped),
+
+		AnnouncementMessageId: draw.AnnouncementMessageId,
+	}
+}
+
+func parseJsonToStrings(content json.RawMessage) []string {
+	if content == nil {
+		return nil
+	}
+
+	result := make([]string, 0)
+	err := json.Unmarshal(content, &result)
+	if err != nil {
+		return nil
+	}
+
+	return result
+}
+
+func parseStringsToJson(content []string) json.RawMessage {
+	if content == nil {
+		content = make([]string, 0)
+	}
+
+	result, err := json.Marshal(content)
+	if err != nil {
+		return json.RawMessage("[]")
+	}
+
+	return result
+}
+
 func FromSqlThxParticipantCandidate(candidate *SqlThxParticipantCandidate) *entities.ThxParticipantCandidate {
 	return &entities.ThxParticipantCandidate{
 		Id:                    candidate.Id,
@@ -171,6 +451,7 @@ func FromSqlThxParticipantCandidate(candidate *SqlThxParticipantCandidate) *enti
 		ChannelId:             candidate.ChannelId,
 		IsAccepted:            candidate.IsAccepted,
 		AcceptTime:            candidate.AcceptTime,
+		CreatedAt:             candidate.CreatedAt,
 	}
 }
 
@@ -188,6 +469,7 @@ func ToSqlThxParticipantCandidate(candidate *entities.ThxParticipantCandidate) *
 		ChannelId:             candidate.ChannelId,
 		IsAccepted:            candidate.IsAccepted,
 		AcceptTime:            candidate.AcceptTime,
+		CreatedAt:             candidate.CreatedAt,
 	}
 }
 
@@ -207,6 +489,66 @@ func ToSqlThxNotification(notification *entities.ThxNotification) *SqlThxNotific
 	}
 }
 
+func FromSqlThxAbuseCheck(check *SqlThxAbuseCheck) *entities.ThxAbuseCheck {
+	var flags []entities.ThxAbuseFlag
+	for _, flag := range strings.Split(check.Flags, ",") {
+		if flag != "" {
+			flags = append(flags, entities.ThxAbuseFlag(flag))
+		}
+	}
+
+	return &entities.ThxAbuseCheck{
+		Id:          check.Id,
+		GuildId:     check.GuildId,
+		MessageId:   check.MessageId,
+		ChannelId:   check.ChannelId,
+		ThankerId:   check.ThankerId,
+		RecipientId: check.RecipientId,
+		Flags:       flags,
+		CreatedAt:   check.CreatedAt,
+	}
+}
+
+func ToSqlThxAbuseCheck(check *entities.ThxAbuseCheck) *SqlThxAbuseCheck {
+	flags := make([]string, len(check.Flags))
+	for i, flag := range check.Flags {
+		flags[i] = string(flag)
+	}
+
+	return &SqlThxAbuseCheck{
+		Id:          check.Id,
+		GuildId:     check.GuildId,
+		MessageId:   check.MessageId,
+		ChannelId:   check.ChannelId,
+		ThankerId:   check.ThankerId,
+		RecipientId: check.RecipientId,
+		Flags:       strings.Join(flags, ","),
+		CreatedAt:   check.CreatedAt,
+	}
+}
+
+func FromSqlThxLimitHit(hit *SqlThxLimitHit) *entities.ThxLimitHit {
+	return &entities.ThxLimitHit{
+		Id:           hit.Id,
+		GuildId:      hit.GuildId,
+		UserId:       hit.UserId,
+		TargetUserId: hit.TargetUserId,
+		Limit:        hit.Limit,
+		CreatedAt:    hit.CreatedAt,
+	}
+}
+
+func ToSqlThxLimitHit(hit *entities.ThxLimitHit) *SqlThxLimitHit {
+	return &SqlThxLimitHit{
+		Id:           hit.Id,
+		GuildId:      hit.GuildId,
+		UserId:       hit.UserId,
+		TargetUserId: hit.TargetUserId,
+		Limit:        hit.Limit,
+		CreatedAt:    hit.CreatedAt,
+	}
+}
+
 func ToSqlThxParticipantWithThxAmount(participant *entities.ThxParticipantWithThxAmount) *SqlThxParticipantWithThxAmount {
 	return &SqlThxParticipantWithThxAmount{
 		UserId:    participant.UserId,
@@ -222,10 +564,11 @@ func FromSqlThxParticipantWithThxAmount(participant *SqlThxParticipantWithThxAmo
 }
 
 func ToSqlDailyUserMessages(dailyUserMessages *entities.DailyUserMessages) *SqlDailyUserMessages {
+	day, _ := time.Parse(time.DateOnly, dailyUserMessages.Day)
 	return &SqlDailyUserMessages{
 		Id:      dailyUserMessages.Id,
 		UserId:  dailyUserMessages.UserId,
-		Day:     dailyUserMessages.Day,
+		Day:     day,
 		GuildId: dailyUserMessages.GuildId,
 		Count:   dailyUserMessages.Count,
 	}
@@ -235,7 +578,7 @@ func FromSqlDailyUserMessages(dailyUserMessages *SqlDailyUserMessages) *entities
 	return &entities.DailyUserMessages{
 		Id:      dailyUserMessages.Id,
 		UserId:  dailyUserMessages.UserId,
-		Day:     dailyUserMessages.Day,
+		Day:     dailyUserMessages.Day.Format(time.DateOnly),
 		GuildId: dailyUserMessages.GuildId,
 		Count:   dailyUserMessages.Count,
 	}
@@ -246,7 +589,12 @@ func NewGiveawaysRepo(mysql *gorp.DbMap) *GiveawaysRepo {
 	mysql.AddTableWithName(SqlGiveawaysParticipant{}, "giveaway_participants").SetKeys(true, "id")
 	mysql.AddTableWithName(SqlThxParticipantCandidate{}, "thx_participant_candidates").SetKeys(true, "id")
 	mysql.AddTableWithName(SqlGiveawaysWinner{}, "giveaway_winners").SetKeys(true, "id")
+	mysql.AddTableWithName(SqlGiveawayDraw{}, "giveaway_draws").SetKeys(true, "id")
+	mysql.AddTableWithName(SqlCustomGiveaway{}, "custom_giveaways").SetKeys(false, "giveaway_id")
+	mysql.AddTableWithName(SqlPendingVoucher{}, "pending_vouchers").SetKeys(true, "id").SetUniqueTogether("winner_id")
 	mysql.AddTableWithName(SqlThxNotification{}, "thx_notifications").SetKeys(true, "id")
+	mysql.AddTableWithName(SqlThxAbuseCheck{}, "thx_abuse_checks").SetKeys(true, "id").SetUniqueTogether("message_id")
+	mysql.AddTableWithName(SqlThxLimitHit{}, "thx_limit_hits").SetKeys(true, "id")
 	mysql.AddTableWithName(SqlDailyUserMessages{}, "daily_user_messages").SetKeys(true, "id").SetUniqueTogether("day", "user_id", "guild_id")
 
 	return &GiveawaysRepo{mysql: mysql}
@@ -254,7 +602,7 @@ func NewGiveawaysRepo(mysql *gorp.DbMap) *GiveawaysRepo {
 
 func (repo GiveawaysRepo) GetGiveawayForGuild(ctx context.Context, guildId, giveawayType string) (*entities.Giveaway, error) {
 	var giveaway SqlGiveaways
-	err := repo.mysql.WithContext(ctx).SelectOne(&giveaway, "SELECT id, type, start_time, end_time, guild_id, info_message_id, level FROM giveaways WHERE end_time IS NULL AND guild_id = ? AND type = ?", guildId, giveawayType)
+	err := repo.mysql.WithContext(ctx).SelectOne(&giveaway, "SELECT id, type, start_time, end_time, guild_id, info_message_id, level, seed, seed_hash FROM giveaways WHERE end_time IS NULL AND guild_id = ? AND type = ?", guildId, giveawayType)
 	if err != nil {
 		return nil, err
 	}
@@ -264,7 +612,7 @@ func (repo GiveawaysRepo) GetGiveawayForGuild(ctx context.Context, guildId, give
 
 func (repo GiveawaysRepo) GetUnfinishedGiveaways(ctx context.Context, giveawayType string) (result []entities.Giveaway, err error) {
 	var giveaways []SqlGiveaways
-	_, err = repo.mysql.WithContext(ctx).Select(&giveaways, "SELECT id, type, start_time, end_time, guild_id, info_message_id, level FROM giveaways WHERE end_time IS NULL AND type = ?", giveawayType)
+	_, err = repo.mysql.WithContext(ctx).Select(&giveaways, "SELECT id, type, start_time, end_time, guild_id, info_message_id, level, seed, seed_hash FROM giveaways WHERE end_time IS NULL AND type = ?", giveawayType)
 	if err != nil {
 		return nil, err
 	}
@@ -279,9 +627,9 @@ func (repo GiveawaysRepo) GetUnfinishedGiveaways(ctx context.Context, giveawayTy
 func (repo GiveawaysRepo) GetParticipantsForGiveaway(ctx context.Context, giveawayId int, accepted *bool) (result []entities.GiveawayParticipant, err error) {
 	var participants []SqlGiveawaysParticipant
 	if accepted == nil {
-		_, err = repo.mysql.WithContext(ctx).Select(&participants, "SELECT id, giveaway_id, guild_id, user_id, user_name, join_time, user_level, message_id, channel_id, is_accepted, accept_time, accept_user, accept_user_id FROM giveaway_participants WHERE giveaway_id = ?", giveawayId)
+		_, err = repo.mysql.WithContext(ctx).Select(&participants, "SELECT id, giveaway_id, guild_id, user_id, user_name, join_time, user_level, message_id, channel_id, is_accepted, accept_time, accept_user, accept_user_id, thanker_id FROM giveaway_participants WHERE giveaway_id = ?", giveawayId)
 	} else {
-		_, err = repo.mysql.WithContext(ctx).Select(&participants, "SELECT id, giveaway_id, guild_id, user_id, user_name, join_time, user_level, message_id, channel_id, is_accepted, accept_time, accept_user, accept_user_id FROM giveaway_participants WHERE giveaway_id = ? AND is_accepted = ?", giveawayId, *accepted)
+		_, err = repo.mysql.WithContext(ctx).Select(&participants, "SELECT id, giveaway_id, guild_id, user_id, user_name, join_time, user_level, message_id, channel_id, is_accepted, accept_time, accept_user, accept_user_id, thanker_id FROM giveaway_participants WHERE giveaway_id = ? AND is_accepted = ?", giveawayId, *accepted)
 	}
 
 	if err != nil {
@@ -305,12 +653,20 @@ func (repo GiveawaysRepo) CountParticipantsForGiveaway(ctx context.Context, give
 }
 
 func (repo GiveawaysRepo) InsertGiveaway(ctx context.Context, guildId string, messageId *string, giveawayType string, level *int) error {
+	seed, err := fairdraw.NewSeed()
+	if err != nil {
+		return err
+	}
+	seedHash := fairdraw.HashSeed(seed)
+
 	giveaway := &SqlGiveaways{
 		Type:          giveawayType,
 		StartTime:     time.Now(),
 		GuildId:       guildId,
 		InfoMessageId: messageId,
 		Level:         level,
+		Seed:          &seed,
+		SeedHash:      &seedHash,
 	}
 	if err := repo.mysql.WithContext(ctx).Insert(giveaway); err != nil {
 		return err
@@ -319,7 +675,7 @@ func (repo GiveawaysRepo) InsertGiveaway(ctx context.Context, guildId string, me
 	return nil
 }
 
-func (repo GiveawaysRepo) InsertParticipant(ctx context.Context, giveawayId, level int, guildId, userId, userName string, messageId, channelId *string) error {
+func (repo GiveawaysRepo) InsertParticipant(ctx context.Context, giveawayId, level int, guildId, userId, userName string, messageId, channelId, thankerId *string) error {
 	participant := &SqlGiveawaysParticipant{
 		GiveawayId: giveawayId,
 		GuildId:    guildId,
@@ -329,6 +685,7 @@ func (repo GiveawaysRepo) InsertParticipant(ctx context.Context, giveawayId, lev
 		UserLevel:  &level,
 		MessageId:  messageId,
 		ChannelId:  channelId,
+		ThankerId:  thankerId,
 	}
 	if err := repo.mysql.WithContext(ctx).Insert(participant); err != nil {
 		return err
@@ -342,6 +699,9 @@ func (repo GiveawaysRepo) InsertWinner(ctx context.Context, giveawayId int, user
 		GiveawayId: giveawayId,
 		UserId:     userId,
 		Code:       code,
+		Notified:   true,
+
+		RedemptionStatus: entities.VoucherStatusUnknown,
 	}
 	if err := repo.mysql.WithContext(ctx).Insert(winner); err != nil {
 		return err
@@ -362,7 +722,7 @@ func (repo GiveawaysRepo) FinishGiveaway(ctx context.Context, giveaway *entities
 }
 
 func (repo GiveawaysRepo) HasWonGiveawayByMessageId(ctx context.Context, messageId, userId string) (bool, error) {
-	count, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM giveaways g JOIN giveaway_winners w ON g.id = w.giveaway_id WHERE g.info_message_id = ? AND w.user_id = ?", messageId, userId)
+	count, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM giveaways g JOIN giveaway_winners w ON g.id = w.giveaway_id WHERE g.info_message_id = ? AND w.user_id = ? AND w.redemption_status != ?", messageId, userId, entities.VoucherStatusReplaced)
 	if err != nil {
 		return false, err
 	}
@@ -371,7 +731,7 @@ func (repo GiveawaysRepo) HasWonGiveawayByMessageId(ctx context.Context, message
 }
 
 func (repo GiveawaysRepo) GetCodeForInfoMessage(ctx context.Context, messageId, userId string) (string, error) {
-	code, err := repo.mysql.WithContext(ctx).SelectStr("SELECT code FROM giveaways g JOIN giveaway_winners w ON g.id = w.giveaway_id WHERE g.info_message_id = ? AND w.user_id = ?", messageId, userId)
+	code, err := repo.mysql.WithContext(ctx).SelectStr("SELECT code FROM giveaways g JOIN giveaway_winners w ON g.id = w.giveaway_id WHERE g.info_message_id = ? AND w.user_id = ? AND w.redemption_status != ? ORDER BY w.id DESC LIMIT 1", messageId, userId, entities.VoucherStatusReplaced)
 	if err != nil {
 		return "", err
 	}
@@ -381,13 +741,363 @@ func (repo GiveawaysRepo) GetCodeForInfoMessage(ctx context.Context, messageId,
 
 func (repo GiveawaysRepo) GetGiveawayByMessageId(ctx context.Context, messageId string) (*entities.Giveaway, error) {
 	var giveaway SqlGiveaways
-	if err := repo.mysql.WithContext(ctx).SelectOne(&giveaway, "SELECT id, type, start_time, end_time, guild_id, info_message_id, level FROM giveaways WHERE info_message_id = ?", messageId); err != nil {
+	if err := repo.mysql.WithContext(ctx).SelectOne(&giveaway, "SELECT id, type, start_time, end_time, guild_id, info_message_id, level, seed, seed_hash FROM giveaways WHERE info_message_id = ?", messageId); err != nil {
 		return nil, err
 	}
 
 	return FromSqlGiveaways(&giveaway), nil
 }
 
+func (repo GiveawaysRepo) StartDraw(ctx context.Context, giveaway *entities.Giveaway, participants, skipped []string, winners []entities.GiveawayWinner) (*entities.GiveawayDraw, error) {
+	tx, err := repo.mysql.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	draw := &SqlGiveawayDraw{
+		GiveawayId:   giveaway.Id,
+		GuildId:      giveaway.GuildId,
+		State:        entities.DrawInProgressState,
+		StartTime:    time.Now(),
+		Participants: parseStringsToJson(participants),
+		Skipped:      parseStringsToJson(skipped),
+	}
+	if err := tx.WithContext(ctx).Insert(draw); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	for _, winner := range winners {
+		sqlWinner := &SqlGiveawaysWinner{
+			GiveawayId: giveaway.Id,
+			DrawId:     &draw.Id,
+			UserId:     winner.UserId,
+			UserName:   winner.UserName,
+
+			RedemptionStatus: entities.VoucherStatusUnknown,
+		}
+		if err := tx.WithContext(ctx).Insert(sqlWinner); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return FromSqlGiveawayDraw(draw), nil
+}
+
+func (repo GiveawaysRepo) GetDrawForGiveaway(ctx context.Context, giveawayId int) (*entities.GiveawayDraw, error) {
+	var draw SqlGiveawayDraw
+	err := repo.mysql.WithContext(ctx).SelectOne(&draw, "SELECT id, giveaway_id, guild_id, state, start_time, end_time, participants, skipped, announcement_message_id FROM giveaway_draws WHERE giveaway_id = ?", giveawayId)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromSqlGiveawayDraw(&draw), nil
+}
+
+func (repo GiveawaysRepo) GetInProgressDraws(ctx context.Context) (result []entities.GiveawayDraw, err error) {
+	var draws []SqlGiveawayDraw
+	_, err = repo.mysql.WithContext(ctx).Select(&draws, "SELECT id, giveaway_id, guild_id, state, start_time, end_time, participants, skipped, announcement_message_id FROM giveaway_draws WHERE state = ? ORDER BY id", entities.DrawInProgressState)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, draw := range draws {
+		result = append(result, *FromSqlGiveawayDraw(&draw))
+	}
+
+	return result, nil
+}
+
+func (repo GiveawaysRepo) GetDrawWinners(ctx context.Context, drawId int) (result []entities.GiveawayWinner, err error) {
+	var winners []SqlGiveawaysWinner
+	_, err = repo.mysql.WithContext(ctx).Select(&winners, "SELECT id, giveaway_id, draw_id, user_id, user_name, code, notified, redemption_status, expires_at, redeemed_at, status_checked_at, replaces_winner_id, voucher_value, voucher_currency FROM giveaway_winners WHERE draw_id = ? AND replaces_winner_id IS NULL ORDER BY id", drawId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, winner := range winners {
+		result = append(result, *FromSqlGiveawaysWinner(&winner))
+	}
+
+	return result, nil
+}
+
+func (repo GiveawaysRepo) GetWinner(ctx context.Context, winnerId int) (*entities.GiveawayWinner, error) {
+	var winner SqlGiveawaysWinner
+	err := repo.mysql.WithContext(ctx).SelectOne(&winner, "SELECT id, giveaway_id, draw_id, user_id, user_name, code, notified, redemption_status, expires_at, redeemed_at, status_checked_at, replaces_winner_id, voucher_value, voucher_currency FROM giveaway_winners WHERE id = ?", winnerId)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromSqlGiveawaysWinner(&winner), nil
+}
+
+func (repo GiveawaysRepo) GetGiveawayWinners(ctx context.Context, giveawayId int) (result []entities.GiveawayWinner, err error) {
+	var winners []SqlGiveawaysWinner
+	_, err = repo.mysql.WithContext(ctx).Select(&winners, "SELECT id, giveaway_id, draw_id, user_id, user_name, code, notified, redemption_status, expires_at, redeemed_at, status_checked_at, replaces_winner_id, voucher_value, voucher_currency FROM giveaway_winners WHERE giveaway_id = ? ORDER BY id", giveawayId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, winner := range winners {
+		result = append(result, *FromSqlGiveawaysWinner(&winner))
+	}
+
+	return result, nil
+}
+
+func (repo GiveawaysRepo) SetWinnerCode(ctx context.Context, winnerId int, code string, voucherConfig entities.VoucherConfig, expiresAt time.Time) error {
+	result, err := repo.mysql.WithContext(ctx).Exec("UPDATE giveaway_winners SET code = ?, voucher_value = ?, voucher_currency = ?, expires_at = ? WHERE id = ? AND code = ''",
+		code, voucherConfig.Value, voucherConfig.Currency, expiresAt, winnerId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return entities.ErrWinnerCodeAlreadySet
+	}
+
+	return nil
+}
+
+func (repo GiveawaysRepo) SetWinnerNotified(ctx context.Context, winnerId int) error {
+	_, err := repo.mysql.WithContext(ctx).Exec("UPDATE giveaway_winners SET notified = TRUE WHERE id = ?", winnerId)
+	return err
+}
+
+func (repo GiveawaysRepo) SetDrawAnnouncement(ctx context.Context, drawId int, messageId string) error {
+	_, err := repo.mysql.WithContext(ctx).Exec("UPDATE giveaway_draws SET announcement_message_id = ? WHERE id = ?", messageId, drawId)
+	return err
+}
+
+func (repo GiveawaysRepo) CompleteDraw(ctx context.Context, draw *entities.GiveawayDraw, giveaway *entities.Giveaway, messageId *string) error {
+	tx, err := repo.mysql.Begin()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = tx.WithContext(ctx).Exec("UPDATE giveaway_draws SET state = ?, end_time = ? WHERE id = ?", entities.DrawCompletedState, now, draw.Id)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	_, err = tx.WithContext(ctx).Exec("UPDATE giveaways SET end_time = ?, info_message_id = ? WHERE id = ?", now, messageId, giveaway.Id)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	draw.State = entities.DrawCompletedState
+	draw.EndTime = &now
+	giveaway.EndTime = &now
+	giveaway.InfoMessageId = messageId
+	return nil
+}
+
+func (repo GiveawaysRepo) RollbackDraw(ctx context.Context, draw *entities.GiveawayDraw) error {
+	tx, err := repo.mysql.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.WithContext(ctx).Exec("DELETE w FROM giveaway_winners w JOIN giveaway_draws d ON w.draw_id = d.id WHERE d.id = ? AND d.state = ?", draw.Id, entities.DrawInProgressState)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	_, err = tx.WithContext(ctx).Exec("DELETE FROM giveaway_draws WHERE id = ? AND state = ?", draw.Id, entities.DrawInProgressState)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repo GiveawaysRepo) InsertCustomGiveaway(ctx context.Context, customGiveaway *entities.CustomGiveaway, messageId string) (*entities.Giveaway, error) {
+	seed, err := fairdraw.NewSeed()
+	if err != nil {
+		return nil, err
+	}
+	seedHash := fairdraw.HashSeed(seed)
+
+	tx, err := repo.mysql.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	giveaway := &SqlGiveaways{
+		Type:          entities.CustomGiveawayType,
+		StartTime:     time.Now(),
+		GuildId:       customGiveaway.GuildId,
+		InfoMessageId: &messageId,
+		Level:         customGiveaway.RequiredLevel,
+		Seed:          &seed,
+		SeedHash:      &seedHash,
+	}
+	if err := tx.WithContext(ctx).Insert(giveaway); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	details := ToSqlCustomGiveaway(customGiveaway)
+	details.GiveawayId = giveaway.Id
+	if err := tx.WithContext(ctx).Insert(details); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	customGiveaway.GiveawayId = giveaway.Id
+	return FromSqlGiveaways(giveaway), nil
+}
+
+func (repo GiveawaysRepo) GetWinnersToCheck(ctx context.Context, checkedBefore time.Time, limit int) (result []entities.GiveawayWinner, err error) {
+	var winners []SqlGiveawaysWinner
+	_, err = repo.mysql.WithContext(ctx).Select(&winners, "SELECT id, giveaway_id, draw_id, user_id, user_name, code, notified, redemption_status, expires_at, redeemed_at, status_checked_at, replaces_winner_id, voucher_value, voucher_currency FROM giveaway_winners WHERE code != '' AND redemption_status IN (?, ?) AND (status_checked_at IS NULL OR status_checked_at < ? OR expires_at < ?) ORDER BY status_checked_at IS NOT NULL, status_checked_at LIMIT ?",
+		entities.VoucherStatusUnknown, entities.VoucherStatusUnclaimed, checkedBefore, time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, winner := range winners {
+		result = append(result, *FromSqlGiveawaysWinner(&winner))
+	}
+
+	return result, nil
+}
+
+func (repo GiveawaysRepo) UpdateWinnerRedemption(ctx context.Context, winner *entities.GiveawayWinner) error {
+	_, err := repo.mysql.WithContext(ctx).Exec("UPDATE giveaway_winners SET redemption_status = ?, expires_at = ?, redeemed_at = ?, status_checked_at = ? WHERE id = ?", winner.RedemptionStatus, winner.ExpiresAt, winner.RedeemedAt, winner.StatusCheckedAt, winner.Id)
+	return err
+}
+
+func (repo GiveawaysRepo) ReplaceWinner(ctx context.Context, winner *entities.GiveawayWinner, replacement *entities.GiveawayWinner) error {
+	tx, err := repo.mysql.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.WithContext(ctx).Exec("UPDATE giveaway_winners SET redemption_status = ?, expires_at = ?, status_checked_at = ? WHERE id = ?", entities.VoucherStatusReplaced, winner.ExpiresAt, winner.StatusCheckedAt, winner.Id)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	replacement.GiveawayId = winner.GiveawayId
+	replacement.DrawId = winner.DrawId
+	replacesWinnerId := winner.Id
+	replacement.ReplacesWinnerId = &replacesWinnerId
+	replacement.RedemptionStatus = entities.VoucherStatusUnknown
+	sqlReplacement := ToSqlGiveawaysWinner(replacement)
+	if err := tx.WithContext(ctx).Insert(sqlReplacement); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	winner.RedemptionStatus = entities.VoucherStatusReplaced
+	replacement.Id = sqlReplacement.Id
+	return nil
+}
+
+func (repo GiveawaysRepo) GetUnclaimedWinners(ctx context.Context, guildId string) (result []entities.GiveawayWin, err error) {
+	var winners []SqlGiveawayWin
+	_, err = repo.mysql.WithContext(ctx).Select(&winners, "SELECT w.id, w.giveaway_id, w.draw_id, w.user_id, w.user_name, w.code, w.notified, w.redemption_status, w.expires_at, w.redeemed_at, w.status_checked_at, w.replaces_winner_id, w.voucher_value, w.voucher_currency, g.guild_id, g.type AS giveaway_type, g.end_time AS giveaway_end_time FROM giveaway_winners w JOIN giveaways g ON w.giveaway_id = g.id WHERE g.guild_id = ? AND w.code != '' AND w.redemption_status IN (?, ?) ORDER BY w.expires_at IS NULL, w.expires_at, w.id",
+		guildId, entities.VoucherStatusUnclaimed, entities.VoucherStatusExpired)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, winner := range winners {
+		result = append(result, *FromSqlGiveawayWin(&winner))
+	}
+
+	return result, nil
+}
+
+// AddPendingVoucher does nothing if the winner already has a pending voucher, e.g. when a draw is resumed.
+func (repo GiveawaysRepo) AddPendingVoucher(ctx context.Context, pendingVoucher *entities.PendingVoucher) error {
+	sqlPendingVoucher := ToSqlPendingVoucher(pendingVoucher)
+	result, err := repo.mysql.WithContext(ctx).Exec("INSERT IGNORE INTO pending_vouchers (winner_id, giveaway_id, guild_id, user_id, giveaway_type, value, currency, expiration_days, prefix, group_id, attempts, last_error, next_attempt_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		sqlPendingVoucher.WinnerId, sqlPendingVoucher.GiveawayId, sqlPendingVoucher.GuildId, sqlPendingVoucher.UserId, sqlPendingVoucher.GiveawayType, sqlPendingVoucher.Value, sqlPendingVoucher.Currency, sqlPendingVoucher.ExpirationDays, sqlPendingVoucher.Prefix, sqlPendingVoucher.GroupId, sqlPendingVoucher.Attempts, sqlPendingVoucher.LastError, sqlPendingVoucher.NextAttemptAt, sqlPendingVoucher.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err == nil && id != 0 {
+		pendingVoucher.Id = int(id)
+	}
+
+	return nil
+}
+
+func (repo GiveawaysRepo) GetDuePendingVouchers(ctx context.Context, now time.Time, limit int) (result []entities.PendingVoucher, err error) {
+	var pendingVouchers []SqlPendingVoucher
+	_, err = repo.mysql.WithContext(ctx).Select(&pendingVouchers, "SELECT id, winner_id, giveaway_id, guild_id, user_id, giveaway_type, value, currency, expiration_days, prefix, group_id, attempts, last_error, next_attempt_at, created_at, resolved_at FROM pending_vouchers WHERE resolved_at IS NULL AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?", now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pendingVoucher := range pendingVouchers {
+		result = append(result, *FromSqlPendingVoucher(&pendingVoucher))
+	}
+
+	return result, nil
+}
+
+func (repo GiveawaysRepo) UpdatePendingVoucher(ctx context.Context, pendingVoucher *entities.PendingVoucher) error {
+	_, err := repo.mysql.WithContext(ctx).Exec("UPDATE pending_vouchers SET attempts = ?, last_error = ?, next_attempt_at = ?, resolved_at = ? WHERE id = ?", pendingVoucher.Attempts, pendingVoucher.LastError, pendingVoucher.NextAttemptAt, pendingVoucher.ResolvedAt, pendingVoucher.Id)
+	return err
+}
+
+func (repo GiveawaysRepo) GetCustomGiveaway(ctx context.Context, giveawayId int) (*entities.CustomGiveaway, error) {
+	var customGiveaway SqlCustomGiveaway
+	err := repo.mysql.WithContext(ctx).SelectOne(&customGiveaway, "SELECT giveaway_id, guild_id, title, prize, winners_count, scheduled_end_time, channel_id, required_level, required_role_id, voucher_value, created_by FROM custom_giveaways WHERE giveaway_id = ?", giveawayId)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromSqlCustomGiveaway(&customGiveaway), nil
+}
+
+func (repo GiveawaysRepo) GetUnfinishedCustomGiveaways(ctx context.Context) (result []entities.CustomGiveaway, err error) {
+	var customGiveaways []SqlCustomGiveaway
+	_, err = repo.mysql.WithContext(ctx).Select(&customGiveaways, "SELECT c.giveaway_id, c.guild_id, c.title, c.prize, c.winners_count, c.scheduled_end_time, c.channel_id, c.required_level, c.required_role_id, c.voucher_value, c.created_by FROM custom_giveaways c JOIN giveaways g ON g.id = c.giveaway_id WHERE g.end_time IS NULL ORDER BY c.scheduled_end_time")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, customGiveaway := range customGiveaways {
+		result = append(result, *FromSqlCustomGiveaway(&customGiveaway))
+	}
+
+	return result, nil
+}
+
 func (repo GiveawaysRepo) InsertParticipantCandidate(ctx context.Context, guildId, guildName, candidateId, candidateName, approverId, approverName, channelId, messageId string, giveawayId int) error {
 	candidate := &SqlThxParticipantCandidate{
 		CandidateId:           candidateId,
@@ -399,6 +1109,7 @@ func (repo GiveawaysRepo) InsertParticipantCandidate(ctx context.Context, guildI
 		GuildName:             guildName,
 		MessageId:             messageId,
 		ChannelId:             channelId,
+		CreatedAt:             time.Now(),
 	}
 	if err := repo.mysql.WithContext(ctx).Insert(candidate); err != nil {
 		return err
@@ -407,9 +1118,18 @@ func (repo GiveawaysRepo) InsertParticipantCandidate(ctx context.Context, guildI
 	return nil
 }
 
-func (repo GiveawaysRepo) GetParticipantsWithThxAmount(ctx context.Context, guildId string, minThxAmount int) (result []entities.ThxParticipantWithThxAmount, err error) {
+func (repo GiveawaysRepo) GetParticipantsWithThxAmount(ctx context.Context, guildId string, minThxAmount int, since time.Time) (result []entities.ThxParticipantWithThxAmount, err error) {
+	query := "SELECT user_id, COUNT(*) AS amount FROM giveaway_participants WHERE guild_id = ? AND is_accepted = 1"
+	args := []interface{}{guildId}
+	if !since.IsZero() {
+		query += " AND join_time >= ?"
+		args = append(args, since)
+	}
+	query += " GROUP BY user_id HAVING amount > ?"
+	args = append(args, minThxAmount)
+
 	var helpers []SqlThxParticipantWithThxAmount
-	_, err = repo.mysql.WithContext(ctx).Select(&helpers, "SELECT user_id, amount FROM (SELECT user_id, COUNT(*) AS amount FROM giveaway_participants WHERE guild_id=? AND is_accepted=1 GROUP BY user_id) AS a WHERE amount > ?", guildId, minThxAmount)
+	_, err = repo.mysql.WithContext(ctx).Select(&helpers, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -421,13 +1141,189 @@ func (repo GiveawaysRepo) GetParticipantsWithThxAmount(ctx context.Context, guil
 	return result, nil
 }
 
-func (repo GiveawaysRepo) HasThxAmount(ctx context.Context, guildId, memberId string, minThxAmount int) (bool, error) {
-	count, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) AS amount  FROM giveaway_participants WHERE guild_id=? AND user_id=? AND is_accepted=1 HAVING amount > ?", guildId, memberId, minThxAmount)
+func (repo GiveawaysRepo) GetThxRanking(ctx context.Context, guildId string, since time.Time, offset, limit int) (result []entities.ThxParticipantWithThxAmount, err error) {
+	query := "SELECT p.user_id, COUNT(*) AS amount FROM giveaway_participants p JOIN giveaways g ON p.giveaway_id = g.id WHERE p.guild_id = ? AND g.type = ? AND p.is_accepted = 1"
+	args := []interface{}{guildId, entities.ThxGiveawayType}
+	if !since.IsZero() {
+		query += " AND p.join_time >= ?"
+		args = append(args, since)
+	}
+	query += " GROUP BY p.user_id ORDER BY amount DESC, p.user_id LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	var ranking []SqlThxParticipantWithThxAmount
+	_, err = repo.mysql.WithContext(ctx).Select(&ranking, query, args...)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	for _, participant := range ranking {
+		result = append(result, *FromSqlThxParticipantWithThxAmount(&participant))
+	}
+
+	return result, nil
+}
+
+func (repo GiveawaysRepo) CountThxRanking(ctx context.Context, guildId string, since time.Time) (int, error) {
+	query := "SELECT COUNT(DISTINCT p.user_id) FROM giveaway_participants p JOIN giveaways g ON p.giveaway_id = g.id WHERE p.guild_id = ? AND g.type = ? AND p.is_accepted = 1"
+	args := []interface{}{guildId, entities.ThxGiveawayType}
+	if !since.IsZero() {
+		query += " AND p.join_time >= ?"
+		args = append(args, since)
+	}
+
+	count, err := repo.mysql.WithContext(ctx).SelectInt(query, args...)
+	return int(count), err
+}
+
+func (repo GiveawaysRepo) GetThxStats(ctx context.Context, guildId, userId string) (entities.ThxStats, error) {
+	var stats SqlThxStats
+	err := repo.mysql.WithContext(ctx).SelectOne(&stats, "SELECT COUNT(*) AS total, COALESCE(SUM(p.is_accepted = 1), 0) AS accepted, COALESCE(SUM(p.is_accepted = 0), 0) AS rejected, COALESCE(SUM(p.is_accepted IS NULL), 0) AS pending, COUNT(DISTINCT CASE WHEN p.is_accepted = 1 THEN p.thanker_id END) AS thankers FROM giveaway_participants p JOIN giveaways g ON p.giveaway_id = g.id WHERE p.guild_id = ? AND p.user_id = ? AND g.type = ?",
+		guildId, userId, entities.ThxGiveawayType)
+	if err != nil {
+		return entities.ThxStats{}, err
 	}
 
-	return count > 1, nil
+	given, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM giveaway_participants p JOIN giveaways g ON p.giveaway_id = g.id WHERE p.guild_id = ? AND p.thanker_id = ? AND g.type = ? AND p.is_accepted = 1",
+		guildId, userId, entities.ThxGiveawayType)
+	if err != nil {
+		return entities.ThxStats{}, err
+	}
+
+	return entities.ThxStats{Total: stats.Total, Accepted: stats.Accepted, Rejected: stats.Rejected, Pending: stats.Pending, Thankers: stats.Thankers, Given: int(given)}, nil
+}
+
+func (repo GiveawaysRepo) GetThxRank(ctx context.Context, guildId, userId string) (int, error) {
+	accepted, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM giveaway_participants p JOIN giveaways g ON p.giveaway_id = g.id WHERE p.guild_id = ? AND p.user_id = ? AND g.type = ? AND p.is_accepted = 1",
+		guildId, userId, entities.ThxGiveawayType)
+	if err != nil || accepted == 0 {
+		return 0, err
+	}
+
+	ahead, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM (SELECT p.user_id FROM giveaway_participants p JOIN giveaways g ON p.giveaway_id = g.id WHERE p.guild_id = ? AND g.type = ? AND p.is_accepted = 1 GROUP BY p.user_id HAVING COUNT(*) > ?) AS a",
+		guildId, entities.ThxGiveawayType, accepted)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(ahead) + 1, nil
+}
+
+func (repo GiveawaysRepo) GetTopThankers(ctx context.Context, guildId, userId string, limit int) (result []entities.ThxParticipantWithThxAmount, err error) {
+	var thankers []SqlThxParticipantWithThxAmount
+	_, err = repo.mysql.WithContext(ctx).Select(&thankers, "SELECT p.thanker_id AS user_id, COUNT(*) AS amount FROM giveaway_participants p JOIN giveaways g ON p.giveaway_id = g.id WHERE p.guild_id = ? AND p.user_id = ? AND g.type = ? AND p.is_accepted = 1 AND p.thanker_id IS NOT NULL GROUP BY p.thanker_id ORDER BY amount DESC, p.thanker_id LIMIT ?",
+		guildId, userId, entities.ThxGiveawayType, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, thanker := range thankers {
+		result = append(result, *FromSqlThxParticipantWithThxAmount(&thanker))
+	}
+
+	return result, nil
+}
+
+func (repo GiveawaysRepo) GetTopThanked(ctx context.Context, guildId, thankerId string, limit int) (result []entities.ThxParticipantWithThxAmount, err error) {
+	var thanked []SqlThxParticipantWithThxAmount
+	_, err = repo.mysql.WithContext(ctx).Select(&thanked, "SELECT p.user_id, COUNT(*) AS amount FROM giveaway_participants p JOIN giveaways g ON p.giveaway_id = g.id WHERE p.guild_id = ? AND p.thanker_id = ? AND g.type = ? AND p.is_accepted = 1 GROUP BY p.user_id ORDER BY amount DESC, p.user_id LIMIT ?",
+		guildId, thankerId, entities.ThxGiveawayType, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, participant := range thanked {
+		result = append(result, *FromSqlThxParticipantWithThxAmount(&participant))
+	}
+
+	return result, nil
+}
+
+func (repo GiveawaysRepo) InsertThxAbuseCheck(ctx context.Context, check *entities.ThxAbuseCheck) error {
+	sqlCheck := ToSqlThxAbuseCheck(check)
+	if err := repo.mysql.WithContext(ctx).Insert(sqlCheck); err != nil {
+		return err
+	}
+	check.Id = sqlCheck.Id
+
+	return nil
+}
+
+func (repo GiveawaysRepo) GetThxAbuseCheck(ctx context.Context, messageId string) (*entities.ThxAbuseCheck, error) {
+	var check SqlThxAbuseCheck
+	err := repo.mysql.WithContext(ctx).SelectOne(&check, "SELECT id, guild_id, message_id, channel_id, thanker_id, recipient_id, flags, created_at FROM thx_abuse_checks WHERE message_id = ?", messageId)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromSqlThxAbuseCheck(&check), nil
+}
+
+func (repo GiveawaysRepo) CountThanksGiven(ctx context.Context, guildId, thankerId string, since time.Time) (int, error) {
+	count, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM giveaway_participants WHERE guild_id = ? AND thanker_id = ? AND join_time >= ?", guildId, thankerId, since)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func (repo GiveawaysRepo) CountThanksBetween(ctx context.Context, guildId, thankerId, recipientId string, since time.Time) (int, error) {
+	count, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM giveaway_participants WHERE guild_id = ? AND thanker_id = ? AND user_id = ? AND join_time >= ?", guildId, thankerId, recipientId, since)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func (repo GiveawaysRepo) CountPendingParticipantCandidates(ctx context.Context, guildId, candidateId string) (int, error) {
+	count, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM thx_participant_candidates WHERE guild_id = ? AND candidate_id = ? AND is_accepted IS NULL", guildId, candidateId)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func (repo GiveawaysRepo) InsertThxLimitHit(ctx context.Context, hit *entities.ThxLimitHit) error {
+	sqlHit := ToSqlThxLimitHit(hit)
+	if err := repo.mysql.WithContext(ctx).Insert(sqlHit); err != nil {
+		return err
+	}
+	hit.Id = sqlHit.Id
+
+	return nil
+}
+
+func (repo GiveawaysRepo) GetThxLimitHits(ctx context.Context, guildId string, limit int) (result []entities.ThxLimitHit, err error) {
+	var hits []SqlThxLimitHit
+	_, err = repo.mysql.WithContext(ctx).Select(&hits, "SELECT id, guild_id, user_id, target_user_id, limit_type, created_at FROM thx_limit_hits WHERE guild_id = ? ORDER BY created_at DESC, id DESC LIMIT ?", guildId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hit := range hits {
+		result = append(result, *FromSqlThxLimitHit(&hit))
+	}
+
+	return result, nil
+}
+
+func (repo GiveawaysRepo) GetThxAmount(ctx context.Context, guildId, memberId string, since time.Time) (int, error) {
+	query := "SELECT COUNT(*) FROM giveaway_participants WHERE guild_id = ? AND user_id = ? AND is_accepted = 1"
+	args := []interface{}{guildId, memberId}
+	if !since.IsZero() {
+		query += " AND join_time >= ?"
+		args = append(args, since)
+	}
+
+	count, err := repo.mysql.WithContext(ctx).SelectInt(query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
 }
 
 func (repo GiveawaysRepo) GetThxNotification(ctx context.Context, thxMessageId string) (entities.ThxNotification, error) {
@@ -471,7 +1367,7 @@ func (repo GiveawaysRepo) IsThxmeMessage(ctx context.Context, messageId string)
 
 func (repo GiveawaysRepo) GetParticipant(ctx context.Context, messageId string) (*entities.GiveawayParticipant, error) {
 	var participant SqlGiveawaysParticipant
-	if err := repo.mysql.WithContext(ctx).SelectOne(&participant, "SELECT id, giveaway_id, guild_id, user_id, user_name, join_time, user_level, message_id, channel_id, is_accepted, accept_time, accept_user, accept_user_id FROM giveaway_participants WHERE message_id = ?", messageId); err != nil {
+	if err := repo.mysql.WithContext(ctx).SelectOne(&participant, "SELECT id, giveaway_id, guild_id, user_id, user_name, join_time, user_level, message_id, channel_id, is_accepted, accept_time, accept_user, accept_user_id, thanker_id FROM giveaway_participants WHERE message_id = ?", messageId); err != nil {
 		return nil, err
 	}
 
@@ -480,7 +1376,7 @@ func (repo GiveawaysRepo) GetParticipant(ctx context.Context, messageId string)
 
 func (repo GiveawaysRepo) GetParticipantCandidate(ctx context.Context, messageId string) (entities.ThxParticipantCandidate, error) {
 	var candidate SqlThxParticipantCandidate
-	if err := repo.mysql.WithContext(ctx).SelectOne(&candidate, "SELECT id, candidate_id, candidate_name, candidate_approver_id, candidate_approver_name, giveaway_id, guild_id, guild_name, message_id, channel_id, is_accepted, accept_time FROM thx_participant_candidates WHERE message_id = ?", messageId); err != nil {
+	if err := repo.mysql.WithContext(ctx).SelectOne(&candidate, "SELECT id, candidate_id, candidate_name, candidate_approver_id, candidate_approver_name, giveaway_id, guild_id, guild_name, message_id, channel_id, is_accepted, accept_time, created_at FROM thx_participant_candidates WHERE message_id = ?", messageId); err != nil {
 		return entities.ThxParticipantCandidate{}, err
 	}
 
@@ -498,6 +1394,58 @@ func (repo GiveawaysRepo) UpdateParticipantCandidate(ctx context.Context, partic
 	return nil
 }
 
+func (repo GiveawaysRepo) GetExpiredParticipantCandidates(ctx context.Context, guildId string, createdBefore time.Time, limit int) (result []entities.ThxParticipantCandidate, err error) {
+	var candidates []SqlThxParticipantCandidate
+	_, err = repo.mysql.WithContext(ctx).Select(&candidates, "SELECT id, candidate_id, candidate_name, candidate_approver_id, candidate_approver_name, giveaway_id, guild_id, guild_name, message_id, channel_id, is_accepted, accept_time, created_at FROM thx_participant_candidates WHERE guild_id = ? AND is_accepted IS NULL AND created_at < ? ORDER BY created_at LIMIT ?", guildId, createdBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range candidates {
+		result = append(result, *FromSqlThxParticipantCandidate(&candidate))
+	}
+
+	return result, nil
+}
+
+func (repo GiveawaysRepo) GetUnreviewedParticipants(ctx context.Context, guildId string, limit int) (result []entities.GiveawayParticipant, err error) {
+	var participants []SqlGiveawaysParticipant
+	_, err = repo.mysql.WithContext(ctx).Select(&participants, "SELECT p.id, p.giveaway_id, p.guild_id, p.user_id, p.user_name, p.join_time, p.user_level, p.message_id, p.channel_id, p.is_accepted, p.accept_time, p.accept_user, p.accept_user_id, p.thanker_id FROM giveaway_participants p JOIN giveaways g ON p.giveaway_id = g.id WHERE p.guild_id = ? AND g.type = ? AND g.end_time IS NOT NULL AND p.is_accepted IS NULL ORDER BY p.id LIMIT ?", guildId, entities.ThxGiveawayType, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, participant := range participants {
+		result = append(result, *FromSqlGiveawaysParticipant(&participant))
+	}
+
+	return result, nil
+}
+
+func (repo GiveawaysRepo) GetPendingParticipants(ctx context.Context, giveawayId int) (result []entities.GiveawayParticipant, err error) {
+	var participants []SqlGiveawaysParticipant
+	_, err = repo.mysql.WithContext(ctx).Select(&participants, "SELECT id, giveaway_id, guild_id, user_id, user_name, join_time, user_level, message_id, channel_id, is_accepted, accept_time, accept_user, accept_user_id, thanker_id FROM giveaway_participants WHERE giveaway_id = ? AND is_accepted IS NULL ORDER BY id", giveawayId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, participant := range participants {
+		result = append(result, *FromSqlGiveawaysParticipant(&participant))
+	}
+
+	return result, nil
+}
+
+func (repo GiveawaysRepo) MoveParticipant(ctx context.Context, participant *entities.GiveawayParticipant, giveawayId int) error {
+	_, err := repo.mysql.WithContext(ctx).Exec("UPDATE giveaway_participants SET giveaway_id = ? WHERE id = ?", giveawayId, participant.Id)
+	if err != nil {
+		return err
+	}
+	participant.GiveawayId = giveawayId
+
+	return nil
+}
+
 func (repo GiveawaysRepo) IsGiveawayEnded(ctx context.Context, giveawayId int) (bool, error) {
 	count, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM giveaways WHERE id = ? AND end_time IS NOT NULL", giveawayId)
 	if err != nil {
@@ -509,21 +1457,30 @@ func (repo GiveawaysRepo) IsGiveawayEnded(ctx context.Context, giveawayId int) (
 
 func (repo GiveawaysRepo) GetGiveawayById(ctx context.Context, giveawayId int) (*entities.Giveaway, error) {
 	var giveaway SqlGiveaways
-	if err := repo.mysql.WithContext(ctx).SelectOne(&giveaway, "SELECT id, type, start_time, end_time, guild_id, info_message_id, level FROM giveaways WHERE id = ?", giveawayId); err != nil {
+	if err := repo.mysql.WithContext(ctx).SelectOne(&giveaway, "SELECT id, type, start_time, end_time, guild_id, info_message_id, level, seed, seed_hash FROM giveaways WHERE id = ?", giveawayId); err != nil {
 		return nil, err
 	}
 
 	return FromSqlGiveaways(&giveaway), nil
 }
 
-func (repo GiveawaysRepo) GetLastCodesForUser(ctx context.Context, userId, giveawayType string, limit int) ([]string, error) {
-	var codes []string
-	_, err := repo.mysql.WithContext(ctx).Select(&codes, "SELECT code FROM giveaway_winners w JOIN giveaways g ON w.giveaway_id = g.id WHERE w.user_id = ? AND g.type = ? ORDER BY g.end_time DESC LIMIT ?", userId, giveawayType, limit)
+func (repo GiveawaysRepo) GetWinsForUser(ctx context.Context, userId string, offset, limit int) (result []entities.GiveawayWin, err error) {
+	var wins []SqlGiveawayWin
+	_, err = repo.mysql.WithContext(ctx).Select(&wins, "SELECT w.id, w.giveaway_id, w.draw_id, w.user_id, w.user_name, w.code, w.notified, w.redemption_status, w.expires_at, w.redeemed_at, w.status_checked_at, w.replaces_winner_id, w.voucher_value, w.voucher_currency, g.guild_id, g.type AS giveaway_type, g.end_time AS giveaway_end_time FROM giveaway_winners w JOIN giveaways g ON w.giveaway_id = g.id WHERE w.user_id = ? ORDER BY g.end_time IS NULL, g.end_time DESC, w.id DESC LIMIT ? OFFSET ?", userId, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 
-	return codes, nil
+	for _, win := range wins {
+		result = append(result, *FromSqlGiveawayWin(&win))
+	}
+
+	return result, nil
+}
+
+func (repo GiveawaysRepo) CountWinsForUser(ctx context.Context, userId string) (int, error) {
+	count, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM giveaway_winners WHERE user_id = ?", userId)
+	return int(count), err
 }
 
 func (repo GiveawaysRepo) RemoveAllThxParticipantEntries(ctx context.Context, giveawayId int, participantId string) error {
@@ -561,7 +1518,7 @@ func (repo GiveawaysRepo) GetUsersWithMessagesFromLastDays(ctx context.Context,
 
 func (repo GiveawaysRepo) GetCodesForInfoMessage(ctx context.Context, messageId, userId string) ([]string, error) {
 	var codes []string
-	_, err := repo.mysql.WithContext(ctx).Select(&codes, "SELECT code FROM giveaways g JOIN giveaway_winners w ON g.id = w.giveaway_id WHERE g.info_message_id = ? AND w.user_id = ?", messageId, userId)
+	_, err := repo.mysql.WithContext(ctx).Select(&codes, "SELECT code FROM giveaways g JOIN giveaway_winners w ON g.id = w.giveaway_id WHERE g.info_message_id = ? AND w.user_id = ? AND w.code != '' AND w.redemption_status != ?", messageId, userId, entities.VoucherStatusReplaced)
 	if err != nil {
 		return nil, err
 	}
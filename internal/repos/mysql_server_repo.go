@@ -14,68 +14,154 @@ type ServerRepo struct {
 
 func NewServerRepo(mysql *gorp.DbMap) *ServerRepo {
 	mysql.AddTableWithName(SqlServerConfig{}, "server_configs").SetKeys(true, "id")
+	mysql.AddTableWithName(SqlVoucherConfig{}, "voucher_configs").SetKeys(true, "id").SetUniqueTogether("guild_id", "giveaway_type")
+	mysql.AddTableWithName(SqlHelperTier{}, "helper_tiers").SetKeys(true, "id").SetUniqueTogether("guild_id", "role_id")
 
 	return &ServerRepo{mysql: mysql}
 }
 
 type SqlServerConfig struct {
-	Id                           int             `db:"id,primarykey,autoincrement"`
-	GuildId                      string          `db:"guild_id,size:255"`
-	AdminRoleId                  string          `db:"admin_role_id,size:255"`
-	StatusChannel                json.RawMessage `db:"status_channel,size:255,default:'{}'"`
-	MainChannel                  string          `db:"main_channel,size:255"`
-	ThxInfoChannel               string          `db:"thx_info_channel,size:255"`
-	HelperRoleId                 string          `db:"helper_role_id,size:255"`
-	HelperRoleThxesNeeded        int             `db:"helper_role_thxes_needed"`
-	MessageGiveawayWinners       int             `db:"message_giveaway_winners,default:0"`
-	UnconditionalGiveawayChannel string          `db:"unconditional_giveaway_channel,size:255"`
-	UnconditionalGiveawayWinners int             `db:"unconditional_giveaway_winners,default:0"`
-	ConditionalGiveawayChannel   string          `db:"conditional_giveaway_channel,size:255"`
-	ConditionalGiveawayWinners   int             `db:"conditional_giveaway_winners,default:0"`
-	ConditionalGiveawayLevels    json.RawMessage `db:"conditional_giveaway_levels,default:'[]'"`
+	Id                            int             `db:"id,primarykey,autoincrement"`
+	GuildId                       string          `db:"guild_id,size:255"`
+	AdminRoleId                   string          `db:"admin_role_id,size:255"`
+	StatusChannel                 json.RawMessage `db:"status_channel,size:255,default:'{}'"`
+	MainChannel                   string          `db:"main_channel,size:255"`
+	ThxInfoChannel                string          `db:"thx_info_channel,size:255"`
+	MessageGiveawayWinners        int             `db:"message_giveaway_winners,default:0"`
+	UnconditionalGiveawayChannel  string          `db:"unconditional_giveaway_channel,size:255"`
+	UnconditionalGiveawayWinners  int             `db:"unconditional_giveaway_winners,default:0"`
+	ConditionalGiveawayChannel    string          `db:"conditional_giveaway_channel,size:255"`
+	ConditionalGiveawayWinners    int             `db:"conditional_giveaway_winners,default:0"`
+	ConditionalGiveawayLevels     json.RawMessage `db:"conditional_giveaway_levels,default:'[]'"`
+	ThxWeighting                  string          `db:"thx_weighting,size:20,default:'thx'"`
+	ThxWeightingCap               int             `db:"thx_weighting_cap,default:5"`
+	Timezone                      string          `db:"timezone,size:64,default:'Europe/Warsaw'"`
+	ThxGiveawaySchedule           string          `db:"thx_giveaway_schedule,size:255"`
+	MessageGiveawaySchedule       string          `db:"message_giveaway_schedule,size:255"`
+	UnconditionalGiveawaySchedule string          `db:"unconditional_giveaway_schedule,size:255"`
+	ConditionalGiveawaySchedule   string          `db:"conditional_giveaway_schedule,size:255"`
+	ExpiredVoucherPolicy          string          `db:"expired_voucher_policy,size:20,default:'none'"`
+	ThxCooldownMinutes            int             `db:"thx_cooldown_minutes,default:0"`
+	ThxDailyLimit                 int             `db:"thx_daily_limit,default:0"`
+	ThxmePendingLimit             int             `db:"thxme_pending_limit,default:0"`
+	ThxmeExpiryHours              int             `db:"thxme_expiry_hours,default:24"`
+	UnreviewedThxPolicy           string          `db:"unreviewed_thx_policy,size:20,default:'reject'"`
+	HelperThxWindowDays           int             `db:"helper_thx_window_days,default:0"`
+	HelperAnnouncementChannel     string          `db:"helper_announcement_channel,size:255"`
+	AdminLogChannel               string          `db:"admin_log_channel,size:255"`
+	AuditChannel                  string          `db:"audit_channel,size:255"`
+	MinAccountAgeDays             int             `db:"min_account_age_days,default:0"`
+	MinMemberDays                 int             `db:"min_member_days,default:0"`
+	EligibilityRoleId             string          `db:"eligibility_role_id,size:255"`
+}
+
+type SqlHelperTier struct {
+	Id          int    `db:"id,primarykey,autoincrement"`
+	GuildId     string `db:"guild_id,size:255"`
+	RoleId      string `db:"role_id,size:255"`
+	ThxesNeeded int    `db:"thxes_needed"`
+}
+
+type SqlVoucherConfig struct {
+	Id             int    `db:"id,primarykey,autoincrement"`
+	GuildId        string `db:"guild_id,size:255"`
+	GiveawayType   string `db:"giveaway_type,size:20"`
+	Value          int    `db:"value"`
+	Currency       string `db:"currency,size:3"`
+	ExpirationDays int    `db:"expiration_days"`
+	Prefix         string `db:"prefix,size:32"`
+	GroupId        string `db:"group_id,size:64"`
+}
+
+func FromSqlVoucherConfig(voucherConfig *SqlVoucherConfig) entities.VoucherConfig {
+	return entities.VoucherConfig{
+		GuildId:        voucherConfig.GuildId,
+		GiveawayType:   voucherConfig.GiveawayType,
+		Value:          voucherConfig.Value,
+		Currency:       voucherConfig.Currency,
+		ExpirationDays: voucherConfig.ExpirationDays,
+		Prefix:         voucherConfig.Prefix,
+		GroupId:        voucherConfig.GroupId,
+	}
 }
 
 func FromSqlServerConfig(serverConfig *SqlServerConfig) *entities.ServerConfig {
 	return &entities.ServerConfig{
-		Id:                           serverConfig.Id,
-		GuildId:                      serverConfig.GuildId,
-		AdminRoleId:                  serverConfig.AdminRoleId,
-		MainChannel:                  serverConfig.MainChannel,
-		StatusChannelsId:             serverConfig.StatusChannel,
-		ThxInfoChannel:               serverConfig.ThxInfoChannel,
-		HelperRoleId:                 serverConfig.HelperRoleId,
-		HelperRoleThxesNeeded:        serverConfig.HelperRoleThxesNeeded,
-		MessageGiveawayWinners:       serverConfig.MessageGiveawayWinners,
-		UnconditionalGiveawayChannel: serverConfig.UnconditionalGiveawayChannel,
-		UnconditionalGiveawayWinners: serverConfig.UnconditionalGiveawayWinners,
-		ConditionalGiveawayChannel:   serverConfig.ConditionalGiveawayChannel,
-		ConditionalGiveawayWinners:   serverConfig.ConditionalGiveawayWinners,
-		ConditionalGiveawayLevels:    serverConfig.ConditionalGiveawayLevels,
+		Id:                            serverConfig.Id,
+		GuildId:                       serverConfig.GuildId,
+		AdminRoleId:                   serverConfig.AdminRoleId,
+		MainChannel:                   serverConfig.MainChannel,
+		StatusChannelsId:              serverConfig.StatusChannel,
+		ThxInfoChannel:                serverConfig.ThxInfoChannel,
+		MessageGiveawayWinners:        serverConfig.MessageGiveawayWinners,
+		UnconditionalGiveawayChannel:  serverConfig.UnconditionalGiveawayChannel,
+		UnconditionalGiveawayWinners:  serverConfig.UnconditionalGiveawayWinners,
+		ConditionalGiveawayChannel:    serverConfig.ConditionalGiveawayChannel,
+		ConditionalGiveawayWinners:    serverConfig.ConditionalGiveawayWinners,
+		ConditionalGiveawayLevels:     serverConfig.ConditionalGiveawayLevels,
+		ThxWeighting:                  serverConfig.ThxWeighting,
+		ThxWeightingCap:               serverConfig.ThxWeightingCap,
+		Timezone:                      serverConfig.Timezone,
+		ThxGiveawaySchedule:           serverConfig.ThxGiveawaySchedule,
+		MessageGiveawaySchedule:       serverConfig.MessageGiveawaySchedule,
+		UnconditionalGiveawaySchedule: serverConfig.UnconditionalGiveawaySchedule,
+		ConditionalGiveawaySchedule:   serverConfig.ConditionalGiveawaySchedule,
+		ExpiredVoucherPolicy:          serverConfig.ExpiredVoucherPolicy,
+		ThxCooldownMinutes:            serverConfig.ThxCooldownMinutes,
+		ThxDailyLimit:                 serverConfig.ThxDailyLimit,
+		ThxmePendingLimit:             serverConfig.ThxmePendingLimit,
+		ThxmeExpiryHours:              serverConfig.ThxmeExpiryHours,
+		UnreviewedThxPolicy:           serverConfig.UnreviewedThxPolicy,
+		HelperThxWindowDays:           serverConfig.HelperThxWindowDays,
+		HelperAnnouncementChannel:     serverConfig.HelperAnnouncementChannel,
+		AdminLogChannel:               serverConfig.AdminLogChannel,
+		AuditChannel:                  serverConfig.AuditChannel,
+		MinAccountAgeDays:             serverConfig.MinAccountAgeDays,
+		MinMemberDays:                 serverConfig.MinMemberDays,
+		EligibilityRoleId:             serverConfig.EligibilityRoleId,
 	}
 }
 
 func ToSqlServerConfig(serverConfig *entities.ServerConfig) *SqlServerConfig {
 	return &SqlServerConfig{
-		Id:                           serverConfig.Id,
-		GuildId:                      serverConfig.GuildId,
-		AdminRoleId:                  serverConfig.AdminRoleId,
-		MainChannel:                  serverConfig.MainChannel,
-		StatusChannel:                serverConfig.StatusChannelsId,
-		ThxInfoChannel:               serverConfig.ThxInfoChannel,
-		HelperRoleId:                 serverConfig.HelperRoleId,
-		HelperRoleThxesNeeded:        serverConfig.HelperRoleThxesNeeded,
-		MessageGiveawayWinners:       serverConfig.MessageGiveawayWinners,
-		UnconditionalGiveawayChannel: serverConfig.UnconditionalGiveawayChannel,
-		UnconditionalGiveawayWinners: serverConfig.UnconditionalGiveawayWinners,
-		ConditionalGiveawayChannel:   serverConfig.ConditionalGiveawayChannel,
-		ConditionalGiveawayWinners:   serverConfig.ConditionalGiveawayWinners,
-		ConditionalGiveawayLevels:    serverConfig.ConditionalGiveawayLevels,
+		Id:                            serverConfig.Id,
+		GuildId:                       serverConfig.GuildId,
+		AdminRoleId:                   serverConfig.AdminRoleId,
+		MainChannel:                   serverConfig.MainChannel,
+		StatusChannel:                 serverConfig.StatusChannelsId,
+		ThxInfoChannel:                serverConfig.ThxInfoChannel,
+		MessageGiveawayWinners:        serverConfig.MessageGiveawayWinners,
+		UnconditionalGiveawayChannel:  serverConfig.UnconditionalGiveawayChannel,
+		UnconditionalGiveawayWinners:  serverConfig.UnconditionalGiveawayWinners,
+		ConditionalGiveawayChannel:    serverConfig.ConditionalGiveawayChannel,
+		ConditionalGiveawayWinners:    serverConfig.ConditionalGiveawayWinners,
+		ConditionalGiveawayLevels:     serverConfig.ConditionalGiveawayLevels,
+		ThxWeighting:                  serverConfig.ThxWeighting,
+		ThxWeightingCap:               serverConfig.ThxWeightingCap,
+		Timezone:                      serverConfig.Timezone,
+		ThxGiveawaySchedule:           serverConfig.ThxGiveawaySchedule,
+		MessageGiveawaySchedule:       serverConfig.MessageGiveawaySchedule,
+		UnconditionalGiveawaySchedule: serverConfig.UnconditionalGiveawaySchedule,
+		ConditionalGiveawaySchedule:   serverConfig.ConditionalGiveawaySchedule,
+		ExpiredVoucherPolicy:          serverConfig.ExpiredVoucherPolicy,
+		ThxCooldownMinutes:            serverConfig.ThxCooldownMinutes,
+		ThxDailyLimit:                 serverConfig.ThxDailyLimit,
+		ThxmePendingLimit:             serverConfig.ThxmePendingLimit,
+		ThxmeExpiryHours:              serverConfig.ThxmeExpiryHours,
+		UnreviewedThxPolicy:           serverConfig.UnreviewedThxPolicy,
+		HelperThxWindowDays:           serverConfig.HelperThxWindowDays,
+		HelperAnnouncementChannel:     serverConfig.HelperAnnouncementChannel,
+		AdminLogChannel:               serverConfig.AdminLogChannel,
+		AuditChannel:                  serverConfig.AuditChannel,
+		MinAccountAgeDays:             serverConfig.MinAccountAgeDays,
+		MinMemberDays:                 serverConfig.MinMemberDays,
+		EligibilityRoleId:             serverConfig.EligibilityRoleId,
 	}
 }
 
 func (repo *ServerRepo) GetServerConfigForGuild(ctx context.Context, guildId string) (entities.ServerConfig, error) {
 	var serverConfig SqlServerConfig
-	err := repo.mysql.WithContext(ctx).SelectOne(&serverConfig, "SELECT id, guild_id, admin_role_id, main_channel, status_channel, thx_info_channel, helper_role_id, helper_role_thxes_needed, message_giveaway_winners, unconditional_giveaway_channel, unconditional_giveaway_winners, conditional_giveaway_channel, conditional_giveaway_winners, conditional_giveaway_levels FROM server_configs WHERE guild_id = ?", guildId)
+	err := repo.mysql.WithContext(ctx).SelectOne(&serverConfig, "SELECT id, guild_id, admin_role_id, main_channel, status_channel, thx_info_channel, message_giveaway_winners, unconditional_giveaway_channel, unconditional_giveaway_winners, conditional_giveaway_channel, conditional_giveaway_winners, conditional_giveaway_levels, thx_weighting, thx_weighting_cap, timezone, thx_giveaway_schedule, message_giveaway_schedule, unconditional_giveaway_schedule, conditional_giveaway_schedule, expired_voucher_policy, thx_cooldown_minutes, thx_daily_limit, thxme_pending_limit, thxme_expiry_hours, unreviewed_thx_policy, helper_thx_window_days, helper_announcement_channel, admin_log_channel, audit_channel, min_account_age_days, min_member_days, eligibility_role_id FROM server_configs WHERE guild_id = ?", guildId)
 	if err != nil {
 		return entities.ServerConfig{}, err
 	}
@@ -89,7 +175,18 @@ func (repo *ServerRepo) InsertServerConfig(ctx context.Context, guildId, giveawa
 	serverConfig.ConditionalGiveawayChannel = giveawayChannel
 	serverConfig.UnconditionalGiveawayChannel = giveawayChannel
 	serverConfig.AdminRoleId = adminRole
-	serverConfig.HelperRoleThxesNeeded = 0
+	serverConfig.StatusChannel = json.RawMessage("{}")
+	serverConfig.ConditionalGiveawayLevels = json.RawMessage("[]")
+	serverConfig.ThxWeighting = entities.ThxWeightingPerThx
+	serverConfig.ThxWeightingCap = entities.DefaultThxWeightingCap
+	serverConfig.Timezone = entities.DefaultTimezone
+	serverConfig.ThxGiveawaySchedule = entities.DefaultThxGiveawaySchedule
+	serverConfig.MessageGiveawaySchedule = entities.DefaultMessageGiveawaySchedule
+	serverConfig.UnconditionalGiveawaySchedule = entities.DefaultUnconditionalGiveawaySchedule
+	serverConfig.ConditionalGiveawaySchedule = entities.DefaultConditionalGiveawaySchedule
+	serverConfig.ExpiredVoucherPolicy = entities.ExpiredVoucherPolicyNone
+	serverConfig.ThxmeExpiryHours = entities.DefaultThxmeExpiryHours
+	serverConfig.UnreviewedThxPolicy = entities.UnreviewedThxPolicyReject
 	err := repo.mysql.WithContext(ctx).Insert(&serverConfig)
 	if err != nil {
 		return err
@@ -130,6 +227,15 @@ func (repo *ServerRepo) GetGuildsWithMessageGiveawaysEnabled(ctx context.Context
 	return guilds, nil
 }
 
+func (repo *ServerRepo) GetGuildsWithHelperTiers(ctx context.Context) ([]string, error) {
+	var guilds []string
+	_, err := repo.mysql.WithContext(ctx).Select(&guilds, "SELECT DISTINCT guild_id FROM helper_tiers")
+	if err != nil {
+		return nil, err
+	}
+	return guilds, nil
+}
+
 func (repo *ServerRepo) GetConditionalGiveawayLevels(ctx context.Context, guildId string) ([]int, error) {
 	var levelsJson json.RawMessage
 	err := repo.mysql.WithContext(ctx).SelectOne(&levelsJson, "SELECT conditional_giveaway_levels FROM server_configs WHERE guild_id = ?", guildId)
@@ -145,3 +251,57 @@ func (repo *ServerRepo) GetConditionalGiveawayLevels(ctx context.Context, guildI
 
 	return levels, nil
 }
+
+func (repo *ServerRepo) GetVoucherConfig(ctx context.Context, guildId, giveawayType string) (entities.VoucherConfig, error) {
+	var voucherConfig SqlVoucherConfig
+	err := repo.mysql.WithContext(ctx).SelectOne(&voucherConfig, "SELECT id, guild_id, giveaway_type, value, currency, expiration_days, prefix, group_id FROM voucher_configs WHERE guild_id = ? AND giveaway_type = ?", guildId, giveawayType)
+	if err != nil {
+		return entities.VoucherConfig{}, err
+	}
+	return FromSqlVoucherConfig(&voucherConfig), nil
+}
+
+func (repo *ServerRepo) GetVoucherConfigs(ctx context.Context) (result []entities.VoucherConfig, err error) {
+	var voucherConfigs []SqlVoucherConfig
+	_, err = repo.mysql.WithContext(ctx).Select(&voucherConfigs, "SELECT id, guild_id, giveaway_type, value, currency, expiration_days, prefix, group_id FROM voucher_configs ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, voucherConfig := range voucherConfigs {
+		result = append(result, FromSqlVoucherConfig(&voucherConfig))
+	}
+
+	return result, nil
+}
+
+func (repo *ServerRepo) SetVoucherConfig(ctx context.Context, voucherConfig *entities.VoucherConfig) error {
+	_, err := repo.mysql.WithContext(ctx).Exec("INSERT INTO voucher_configs (guild_id, giveaway_type, value, currency, expiration_days, prefix, group_id) VALUES (?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE value = VALUES(value), currency = VALUES(currency), expiration_days = VALUES(expiration_days), prefix = VALUES(prefix), group_id = VALUES(group_id)",
+		voucherConfig.GuildId, voucherConfig.GiveawayType, voucherConfig.Value, voucherConfig.Currency, voucherConfig.ExpirationDays, voucherConfig.Prefix, voucherConfig.GroupId)
+	return err
+}
+
+func (repo *ServerRepo) GetHelperTiers(ctx context.Context, guildId string) (result []entities.HelperTier, err error) {
+	var tiers []SqlHelperTier
+	_, err = repo.mysql.WithContext(ctx).Select(&tiers, "SELECT id, guild_id, role_id, thxes_needed FROM helper_tiers WHERE guild_id = ? ORDER BY thxes_needed, id", guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tier := range tiers {
+		result = append(result, entities.HelperTier{GuildId: tier.GuildId, RoleId: tier.RoleId, ThxesNeeded: tier.ThxesNeeded})
+	}
+
+	return result, nil
+}
+
+func (repo *ServerRepo) SetHelperTier(ctx context.Context, tier *entities.HelperTier) error {
+	_, err := repo.mysql.WithContext(ctx).Exec("INSERT INTO helper_tiers (guild_id, role_id, thxes_needed) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE thxes_needed = VALUES(thxes_needed)",
+		tier.GuildId, tier.RoleId, tier.ThxesNeeded)
+	return err
+}
+
+func (repo *ServerRepo) RemoveHelperTier(ctx context.Context, guildId, roleId string) error {
+	_, err := repo.mysql.WithContext(ctx).Exec("DELETE FROM helper_tiers WHERE guild_id = ? AND role_id = ?", guildId, roleId)
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"csrvbot/domain/entities"
 	"github.com/go-gorp/gorp"
+	"time"
 )
 
 type UserRepo struct {
@@ -19,10 +20,13 @@ func NewUserRepo(mysql *gorp.DbMap) *UserRepo {
 }
 
 type SqlBlacklist struct {
-	Id            int    `db:"id,primarykey,autoincrement"`
-	GuildId       string `db:"guild_id,size:255"`
-	UserId        string `db:"user_id,size:255"`
-	BlacklisterId string `db:"blacklister_id,size:255"`
+	Id            int        `db:"id,primarykey,autoincrement"`
+	GuildId       string     `db:"guild_id,size:255"`
+	UserId        string     `db:"user_id,size:255"`
+	BlacklisterId string     `db:"blacklister_id,size:255"`
+	Reason        string     `db:"reason,size:255"`
+	CreatedAt     *time.Time `db:"created_at"`
+	ExpiresAt     *time.Time `db:"expires_at"`
 }
 
 type SqlMemberRole struct {
@@ -33,10 +37,13 @@ type SqlMemberRole struct {
 }
 
 type SqlHelperBlacklist struct {
-	Id            int    `db:"id,primarykey,autoincrement"`
-	GuildId       string `db:"guild_id,size:255"`
-	UserId        string `db:"user_id,size:255"`
-	BlacklisterId string `db:"blacklister_id,size:255"`
+	Id            int        `db:"id,primarykey,autoincrement"`
+	GuildId       string     `db:"guild_id,size:255"`
+	UserId        string     `db:"user_id,size:255"`
+	BlacklisterId string     `db:"blacklister_id,size:255"`
+	Reason        string     `db:"reason,size:255"`
+	CreatedAt     *time.Time `db:"created_at"`
+	ExpiresAt     *time.Time `db:"expires_at"`
 }
 
 func FromSqlBlacklist(blacklist *SqlBlacklist) *entities.Blacklist {
@@ -45,6 +52,9 @@ func FromSqlBlacklist(blacklist *SqlBlacklist) *entities.Blacklist {
 		GuildId:       blacklist.GuildId,
 		UserId:        blacklist.UserId,
 		BlacklisterId: blacklist.BlacklisterId,
+		Reason:        blacklist.Reason,
+		CreatedAt:     blacklist.CreatedAt,
+		ExpiresAt:     blacklist.ExpiresAt,
 	}
 }
 
@@ -54,6 +64,9 @@ func ToSqlBlacklist(blacklist *entities.Blacklist) *SqlBlacklist {
 		GuildId:       blacklist.GuildId,
 		UserId:        blacklist.UserId,
 		BlacklisterId: blacklist.BlacklisterId,
+		Reason:        blacklist.Reason,
+		CreatedAt:     blacklist.CreatedAt,
+		ExpiresAt:     blacklist.ExpiresAt,
 	}
 }
 
@@ -81,6 +94,9 @@ func FromSqlHelperBlacklist(helperBlacklist *SqlHelperBlacklist) *entities.Helpe
 		GuildId:       helperBlacklist.GuildId,
 		UserId:        helperBlacklist.UserId,
 		BlacklisterId: helperBlacklist.BlacklisterId,
+		Reason:        helperBlacklist.Reason,
+		CreatedAt:     helperBlacklist.CreatedAt,
+		ExpiresAt:     helperBlacklist.ExpiresAt,
 	}
 }
 
@@ -90,6 +106,9 @@ func ToSqlHelperBlacklist(helperBlacklist *entities.HelperBlacklist) *SqlHelperB
 		GuildId:       helperBlacklist.GuildId,
 		UserId:        helperBlacklist.UserId,
 		BlacklisterId: helperBlacklist.BlacklisterId,
+		Reason:        helperBlacklist.Reason,
+		CreatedAt:     helperBlacklist.CreatedAt,
+		ExpiresAt:     helperBlacklist.ExpiresAt,
 	}
 }
 
@@ -126,7 +145,7 @@ func (repo *UserRepo) RemoveRoleForMember(ctx context.Context, guildId, memberId
 }
 
 func (repo *UserRepo) IsUserHelperBlacklisted(ctx context.Context, userId, guildId string) (bool, error) {
-	ret, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM helper_blacklists WHERE guild_id = ? AND user_id = ?", guildId, userId)
+	ret, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM helper_blacklists WHERE guild_id = ? AND user_id = ? AND (expires_at IS NULL OR expires_at > ?)", guildId, userId, time.Now())
 	if err != nil {
 		return false, err
 	}
@@ -134,16 +153,16 @@ func (repo *UserRepo) IsUserHelperBlacklisted(ctx context.Context, userId, guild
 }
 
 func (repo *UserRepo) IsUserBlacklisted(ctx context.Context, userId string, guildId string) (bool, error) {
-	ret, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM blacklists WHERE guild_id = ? AND user_id = ?", guildId, userId)
+	ret, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM blacklists WHERE guild_id = ? AND user_id = ? AND (expires_at IS NULL OR expires_at > ?)", guildId, userId, time.Now())
 	if err != nil {
 		return false, err
 	}
 	return ret > 0, nil
 }
 
-func (repo *UserRepo) AddBlacklistForUser(ctx context.Context, userId, guildId, blacklisterId string) error {
-	blacklist := SqlBlacklist{UserId: userId, GuildId: guildId, BlacklisterId: blacklisterId}
-	err := repo.mysql.WithContext(ctx).Insert(&blacklist)
+func (repo *UserRepo) AddBlacklistForUser(ctx context.Context, userId, guildId, blacklisterId, reason string, expiresAt *time.Time) error {
+	_, err := repo.mysql.WithContext(ctx).Exec("INSERT INTO blacklists (guild_id, user_id, blacklister_id, reason, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE blacklister_id = VALUES(blacklister_id), reason = VALUES(reason), created_at = VALUES(created_at), expires_at = VALUES(expires_at)",
+		guildId, userId, blacklisterId, reason, time.Now(), expiresAt)
 	if err != nil {
 		return err
 	}
@@ -158,9 +177,9 @@ func (repo *UserRepo) RemoveBlacklistForUser(ctx context.Context, userId, guildI
 	return nil
 }
 
-func (repo *UserRepo) AddHelperBlacklistForUser(ctx context.Context, userId, guildId, blacklisterId string) error {
-	blacklist := SqlHelperBlacklist{UserId: userId, GuildId: guildId, BlacklisterId: blacklisterId}
-	err := repo.mysql.WithContext(ctx).Insert(&blacklist)
+func (repo *UserRepo) AddHelperBlacklistForUser(ctx context.Context, userId, guildId, blacklisterId, reason string, expiresAt *time.Time) error {
+	_, err := repo.mysql.WithContext(ctx).Exec("INSERT INTO helper_blacklists (guild_id, user_id, blacklister_id, reason, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE blacklister_id = VALUES(blacklister_id), reason = VALUES(reason), created_at = VALUES(created_at), expires_at = VALUES(expires_at)",
+		guildId, userId, blacklisterId, reason, time.Now(), expiresAt)
 	if err != nil {
 		return err
 	}
@@ -174,3 +193,59 @@ func (repo *UserRepo) RemoveHelperBlacklistForUser(ctx context.Context, userId,
 	}
 	return nil
 }
+
+func (repo *UserRepo) GetBlacklists(ctx context.Context, guildId string) (result []entities.Blacklist, err error) {
+	var blacklists []SqlBlacklist
+	_, err = repo.mysql.WithContext(ctx).Select(&blacklists, "SELECT id, guild_id, user_id, blacklister_id, reason, created_at, expires_at FROM blacklists WHERE guild_id = ? AND (expires_at IS NULL OR expires_at > ?) ORDER BY expires_at IS NULL, expires_at, id", guildId, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, blacklist := range blacklists {
+		result = append(result, *FromSqlBlacklist(&blacklist))
+	}
+
+	return result, nil
+}
+
+func (repo *UserRepo) GetExpiredBlacklists(ctx context.Context, now time.Time) (result []entities.Blacklist, err error) {
+	var blacklists []SqlBlacklist
+	_, err = repo.mysql.WithContext(ctx).Select(&blacklists, "SELECT id, guild_id, user_id, blacklister_id, reason, created_at, expires_at FROM blacklists WHERE expires_at <= ? ORDER BY expires_at, id", now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, blacklist := range blacklists {
+		result = append(result, *FromSqlBlacklist(&blacklist))
+	}
+
+	return result, nil
+}
+
+func (repo *UserRepo) GetHelperBlacklists(ctx context.Context, guildId string) (result []entities.HelperBlacklist, err error) {
+	var blacklists []SqlHelperBlacklist
+	_, err = repo.mysql.WithContext(ctx).Select(&blacklists, "SELECT id, guild_id, user_id, blacklister_id, reason, created_at, expires_at FROM helper_blacklists WHERE guild_id = ? AND (expires_at IS NULL OR expires_at > ?) ORDER BY expires_at IS NULL, expires_at, id", guildId, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, blacklist := range blacklists {
+		result = append(result, *FromSqlHelperBlacklist(&blacklist))
+	}
+
+	return result, nil
+}
+
+func (repo *UserRepo) GetExpiredHelperBlacklists(ctx context.Context, now time.Time) (result []entities.HelperBlacklist, err error) {
+	var blacklists []SqlHelperBlacklist
+	_, err = repo.mysql.WithContext(ctx).Select(&blacklists, "SELECT id, guild_id, user_id, blacklister_id, reason, created_at, expires_at FROM helper_blacklists WHERE expires_at <= ? ORDER BY expires_at, id", now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, blacklist := range blacklists {
+		result = append(result, *FromSqlHelperBlacklist(&blacklist))
+	}
+
+	return result, nil
+}
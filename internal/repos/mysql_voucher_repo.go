@@ -0,0 +1,186 @@
+package repos
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"time"
+
+	"github.com/go-gorp/gorp"
+)
+
+type VoucherRepo struct {
+	mysql *gorp.DbMap
+}
+
+func NewVoucherRepo(mysql *gorp.DbMap) *VoucherRepo {
+	mysql.AddTableWithName(SqlPooledVoucher{}, "voucher_pool").SetKeys(true, "id")
+
+	return &VoucherRepo{mysql: mysql}
+}
+
+type SqlPooledVoucher struct {
+	Id             int       `db:"id,primarykey,autoincrement"`
+	Code           string    `db:"code,size:255"`
+	Value          int       `db:"value"`
+	Currency       string    `db:"currency,size:3"`
+	ExpirationDays int       `db:"expiration_days"`
+	Prefix         string    `db:"prefix,size:32"`
+	GroupId        string    `db:"group_id,size:64"`
+	CreatedAt      time.Time `db:"created_at"`
+	ExpiresAt      time.Time `db:"expires_at"`
+	WinnerId       *int      `db:"winner_id"`
+}
+
+func FromSqlPooledVoucher(voucher *SqlPooledVoucher) *entities.PooledVoucher {
+	return &entities.PooledVoucher{
+		Id:   voucher.Id,
+		Code: voucher.Code,
+		Profile: entities.VoucherProfile{
+			Value:          voucher.Value,
+			Currency:       voucher.Currency,
+			ExpirationDays: voucher.ExpirationDays,
+			Prefix:         voucher.Prefix,
+			GroupId:        voucher.GroupId,
+		},
+		CreatedAt: voucher.CreatedAt,
+		ExpiresAt: voucher.ExpiresAt,
+		WinnerId:  voucher.WinnerId,
+	}
+}
+
+func ToSqlPooledVoucher(voucher *entities.PooledVoucher) *SqlPooledVoucher {
+	return &SqlPooledVoucher{
+		Id:             voucher.Id,
+		Code:           voucher.Code,
+		Value:          voucher.Profile.Value,
+		Currency:       voucher.Profile.Currency,
+		ExpirationDays: voucher.Profile.ExpirationDays,
+		Prefix:         voucher.Profile.Prefix,
+		GroupId:        voucher.Profile.GroupId,
+		CreatedAt:      voucher.CreatedAt,
+		ExpiresAt:      voucher.ExpiresAt,
+		WinnerId:       voucher.WinnerId,
+	}
+}
+
+func (repo *VoucherRepo) AddPooledVouchers(ctx context.Context, vouchers []entities.PooledVoucher) error {
+	tx, err := repo.mysql.Begin()
+	if err != nil {
+		return err
+	}
+
+	for i := range vouchers {
+		sqlVoucher := ToSqlPooledVoucher(&vouchers[i])
+		err = tx.WithContext(ctx).Insert(sqlVoucher)
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		vouchers[i].Id = sqlVoucher.Id
+	}
+
+	return tx.Commit()
+}
+
+func (repo *VoucherRepo) TakePooledVoucher(ctx context.Context, profile entities.VoucherProfile, createdAfter, expiresAfter time.Time) (*entities.PooledVoucher, error) {
+	tx, err := repo.mysql.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	var voucher SqlPooledVoucher
+	err = tx.WithContext(ctx).SelectOne(&voucher, "SELECT id, code, value, currency, expiration_days, prefix, group_id, created_at, expires_at, winner_id FROM voucher_pool WHERE winner_id IS NULL AND value = ? AND currency = ? AND expiration_days = ? AND prefix = ? AND group_id = ? AND created_at > ? AND expires_at > ? ORDER BY created_at, id LIMIT 1 FOR UPDATE",
+		profile.Value, profile.Currency, profile.ExpirationDays, profile.Prefix, profile.GroupId, createdAfter, expiresAfter)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	_, err = tx.WithContext(ctx).Exec("DELETE FROM voucher_pool WHERE id = ?", voucher.Id)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return FromSqlPooledVoucher(&voucher), nil
+}
+
+func (repo *VoucherRepo) ReservePooledVoucher(ctx context.Context, profile entities.VoucherProfile, createdAfter, expiresAfter time.Time, winnerId int) (*entities.PooledVoucher, error) {
+	tx, err := repo.mysql.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	var voucher SqlPooledVoucher
+	err = tx.WithContext(ctx).SelectOne(&voucher, "SELECT id, code, value, currency, expiration_days, prefix, group_id, created_at, expires_at, winner_id FROM voucher_pool WHERE winner_id IS NULL AND value = ? AND currency = ? AND expiration_days = ? AND prefix = ? AND group_id = ? AND created_at > ? AND expires_at > ? ORDER BY created_at, id LIMIT 1 FOR UPDATE",
+		profile.Value, profile.Currency, profile.ExpirationDays, profile.Prefix, profile.GroupId, createdAfter, expiresAfter)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	_, err = tx.WithContext(ctx).Exec("UPDATE voucher_pool SET winner_id = ? WHERE id = ?", winnerId, voucher.Id)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	voucher.WinnerId = &winnerId
+	return FromSqlPooledVoucher(&voucher), nil
+}
+
+func (repo *VoucherRepo) GetReservedVoucher(ctx context.Context, winnerId int) (*entities.PooledVoucher, error) {
+	var voucher SqlPooledVoucher
+	err := repo.mysql.WithContext(ctx).SelectOne(&voucher, "SELECT id, code, value, currency, expiration_days, prefix, group_id, created_at, expires_at, winner_id FROM voucher_pool WHERE winner_id = ?", winnerId)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromSqlPooledVoucher(&voucher), nil
+}
+
+func (repo *VoucherRepo) RemoveReservedVoucher(ctx context.Context, winnerId int) error {
+	_, err := repo.mysql.WithContext(ctx).Exec("DELETE FROM voucher_pool WHERE winner_id = ?", winnerId)
+	return err
+}
+
+func (repo *VoucherRepo) CountPooledVouchers(ctx context.Context, profile entities.VoucherProfile, createdAfter, expiresAfter time.Time) (int, error) {
+	count, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM voucher_pool WHERE winner_id IS NULL AND value = ? AND currency = ? AND expiration_days = ? AND prefix = ? AND group_id = ? AND created_at > ? AND expires_at > ?",
+		profile.Value, profile.Currency, profile.ExpirationDays, profile.Prefix, profile.GroupId, createdAfter, expiresAfter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func (repo *VoucherRepo) RemoveExpiredPooledVouchers(ctx context.Context, expiresBefore time.Time) (int, error) {
+	result, err := repo.mysql.WithContext(ctx).Exec("DELETE FROM voucher_pool WHERE winner_id IS NULL AND expires_at <= ?", expiresBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(removed), nil
+}
+
+func (repo *VoucherRepo) CountAgedPooledVouchers(ctx context.Context, createdBefore time.Time) (int, error) {
+	count, err := repo.mysql.WithContext(ctx).SelectInt("SELECT COUNT(*) FROM voucher_pool WHERE winner_id IS NULL AND created_at <= ?", createdBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/logger"
+	"encoding/json"
+	"time"
+)
+
+type AuditService struct {
+	CraftserveUrl string
+	AuditLogRepo  entities.AuditLogRepo
+	ServerRepo    entities.ServerRepo
+}
+
+func NewAuditService(craftserveUrl string, auditLogRepo entities.AuditLogRepo, serverRepo entities.ServerRepo) *AuditService {
+	return &AuditService{
+		CraftserveUrl: craftserveUrl,
+		AuditLogRepo:  auditLogRepo,
+		ServerRepo:    serverRepo,
+	}
+}
+
+// Record saves the administrative action in the audit log and mirrors it to the audit channel of the guild.
+func (h *AuditService) Record(ctx context.Context, s discord.Session, entry *entities.AuditLogEntry) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(entry.GuildId)
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	err := h.AuditLogRepo.InsertAuditLogEntry(ctx, entry)
+	if err != nil {
+		log.WithError(err).Error("Record#h.AuditLogRepo.InsertAuditLogEntry")
+	}
+
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, entry.GuildId)
+	if err != nil {
+		log.WithError(err).Error("Record#h.ServerRepo.GetServerConfigForGuild")
+		return
+	}
+	if serverConfig.AuditChannel == "" {
+		return
+	}
+	_, err = s.ChannelMessageSendEmbed(serverConfig.AuditChannel, discord.ConstructAuditLogEntryEmbed(h.CraftserveUrl, *entry))
+	if err != nil {
+		log.WithError(err).Error("Record#s.ChannelMessageSendEmbed")
+	}
+}
+
+// RecordChange records the action with the fields of before and after that differ, both are marshalled to JSON.
+// Nothing is recorded when they are equal.
+func (h *AuditService) RecordChange(ctx context.Context, s discord.Session, entry *entities.AuditLogEntry, before, after any) {
+	entry.Before, entry.After = auditDiff(before, after)
+	if entry.Before == entry.After {
+		return
+	}
+
+	h.Record(ctx, s, entry)
+}
+
+// auditDiff returns the JSON of the fields of before and after that differ, or of the whole values if they are not
+// JSON objects.
+func auditDiff(before, after any) (string, string) {
+	beforeJson, _ := json.Marshal(before)
+	afterJson, _ := json.Marshal(after)
+
+	var beforeFields, afterFields map[string]json.RawMessage
+	if json.Unmarshal(beforeJson, &beforeFields) != nil || json.Unmarshal(afterJson, &afterFields) != nil {
+		if bytes.Equal(beforeJson, afterJson) {
+			return "", ""
+		}
+		return auditValue(beforeJson), auditValue(afterJson)
+	}
+
+	for key, value := range afterFields {
+		if previous, ok := beforeFields[key]; ok && bytes.Equal(previous, value) {
+			delete(beforeFields, key)
+			delete(afterFields, key)
+		}
+	}
+	if len(beforeFields) == 0 && len(afterFields) == 0 {
+		return "", ""
+	}
+
+	beforeJson, _ = json.Marshal(beforeFields)
+	afterJson, _ = json.Marshal(afterFields)
+	return string(beforeJson), string(afterJson)
+}
+
+// auditValue records a missing value, e.g. of a removed helper tier, as empty.
+func auditValue(value []byte) string {
+	if string(value) == "null" {
+		return ""
+	}
+	return string(value)
+}
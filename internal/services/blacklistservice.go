@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/logger"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type BlacklistService struct {
+	UserRepo      entities.UserRepo
+	HelperService *HelperService
+	AuditService  *AuditService
+}
+
+func NewBlacklistService(userRepo entities.UserRepo, helperService *HelperService, auditService *AuditService) *BlacklistService {
+	return &BlacklistService{
+		UserRepo:      userRepo,
+		HelperService: helperService,
+		AuditService:  auditService,
+	}
+}
+
+// RunBlacklistExpiry lifts the expired blacklist and helper blacklist entries every interval until ctx is done.
+func (h *BlacklistService) RunBlacklistExpiry(ctx context.Context, s discord.Session, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		h.ExpireBlacklists(ctx, s)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireBlacklists removes the expired blacklist and helper blacklist entries of all guilds and lets the users know
+// in a DM. The lifts are recorded in the audit log and members whose helper blacklist expired get their helper roles
+// back.
+func (h *BlacklistService) ExpireBlacklists(ctx context.Context, s discord.Session) {
+	log := logger.GetLoggerFromContext(ctx)
+	now := time.Now()
+
+	blacklists, err := h.UserRepo.GetExpiredBlacklists(ctx, now)
+	if err != nil {
+		log.WithError(err).Error("ExpireBlacklists#h.UserRepo.GetExpiredBlacklists")
+	}
+	for _, blacklist := range blacklists {
+		err = h.UserRepo.RemoveBlacklistForUser(ctx, blacklist.UserId, blacklist.GuildId)
+		if err != nil {
+			log.WithGuild(blacklist.GuildId).WithError(err).Error("ExpireBlacklists#h.UserRepo.RemoveBlacklistForUser")
+			continue
+		}
+		log.WithGuild(blacklist.GuildId).Infof("Blacklist of %s expired", blacklist.UserId)
+		h.recordExpiry(ctx, s, blacklist.GuildId, blacklist.UserId, entities.AuditActionUnblacklist)
+		h.notifyExpiry(ctx, s, blacklist.GuildId, blacklist.UserId, "Twoja blokada udziału w giveawayach na serwerze %s wygasła, możesz znowu w nich uczestniczyć.")
+	}
+
+	helperBlacklists, err := h.UserRepo.GetExpiredHelperBlacklists(ctx, now)
+	if err != nil {
+		log.WithError(err).Error("ExpireBlacklists#h.UserRepo.GetExpiredHelperBlacklists")
+	}
+	for _, blacklist := range helperBlacklists {
+		err = h.UserRepo.RemoveHelperBlacklistForUser(ctx, blacklist.UserId, blacklist.GuildId)
+		if err != nil {
+			log.WithGuild(blacklist.GuildId).WithError(err).Error("ExpireBlacklists#h.UserRepo.RemoveHelperBlacklistForUser")
+			continue
+		}
+		log.WithGuild(blacklist.GuildId).Infof("Helper blacklist of %s expired", blacklist.UserId)
+		h.recordExpiry(ctx, s, blacklist.GuildId, blacklist.UserId, entities.AuditActionHelperUnblacklist)
+		h.notifyExpiry(ctx, s, blacklist.GuildId, blacklist.UserId, "Twoja blokada rang helpera na serwerze %s wygasła, znowu możesz je otrzymywać za podziękowania.")
+		h.HelperService.CheckHelper(ctx, s, blacklist.GuildId, blacklist.UserId)
+	}
+}
+
+// recordExpiry records the lift of an expired blacklist in the audit log as an action of the bot.
+func (h *BlacklistService) recordExpiry(ctx context.Context, s discord.Session, guildId, userId, action string) {
+	h.AuditService.Record(ctx, s, &entities.AuditLogEntry{
+		GuildId: guildId,
+		Action:  action,
+		Target:  userId,
+	})
+}
+
+// notifyExpiry sends the user a DM with the content, formatted with the name of the guild.
+func (h *BlacklistService) notifyExpiry(ctx context.Context, s discord.Session, guildId, userId, content string) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId).WithUser(userId)
+	guild, err := s.Guild(guildId)
+	if err != nil {
+		log.WithError(err).Error("notifyExpiry#s.Guild")
+		return
+	}
+
+	dm, err := s.UserChannelCreate(userId)
+	if err != nil {
+		log.WithError(err).Error("notifyExpiry#s.UserChannelCreate")
+		return
+	}
+	_, err = s.ChannelMessageSend(dm.ID, fmt.Sprintf(content, "**"+guild.Name+"**"))
+	if err != nil && !discord.EqualError(err, discordgo.ErrCodeCannotSendMessagesToThisUser) {
+		log.WithError(err).Error("notifyExpiry#s.ChannelMessageSend")
+	}
+}
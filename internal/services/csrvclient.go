@@ -6,39 +6,154 @@ import (
 	"csrvbot/domain/entities"
 	"csrvbot/domain/values"
 	"csrvbot/dtos"
+	"csrvbot/pkg/backoff"
+	"csrvbot/pkg/circuitbreaker"
 	"csrvbot/pkg/logger"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/Craftserve/monies"
 	"io"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"time"
 )
 
 type CsrvClient struct {
-	Secret         string
-	Environment    string
-	CraftserveUrl  string
-	ValuePLN       int
-	ExpirationDays int
+	Secret      string
+	Environment string
+	ApiUrl      string
+	HttpClient  *http.Client
+	MaxAttempts int
+	RetryDelay  time.Duration
+	Breaker     *circuitbreaker.Breaker
 }
 
-func NewCsrvClient(secret, environment, craftserveUrl string, valuePLN, expirationDays int) *CsrvClient {
-	return &CsrvClient{Secret: secret, Environment: environment, CraftserveUrl: craftserveUrl, ValuePLN: valuePLN, ExpirationDays: expirationDays}
+func NewCsrvClient(secret, environment, apiUrl string) *CsrvClient {
+	return &CsrvClient{
+		Secret:      secret,
+		Environment: environment,
+		ApiUrl:      apiUrl,
+		HttpClient:  &http.Client{Timeout: 15 * time.Second},
+		MaxAttempts: 3,
+		RetryDelay:  time.Second,
+		Breaker:     circuitbreaker.New(5, time.Minute),
+	}
+}
+
+// ErrUnexpectedResponse is returned when the API accepted a request, but its response could not be used. The request
+// may still have taken effect, e.g. created the voucher, so it is not retried and the admins have to check it.
+var ErrUnexpectedResponse = errors.New("unexpected response")
+
+// permanentError is an error which will not go away by retrying the same request, e.g. a 4xx response.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// GenerateVoucher generates a single use voucher with the value, expiry, prefix and group from voucherConfig.
+func (c *CsrvClient) GenerateVoucher(ctx context.Context, voucherConfig entities.VoucherConfig) (string, error) {
+	vouchers, err := c.GenerateVouchers(ctx, voucherConfig, 1)
+	if err != nil {
+		return "", err
+	}
+
+	return vouchers[0].Id, nil
 }
 
-func (c *CsrvClient) GetCSRVCode(ctx context.Context) (string, error) {
+// GenerateVouchers generates quantity single use vouchers (at most 100) in one request.
+func (c *CsrvClient) GenerateVouchers(ctx context.Context, voucherConfig entities.VoucherConfig, quantity int) ([]entities.Voucher, error) {
 	log := logger.GetLoggerFromContext(ctx)
-	log.Debug("Generating CSRV voucher")
+	log.Debugf("Generating %d CSRV voucher(s)", quantity)
 
 	if c.Environment == "development" {
-		return fmt.Sprintf("DEV-%d", rand.Int()), nil
+		expires := voucherConfig.ExpiresAt(time.Now())
+		vouchers := make([]entities.Voucher, quantity)
+		for i := range vouchers {
+			vouchers[i] = entities.Voucher{Id: fmt.Sprintf("DEV-%d", rand.Int()), CreatedAt: time.Now(), Expires: &expires}
+		}
+		return vouchers, nil
 	}
 
-	prefix, group := "discord", "discord-giveaway"
-	expires := time.Now().Add(24 * time.Duration(c.ExpirationDays) * time.Hour)
-	uses, quantity := 1, 1
+	payload, err := voucherPayload(voucherConfig, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	var vouchers []entities.Voucher
+	// A request which timed out may still have created the vouchers, retrying only leaves unused ones behind
+	err = c.withRetries(ctx, "GenerateVoucher", func() error {
+		vouchers, err = c.generateVouchers(ctx, payload, quantity)
+		return err
+	})
+
+	return vouchers, err
+}
+
+// GetVoucher returns the current state of a voucher, including when it was redeemed.
+func (c *CsrvClient) GetVoucher(ctx context.Context, code string) (*entities.Voucher, error) {
+	if c.Environment == "development" {
+		return &entities.Voucher{Id: code}, nil
+	}
+
+	var voucher *entities.Voucher
+	err := c.withRetries(ctx, "GetVoucher", func() (err error) {
+		voucher, err = c.getVoucher(ctx, code)
+		return err
+	})
+
+	return voucher, err
+}
+
+// withRetries retries request with backoff on errors which are not permanent, e.g. server errors and timeouts.
+// While the circuit breaker is open it fails immediately with circuitbreaker.ErrOpen.
+func (c *CsrvClient) withRetries(ctx context.Context, name string, request func() error) error {
+	log := logger.GetLoggerFromContext(ctx)
+	for attempt := 1; ; attempt++ {
+		if err := c.Breaker.Allow(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		err := request()
+		var permanent *permanentError
+		if err == nil || errors.As(err, &permanent) {
+			// The API is responding, even if it rejected the request
+			c.Breaker.Success()
+			return err
+		}
+
+		c.Breaker.Failure()
+		if attempt >= c.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		delay := backoff.Exponential(attempt, c.RetryDelay, 30*time.Second)
+		log.WithError(err).Warnf("%s attempt %d failed, retrying in %s", name, attempt, delay)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+func voucherPayload(voucherConfig entities.VoucherConfig, quantity int) ([]byte, error) {
+	value, err := voucherConfig.Money()
+	if err != nil {
+		return nil, fmt.Errorf("GenerateVoucher invalid voucher value: %w", err)
+	}
+
+	prefix, group := voucherConfig.Prefix, voucherConfig.GroupId
+	expires := voucherConfig.ExpiresAt(time.Now())
+	uses := 1
 	payload := dtos.GenerateVoucherPayload{
 		Length:   values.VoucherLength,
 		Charset:  values.VoucherCharset,
@@ -51,53 +166,111 @@ func (c *CsrvClient) GetCSRVCode(ctx context.Context) (string, error) {
 		Actions: []entities.VoucherAction{
 			{
 				WalletTx: map[monies.CurrencyCode]monies.Money{
-					monies.PLN: monies.MustNew(int64(c.ValuePLN), monies.PLN),
+					value.Currency().Code: value,
 				},
 			},
 		},
 	}
 
-	bodyPayload := new(bytes.Buffer)
-	err := json.NewEncoder(bodyPayload).Encode(payload)
+	body, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("GetCSRVCode json.NewEncoder failed: %w", err)
+		return nil, fmt.Errorf("GenerateVoucher json.Marshal failed: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/admin/voucher/generate", c.CraftserveUrl), bodyPayload)
+	return body, nil
+}
+
+func (c *CsrvClient) generateVouchers(ctx context.Context, payload []byte, quantity int) ([]entities.Voucher, error) {
+	log := logger.GetLoggerFromContext(ctx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api/admin/voucher/generate", c.ApiUrl), bytes.NewReader(payload))
 	if err != nil {
-		return "", err
+		return nil, &permanentError{err}
 	}
 
 	req.AddCookie(&http.Cookie{Name: "user_access_token", Value: c.Secret})
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.HttpClient.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer func() {
 		if cerr := resp.Body.Close(); cerr != nil {
-			log.WithError(cerr).Error("GetCSRVCode failed to close response body")
+			log.WithError(cerr).Error("GenerateVoucher failed to close response body")
 		}
 	}()
 
 	if resp.StatusCode != http.StatusCreated {
-		return "", fmt.Errorf("GetCSRVCode failed with status: %d", resp.StatusCode)
+		err = fmt.Errorf("GenerateVoucher failed with status: %d", resp.StatusCode)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, &permanentError{err}
+		}
+		return nil, err
 	}
 
-	var voucher [1]entities.Voucher
+	// The vouchers were created, retrying would create them again
+	var vouchers []entities.Voucher
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("GetCSRVCode io.ReadAll failed: %w", err)
+		return nil, &permanentError{fmt.Errorf("GenerateVoucher %w, io.ReadAll failed: %w", ErrUnexpectedResponse, err)}
 	}
 
-	err = json.Unmarshal(bodyBytes, &voucher)
+	err = json.Unmarshal(bodyBytes, &vouchers)
+	if err != nil {
+		return nil, &permanentError{fmt.Errorf("GenerateVoucher %w, json.Unmarshal failed: %w with body: %s", ErrUnexpectedResponse, err, string(bodyBytes))}
+	}
+
+	if len(vouchers) != quantity {
+		return nil, &permanentError{fmt.Errorf("GenerateVoucher %w, expected %d vouchers, got %d", ErrUnexpectedResponse, quantity, len(vouchers))}
+	}
+	for _, voucher := range vouchers {
+		if voucher.Id == "" {
+			return nil, &permanentError{fmt.Errorf("GenerateVoucher %w, voucher without id", ErrUnexpectedResponse)}
+		}
+	}
+
+	return vouchers, nil
+}
+
+func (c *CsrvClient) getVoucher(ctx context.Context, code string) (*entities.Voucher, error) {
+	log := logger.GetLoggerFromContext(ctx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/admin/voucher/%s", c.ApiUrl, url.PathEscape(code)), nil)
 	if err != nil {
-		return "", fmt.Errorf("GetCSRVCode json.Unmarshal failed: %w with body: %s", err, string(bodyBytes))
+		return nil, &permanentError{err}
 	}
 
-	if len(voucher) == 0 {
-		return "", fmt.Errorf("GetCSRVCode voucher not found in response")
+	req.AddCookie(&http.Cookie{Name: "user_access_token", Value: c.Secret})
+
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			log.WithError(cerr).Error("GetVoucher failed to close response body")
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("GetVoucher failed with status: %d", resp.StatusCode)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, &permanentError{err}
+		}
+		return nil, err
+	}
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("GetVoucher io.ReadAll failed: %w", err)
+	}
+
+	var voucher entities.Voucher
+	err = json.Unmarshal(bodyBytes, &voucher)
+	if err != nil {
+		return nil, fmt.Errorf("GetVoucher json.Unmarshal failed: %w with body: %s", err, string(bodyBytes))
+	}
+	if voucher.Id == "" {
+		return nil, fmt.Errorf("GetVoucher voucher without id in response")
 	}
 
-	return voucher[0].Id, nil
+	return &voucher, nil
 }
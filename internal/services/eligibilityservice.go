@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/discord"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// EligibilityService decides who can take part in the giveaways of a guild. Every way of entering a giveaway and
+// every draw asks it, so no giveaway type skips the requirements of the guild.
+type EligibilityService struct {
+	UserRepo entities.UserRepo
+}
+
+func NewEligibilityService(userRepo entities.UserRepo) *EligibilityService {
+	return &EligibilityService{
+		UserRepo: userRepo,
+	}
+}
+
+// CheckEligibility returns why the member cannot take part in a giveaway of the guild with the requirements, or nil
+// if they can.
+func (h *EligibilityService) CheckEligibility(ctx context.Context, s discord.Session, serverConfig entities.ServerConfig, member *discordgo.Member, requirements entities.EligibilityRequirements) (*entities.Ineligibility, error) {
+	isUserBlacklisted, err := h.UserRepo.IsUserBlacklisted(ctx, member.User.ID, serverConfig.GuildId)
+	if err != nil {
+		return nil, err
+	}
+	if isUserBlacklisted {
+		return &entities.Ineligibility{Reason: entities.IneligibleBlacklisted}, nil
+	}
+
+	now := time.Now()
+	if serverConfig.MinAccountAgeDays > 0 {
+		createdAt, err := discordgo.SnowflakeTimestamp(member.User.ID)
+		if err != nil {
+			return nil, err
+		}
+		if now.Sub(createdAt) < time.Duration(serverConfig.MinAccountAgeDays)*24*time.Hour {
+			return &entities.Ineligibility{Reason: entities.IneligibleAccountAge, Days: serverConfig.MinAccountAgeDays}, nil
+		}
+	}
+
+	if serverConfig.MinMemberDays > 0 && now.Sub(member.JoinedAt) < time.Duration(serverConfig.MinMemberDays)*24*time.Hour {
+		return &entities.Ineligibility{Reason: entities.IneligibleMemberAge, Days: serverConfig.MinMemberDays}, nil
+	}
+
+	if serverConfig.EligibilityRoleId != "" && !discord.HasRoleById(member, serverConfig.EligibilityRoleId) {
+		return &entities.Ineligibility{Reason: entities.IneligibleRole, RoleId: serverConfig.EligibilityRoleId}, nil
+	}
+	if requirements.RoleId != nil && !discord.HasRoleById(member, *requirements.RoleId) {
+		return &entities.Ineligibility{Reason: entities.IneligibleRole, RoleId: *requirements.RoleId}, nil
+	}
+	if requirements.Level != nil {
+		level, err := discord.GetMemberLevel(ctx, s, member, serverConfig.GuildId)
+		if err != nil {
+			return nil, err
+		}
+		if level < *requirements.Level {
+			return &entities.Ineligibility{Reason: entities.IneligibleLevel, Level: *requirements.Level}, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/internal/repos"
+	"csrvbot/pkg/discord"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestEligibilityService_CheckEligibility(t *testing.T) {
+	discord.LevelPrefix = "Poziom "
+	level := 10
+	roleId := "required"
+	tests := []struct {
+		name         string
+		roles        []string
+		blacklisted  bool
+		requirements entities.EligibilityRequirements
+		want         string // the reason of the ineligibility, empty if the member is eligible
+	}{
+		{"no requirements", nil, false, entities.EligibilityRequirements{}, ""},
+		{"blacklisted", nil, true, entities.EligibilityRequirements{}, entities.IneligibleBlacklisted},
+		{"missing role", nil, false, entities.EligibilityRequirements{RoleId: &roleId}, entities.IneligibleRole},
+		{"required role", []string{roleId}, false, entities.EligibilityRequirements{RoleId: &roleId}, ""},
+		{"no level", nil, false, entities.EligibilityRequirements{Level: &level}, entities.IneligibleLevel},
+		{"lower level", []string{"level-5"}, false, entities.EligibilityRequirements{Level: &level}, entities.IneligibleLevel},
+		{"required level", []string{"level-10"}, false, entities.EligibilityRequirements{Level: &level}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			session := discord.NewFakeSession()
+			session.AddGuild(&discordgo.Guild{ID: testGuildId, Name: "Guild"})
+			session.AddRole(testGuildId, &discordgo.Role{ID: roleId, Name: "Required"})
+			session.AddRole(testGuildId, &discordgo.Role{ID: "level-5", Name: "Poziom 5"})
+			session.AddRole(testGuildId, &discordgo.Role{ID: "level-10", Name: "Poziom 10"})
+			member := &discordgo.Member{GuildID: testGuildId, User: &discordgo.User{ID: "member", Username: "member"}, Roles: tt.roles}
+			userRepo := repos.NewMemoryUserRepo()
+			if tt.blacklisted {
+				err := userRepo.AddBlacklistForUser(ctx, "member", testGuildId, "admin", "spam", nil)
+				if err != nil {
+					t.Fatalf("AddBlacklistForUser: %v", err)
+				}
+			}
+			service := NewEligibilityService(userRepo)
+
+			ineligibility, err := service.CheckEligibility(ctx, session, entities.ServerConfig{GuildId: testGuildId}, member, tt.requirements)
+			if err != nil {
+				t.Fatalf("CheckEligibility: %v", err)
+			}
+			var got string
+			if ineligibility != nil {
+				got = ineligibility.Reason
+			}
+			if got != tt.want {
+				t.Errorf("CheckEligibility() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/logger"
+	"csrvbot/pkg/schedule"
+	"sync"
+	"time"
+)
+
+// ScheduledGiveawayTypes are the giveaway types finished on the per-guild daily schedules.
+var ScheduledGiveawayTypes = []string{
+	entities.ThxGiveawayType,
+	entities.MessageGiveawayType,
+	entities.JoinedGiveawayType,
+	entities.LevelGiveawayType,
+}
+
+type GiveawayScheduler struct {
+	GiveawayService *GiveawayService
+	ServerRepo      entities.ServerRepo
+	mu              sync.Mutex
+	timers          map[string]map[string]*time.Timer
+}
+
+func NewGiveawayScheduler(giveawayService *GiveawayService, serverRepo entities.ServerRepo) *GiveawayScheduler {
+	return &GiveawayScheduler{
+		GiveawayService: giveawayService,
+		ServerRepo:      serverRepo,
+		timers:          make(map[string]map[string]*time.Timer),
+	}
+}
+
+// ScheduleGuild replaces the pending giveaway timers of the guild with ones following its current server config.
+func (h *GiveawayScheduler) ScheduleGuild(ctx context.Context, s discord.Session, guildId string) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId)
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, guildId)
+	if err != nil {
+		log.WithError(err).Error("ScheduleGuild#h.ServerRepo.GetServerConfigForGuild")
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, giveawayType := range ScheduledGiveawayTypes {
+		h.schedule(ctx, s, serverConfig, giveawayType)
+	}
+}
+
+// UnscheduleGuild stops all pending giveaway timers of the guild.
+func (h *GiveawayScheduler) UnscheduleGuild(guildId string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, timer := range h.timers[guildId] {
+		timer.Stop()
+	}
+	delete(h.timers, guildId)
+}
+
+// NextRun returns when the given giveaway type is finished next in the guild.
+func (h *GiveawayScheduler) NextRun(serverConfig entities.ServerConfig, giveawayType string) (time.Time, bool) {
+	daily, err := schedule.Parse(serverConfig.GiveawaySchedule(giveawayType))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return daily.Next(time.Now(), serverConfig.Location())
+}
+
+// schedule must be called with h.mu held.
+func (h *GiveawayScheduler) schedule(ctx context.Context, s discord.Session, serverConfig entities.ServerConfig, giveawayType string) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(serverConfig.GuildId)
+	guildId := serverConfig.GuildId
+	if h.timers[guildId] == nil {
+		h.timers[guildId] = make(map[string]*time.Timer)
+	}
+	if timer, ok := h.timers[guildId][giveawayType]; ok {
+		timer.Stop()
+		delete(h.timers[guildId], giveawayType)
+	}
+
+	daily, err := schedule.Parse(serverConfig.GiveawaySchedule(giveawayType))
+	if err != nil {
+		log.WithError(err).Errorf("Invalid %s giveaway schedule", giveawayType)
+		return
+	}
+	next, ok := daily.Next(time.Now(), serverConfig.Location())
+	if !ok {
+		log.Debugf("No %s giveaway schedule", giveawayType)
+		return
+	}
+
+	log.Debugf("Next %s giveaway at %s", giveawayType, next.Format(time.DateTime+" MST"))
+	var timer *time.Timer
+	timer = time.AfterFunc(time.Until(next), func() {
+		h.finish(ctx, s, guildId, giveawayType)
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		// The guild was rescheduled or unscheduled in the meantime
+		if h.timers[guildId][giveawayType] != timer {
+			return
+		}
+
+		serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, guildId)
+		if err != nil {
+			log.WithError(err).Error("GiveawayScheduler#h.ServerRepo.GetServerConfigForGuild")
+			delete(h.timers[guildId], giveawayType)
+			return
+		}
+		h.schedule(ctx, s, serverConfig, giveawayType)
+	})
+	h.timers[guildId][giveawayType] = timer
+}
+
+func (h *GiveawayScheduler) finish(ctx context.Context, s discord.Session, guildId, giveawayType string) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId)
+	log.Infof("Finishing scheduled %s giveaway", giveawayType)
+
+	switch giveawayType {
+	case entities.ThxGiveawayType:
+		h.GiveawayService.FinishGiveaway(ctx, s, guildId)
+	case entities.MessageGiveawayType:
+		serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, guildId)
+		if err != nil {
+			log.WithError(err).Error("GiveawayScheduler.finish#h.ServerRepo.GetServerConfigForGuild")
+			return
+		}
+		if serverConfig.MessageGiveawayWinners == 0 {
+			log.Debug("Message giveaways are disabled")
+			return
+		}
+		h.GiveawayService.FinishMessageGiveaway(ctx, s, guildId)
+	case entities.JoinedGiveawayType:
+		h.GiveawayService.FinishJoinableGiveaway(ctx, s, guildId, false)
+	case entities.LevelGiveawayType:
+		h.GiveawayService.FinishJoinableGiveaway(ctx, s, guildId, true)
+	}
+}
@@ -3,34 +3,59 @@ package services
 import (
 	"context"
 	"csrvbot/domain/entities"
+	"csrvbot/pkg/backoff"
 	"csrvbot/pkg/discord"
+	"csrvbot/pkg/fairdraw"
 	"csrvbot/pkg/logger"
 	"database/sql"
 	"errors"
-	"math/rand"
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
+const (
+	customGiveawayRetryDelay    = 5 * time.Minute
+	customGiveawayMaxRetryDelay = time.Hour
+	// customGiveawayAlertAttempts is the number of failed finishes after which the admins are alerted
+	customGiveawayAlertAttempts = 3
+)
+
 type GiveawayService struct {
-	CsrvClient    CsrvClient
-	CraftserveUrl string
-	ServerRepo    entities.ServerRepo
-	GiveawaysRepo entities.GiveawaysRepo
+	VoucherService     *VoucherService
+	ThxAbuseService    *ThxAbuseService
+	AuditService       *AuditService
+	EligibilityService *EligibilityService
+	CraftserveUrl      string
+	ServerRepo         entities.ServerRepo
+	GiveawaysRepo      entities.GiveawaysRepo
+	drawLocks          *drawLocks // shared by the copies of the service
+}
+
+// drawLocks holds a lock by giveaway id, held while the draw of the giveaway is completed.
+type drawLocks struct {
+	mu    sync.Mutex
+	locks map[int]*sync.Mutex
 }
 
-func NewGiveawayService(csrvClient *CsrvClient, craftserveUrl string, serverRepo entities.ServerRepo, giveawaysRepo entities.GiveawaysRepo) *GiveawayService {
+func NewGiveawayService(voucherService *VoucherService, thxAbuseService *ThxAbuseService, auditService *AuditService, eligibilityService *EligibilityService, craftserveUrl string, serverRepo entities.ServerRepo, giveawaysRepo entities.GiveawaysRepo) *GiveawayService {
 	return &GiveawayService{
-		CsrvClient:    *csrvClient,
-		CraftserveUrl: craftserveUrl,
-		ServerRepo:    serverRepo,
-		GiveawaysRepo: giveawaysRepo,
+		VoucherService:     voucherService,
+		ThxAbuseService:    thxAbuseService,
+		AuditService:       auditService,
+		EligibilityService: eligibilityService,
+		CraftserveUrl:      craftserveUrl,
+		ServerRepo:         serverRepo,
+		GiveawaysRepo:      giveawaysRepo,
+		drawLocks:          &drawLocks{locks: make(map[int]*sync.Mutex)},
 	}
 }
 
-func (h *GiveawayService) FinishGiveaway(ctx context.Context, s *discordgo.Session, guildId string) {
+func (h *GiveawayService) FinishGiveaway(ctx context.Context, s discord.Session, guildId string) {
 	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId)
 	log.Debug("Finishing giveaway for guild")
 
@@ -52,11 +77,16 @@ func (h *GiveawayService) FinishGiveaway(ctx context.Context, s *discordgo.Sessi
 		return
 	}
 
-	giveawayChannelId, err := h.ServerRepo.GetMainChannelForGuild(ctx, guildId)
+	if h.resumeDraw(ctx, s, giveaway) {
+		return
+	}
+
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, guildId)
 	if err != nil {
-		log.WithError(err).Error("FinishGiveaway#h.ServerRepo.GetMainChannelForGuild")
+		log.WithError(err).Error("FinishGiveaway#h.ServerRepo.GetServerConfigForGuild")
 		return
 	}
+	giveawayChannelId := serverConfig.MainChannel
 
 	accepted := true
 	participants, err := h.GiveawaysRepo.GetParticipantsForGiveaway(ctx, giveaway.Id, &accepted)
@@ -64,80 +94,51 @@ func (h *GiveawayService) FinishGiveaway(ctx context.Context, s *discordgo.Sessi
 		log.WithError(err).Error("FinishGiveaway#h.GiveawaysRepo.GetParticipantsForGiveaway")
 	}
 
-	if participants == nil || len(participants) == 0 {
-		message, err := s.ChannelMessageSend(giveawayChannelId, "Dzisiaj nikt nie wygrywa, ponieważ nikt nie był w loterii.")
-		if err != nil {
-			log.WithError(err).Error("FinishGiveaway#s.ChannelMessageSend")
-		}
-		err = h.GiveawaysRepo.FinishGiveaway(ctx, giveaway, &message.ID)
-		if err != nil {
-			log.WithError(err).Error("FinishGiveaway#h.GiveawaysRepo.FinishGiveaway")
-		}
-		log.Infof("Giveaway ended without any participants.")
-
-		// Create new giveaway
-		log.Info("Creating missing giveaways")
-		h.CreateMissingThxGiveaways(ctx, s, guild)
-
+	entries := serverConfig.ThxGiveawayEntries(participants)
+	if len(entries) == 0 {
+		h.finishGiveawayWithoutWinners(ctx, s, guild, giveaway, giveawayChannelId)
 		return
 	}
 
-	code, err := h.CsrvClient.GetCSRVCode(ctx)
+	winners, skipped, err := h.drawWinners(ctx, s, giveaway, entries, 1)
 	if err != nil {
-		log.WithError(err).Error("FinishGiveaway#h.CsrvClient.GetCSRVCode")
-		_, err = s.ChannelMessageSend(giveawayChannelId, "Błąd API Craftserve, nie udało się pobrać kodu!")
-		if err != nil {
-			log.WithError(err).Error("FinishGiveaway#s.ChannelMessageSend")
-			return
-		}
+		log.WithError(err).Error("FinishGiveaway#h.drawWinners")
+		h.alertAbortedDraw(ctx, s, giveaway)
 		return
 	}
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	winner := participants[r.Intn(len(participants))]
-
-	member, err := s.GuildMember(guildId, winner.UserId)
-	if err != nil {
-		log.WithError(err).Error("FinishGiveaway#s.GuildMember")
+	if len(winners) == 0 {
+		h.finishGiveawayWithoutWinners(ctx, s, guild, giveaway, giveawayChannelId)
 		return
 	}
-	dmEmbed := discord.ConstructWinnerEmbed(h.CraftserveUrl, code)
-	dm, err := s.UserChannelCreate(winner.UserId)
+
+	draw, err := h.GiveawaysRepo.StartDraw(ctx, giveaway, entries, skipped, winners)
 	if err != nil {
-		log.WithError(err).Error("FinishGiveaway#s.UserChannelCreate")
-	}
-	_, err = s.ChannelMessageSendEmbed(dm.ID, dmEmbed)
-	if err != nil && !discord.EqualError(err, discordgo.ErrCodeCannotSendMessagesToThisUser) {
-		log.WithError(err).Error("FinishGiveaway#s.ChannelMessageSendEmbed")
+		log.WithError(err).Error("FinishGiveaway#h.GiveawaysRepo.StartDraw")
+		return
 	}
 
-	mainEmbed := discord.ConstructChannelWinnerEmbed(h.CraftserveUrl, member.User.Username)
-	message, err := s.ChannelMessageSendComplex(giveawayChannelId, &discordgo.MessageSend{
-		Embed:      mainEmbed,
-		Components: discord.ConstructThxWinnerComponents(false),
-	})
+	h.completeDraw(ctx, s, giveaway, draw)
+}
 
+func (h *GiveawayService) finishGiveawayWithoutWinners(ctx context.Context, s discord.Session, guild *discordgo.Guild, giveaway *entities.Giveaway, giveawayChannelId string) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(guild.ID)
+	message, err := s.ChannelMessageSend(giveawayChannelId, "Dzisiaj nikt nie wygrywa, ponieważ nikt nie był w loterii.")
 	if err != nil {
-		log.WithError(err).Error("FinishGiveaway#s.ChannelMessageSendComplex")
+		log.WithError(err).Error("finishGiveawayWithoutWinners#s.ChannelMessageSend")
+		return
 	}
-
-	log.Debug("Updating giveaway with winner, message and code")
 	err = h.GiveawaysRepo.FinishGiveaway(ctx, giveaway, &message.ID)
 	if err != nil {
-		log.WithError(err).Error("FinishGiveaway#h.GiveawaysRepo.FinishGiveaway")
+		log.WithError(err).Error("finishGiveawayWithoutWinners#h.GiveawaysRepo.FinishGiveaway")
 	}
+	log.Infof("Giveaway ended without any participants.")
 
-	err = h.GiveawaysRepo.InsertWinner(ctx, giveaway.Id, winner.UserId, code)
-	if err != nil {
-		log.WithError(err).Error("FinishGiveaway#h.GiveawaysRepo.InsertWinner")
-	}
-
-	log.Infof("Giveaway ended with a winner: %s", member.User.Username)
-
+	// Create new giveaway
 	log.Info("Creating missing giveaways")
 	h.CreateMissingThxGiveaways(ctx, s, guild)
 }
 
-func (h *GiveawayService) FinishGiveaways(ctx context.Context, s *discordgo.Session) {
+func (h *GiveawayService) FinishGiveaways(ctx context.Context, s discord.Session) {
 	log := logger.GetLoggerFromContext(ctx)
 	giveaways, err := h.GiveawaysRepo.GetUnfinishedGiveaways(ctx, entities.ThxGiveawayType)
 	if err != nil {
@@ -153,7 +154,7 @@ func (h *GiveawayService) FinishGiveaways(ctx context.Context, s *discordgo.Sess
 	}
 }
 
-func (h *GiveawayService) CreateMissingThxGiveaways(ctx context.Context, s *discordgo.Session, guild *discordgo.Guild) {
+func (h *GiveawayService) CreateMissingThxGiveaways(ctx context.Context, s discord.Session, guild *discordgo.Guild) {
 	log := logger.GetLoggerFromContext(ctx).WithGuild(guild.ID)
 	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, guild.ID)
 	if err != nil {
@@ -174,16 +175,11 @@ func (h *GiveawayService) CreateMissingThxGiveaways(ctx context.Context, s *disc
 
 	if errors.Is(err, sql.ErrNoRows) {
 		log.Debug("Giveaway for guild does not exist, creating...")
-		err = h.GiveawaysRepo.InsertGiveaway(ctx, guild.ID, nil, entities.ThxGiveawayType, nil)
-		if err != nil {
-			log.WithError(err).Error("CreateMissingThxGiveaways#h.GiveawaysRepo.InsertGiveaway")
-			return
-		}
+		h.createNextGiveaway(ctx, s, guild.ID, entities.ThxGiveawayType, nil)
 	}
-
 }
 
-func (h *GiveawayService) FinishMessageGiveaways(ctx context.Context, session *discordgo.Session) {
+func (h *GiveawayService) FinishMessageGiveaways(ctx context.Context, session discord.Session) {
 	log := logger.GetLoggerFromContext(ctx)
 	guildIds, err := h.ServerRepo.GetGuildsWithMessageGiveawaysEnabled(ctx)
 	if err != nil {
@@ -201,7 +197,7 @@ func (h *GiveawayService) FinishMessageGiveaways(ctx context.Context, session *d
 	}
 }
 
-func (h *GiveawayService) FinishMessageGiveaway(ctx context.Context, session *discordgo.Session, guildId string) {
+func (h *GiveawayService) FinishMessageGiveaway(ctx context.Context, session discord.Session, guildId string) {
 	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId)
 	log.Debug("Finishing message giveaway for guild")
 	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, guildId)
@@ -220,118 +216,56 @@ func (h *GiveawayService) FinishMessageGiveaway(ctx context.Context, session *di
 		return
 	}
 
-	participants, err := h.GiveawaysRepo.GetUsersWithMessagesFromLastDays(ctx, 30, guildId)
-	if err != nil {
-		log.WithError(err).Error("FinishMessageGiveaway#messageGiveawaysRepo.GetUsersWithMessagesFromLastDays")
-		return
-	}
-
-	if len(participants) == 0 {
-		_, err := session.ChannelMessageSend(giveawayChannelId, "Dzisiaj nikt nie wygrywa, ponieważ nikt nie był aktywny.")
-		if err != nil {
-			log.WithError(err).Error("FinishMessageGiveaway#session.ChannelMessageSend")
-		}
-		log.Infof("Message giveaway ended without any participants.")
-		return
-	}
-
 	giveaway, err := h.GiveawaysRepo.GetGiveawayForGuild(ctx, guildId, entities.MessageGiveawayType)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		log.WithError(err).Error("FinishMessageGiveaway#GiveawaysRepo.GetMessageGiveaway")
 		return
 	}
 
+	// The seed hash of a new giveaway has to be published before it is drawn, so it is drawn on the next run
 	if errors.Is(err, sql.ErrNoRows) {
 		log.Debug("Inserting message giveaway into database")
-		err = h.GiveawaysRepo.InsertGiveaway(ctx, guildId, nil, entities.MessageGiveawayType, nil)
-		if err != nil {
-			log.WithError(err).Error("FinishMessageGiveaway#GiveawaysRepo.InsertMessageGiveaway")
-			return
-		}
-
-		giveaway, err = h.GiveawaysRepo.GetGiveawayForGuild(ctx, guildId, entities.MessageGiveawayType)
-		if err != nil {
-			log.WithError(err).Error("FinishMessageGiveaway#GiveawaysRepo.GetMessageGiveaway")
-			return
-		}
+		h.createNextGiveaway(ctx, session, guildId, entities.MessageGiveawayType, nil)
+		return
 	}
 
-	winnerIds := make([]string, serverConfig.MessageGiveawayWinners)
-	winnerNames := make([]string, serverConfig.MessageGiveawayWinners)
-
-	for i := 0; i < serverConfig.MessageGiveawayWinners; i++ {
-		r := rand.New(rand.NewSource(time.Now().UnixNano()))
-		winnerId := participants[r.Intn(len(participants))]
-		member, err := session.GuildMember(guildId, winnerId)
-		if err != nil {
-			var memberIndex int
-			for j, p := range participants {
-				if p == winnerId {
-					memberIndex = j
-					break
-				}
-			}
-			log.WithError(err).Error("FinishMessageGiveaway#session.GuildMember")
-			participants = append(participants[:memberIndex], participants[memberIndex+1:]...)
-			i--
-			continue
-		}
-		winnerIds[i] = winnerId
-		winnerNames[i] = member.User.Username
-		code, err := h.CsrvClient.GetCSRVCode(ctx)
-		if err != nil {
-			log.WithError(err).Error("FinishMessageGiveaway#csrvClient.GetCSRVCode")
-			message, err := session.ChannelMessageSend(giveawayChannelId, "Błąd API Craftserve, nie udało się pobrać kodu!")
-			if err != nil {
-				log.WithError(err).Error("FinishMessageGiveaway#s.ChannelMessageSend")
-			}
-
-			log.Debug("Updating message giveaway in database with message id")
-			err = h.GiveawaysRepo.FinishGiveaway(ctx, giveaway, &message.ID)
-			if err != nil {
-				log.WithError(err).Error("FinishMessageGiveaway#GiveawaysRepo.FinishMessageGiveaway")
-			}
+	if h.resumeDraw(ctx, session, giveaway) {
+		return
+	}
 
-			return
-		}
+	participants, err := h.GiveawaysRepo.GetUsersWithMessagesFromLastDays(ctx, 30, guildId)
+	if err != nil {
+		log.WithError(err).Error("FinishMessageGiveaway#messageGiveawaysRepo.GetUsersWithMessagesFromLastDays")
+		return
+	}
 
-		log.Debug("Inserting message giveaway winner into database")
-		err = h.GiveawaysRepo.InsertWinner(ctx, giveaway.Id, winnerId, code)
-		if err != nil {
-			log.WithError(err).Error("FinishMessageGiveaway#GiveawaysRepo.InsertMessageGiveawayWinner")
-			continue
-		}
+	sort.Strings(participants)
+	winners, skipped, err := h.drawWinners(ctx, session, giveaway, participants, serverConfig.MessageGiveawayWinners)
+	if err != nil {
+		log.WithError(err).Error("FinishMessageGiveaway#h.drawWinners")
+		h.alertAbortedDraw(ctx, session, giveaway)
+		return
+	}
 
-		dmEmbed := discord.ConstructWinnerEmbed(h.CraftserveUrl, code)
-		dm, err := session.UserChannelCreate(winnerId)
+	if len(winners) == 0 {
+		_, err := session.ChannelMessageSend(giveawayChannelId, "Dzisiaj nikt nie wygrywa, ponieważ nikt nie był aktywny.")
 		if err != nil {
-			log.WithError(err).Error("FinishMessageGiveaway#session.UserChannelCreate")
-			continue
-		}
-		_, err = session.ChannelMessageSendEmbed(dm.ID, dmEmbed)
-		if err != nil && !discord.EqualError(err, discordgo.ErrCodeCannotSendMessagesToThisUser) {
-			log.WithError(err).Error("FinishMessageGiveaway#session.ChannelMessageSendEmbed")
+			log.WithError(err).Error("FinishMessageGiveaway#session.ChannelMessageSend")
 		}
+		log.Infof("Message giveaway ended without any participants.")
+		return
 	}
 
-	mainEmbed := discord.ConstructChannelMessageWinnerEmbed(h.CraftserveUrl, winnerNames)
-	message, err := session.ChannelMessageSendComplex(giveawayChannelId, &discordgo.MessageSend{
-		Embed:      mainEmbed,
-		Components: discord.ConstructMessageWinnerComponents(false),
-	})
+	draw, err := h.GiveawaysRepo.StartDraw(ctx, giveaway, participants, skipped, winners)
 	if err != nil {
-		log.WithError(err).Error("FinishMessageGiveaway#session.ChannelMessageSendComplex")
+		log.WithError(err).Error("FinishMessageGiveaway#GiveawaysRepo.StartDraw")
+		return
 	}
 
-	log.Debug("Updating message giveaway in database with message id")
-	err = h.GiveawaysRepo.FinishGiveaway(ctx, giveaway, &message.ID)
-	if err != nil {
-		log.WithError(err).Error("FinishMessageGiveaway#GiveawaysRepo.FinishMessageGiveaway")
-	}
-	log.Infof("Message giveaway ended with a winners: %s", strings.Join(winnerNames, ", "))
+	h.completeDraw(ctx, session, giveaway, draw)
 }
 
-func (h *GiveawayService) FinishJoinableGiveaway(ctx context.Context, session *discordgo.Session, guildId string, withLevel bool) {
+func (h *GiveawayService) FinishJoinableGiveaway(ctx context.Context, session discord.Session, guildId string, withLevel bool) {
 	log := logger.GetLoggerFromContext(ctx).WithField("withLevel", withLevel)
 	log.Debug("Finishing joinable giveaway for guild")
 
@@ -387,6 +321,10 @@ func (h *GiveawayService) FinishJoinableGiveaway(ctx context.Context, session *d
 		return
 	}
 
+	if h.resumeDraw(ctx, session, giveaway) {
+		return
+	}
+
 	// Get participants
 	participants, err := h.GiveawaysRepo.GetParticipantsForGiveaway(ctx, giveaway.Id, nil)
 	if err != nil {
@@ -410,6 +348,7 @@ func (h *GiveawayService) FinishJoinableGiveaway(ctx context.Context, session *d
 		} else {
 			embed = discord.ConstructJoinableGiveawayEmbed(h.CraftserveUrl, len(participants), nil)
 		}
+		discord.SetSeedHashFooter(embed, giveaway.Id, giveaway.SeedHash)
 
 		components := discord.ConstructJoinComponents(true)
 		_, err := session.ChannelMessageEditComplex(&discordgo.MessageEdit{
@@ -440,135 +379,31 @@ func (h *GiveawayService) FinishJoinableGiveaway(ctx context.Context, session *d
 		return
 	}
 
-	var winnerIds []string
-	retries := 0
-	maxRetries := len(participants) * 2
-
-	for i := 0; i < winnersCount; i++ {
-		r := rand.New(rand.NewSource(time.Now().UnixNano()))
-		winnerIndex := r.Intn(len(participants))
-		winner := participants[winnerIndex]
-		_, err := session.GuildMember(guildId, winner.UserId)
-		if err != nil {
-			log.WithError(err).Error("FinishJoinableGiveaway#session.GuildMember")
-			participants = append(participants[:winnerIndex], participants[winnerIndex+1:]...)
-			retries++
-			if retries >= maxRetries {
-				log.Error("Too many retries, breaking loop")
-				break
-			}
-			i--
-			continue
-		}
-
-		winnerIds = append(winnerIds, winner.UserId)
-		participants = append(participants[:winnerIndex], participants[winnerIndex+1:]...)
-
-		code, err := h.CsrvClient.GetCSRVCode(ctx)
-		if err != nil {
-			log.WithError(err).Error("FinishJoinableGiveaway#h.CsrvClient.GetCSRVCode")
-			_, err = session.ChannelMessageSend(channelId, "Błąd API Craftserve, nie udało się pobrać kodu!")
-			if err != nil {
-				log.WithError(err).Error("FinishJoinableGiveaway#session.ChannelMessageSend")
-				return
-			}
-
-			return
-		}
-
-		log.Debug("Inserting joinable giveaway winner into database")
-		err = h.GiveawaysRepo.InsertWinner(ctx, giveaway.Id, winner.UserId, code)
-		if err != nil {
-			log.WithError(err).Error("FinishJoinableGiveaway#h.GiveawaysRepo.InsertWinner")
-			continue
-		}
-
-		log.Debug("Sending DM to joinable giveaway winner")
-
-		dmEmbed := discord.ConstructWinnerEmbed(h.CraftserveUrl, code)
-		dm, err := session.UserChannelCreate(winner.UserId)
-		if err != nil {
-			log.WithError(err).Error("FinishJoinableGiveaway#session.UserChannelCreate")
-			continue
-		}
-
-		_, err = session.ChannelMessageSendEmbed(dm.ID, dmEmbed)
-		if err != nil && !discord.EqualError(err, discordgo.ErrCodeCannotSendMessagesToThisUser) {
-			log.WithError(err).Error("FinishJoinableGiveaway#session.ChannelMessageSendEmbed")
-		}
-	}
-
-	// Disable join button
-	var embed *discordgo.MessageEmbed
-
-	if withLevel {
-		levelRole, err := discord.GetRoleForLevel(ctx, session, guildId, *giveaway.Level)
-		if err != nil {
-			log.WithError(err).Error("FinishJoinableGiveaway#discord.GetRoleForLevel")
-			return
-		}
-
-		embed = discord.ConstructJoinableGiveawayEmbed(h.CraftserveUrl, len(participants), &levelRole.ID)
-	} else {
-		embed = discord.ConstructJoinableGiveawayEmbed(h.CraftserveUrl, len(participants), nil)
-	}
-
-	components := discord.ConstructJoinComponents(true)
-	_, err = session.ChannelMessageEditComplex(&discordgo.MessageEdit{
-		Channel:    channelId,
-		ID:         *giveaway.InfoMessageId,
-		Embed:      embed,
-		Components: &components,
+	sort.Slice(participants, func(i, j int) bool {
+		return participants[i].Id < participants[j].Id
 	})
-	if err != nil {
-		log.WithError(err).Error("FinishJoinableGiveaway#session.ChannelMessageEditComplex")
-	}
-
-	// Send winners message
-	var winnersEmbed *discordgo.MessageEmbed
-	if withLevel {
-		levelRole, err := discord.GetRoleForLevel(ctx, session, guildId, *giveaway.Level)
-		if err != nil {
-			log.WithError(err).Error("FinishJoinableGiveaway#discord.GetRoleForLevel")
-			return
-		}
-
-		winnersEmbed = discord.ConstructJoinableWinnersEmbed(h.CraftserveUrl, winnerIds, &levelRole.ID)
-	} else {
-		winnersEmbed = discord.ConstructJoinableWinnersEmbed(h.CraftserveUrl, winnerIds, nil)
+	entries := make([]string, len(participants))
+	for i, participant := range participants {
+		entries[i] = participant.UserId
 	}
 
-	var message *discordgo.Message
-	if len(winnerIds) == 0 {
-		message, err = session.ChannelMessageSend(channelId, "Nie udało się wylosować wszystkich zwycięzców.")
-		if err != nil {
-			log.WithError(err).Error("FinishJoinableGiveaway#session.ChannelMessageSend")
-		}
-
-	} else {
-		message, err = session.ChannelMessageSendComplex(channelId, &discordgo.MessageSend{
-			Embed:      winnersEmbed,
-			Components: discord.ConstructJoinableGiveawayWinnerComponents(false),
-		})
-		if err != nil {
-			log.WithError(err).Error("FinishJoinableGiveaway#session.ChannelMessageSendComplex")
-		}
+	winners, skipped, err := h.drawWinners(ctx, session, giveaway, entries, winnersCount)
+	if err != nil {
+		log.WithError(err).Error("FinishJoinableGiveaway#h.drawWinners")
+		h.alertAbortedDraw(ctx, session, giveaway)
+		return
 	}
 
-	log.Debug("Updating joinable giveaway in database with winners")
-	err = h.GiveawaysRepo.FinishGiveaway(ctx, giveaway, &message.ID)
+	draw, err := h.GiveawaysRepo.StartDraw(ctx, giveaway, entries, skipped, winners)
 	if err != nil {
-		log.WithError(err).Error("FinishJoinableGiveaway#h.GiveawaysRepo.FinishGiveaway")
+		log.WithError(err).Error("FinishJoinableGiveaway#h.GiveawaysRepo.StartDraw")
+		return
 	}
 
-	log.Infof("Joinable giveaway ended with winners: %s", strings.Join(winnerIds, ", "))
-
-	// Create new joinable giveaway
-	log.Info("Creating missing joinable giveaways")
-	h.CreateJoinableGiveaway(ctx, session, guild, withLevel)
+	h.completeDraw(ctx, session, giveaway, draw)
 }
 
-func (h *GiveawayService) FinishJoinableGiveaways(ctx context.Context, session *discordgo.Session, withLevel bool) {
+func (h *GiveawayService) FinishJoinableGiveaways(ctx context.Context, session discord.Session, withLevel bool) {
 	log := logger.GetLoggerFromContext(ctx).WithField("withLevel", withLevel)
 	var giveaways []entities.Giveaway
 	var err error
@@ -592,7 +427,7 @@ func (h *GiveawayService) FinishJoinableGiveaways(ctx context.Context, session *
 	}
 }
 
-func (h *GiveawayService) CreateJoinableGiveaway(ctx context.Context, session *discordgo.Session, guild *discordgo.Guild, withLevel bool) {
+func (h *GiveawayService) CreateJoinableGiveaway(ctx context.Context, session discord.Session, guild *discordgo.Guild, withLevel bool) {
 	log := logger.GetLoggerFromContext(ctx)
 	log.Info("Creating joinable giveaway for guild")
 
@@ -683,5 +518,701 @@ func (h *GiveawayService) CreateJoinableGiveaway(ctx context.Context, session *d
 			log.WithError(err).Error("CreateJoinableGiveaway#h.GiveawaysRepo.InsertGiveaway")
 			return
 		}
+
+		// Publish the seed hash, the seed is generated with the giveaway
+		giveaway, err := h.GiveawaysRepo.GetGiveawayForGuild(ctx, guild.ID, giveawayType)
+		if err != nil {
+			log.WithError(err).Error("CreateJoinableGiveaway#h.GiveawaysRepo.GetGiveawayForGuild")
+			return
+		}
+		discord.SetSeedHashFooter(embed, giveaway.Id, giveaway.SeedHash)
+		_, err = session.ChannelMessageEditEmbed(channelId, message.ID, embed)
+		if err != nil {
+			log.WithError(err).Error("CreateJoinableGiveaway#session.ChannelMessageEditEmbed")
+		}
+	}
+}
+
+// ScheduleCustomGiveaways schedules every unfinished custom giveaway, the overdue ones are finished right away.
+func (h *GiveawayService) ScheduleCustomGiveaways(ctx context.Context, s discord.Session) {
+	log := logger.GetLoggerFromContext(ctx)
+	customGiveaways, err := h.GiveawaysRepo.GetUnfinishedCustomGiveaways(ctx)
+	if err != nil {
+		log.WithError(err).Error("ScheduleCustomGiveaways#h.GiveawaysRepo.GetUnfinishedCustomGiveaways")
+		return
+	}
+
+	for _, customGiveaway := range customGiveaways {
+		h.ScheduleCustomGiveaway(ctx, s, customGiveaway.GiveawayId, customGiveaway.ScheduledEndTime)
+	}
+}
+
+// ScheduleCustomGiveaway finishes the custom giveaway at endTime. A finish that failed is retried with a doubling delay
+// until it succeeds, the admins are alerted once it failed customGiveawayAlertAttempts times.
+func (h *GiveawayService) ScheduleCustomGiveaway(ctx context.Context, s discord.Session, giveawayId int, endTime time.Time) {
+	h.scheduleCustomGiveaway(ctx, s, giveawayId, endTime, 0)
+}
+
+func (h *GiveawayService) scheduleCustomGiveaway(ctx context.Context, s discord.Session, giveawayId int, endTime time.Time, failedAttempts int) {
+	log := logger.GetLoggerFromContext(ctx).WithField("giveawayId", giveawayId)
+	log.Debugf("Scheduling custom giveaway to end at %s", endTime.Format(time.DateTime))
+
+	time.AfterFunc(time.Until(endTime), func() {
+		if ctx.Err() != nil {
+			return
+		}
+		h.FinishCustomGiveaway(ctx, s, giveawayId)
+
+		ended, err := h.GiveawaysRepo.IsGiveawayEnded(ctx, giveawayId)
+		if err != nil {
+			log.WithError(err).Error("ScheduleCustomGiveaway#h.GiveawaysRepo.IsGiveawayEnded")
+		}
+		if err == nil && ended {
+			return
+		}
+
+		failedAttempts++
+		if failedAttempts == customGiveawayAlertAttempts {
+			h.alertCustomGiveawayFailure(ctx, s, giveawayId, failedAttempts)
+		}
+		delay := backoff.Exponential(failedAttempts, customGiveawayRetryDelay, customGiveawayMaxRetryDelay)
+		log.Warnf("Finishing custom giveaway failed %d times, retrying in %s", failedAttempts, delay)
+		h.scheduleCustomGiveaway(ctx, s, giveawayId, time.Now().Add(delay), failedAttempts)
+	})
+}
+
+// alertCustomGiveawayFailure tells the admins in the admin log channel that the custom giveaway could not be finished.
+func (h *GiveawayService) alertCustomGiveawayFailure(ctx context.Context, s discord.Session, giveawayId, failedAttempts int) {
+	log := logger.GetLoggerFromContext(ctx).WithField("giveawayId", giveawayId)
+	log.Errorf("Custom giveaway could not be finished after %d attempts", failedAttempts)
+
+	giveaway, err := h.GiveawaysRepo.GetGiveawayById(ctx, giveawayId)
+	if err != nil {
+		log.WithError(err).Error("alertCustomGiveawayFailure#h.GiveawaysRepo.GetGiveawayById")
+		return
+	}
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, giveaway.GuildId)
+	if err != nil {
+		log.WithError(err).Error("alertCustomGiveawayFailure#h.ServerRepo.GetServerConfigForGuild")
+		return
+	}
+	if serverConfig.AdminLogChannel == "" {
+		return
+	}
+
+	_, err = s.ChannelMessageSend(serverConfig.AdminLogChannel, fmt.Sprintf("Nie udało się zakończyć giveawayu #%d mimo %d prób. Bot będzie próbował dalej, sprawdź logi.", giveawayId, failedAttempts))
+	if err != nil {
+		log.WithError(err).Error("alertCustomGiveawayFailure#s.ChannelMessageSend")
+	}
+}
+
+func (h *GiveawayService) FinishCustomGiveaway(ctx context.Context, s discord.Session, giveawayId int) {
+	log := logger.GetLoggerFromContext(ctx)
+	giveaway, err := h.GiveawaysRepo.GetGiveawayById(ctx, giveawayId)
+	if err != nil {
+		log.WithError(err).Errorf("FinishCustomGiveaway#h.GiveawaysRepo.GetGiveawayById %d", giveawayId)
+		return
+	}
+	if giveaway.EndTime != nil {
+		return
+	}
+	log = log.WithGuild(giveaway.GuildId)
+	log.Debugf("Finishing custom giveaway %d", giveawayId)
+
+	customGiveaway, err := h.GiveawaysRepo.GetCustomGiveaway(ctx, giveawayId)
+	if err != nil {
+		log.WithError(err).Error("FinishCustomGiveaway#h.GiveawaysRepo.GetCustomGiveaway")
+		return
+	}
+
+	if h.resumeDraw(ctx, s, giveaway) {
+		return
+	}
+
+	participants, err := h.GiveawaysRepo.GetParticipantsForGiveaway(ctx, giveaway.Id, nil)
+	if err != nil {
+		log.WithError(err).Error("FinishCustomGiveaway#h.GiveawaysRepo.GetParticipantsForGiveaway")
+		return
+	}
+
+	sort.Slice(participants, func(i, j int) bool {
+		return participants[i].Id < participants[j].Id
+	})
+	entries := make([]string, len(participants))
+	for i, participant := range participants {
+		entries[i] = participant.UserId
+	}
+
+	winners, skipped, err := h.drawWinners(ctx, s, giveaway, entries, customGiveaway.WinnersCount)
+	if err != nil {
+		log.WithError(err).Error("FinishCustomGiveaway#h.drawWinners")
+		return
+	}
+
+	draw, err := h.GiveawaysRepo.StartDraw(ctx, giveaway, entries, skipped, winners)
+	if err != nil {
+		log.WithError(err).Error("FinishCustomGiveaway#h.GiveawaysRepo.StartDraw")
+		return
+	}
+
+	h.completeDraw(ctx, s, giveaway, draw)
+}
+
+// drawWinners picks winners from the ordered entries with the giveaway seed, so the draw can be verified once
+// the seed is revealed. Users that are no longer members of the guild or are not eligible anymore are skipped. Any
+// other error aborts the draw, so it is retried instead of skipping a user who may have won.
+func (h *GiveawayService) drawWinners(ctx context.Context, s discord.Session, giveaway *entities.Giveaway, entries []string, count int) ([]entities.GiveawayWinner, []string, error) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(giveaway.GuildId)
+	if giveaway.Seed == "" {
+		return nil, nil, fmt.Errorf("giveaway %d has no seed", giveaway.Id)
+	}
+
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, giveaway.GuildId)
+	if err != nil {
+		return nil, nil, err
+	}
+	requirements := entities.EligibilityRequirements{Level: giveaway.Level}
+	if giveaway.Type == entities.CustomGiveawayType {
+		customGiveaway, err := h.GiveawaysRepo.GetCustomGiveaway(ctx, giveaway.Id)
+		if err != nil {
+			return nil, nil, err
+		}
+		requirements.RoleId = customGiveaway.RequiredRoleId
+	}
+
+	members := make(map[string]*discordgo.Member)
+	var drawErr error
+	eligible := func(userId string) bool {
+		if drawErr != nil {
+			return false
+		}
+		member, err := s.GuildMember(giveaway.GuildId, userId)
+		if err != nil {
+			if discord.EqualError(err, discordgo.ErrCodeUnknownMember) {
+				log.WithUser(userId).Debug("Skipping participant, no longer a member")
+				return false
+			}
+			drawErr = fmt.Errorf("GuildMember of %s: %w", userId, err)
+			return false
+		}
+		ineligibility, err := h.EligibilityService.CheckEligibility(ctx, s, serverConfig, member, requirements)
+		if err != nil {
+			drawErr = fmt.Errorf("CheckEligibility of %s: %w", userId, err)
+			return false
+		}
+		if ineligibility != nil {
+			log.WithUser(userId).Debugf("Skipping participant, not eligible: %s", ineligibility.Reason)
+			return false
+		}
+		members[userId] = member
+		return true
+	}
+
+	winnerIds, skipped := fairdraw.Pick(giveaway.Seed, entries, count, entities.DrawsWithReplacement(giveaway.Type), eligible)
+	if drawErr != nil {
+		return nil, nil, drawErr
+	}
+	winners := make([]entities.GiveawayWinner, len(winnerIds))
+	for i, winnerId := range winnerIds {
+		winners[i] = entities.GiveawayWinner{UserId: winnerId, UserName: members[winnerId].User.Username}
+	}
+
+	return winners, skipped, nil
+}
+
+// alertAbortedDraw tells the admins in the admin log channel that the draw of the giveaway was aborted. The giveaway
+// stays open, so it is drawn again at its next scheduled finish.
+func (h *GiveawayService) alertAbortedDraw(ctx context.Context, s discord.Session, giveaway *entities.Giveaway) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(giveaway.GuildId).WithField("giveawayId", giveaway.Id)
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, giveaway.GuildId)
+	if err != nil {
+		log.WithError(err).Error("alertAbortedDraw#h.ServerRepo.GetServerConfigForGuild")
+		return
+	}
+	if serverConfig.AdminLogChannel == "" {
+		return
+	}
+
+	_, err = s.ChannelMessageSend(serverConfig.AdminLogChannel, fmt.Sprintf("⚠️ Losowanie giveawayu #%d zostało przerwane, bo nie udało się sprawdzić uczestników. Giveaway pozostaje otwarty i zostanie rozlosowany przy następnym zakończeniu, sprawdź logi.", giveaway.Id))
+	if err != nil {
+		log.WithError(err).Error("alertAbortedDraw#s.ChannelMessageSend")
+	}
+}
+
+// RecoverDraws handles draws interrupted by a crash or restart. Draws that already issued or reserved a code or notified
+// a winner are completed, the others are rolled back so the giveaway is drawn again on its next run.
+func (h *GiveawayService) RecoverDraws(ctx context.Context, s discord.Session) {
+	log := logger.GetLoggerFromContext(ctx)
+	draws, err := h.GiveawaysRepo.GetInProgressDraws(ctx)
+	if err != nil {
+		log.WithError(err).Error("RecoverDraws#h.GiveawaysRepo.GetInProgressDraws")
+		return
+	}
+
+	for _, draw := range draws {
+		draw := draw
+		log := log.WithGuild(draw.GuildId).WithField("drawId", draw.Id)
+
+		giveaway, err := h.GiveawaysRepo.GetGiveawayById(ctx, draw.GiveawayId)
+		if err != nil {
+			log.WithError(err).Error("RecoverDraws#h.GiveawaysRepo.GetGiveawayById")
+			continue
+		}
+
+		winners, err := h.GiveawaysRepo.GetDrawWinners(ctx, draw.Id)
+		if err != nil {
+			log.WithError(err).Error("RecoverDraws#h.GiveawaysRepo.GetDrawWinners")
+			continue
+		}
+
+		issued := false
+		for _, winner := range winners {
+			if winner.Code != "" || winner.Notified {
+				issued = true
+				break
+			}
+			// A voucher reserved before the crash is handed out to the same winner
+			reserved, err := h.VoucherService.HasReservedVoucher(ctx, winner.Id)
+			if err != nil {
+				log.WithError(err).Error("RecoverDraws#h.VoucherService.HasReservedVoucher")
+			}
+			if reserved || err != nil {
+				issued = true
+				break
+			}
+		}
+
+		if !issued {
+			log.Info("Rolling back interrupted draw without issued codes")
+			err = h.GiveawaysRepo.RollbackDraw(ctx, &draw)
+			if err != nil {
+				log.WithError(err).Error("RecoverDraws#h.GiveawaysRepo.RollbackDraw")
+			}
+			continue
+		}
+
+		log.Info("Resuming interrupted draw")
+		h.completeDraw(ctx, s, giveaway, &draw)
+	}
+}
+
+// resumeDraw completes a draw that was already started for the giveaway. It returns false if there is none.
+func (h *GiveawayService) resumeDraw(ctx context.Context, s discord.Session, giveaway *entities.Giveaway) bool {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(giveaway.GuildId)
+	draw, err := h.GiveawaysRepo.GetDrawForGiveaway(ctx, giveaway.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		log.WithError(err).Error("resumeDraw#h.GiveawaysRepo.GetDrawForGiveaway")
+		return true
+	}
+
+	log.WithField("drawId", draw.Id).Info("Resuming giveaway draw")
+	h.completeDraw(ctx, s, giveaway, draw)
+	return true
+}
+
+// lockDraw locks the draw of the giveaway, so it is not completed by the startup recovery and a scheduled run at once.
+func (h *GiveawayService) lockDraw(giveawayId int) func() {
+	h.drawLocks.mu.Lock()
+	lock, ok := h.drawLocks.locks[giveawayId]
+	if !ok {
+		lock = &sync.Mutex{}
+		h.drawLocks.locks[giveawayId] = lock
+	}
+	h.drawLocks.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// completeDraw issues missing codes, notifies the winners, announces them and finishes the giveaway.
+// Every step is persisted, so calling it again for the same draw never issues a second code to a winner.
+func (h *GiveawayService) completeDraw(ctx context.Context, s discord.Session, giveaway *entities.Giveaway, draw *entities.GiveawayDraw) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(giveaway.GuildId).WithField("drawId", draw.Id)
+
+	unlock := h.lockDraw(giveaway.Id)
+	defer unlock()
+
+	// The draw may have been completed while waiting for the lock
+	current, err := h.GiveawaysRepo.GetDrawForGiveaway(ctx, giveaway.Id)
+	if err != nil {
+		log.WithError(err).Error("completeDraw#h.GiveawaysRepo.GetDrawForGiveaway")
+		return
+	}
+	if current.Id != draw.Id || current.State != entities.DrawInProgressState {
+		log.Debug("Draw was already completed")
+		return
+	}
+	*draw = *current
+
+	channelId, err := h.getGiveawayChannel(ctx, giveaway)
+	if err != nil {
+		log.WithError(err).Error("completeDraw#h.getGiveawayChannel")
+		return
+	}
+
+	winners, err := h.GiveawaysRepo.GetDrawWinners(ctx, draw.Id)
+	if err != nil {
+		log.WithError(err).Error("completeDraw#h.GiveawaysRepo.GetDrawWinners")
+		return
+	}
+
+	var customGiveaway *entities.CustomGiveaway
+	if giveaway.Type == entities.CustomGiveawayType {
+		customGiveaway, err = h.GiveawaysRepo.GetCustomGiveaway(ctx, giveaway.Id)
+		if err != nil {
+			log.WithError(err).Error("completeDraw#h.GiveawaysRepo.GetCustomGiveaway")
+			return
+		}
 	}
+
+	// Without a voucher the prize is handed out by the admins
+	voucherConfig, hasVoucher := h.VoucherService.GetVoucherConfigForGiveaway(ctx, giveaway, customGiveaway)
+	var pendingWinnerIds []string
+	for i := range winners {
+		if winners[i].Code != "" || !hasVoucher {
+			continue
+		}
+
+		voucher, err := h.VoucherService.ReserveVoucher(ctx, winners[i].Id, voucherConfig)
+		if err != nil {
+			log.WithError(err).Error("completeDraw#h.VoucherService.ReserveVoucher")
+			h.alertUnexpectedVoucherResponse(ctx, s, giveaway.GuildId, winners[i].UserId, err)
+			// The giveaway ends anyway, the code is sent to the winner once the API works again
+			err = h.addPendingVoucher(ctx, &winners[i], voucherConfig, err)
+			if err != nil {
+				log.WithError(err).Error("completeDraw#h.addPendingVoucher")
+				_, err = s.ChannelMessageSend(channelId, "Błąd API Craftserve, nie udało się pobrać kodu!")
+				if err != nil {
+					log.WithError(err).Error("completeDraw#s.ChannelMessageSend")
+				}
+				return
+			}
+			pendingWinnerIds = append(pendingWinnerIds, winners[i].UserId)
+			continue
+		}
+
+		err = h.GiveawaysRepo.SetWinnerCode(ctx, winners[i].Id, voucher.Code, voucherConfig, voucher.ExpiresAt)
+		if err != nil {
+			log.WithError(err).Error("completeDraw#h.GiveawaysRepo.SetWinnerCode")
+			return
+		}
+		h.VoucherService.ReleaseVoucher(ctx, winners[i].Id)
+		winners[i].Code = voucher.Code
+	}
+
+	for i := range winners {
+		// Winners with a pending voucher are notified once the code is issued
+		if winners[i].Notified || (hasVoucher && winners[i].Code == "") {
+			continue
+		}
+
+		err = h.notifyWinner(ctx, s, &winners[i], customGiveaway, "")
+		if err != nil {
+			log.WithError(err).Error("completeDraw#h.notifyWinner")
+		}
+	}
+
+	message, err := h.announceDraw(ctx, s, giveaway, draw, channelId, winners)
+	if err != nil {
+		log.WithError(err).Error("completeDraw#h.announceDraw")
+		return
+	}
+
+	err = h.GiveawaysRepo.CompleteDraw(ctx, draw, giveaway, &message.ID)
+	if err != nil {
+		log.WithError(err).Error("completeDraw#h.GiveawaysRepo.CompleteDraw")
+		return
+	}
+
+	winnerNames := make([]string, len(winners))
+	for i, winner := range winners {
+		winnerNames[i] = winner.UserName
+	}
+	log.Infof("Giveaway ended with winners: %s", strings.Join(winnerNames, ", "))
+
+	if len(pendingWinnerIds) > 0 {
+		mentions := make([]string, len(pendingWinnerIds))
+		for i, userId := range pendingWinnerIds {
+			mentions[i] = "<@" + userId + ">"
+		}
+		_, err = s.ChannelMessageSend(channelId, fmt.Sprintf("Nie udało się teraz wygenerować kodu dla: %s. Wyślemy go w wiadomości prywatnej, gdy tylko API Craftserve znów zacznie działać.", strings.Join(mentions, ", ")))
+		if err != nil {
+			log.WithError(err).Error("completeDraw#s.ChannelMessageSend")
+		}
+	}
+
+	switch giveaway.Type {
+	case entities.ThxGiveawayType, entities.MessageGiveawayType:
+		h.createNextGiveaway(ctx, s, giveaway.GuildId, giveaway.Type, message)
+		return
+	case entities.CustomGiveawayType:
+		return
+	}
+
+	guild, err := s.Guild(giveaway.GuildId)
+	if err != nil {
+		log.WithError(err).Error("completeDraw#s.Guild")
+		return
+	}
+
+	log.Info("Creating missing giveaways")
+	switch giveaway.Type {
+	case entities.JoinedGiveawayType:
+		h.CreateJoinableGiveaway(ctx, s, guild, false)
+	case entities.LevelGiveawayType:
+		h.CreateJoinableGiveaway(ctx, s, guild, true)
+	}
+}
+
+// createNextGiveaway starts the next thx or message giveaway right away and publishes its seed hash under the results
+// of the previous one, or in a new message in the main channel if there are no results to put it under.
+func (h *GiveawayService) createNextGiveaway(ctx context.Context, s discord.Session, guildId, giveawayType string, message *discordgo.Message) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId)
+	err := h.GiveawaysRepo.InsertGiveaway(ctx, guildId, nil, giveawayType, nil)
+	if err != nil {
+		log.WithError(err).Error("createNextGiveaway#h.GiveawaysRepo.InsertGiveaway")
+		return
+	}
+
+	giveaway, err := h.GiveawaysRepo.GetGiveawayForGuild(ctx, guildId, giveawayType)
+	if err != nil {
+		log.WithError(err).Error("createNextGiveaway#h.GiveawaysRepo.GetGiveawayForGuild")
+		return
+	}
+
+	if message != nil && len(message.Embeds) > 0 {
+		embed := message.Embeds[0]
+		discord.SetSeedHashFooter(embed, giveaway.Id, giveaway.SeedHash)
+		_, err = s.ChannelMessageEditEmbed(message.ChannelID, message.ID, embed)
+		if err != nil {
+			log.WithError(err).Error("createNextGiveaway#s.ChannelMessageEditEmbed")
+		}
+		return
+	}
+
+	channelId, err := h.getGiveawayChannel(ctx, giveaway)
+	if err != nil {
+		log.WithError(err).Error("createNextGiveaway#h.getGiveawayChannel")
+		return
+	}
+	embed := discord.ConstructThxGiveawayStartEmbed(h.CraftserveUrl)
+	if giveawayType == entities.MessageGiveawayType {
+		embed = discord.ConstructMessageGiveawayStartEmbed(h.CraftserveUrl)
+	}
+	discord.SetSeedHashFooter(embed, giveaway.Id, giveaway.SeedHash)
+	_, err = s.ChannelMessageSendEmbed(channelId, embed)
+	if err != nil {
+		log.WithError(err).Error("createNextGiveaway#s.ChannelMessageSendEmbed")
+	}
+}
+
+func (h *GiveawayService) getGiveawayChannel(ctx context.Context, giveaway *entities.Giveaway) (string, error) {
+	if giveaway.Type == entities.CustomGiveawayType {
+		customGiveaway, err := h.GiveawaysRepo.GetCustomGiveaway(ctx, giveaway.Id)
+		if err != nil {
+			return "", err
+		}
+		return customGiveaway.ChannelId, nil
+	}
+
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, giveaway.GuildId)
+	if err != nil {
+		return "", err
+	}
+
+	switch giveaway.Type {
+	case entities.JoinedGiveawayType:
+		return serverConfig.UnconditionalGiveawayChannel, nil
+	case entities.LevelGiveawayType:
+		return serverConfig.ConditionalGiveawayChannel, nil
+	default:
+		return serverConfig.MainChannel, nil
+	}
+}
+
+// notifyWinner sends the code to the winner in a DM, with an optional message above it, and marks the winner as notified.
+func (h *GiveawayService) notifyWinner(ctx context.Context, s discord.Session, winner *entities.GiveawayWinner, customGiveaway *entities.CustomGiveaway, content string) error {
+	dm, err := s.UserChannelCreate(winner.UserId)
+	if err != nil {
+		return err
+	}
+
+	embed := discord.ConstructWinnerEmbed(h.CraftserveUrl, winner.Code)
+	if customGiveaway != nil {
+		embed = discord.ConstructCustomGiveawayWinnerEmbed(h.CraftserveUrl, customGiveaway, winner.Code)
+	}
+
+	_, err = s.ChannelMessageSendComplex(dm.ID, &discordgo.MessageSend{Content: content, Embed: embed})
+	if err != nil && !discord.EqualError(err, discordgo.ErrCodeCannotSendMessagesToThisUser) {
+		return err
+	}
+
+	return h.GiveawaysRepo.SetWinnerNotified(ctx, winner.Id)
+}
+
+func (h *GiveawayService) announceDraw(ctx context.Context, s discord.Session, giveaway *entities.Giveaway, draw *entities.GiveawayDraw, channelId string, winners []entities.GiveawayWinner) (*discordgo.Message, error) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(giveaway.GuildId)
+
+	switch giveaway.Type {
+	case entities.ThxGiveawayType:
+		embed := discord.ConstructChannelWinnerEmbed(h.CraftserveUrl, winners[0].UserName)
+		discord.AddDrawVerificationFields(embed, giveaway.Id, giveaway.Seed, draw.Participants, draw.Skipped)
+
+		return h.sendAnnouncement(ctx, s, draw, channelId, &discordgo.MessageSend{
+			Embed:      embed,
+			Components: discord.ConstructThxWinnerComponents(false),
+		})
+	case entities.MessageGiveawayType:
+		winnerNames := make([]string, len(winners))
+		for i, winner := range winners {
+			winnerNames[i] = winner.UserName
+		}
+
+		embed := discord.ConstructChannelMessageWinnerEmbed(h.CraftserveUrl, winnerNames)
+		discord.AddDrawVerificationFields(embed, giveaway.Id, giveaway.Seed, draw.Participants, draw.Skipped)
+
+		return h.sendAnnouncement(ctx, s, draw, channelId, &discordgo.MessageSend{
+			Embed:      embed,
+			Components: discord.ConstructMessageWinnerComponents(false),
+		})
+	case entities.JoinedGiveawayType, entities.LevelGiveawayType:
+		var levelRoleId *string
+		if giveaway.Type == entities.LevelGiveawayType {
+			levelRole, err := discord.GetRoleForLevel(ctx, s, giveaway.GuildId, *giveaway.Level)
+			if err != nil {
+				return nil, err
+			}
+			levelRoleId = &levelRole.ID
+		}
+
+		// Disable join button
+		participantsCount, err := h.GiveawaysRepo.CountParticipantsForGiveaway(ctx, giveaway.Id)
+		if err != nil {
+			log.WithError(err).Error("announceDraw#h.GiveawaysRepo.CountParticipantsForGiveaway")
+		}
+		infoEmbed := discord.ConstructJoinableGiveawayEmbed(h.CraftserveUrl, participantsCount, levelRoleId)
+		discord.SetSeedHashFooter(infoEmbed, giveaway.Id, giveaway.SeedHash)
+		components := discord.ConstructJoinComponents(true)
+		_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			Channel:    channelId,
+			ID:         *giveaway.InfoMessageId,
+			Embed:      infoEmbed,
+			Components: &components,
+		})
+		if err != nil {
+			log.WithError(err).Error("announceDraw#s.ChannelMessageEditComplex")
+		}
+
+		if len(winners) == 0 {
+			return h.sendAnnouncement(ctx, s, draw, channelId, &discordgo.MessageSend{Content: "Nie udało się wylosować wszystkich zwycięzców."})
+		}
+
+		winnerIds := make([]string, len(winners))
+		for i, winner := range winners {
+			winnerIds[i] = winner.UserId
+		}
+
+		embed := discord.ConstructJoinableWinnersEmbed(h.CraftserveUrl, winnerIds, levelRoleId)
+		discord.AddDrawVerificationFields(embed, giveaway.Id, giveaway.Seed, draw.Participants, draw.Skipped)
+
+		return h.sendAnnouncement(ctx, s, draw, channelId, &discordgo.MessageSend{
+			Embed:      embed,
+			Components: discord.ConstructJoinableGiveawayWinnerComponents(false),
+		})
+	case entities.CustomGiveawayType:
+		customGiveaway, err := h.GiveawaysRepo.GetCustomGiveaway(ctx, giveaway.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		var levelRoleId *string
+		if giveaway.Level != nil {
+			levelRole, err := discord.GetRoleForLevel(ctx, s, giveaway.GuildId, *giveaway.Level)
+			if err != nil {
+				return nil, err
+			}
+			levelRoleId = &levelRole.ID
+		}
+
+		// Disable join button
+		participantsCount, err := h.GiveawaysRepo.CountParticipantsForGiveaway(ctx, giveaway.Id)
+		if err != nil {
+			log.WithError(err).Error("announceDraw#h.GiveawaysRepo.CountParticipantsForGiveaway")
+		}
+		infoEmbed := discord.ConstructCustomGiveawayEmbed(h.CraftserveUrl, customGiveaway, participantsCount, levelRoleId, true)
+		discord.SetSeedHashFooter(infoEmbed, giveaway.Id, giveaway.SeedHash)
+		components := discord.ConstructJoinComponents(true)
+		_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			Channel:    channelId,
+			ID:         *giveaway.InfoMessageId,
+			Embed:      infoEmbed,
+			Components: &components,
+		})
+		if err != nil {
+			log.WithError(err).Error("announceDraw#s.ChannelMessageEditComplex")
+		}
+
+		if len(winners) == 0 {
+			return h.sendAnnouncement(ctx, s, draw, channelId, &discordgo.MessageSend{Content: fmt.Sprintf("Nikt nie wziął udziału w giveawayu **%s**.", customGiveaway.Title)})
+		}
+
+		winnerIds := make([]string, len(winners))
+		for i, winner := range winners {
+			winnerIds[i] = winner.UserId
+		}
+
+		embed := discord.ConstructCustomGiveawayWinnersEmbed(h.CraftserveUrl, customGiveaway, winnerIds)
+		discord.AddDrawVerificationFields(embed, giveaway.Id, giveaway.Seed, draw.Participants, draw.Skipped)
+
+		message := &discordgo.MessageSend{Embed: embed}
+		if customGiveaway.VoucherValue != nil {
+			message.Components = discord.ConstructJoinableGiveawayWinnerComponents(false)
+		}
+		return h.sendAnnouncement(ctx, s, draw, channelId, message)
+	}
+
+	return nil, fmt.Errorf("unknown giveaway type %s", giveaway.Type)
+}
+
+// sendAnnouncement posts the announcement of the draw and stores its id on the draw. A resumed draw edits the announcement
+// it already posted instead, unless it was deleted in the meantime.
+func (h *GiveawayService) sendAnnouncement(ctx context.Context, s discord.Session, draw *entities.GiveawayDraw, channelId string, message *discordgo.MessageSend) (*discordgo.Message, error) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(draw.GuildId).WithField("drawId", draw.Id)
+	if draw.AnnouncementMessageId != nil {
+		edit := discordgo.NewMessageEdit(channelId, *draw.AnnouncementMessageId)
+		if message.Content != "" {
+			edit.SetContent(message.Content)
+		}
+		if message.Embed != nil {
+			edit.SetEmbed(message.Embed)
+		}
+		if message.Components != nil {
+			edit.Components = &message.Components
+		}
+
+		edited, err := s.ChannelMessageEditComplex(edit)
+		if err == nil {
+			return edited, nil
+		}
+		if !discord.EqualError(err, discordgo.ErrCodeUnknownMessage) {
+			return nil, err
+		}
+		log.Warn("Announcement of the resumed draw was deleted, posting it again")
+	}
+
+	posted, err := s.ChannelMessageSendComplex(channelId, message)
+	if err != nil {
+		return nil, err
+	}
+
+	draw.AnnouncementMessageId = &posted.ID
+	err = h.GiveawaysRepo.SetDrawAnnouncement(ctx, draw.Id, posted.ID)
+	if err != nil {
+		log.WithError(err).Error("sendAnnouncement#h.GiveawaysRepo.SetDrawAnnouncement")
+	}
+
+	return posted, nil
 }
@@ -0,0 +1,531 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/internal/repos"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/logger"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	testGuildId   = "guild"
+	testChannelId = "giveaways"
+)
+
+func TestMain(m *testing.M) {
+	logger.Logger.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+type giveawayTestEnv struct {
+	ctx           context.Context
+	session       *discord.FakeSession
+	serverRepo    *repos.MemoryServerRepo
+	giveawaysRepo *repos.MemoryGiveawaysRepo
+	voucherRepo   *repos.MemoryVoucherRepo
+	userRepo      *repos.MemoryUserRepo
+	service       *GiveawayService
+}
+
+// newGiveawayTestEnv sets up a guild with a giveaway channel and the given members, the vouchers are generated
+// by the development client, so no request leaves the process.
+func newGiveawayTestEnv(t *testing.T, memberIds ...string) *giveawayTestEnv {
+	t.Helper()
+	env := &giveawayTestEnv{
+		ctx:           context.Background(),
+		session:       discord.NewFakeSession(),
+		serverRepo:    repos.NewMemoryServerRepo(),
+		giveawaysRepo: repos.NewMemoryGiveawaysRepo(),
+		voucherRepo:   repos.NewMemoryVoucherRepo(),
+		userRepo:      repos.NewMemoryUserRepo(),
+	}
+	env.session.AddGuild(&discordgo.Guild{ID: testGuildId, Name: "Guild"})
+	env.session.AddChannel(&discordgo.Channel{ID: testChannelId, GuildID: testGuildId, Type: discordgo.ChannelTypeGuildText})
+	for _, memberId := range memberIds {
+		env.session.AddMember(testGuildId, &discordgo.Member{User: &discordgo.User{ID: memberId, Username: memberId}})
+	}
+
+	err := env.serverRepo.InsertServerConfig(env.ctx, testGuildId, testChannelId, "admin")
+	if err != nil {
+		t.Fatalf("InsertServerConfig: %v", err)
+	}
+
+	csrvClient := NewCsrvClient("", "development", "")
+	voucherService := NewVoucherService(csrvClient, nil, env.voucherRepo, env.serverRepo, 10, 30)
+	env.service = NewGiveawayService(voucherService, NewThxAbuseService(env.giveawaysRepo), nil, NewEligibilityService(env.userRepo), "https://craftserve.pl", env.serverRepo, env.giveawaysRepo)
+	return env
+}
+
+func (env *giveawayTestEnv) updateServerConfig(t *testing.T, update func(serverConfig *entities.ServerConfig)) {
+	t.Helper()
+	serverConfig, err := env.serverRepo.GetServerConfigForGuild(env.ctx, testGuildId)
+	if err != nil {
+		t.Fatalf("GetServerConfigForGuild: %v", err)
+	}
+	update(&serverConfig)
+	err = env.serverRepo.UpdateServerConfig(env.ctx, &serverConfig)
+	if err != nil {
+		t.Fatalf("UpdateServerConfig: %v", err)
+	}
+}
+
+func (env *giveawayTestEnv) giveaway(t *testing.T, giveawayType string) *entities.Giveaway {
+	t.Helper()
+	giveaway, err := env.giveawaysRepo.GetGiveawayForGuild(env.ctx, testGuildId, giveawayType)
+	if err != nil {
+		t.Fatalf("GetGiveawayForGuild(%s): %v", giveawayType, err)
+	}
+	return giveaway
+}
+
+// winnerCodes returns the codes of the winners of the finished giveaway by user.
+func (env *giveawayTestEnv) winnerCodes(t *testing.T, giveawayId int, userIds ...string) map[string]string {
+	t.Helper()
+	giveaway, err := env.giveawaysRepo.GetGiveawayById(env.ctx, giveawayId)
+	if err != nil {
+		t.Fatalf("GetGiveawayById: %v", err)
+	}
+	if giveaway.EndTime == nil || giveaway.InfoMessageId == nil {
+		t.Fatalf("giveaway %d is not finished", giveawayId)
+	}
+	codes := make(map[string]string)
+	for _, userId := range userIds {
+		userCodes, err := env.giveawaysRepo.GetCodesForInfoMessage(env.ctx, *giveaway.InfoMessageId, userId)
+		if err != nil {
+			t.Fatalf("GetCodesForInfoMessage: %v", err)
+		}
+		for _, code := range userCodes {
+			codes[userId] = code
+		}
+	}
+	return codes
+}
+
+func (env *giveawayTestEnv) assertCodeSent(t *testing.T, userId, code string) {
+	t.Helper()
+	if !strings.HasPrefix(code, "DEV-") {
+		t.Fatalf("winner %s got code %q, want a generated one", userId, code)
+	}
+	messages := env.session.DirectMessages(userId)
+	if len(messages) != 1 {
+		t.Fatalf("winner %s got %d direct messages, want 1", userId, len(messages))
+	}
+	if len(messages[0].Embeds) == 0 || !strings.Contains(messages[0].Embeds[0].Description+embedFieldValues(messages[0].Embeds[0]), code) {
+		t.Errorf("direct message to %s does not contain the code %s", userId, code)
+	}
+}
+
+func assertSeedHash(t *testing.T, message *discordgo.Message, seedHash string) {
+	t.Helper()
+	if len(message.Embeds) == 0 || message.Embeds[0].Footer == nil || !strings.Contains(message.Embeds[0].Footer.Text, seedHash) {
+		t.Errorf("message %s does not publish the seed hash %s", message.ID, seedHash)
+	}
+}
+
+func embedFieldValues(embed *discordgo.MessageEmbed) string {
+	var values strings.Builder
+	for _, field := range embed.Fields {
+		values.WriteString(field.Value)
+	}
+	return values.String()
+}
+
+func (env *giveawayTestEnv) acceptThx(t *testing.T, giveawayId int, userId string) {
+	t.Helper()
+	thankerId := "thanker"
+	err := env.giveawaysRepo.InsertParticipant(env.ctx, giveawayId, 0, testGuildId, userId, userId, nil, nil, &thankerId)
+	if err != nil {
+		t.Fatalf("InsertParticipant: %v", err)
+	}
+	participants, err := env.giveawaysRepo.GetParticipantsForGiveaway(env.ctx, giveawayId, nil)
+	if err != nil {
+		t.Fatalf("GetParticipantsForGiveaway: %v", err)
+	}
+	participant := participants[len(participants)-1]
+	err = env.giveawaysRepo.UpdateParticipant(env.ctx, &participant, "admin", "admin", true)
+	if err != nil {
+		t.Fatalf("UpdateParticipant: %v", err)
+	}
+}
+
+func TestGiveawayService_FinishGiveaway(t *testing.T) {
+	env := newGiveawayTestEnv(t, "winner", "banned")
+	guild, _ := env.session.Guild(testGuildId)
+	env.service.CreateMissingThxGiveaways(env.ctx, env.session, guild)
+	giveaway := env.giveaway(t, entities.ThxGiveawayType)
+
+	env.acceptThx(t, giveaway.Id, "winner")
+	env.acceptThx(t, giveaway.Id, "banned")
+	err := env.userRepo.AddBlacklistForUser(env.ctx, "banned", testGuildId, "admin", "spam", nil)
+	if err != nil {
+		t.Fatalf("AddBlacklistForUser: %v", err)
+	}
+
+	env.service.FinishGiveaway(env.ctx, env.session, testGuildId)
+
+	codes := env.winnerCodes(t, giveaway.Id, "winner", "banned")
+	if len(codes) != 1 || codes["winner"] == "" {
+		t.Fatalf("winners = %v, want only winner with a code", codes)
+	}
+	env.assertCodeSent(t, "winner", codes["winner"])
+	if messages := env.session.DirectMessages("banned"); len(messages) != 0 {
+		t.Errorf("blacklisted participant got %d direct messages", len(messages))
+	}
+
+	next := env.giveaway(t, entities.ThxGiveawayType)
+	if next.Id == giveaway.Id {
+		t.Fatalf("the finished thx giveaway is still the current one")
+	}
+
+	messages := env.session.Messages(testChannelId)
+	if len(messages) != 2 {
+		t.Fatalf("giveaway channel has %d messages, want the start of the giveaway and the winner announcement", len(messages))
+	}
+	assertSeedHash(t, messages[0], giveaway.SeedHash)
+	assertSeedHash(t, messages[1], next.SeedHash)
+
+	// Nobody was thanked in the next giveaway
+	env.service.FinishGiveaway(env.ctx, env.session, testGuildId)
+	messages = env.session.Messages(testChannelId)
+	if len(messages) != 4 || !strings.Contains(messages[2].Content, "nikt nie wygrywa") {
+		t.Fatalf("giveaway channel has %d messages, want the no winners message and the start of the next giveaway", len(messages))
+	}
+	assertSeedHash(t, messages[3], env.giveaway(t, entities.ThxGiveawayType).SeedHash)
+	if env.giveaway(t, entities.ThxGiveawayType).Id == next.Id {
+		t.Errorf("no thx giveaway was created after the one without participants")
+	}
+}
+
+func TestGiveawayService_FinishGiveawayAbortedDraw(t *testing.T) {
+	env := newGiveawayTestEnv(t, "first", "second")
+	env.session.AddChannel(&discordgo.Channel{ID: "admin-log", GuildID: testGuildId, Type: discordgo.ChannelTypeGuildText})
+	env.updateServerConfig(t, func(serverConfig *entities.ServerConfig) {
+		serverConfig.AdminLogChannel = "admin-log"
+	})
+	guild, _ := env.session.Guild(testGuildId)
+	env.service.CreateMissingThxGiveaways(env.ctx, env.session, guild)
+	giveaway := env.giveaway(t, entities.ThxGiveawayType)
+	env.acceptThx(t, giveaway.Id, "first")
+	env.acceptThx(t, giveaway.Id, "second")
+
+	// Members cannot be looked up, so the draw cannot tell who is still eligible
+	env.service.FinishGiveaway(env.ctx, &failingMemberSession{env.session}, testGuildId)
+
+	if current := env.giveaway(t, entities.ThxGiveawayType); current.Id != giveaway.Id {
+		t.Fatalf("giveaway was finished by an aborted draw")
+	}
+	if draw, err := env.giveawaysRepo.GetDrawForGiveaway(env.ctx, giveaway.Id); err == nil {
+		t.Fatalf("aborted draw was started: %+v", draw)
+	}
+	alerts := env.session.Messages("admin-log")
+	if len(alerts) != 1 || !strings.Contains(alerts[0].Content, fmt.Sprintf("#%d", giveaway.Id)) {
+		t.Errorf("admin log has %d messages, want an alert about the aborted draw", len(alerts))
+	}
+
+	// The next finish draws the giveaway once the members can be looked up again
+	env.service.FinishGiveaway(env.ctx, env.session, testGuildId)
+	if codes := env.winnerCodes(t, giveaway.Id, "first", "second"); len(codes) != 1 {
+		t.Errorf("winners = %v, want one", codes)
+	}
+}
+
+func TestGiveawayService_RecoverDraws(t *testing.T) {
+	env := newGiveawayTestEnv(t, "winner")
+	guild, _ := env.session.Guild(testGuildId)
+	env.service.CreateMissingThxGiveaways(env.ctx, env.session, guild)
+	giveaway := env.giveaway(t, entities.ThxGiveawayType)
+	env.acceptThx(t, giveaway.Id, "winner")
+
+	// The bot stopped after issuing the code and announcing the winner, but before notifying them
+	draw, err := env.giveawaysRepo.StartDraw(env.ctx, giveaway, []string{"gone", "winner"}, []string{"gone"}, []entities.GiveawayWinner{{UserId: "winner", UserName: "winner"}})
+	if err != nil {
+		t.Fatalf("StartDraw: %v", err)
+	}
+	winners, err := env.giveawaysRepo.GetDrawWinners(env.ctx, draw.Id)
+	if err != nil {
+		t.Fatalf("GetDrawWinners: %v", err)
+	}
+	voucherConfig, _ := env.service.VoucherService.GetVoucherConfigForGiveaway(env.ctx, giveaway, nil)
+	err = env.giveawaysRepo.SetWinnerCode(env.ctx, winners[0].Id, "DEV-1", voucherConfig, voucherConfig.ExpiresAt(time.Now()))
+	if err != nil {
+		t.Fatalf("SetWinnerCode: %v", err)
+	}
+	announcement, err := env.session.ChannelMessageSend(testChannelId, "announcement")
+	if err != nil {
+		t.Fatalf("ChannelMessageSend: %v", err)
+	}
+	err = env.giveawaysRepo.SetDrawAnnouncement(env.ctx, draw.Id, announcement.ID)
+	if err != nil {
+		t.Fatalf("SetDrawAnnouncement: %v", err)
+	}
+
+	env.service.RecoverDraws(env.ctx, env.session)
+
+	codes := env.winnerCodes(t, giveaway.Id, "winner")
+	if codes["winner"] != "DEV-1" {
+		t.Fatalf("winner got code %q, want the issued DEV-1", codes["winner"])
+	}
+	env.assertCodeSent(t, "winner", "DEV-1")
+
+	messages := env.session.Messages(testChannelId)
+	if len(messages) != 2 || messages[1].ID != announcement.ID || len(messages[1].Embeds) == 0 {
+		t.Fatalf("giveaway channel has %d messages, want the start of the giveaway and the edited announcement", len(messages))
+	}
+	if !strings.Contains(embedFieldValues(messages[1].Embeds[0]), "<@gone>") {
+		t.Errorf("announcement does not publish the skipped users")
+	}
+	if draw, _ := env.giveawaysRepo.GetDrawForGiveaway(env.ctx, giveaway.Id); draw.State != entities.DrawCompletedState {
+		t.Errorf("draw state = %s, want completed", draw.State)
+	}
+}
+
+func TestGiveawayService_RecoverDrawsWithReservedVoucher(t *testing.T) {
+	env := newGiveawayTestEnv(t, "winner")
+	guild, _ := env.session.Guild(testGuildId)
+	env.service.CreateMissingThxGiveaways(env.ctx, env.session, guild)
+	giveaway := env.giveaway(t, entities.ThxGiveawayType)
+	env.acceptThx(t, giveaway.Id, "winner")
+
+	// The bot stopped after reserving the code, but before saving it on the winner
+	draw, err := env.giveawaysRepo.StartDraw(env.ctx, giveaway, []string{"winner"}, nil, []entities.GiveawayWinner{{UserId: "winner", UserName: "winner"}})
+	if err != nil {
+		t.Fatalf("StartDraw: %v", err)
+	}
+	winners, err := env.giveawaysRepo.GetDrawWinners(env.ctx, draw.Id)
+	if err != nil {
+		t.Fatalf("GetDrawWinners: %v", err)
+	}
+	voucherConfig, _ := env.service.VoucherService.GetVoucherConfigForGiveaway(env.ctx, giveaway, nil)
+	reserved, err := env.service.VoucherService.ReserveVoucher(env.ctx, winners[0].Id, voucherConfig)
+	if err != nil {
+		t.Fatalf("ReserveVoucher: %v", err)
+	}
+
+	env.service.RecoverDraws(env.ctx, env.session)
+
+	codes := env.winnerCodes(t, giveaway.Id, "winner")
+	if codes["winner"] != reserved.Code {
+		t.Fatalf("winner got code %q, want the reserved %q", codes["winner"], reserved.Code)
+	}
+	env.assertCodeSent(t, "winner", reserved.Code)
+	if ok, _ := env.service.VoucherService.HasReservedVoucher(env.ctx, winners[0].Id); ok {
+		t.Errorf("reservation is kept after the code was saved on the winner")
+	}
+	if draw, _ := env.giveawaysRepo.GetDrawForGiveaway(env.ctx, giveaway.Id); draw.State != entities.DrawCompletedState {
+		t.Errorf("draw state = %s, want completed", draw.State)
+	}
+}
+
+func TestGiveawayService_ConcurrentResumes(t *testing.T) {
+	env := newGiveawayTestEnv(t, "winner")
+	guild, _ := env.session.Guild(testGuildId)
+	env.service.CreateMissingThxGiveaways(env.ctx, env.session, guild)
+	giveaway := env.giveaway(t, entities.ThxGiveawayType)
+	env.acceptThx(t, giveaway.Id, "winner")
+	_, err := env.giveawaysRepo.StartDraw(env.ctx, giveaway, []string{"winner"}, nil, []entities.GiveawayWinner{{UserId: "winner", UserName: "winner"}})
+	if err != nil {
+		t.Fatalf("StartDraw: %v", err)
+	}
+
+	// The startup recovery and a scheduled run resume the same draw at once, the slow direct message lets both of them
+	// pass the state check if the draw is not locked
+	session := &slowDMSession{FakeSession: env.session}
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		giveaway := *giveaway
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			env.service.resumeDraw(env.ctx, session, &giveaway)
+		}()
+	}
+	wg.Wait()
+
+	codes := env.winnerCodes(t, giveaway.Id, "winner")
+	env.assertCodeSent(t, "winner", codes["winner"])
+	if messages := env.session.Messages(testChannelId); len(messages) != 2 {
+		t.Fatalf("giveaway channel has %d messages, want the start of the giveaway and one announcement", len(messages))
+	}
+	unfinished, err := env.giveawaysRepo.GetUnfinishedGiveaways(env.ctx, entities.ThxGiveawayType)
+	if err != nil {
+		t.Fatalf("GetUnfinishedGiveaways: %v", err)
+	}
+	if len(unfinished) != 1 || unfinished[0].Id == giveaway.Id {
+		t.Errorf("%d unfinished thx giveaways, want only the next one", len(unfinished))
+	}
+}
+
+func TestGiveawayService_FinishMessageGiveaway(t *testing.T) {
+	env := newGiveawayTestEnv(t, "active", "quiet")
+	env.updateServerConfig(t, func(serverConfig *entities.ServerConfig) {
+		serverConfig.MessageGiveawayWinners = 1
+	})
+	// gone wrote messages, but left the guild before the draw
+	for _, userId := range []string{"active", "active", "gone"} {
+		err := env.giveawaysRepo.UpdateUserDailyMessageCount(env.ctx, userId, testGuildId)
+		if err != nil {
+			t.Fatalf("UpdateUserDailyMessageCount: %v", err)
+		}
+	}
+
+	// The first run only creates the giveaway and publishes its seed hash
+	env.service.FinishMessageGiveaway(env.ctx, env.session, testGuildId)
+	giveaway := env.giveaway(t, entities.MessageGiveawayType)
+	messages := env.session.Messages(testChannelId)
+	if len(messages) != 1 {
+		t.Fatalf("giveaway channel has %d messages, want the start of the giveaway", len(messages))
+	}
+	assertSeedHash(t, messages[0], giveaway.SeedHash)
+	if giveaway.EndTime != nil {
+		t.Fatalf("giveaway was drawn before its seed hash was published")
+	}
+
+	env.service.FinishMessageGiveaway(env.ctx, env.session, testGuildId)
+
+	codes := env.winnerCodes(t, giveaway.Id, "active", "quiet", "gone")
+	if len(codes) != 1 || codes["active"] == "" {
+		t.Fatalf("winners = %v, want only active with a code", codes)
+	}
+	env.assertCodeSent(t, "active", codes["active"])
+	if messages := env.session.DirectMessages("quiet"); len(messages) != 0 {
+		t.Errorf("user without messages got %d direct messages", len(messages))
+	}
+
+	next := env.giveaway(t, entities.MessageGiveawayType)
+	messages = env.session.Messages(testChannelId)
+	if len(messages) != 2 || len(messages[1].Embeds) == 0 {
+		t.Fatalf("giveaway channel has %d messages, want the winner announcement", len(messages))
+	}
+	assertSeedHash(t, messages[1], next.SeedHash)
+}
+
+func TestGiveawayService_FinishJoinableGiveaway(t *testing.T) {
+	env := newGiveawayTestEnv(t, "first", "second", "third")
+	env.updateServerConfig(t, func(serverConfig *entities.ServerConfig) {
+		serverConfig.UnconditionalGiveawayWinners = 2
+	})
+
+	// The first run creates the giveaway with its join button
+	env.service.FinishJoinableGiveaway(env.ctx, env.session, testGuildId, false)
+	giveaway := env.giveaway(t, entities.JoinedGiveawayType)
+	if giveaway.InfoMessageId == nil {
+		t.Fatalf("joinable giveaway has no info message")
+	}
+
+	for _, userId := range []string{"first", "second", "third"} {
+		err := env.giveawaysRepo.InsertParticipant(env.ctx, giveaway.Id, 0, testGuildId, userId, userId, giveaway.InfoMessageId, nil, nil)
+		if err != nil {
+			t.Fatalf("InsertParticipant: %v", err)
+		}
+	}
+
+	env.service.FinishJoinableGiveaway(env.ctx, env.session, testGuildId, false)
+
+	finished, err := env.giveawaysRepo.GetGiveawayById(env.ctx, giveaway.Id)
+	if err != nil {
+		t.Fatalf("GetGiveawayById: %v", err)
+	}
+	if finished.EndTime == nil {
+		t.Fatalf("joinable giveaway is not finished")
+	}
+	winners := 0
+	for _, userId := range []string{"first", "second", "third"} {
+		codes, err := env.giveawaysRepo.GetCodesForInfoMessage(env.ctx, *finished.InfoMessageId, userId)
+		if err != nil {
+			t.Fatalf("GetCodesForInfoMessage: %v", err)
+		}
+		if len(codes) == 0 {
+			continue
+		}
+		winners++
+		env.assertCodeSent(t, userId, codes[0])
+	}
+	if winners != 2 {
+		t.Fatalf("%d winners, want 2", winners)
+	}
+
+	info := env.session.Messages(testChannelId)[0]
+	if info.ID != *giveaway.InfoMessageId {
+		t.Fatalf("first message is not the info message of the giveaway")
+	}
+	if !joinButtonDisabled(info) {
+		t.Errorf("join button of the finished giveaway is still enabled")
+	}
+
+	next := env.giveaway(t, entities.JoinedGiveawayType)
+	if next.Id == giveaway.Id || next.InfoMessageId == nil || *next.InfoMessageId == info.ID {
+		t.Errorf("no new joinable giveaway was created")
+	}
+}
+
+func TestGiveawayService_FinishJoinableGiveawayWithTooFewParticipants(t *testing.T) {
+	env := newGiveawayTestEnv(t, "only")
+	env.updateServerConfig(t, func(serverConfig *entities.ServerConfig) {
+		serverConfig.UnconditionalGiveawayWinners = 2
+	})
+	env.service.FinishJoinableGiveaway(env.ctx, env.session, testGuildId, false)
+	giveaway := env.giveaway(t, entities.JoinedGiveawayType)
+	err := env.giveawaysRepo.InsertParticipant(env.ctx, giveaway.Id, 0, testGuildId, "only", "only", giveaway.InfoMessageId, nil, nil)
+	if err != nil {
+		t.Fatalf("InsertParticipant: %v", err)
+	}
+
+	env.service.FinishJoinableGiveaway(env.ctx, env.session, testGuildId, false)
+
+	if messages := env.session.DirectMessages("only"); len(messages) != 0 {
+		t.Errorf("participant got %d direct messages", len(messages))
+	}
+	found := false
+	for _, message := range env.session.Messages(testChannelId) {
+		if strings.Contains(message.Content, "zbyt małej ilości uczestników") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no message about too few participants")
+	}
+}
+
+func joinButtonDisabled(message *discordgo.Message) bool {
+	for _, component := range message.Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, rowComponent := range row.Components {
+			if button, ok := rowComponent.(*discordgo.Button); ok && button.CustomID == "giveawayjoin" {
+				return button.Disabled
+			}
+		}
+	}
+	return false
+}
+
+type slowDMSession struct {
+	*discord.FakeSession
+}
+
+func (s *slowDMSession) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	time.Sleep(50 * time.Millisecond)
+	return s.FakeSession.UserChannelCreate(recipientID, options...)
+}
+
+// failingMemberSession fails every member lookup with an error other than an unknown member.
+type failingMemberSession struct {
+	*discord.FakeSession
+}
+
+func (s *failingMemberSession) GuildMember(guildID, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error) {
+	return nil, errors.New("HTTP 500 Internal Server Error")
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/logger"
+	"time"
+)
+
+// RunHelperReconciliation reconciles the helper roles of every guild with helper tiers every interval until ctx is
+// done.
+func (h *HelperService) RunHelperReconciliation(ctx context.Context, s discord.Session, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// The helpers of every guild are already checked on GuildCreate
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		h.ReconcileHelpers(ctx, s)
+	}
+}
+
+// ReconcileHelpers grants and revokes the helper roles in every guild with helper tiers, so the roles follow the
+// thanks rejected or deleted after they were given, and reports the changes on the admin log channel of the guild.
+func (h *HelperService) ReconcileHelpers(ctx context.Context, s discord.Session) {
+	log := logger.GetLoggerFromContext(ctx)
+	guilds, err := h.ServerRepo.GetGuildsWithHelperTiers(ctx)
+	if err != nil {
+		log.WithError(err).Error("ReconcileHelpers#h.ServerRepo.GetGuildsWithHelperTiers")
+		return
+	}
+
+	for _, guildId := range guilds {
+		if ctx.Err() != nil {
+			return
+		}
+
+		changes := h.CheckHelpers(ctx, s, guildId)
+		if len(changes) == 0 {
+			continue
+		}
+		log.WithGuild(guildId).Infof("Helper reconciliation changed the roles of %d members", len(changes))
+
+		serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, guildId)
+		if err != nil {
+			log.WithGuild(guildId).WithError(err).Error("ReconcileHelpers#h.ServerRepo.GetServerConfigForGuild")
+			continue
+		}
+		if serverConfig.AdminLogChannel == "" {
+			continue
+		}
+		_, err = s.ChannelMessageSendEmbed(serverConfig.AdminLogChannel, discord.ConstructHelperReconciliationEmbed(h.CraftserveUrl, changes))
+		if err != nil {
+			log.WithGuild(guildId).WithError(err).Error("ReconcileHelpers#s.ChannelMessageSendEmbed")
+		}
+	}
+}
@@ -5,90 +5,96 @@ import (
 	"csrvbot/domain/entities"
 	"csrvbot/pkg/discord"
 	"csrvbot/pkg/logger"
+	"fmt"
+	"time"
+
 	"github.com/bwmarrin/discordgo"
 )
 
 type HelperService struct {
-	ServerRepo entities.ServerRepo
+	CraftserveUrl string
+	ServerRepo    entities.ServerRepo
 	//GiveawayRepo  entities.GiveawayRepo
 	UserRepo      entities.UserRepo
 	GiveawaysRepo entities.GiveawaysRepo
 }
 
-func NewHelperService(serverRepo entities.ServerRepo, userRepo entities.UserRepo, giveawaysRepo entities.GiveawaysRepo) *HelperService {
+func NewHelperService(craftserveUrl string, serverRepo entities.ServerRepo, userRepo entities.UserRepo, giveawaysRepo entities.GiveawaysRepo) *HelperService {
 	return &HelperService{
+		CraftserveUrl: craftserveUrl,
 		ServerRepo:    serverRepo,
 		UserRepo:      userRepo,
 		GiveawaysRepo: giveawaysRepo,
 	}
 }
 
-func (h *HelperService) CheckHelpers(ctx context.Context, session *discordgo.Session, guildId string) {
+// CheckHelpers reconciles the whole helper ladder of the guild, the members with enough thanks are promoted and
+// the members holding a tier role they no longer reach are demoted. It returns the changes made.
+func (h *HelperService) CheckHelpers(ctx context.Context, session discord.Session, guildId string) []entities.HelperTierChange {
 	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId)
 	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, guildId)
 	if err != nil {
 		log.WithError(err).Error("CheckHelpers#ServerRepo.GetServerConfigForGuild")
-		return
+		return nil
 	}
-	if serverConfig.HelperRoleThxesNeeded <= 0 {
-		log.Debug("HelperRoleThxesNeeded is 0 or less, not checking helpers")
-		return
+	tiers, err := h.ServerRepo.GetHelperTiers(ctx, guildId)
+	if err != nil {
+		log.WithError(err).Error("CheckHelpers#ServerRepo.GetHelperTiers")
+		return nil
 	}
-	if serverConfig.HelperRoleId == "" {
-		log.Debug("HelperRoleId is empty, not checking helpers")
-		return
+	if len(tiers) == 0 {
+		log.Debug("No helper tiers, not checking helpers")
+		return nil
 	}
 
-	helpers, err := h.GiveawaysRepo.GetParticipantsWithThxAmount(ctx, guildId, serverConfig.HelperRoleThxesNeeded)
+	helpers, err := h.GiveawaysRepo.GetParticipantsWithThxAmount(ctx, guildId, tiers[0].ThxesNeeded, serverConfig.HelperThxSince(time.Now()))
 	if err != nil {
 		log.WithError(err).Error("CheckHelpers#GiveawaysRepo.GetParticipantsWithThxAmount")
-		return
+		return nil
 	}
-
+	thxAmounts := make(map[string]int, len(helpers))
 	for _, helper := range helpers {
-		isHelperBlacklisted, err := h.UserRepo.IsUserHelperBlacklisted(ctx, helper.UserId, guildId)
-		if err != nil {
-			log.WithError(err).Error("CheckHelpers#UserRepo.IsUserHelperBlacklisted")
+		thxAmounts[helper.UserId] = helper.ThxAmount
+	}
+
+	members := discord.GetAllMembers(ctx, session, guildId)
+	if members == nil {
+		return nil
+	}
+	var changes []entities.HelperTierChange
+	for _, member := range members {
+		thxAmount, isHelper := thxAmounts[member.User.ID]
+		if !isHelper && heldHelperTier(tiers, member) == nil {
 			continue
 		}
-		member, err := session.GuildMember(guildId, helper.UserId)
+		isHelperBlacklisted, err := h.UserRepo.IsUserHelperBlacklisted(ctx, member.User.ID, guildId)
 		if err != nil {
-			log.WithError(err).Error("CheckHelpers#session.GuildMember")
+			log.WithError(err).Error("CheckHelpers#UserRepo.IsUserHelperBlacklisted")
 			continue
 		}
-		hasRole := discord.HasRoleById(member, serverConfig.HelperRoleId)
-		if !hasRole && !isHelperBlacklisted {
-			log.Infof("Adding helper role to %s (%s)", member.User.Username, helper.UserId)
-			err = session.GuildMemberRoleAdd(guildId, helper.UserId, serverConfig.HelperRoleId)
-			if err != nil {
-				log.WithError(err).Error("CheckHelpers#session.GuildMemberRoleAdd")
-			}
-			continue
-		}
-		if isHelperBlacklisted && hasRole {
-			log.Infof("Removing helper role from %s (%s)", member.User.Username, helper.UserId)
-			err = session.GuildMemberRoleRemove(guildId, helper.UserId, serverConfig.HelperRoleId)
-			if err != nil {
-				log.WithError(err).Error("CheckHelpers#session.GuildMemberRoleRemove")
-			}
-			continue
+		change := h.updateHelperTier(ctx, session, serverConfig, tiers, member, thxAmount, isHelperBlacklisted)
+		if change != nil {
+			changes = append(changes, *change)
 		}
 	}
+
+	return changes
 }
 
-func (h *HelperService) CheckHelper(ctx context.Context, session *discordgo.Session, guildId, memberId string) {
+func (h *HelperService) CheckHelper(ctx context.Context, session discord.Session, guildId, memberId string) {
 	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId)
 	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, guildId)
 	if err != nil {
 		log.WithError(err).Error("CheckHelper#ServerRepo.GetServerConfigForGuild")
 		return
 	}
-	if serverConfig.HelperRoleThxesNeeded <= 0 {
-		log.Debug("HelperRoleThxesNeeded is 0 or less, not checking helper")
+	tiers, err := h.ServerRepo.GetHelperTiers(ctx, guildId)
+	if err != nil {
+		log.WithError(err).Error("CheckHelper#ServerRepo.GetHelperTiers")
 		return
 	}
-	if serverConfig.HelperRoleId == "" {
-		log.Debug("HelperRoleId is empty, not checking helper")
+	if len(tiers) == 0 {
+		log.Debug("No helper tiers, not checking helper")
 		return
 	}
 
@@ -98,9 +104,9 @@ func (h *HelperService) CheckHelper(ctx context.Context, session *discordgo.Sess
 		return
 	}
 
-	hasHelperAmount, err := h.GiveawaysRepo.HasThxAmount(ctx, guildId, memberId, serverConfig.HelperRoleThxesNeeded)
+	thxAmount, err := h.GiveawaysRepo.GetThxAmount(ctx, guildId, memberId, serverConfig.HelperThxSince(time.Now()))
 	if err != nil {
-		log.WithError(err).Error("CheckHelper#GiveawaysRepo.HasThxAmount")
+		log.WithError(err).Error("CheckHelper#GiveawaysRepo.GetThxAmount")
 		return
 	}
 	isHelperBlacklisted, err := h.UserRepo.IsUserHelperBlacklisted(ctx, memberId, guildId)
@@ -108,25 +114,84 @@ func (h *HelperService) CheckHelper(ctx context.Context, session *discordgo.Sess
 		log.WithError(err).Error("CheckHelper#UserRepo.IsUserHelperBlacklisted")
 		return
 	}
-	hasRole := discord.HasRoleById(member, serverConfig.HelperRoleId)
 
-	if isHelperBlacklisted && hasRole {
-		log.Debug("User is blacklisted, has role")
-		log.Infof("Removing helper role from %s (%s)", member.User.Username, memberId)
-		err = session.GuildMemberRoleRemove(guildId, memberId, serverConfig.HelperRoleId)
-		if err != nil {
-			log.WithError(err).Error("CheckHelper#session.GuildMemberRoleRemove")
+	h.updateHelperTier(ctx, session, serverConfig, tiers, member, thxAmount, isHelperBlacklisted)
+}
+
+// updateHelperTier leaves the member only the role of the highest tier reached with thxAmount and announces the
+// promotion or demotion. Blacklisted members lose all tier roles without an announcement. It returns the change of
+// the tier, or nil when the tier is unchanged or the roles could not be updated.
+func (h *HelperService) updateHelperTier(ctx context.Context, session discord.Session, serverConfig entities.ServerConfig, tiers []entities.HelperTier, member *discordgo.Member, thxAmount int, isHelperBlacklisted bool) *entities.HelperTierChange {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(serverConfig.GuildId).WithUser(member.User.ID)
+	var tier *entities.HelperTier
+	if !isHelperBlacklisted {
+		tier = entities.HelperTierFor(tiers, thxAmount)
+	}
+	previousTier := heldHelperTier(tiers, member)
+
+	updated := true
+	for _, t := range tiers {
+		hasRole := discord.HasRoleById(member, t.RoleId)
+		isTier := tier != nil && tier.RoleId == t.RoleId
+		if isTier && !hasRole {
+			log.Infof("Adding helper role %s to %s (%s)", t.RoleId, member.User.Username, member.User.ID)
+			err := session.GuildMemberRoleAdd(serverConfig.GuildId, member.User.ID, t.RoleId)
+			if err != nil {
+				log.WithError(err).Error("updateHelperTier#session.GuildMemberRoleAdd")
+				updated = false
+			}
+		}
+		if !isTier && hasRole {
+			log.Infof("Removing helper role %s from %s (%s)", t.RoleId, member.User.Username, member.User.ID)
+			err := session.GuildMemberRoleRemove(serverConfig.GuildId, member.User.ID, t.RoleId)
+			if err != nil {
+				log.WithError(err).Error("updateHelperTier#session.GuildMemberRoleRemove")
+				updated = false
+			}
 		}
-		return
 	}
-	if hasRole || isHelperBlacklisted || !hasHelperAmount {
-		log.Debug("User has role, is blacklisted or doesn't have enough thxes - not adding role")
-		return
+
+	if !updated || sameHelperTier(previousTier, tier) {
+		return nil
+	}
+	change := &entities.HelperTierChange{UserId: member.User.ID, UserName: member.User.Username, PreviousTier: previousTier, Tier: tier}
+	if isHelperBlacklisted || serverConfig.HelperAnnouncementChannel == "" {
+		return change
 	}
-	log.Infof("Adding helper role to %s (%s)", member.User.Username, memberId)
-	err = session.GuildMemberRoleAdd(guildId, memberId, serverConfig.HelperRoleId)
+	var content string
+	switch {
+	case tier == nil:
+		content = fmt.Sprintf("<@%s> traci rangę <@&%s>", member.User.ID, previousTier.RoleId)
+	case previousTier == nil || tier.ThxesNeeded > previousTier.ThxesNeeded:
+		content = fmt.Sprintf("🎉 <@%s> otrzymuje rangę <@&%s>!", member.User.ID, tier.RoleId)
+	default:
+		content = fmt.Sprintf("<@%s> przechodzi z rangi <@&%s> do <@&%s>", member.User.ID, previousTier.RoleId, tier.RoleId)
+	}
+	_, err := session.ChannelMessageSendComplex(serverConfig.HelperAnnouncementChannel, &discordgo.MessageSend{
+		Content:         content,
+		AllowedMentions: &discordgo.MessageAllowedMentions{Users: []string{member.User.ID}},
+	})
 	if err != nil {
-		log.WithError(err).Error("CheckHelper#session.GuildMemberRoleAdd")
+		log.WithError(err).Error("updateHelperTier#session.ChannelMessageSendComplex")
 	}
 
+	return change
+}
+
+// heldHelperTier returns the highest of the tiers whose role the member has, or nil.
+func heldHelperTier(tiers []entities.HelperTier, member *discordgo.Member) *entities.HelperTier {
+	var held *entities.HelperTier
+	for i := range tiers {
+		if discord.HasRoleById(member, tiers[i].RoleId) {
+			held = &tiers[i]
+		}
+	}
+	return held
+}
+
+func sameHelperTier(a, b *entities.HelperTier) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.RoleId == b.RoleId
 }
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/internal/repos"
+	"csrvbot/pkg/discord"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const testHelperAnnouncementChannelId = "helpers"
+
+var testHelperTiers = []entities.HelperTier{
+	{GuildId: testGuildId, RoleId: "helper", ThxesNeeded: 5},
+	{GuildId: testGuildId, RoleId: "super-helper", ThxesNeeded: 20},
+}
+
+func TestHelperService_UpdateHelperTier(t *testing.T) {
+	tests := []struct {
+		name          string
+		roles         []string
+		thxAmount     int
+		blacklisted   bool
+		wantRoles     []string
+		wantAnnounced string // the role mentioned in the announcement, empty if none is sent
+	}{
+		{"promotion to the first tier", nil, 6, false, []string{"helper"}, "helper"},
+		{"promotion to a higher tier", []string{"helper"}, 21, false, []string{"super-helper"}, "super-helper"},
+		{"demotion to a lower tier", []string{"super-helper"}, 20, false, []string{"helper"}, "helper"},
+		{"demotion below the tiers", []string{"helper"}, 5, false, nil, "helper"},
+		{"tier kept", []string{"helper"}, 10, false, []string{"helper"}, ""},
+		{"blacklisted member loses the tier", []string{"super-helper"}, 30, true, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			session := discord.NewFakeSession()
+			session.AddGuild(&discordgo.Guild{ID: testGuildId, Name: "Guild"})
+			session.AddChannel(&discordgo.Channel{ID: testHelperAnnouncementChannelId, GuildID: testGuildId, Type: discordgo.ChannelTypeGuildText})
+			for _, tier := range testHelperTiers {
+				session.AddRole(testGuildId, &discordgo.Role{ID: tier.RoleId, Name: tier.RoleId})
+			}
+			session.AddMember(testGuildId, &discordgo.Member{User: &discordgo.User{ID: "member", Username: "member"}, Roles: slices.Clone(tt.roles)})
+			member := session.Member(testGuildId, "member")
+			service := NewHelperService("https://craftserve.pl", repos.NewMemoryServerRepo(), repos.NewMemoryUserRepo(), repos.NewMemoryGiveawaysRepo())
+			serverConfig := entities.ServerConfig{GuildId: testGuildId, HelperAnnouncementChannel: testHelperAnnouncementChannelId}
+
+			service.updateHelperTier(ctx, session, serverConfig, testHelperTiers, member, tt.thxAmount, tt.blacklisted)
+
+			if roles := session.Member(testGuildId, "member").Roles; !slices.Equal(roles, tt.wantRoles) {
+				t.Errorf("member roles = %v, want %v", roles, tt.wantRoles)
+			}
+			messages := session.Messages(testHelperAnnouncementChannelId)
+			if tt.wantAnnounced == "" {
+				if len(messages) != 0 {
+					t.Errorf("announced %q, want no announcement", messages[0].Content)
+				}
+				return
+			}
+			if len(messages) != 1 || !strings.HasSuffix(strings.TrimSuffix(messages[0].Content, "!"), "<@&"+tt.wantAnnounced+">") {
+				t.Errorf("announcements = %v, want one about <@&%s>", messages, tt.wantAnnounced)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/backoff"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/logger"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	pendingVoucherBatchSize     = 20
+	pendingVoucherRetryDelay    = time.Minute
+	pendingVoucherMaxRetryDelay = time.Hour
+)
+
+// addPendingVoucher queues the voucher of a winner whose code could not be generated when the draw was completed.
+func (h *GiveawayService) addPendingVoucher(ctx context.Context, winner *entities.GiveawayWinner, voucherConfig entities.VoucherConfig, cause error) error {
+	now := time.Now()
+	giveaway, err := h.GiveawaysRepo.GetGiveawayById(ctx, winner.GiveawayId)
+	if err != nil {
+		return err
+	}
+
+	return h.GiveawaysRepo.AddPendingVoucher(ctx, &entities.PendingVoucher{
+		WinnerId:      winner.Id,
+		GiveawayId:    winner.GiveawayId,
+		GuildId:       giveaway.GuildId,
+		UserId:        winner.UserId,
+		VoucherConfig: voucherConfig,
+		Attempts:      1,
+		LastError:     cause.Error(),
+		NextAttemptAt: now.Add(backoff.Exponential(1, pendingVoucherRetryDelay, pendingVoucherMaxRetryDelay)),
+		CreatedAt:     now,
+	})
+}
+
+// RunPendingVouchers retries the due pending vouchers every interval until ctx is done.
+func (h *GiveawayService) RunPendingVouchers(ctx context.Context, s discord.Session, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		h.RetryPendingVouchers(ctx, s)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RetryPendingVouchers tries to generate the due pending vouchers and sends the issued codes to the winners.
+func (h *GiveawayService) RetryPendingVouchers(ctx context.Context, s discord.Session) {
+	log := logger.GetLoggerFromContext(ctx)
+	pendingVouchers, err := h.GiveawaysRepo.GetDuePendingVouchers(ctx, time.Now(), pendingVoucherBatchSize)
+	if err != nil {
+		log.WithError(err).Error("RetryPendingVouchers#h.GiveawaysRepo.GetDuePendingVouchers")
+		return
+	}
+
+	for i := range pendingVouchers {
+		if ctx.Err() != nil {
+			return
+		}
+		h.retryPendingVoucher(ctx, s, &pendingVouchers[i])
+	}
+}
+
+func (h *GiveawayService) retryPendingVoucher(ctx context.Context, s discord.Session, pendingVoucher *entities.PendingVoucher) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(pendingVoucher.GuildId).WithUser(pendingVoucher.UserId).WithField("pendingVoucherId", pendingVoucher.Id)
+
+	winner, err := h.GiveawaysRepo.GetWinner(ctx, pendingVoucher.WinnerId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && winner.Code != "") {
+		// The draw was rolled back, or the code was issued when an interrupted draw was resumed
+		log.Info("Pending voucher is no longer needed")
+		h.resolvePendingVoucher(ctx, pendingVoucher)
+		return
+	}
+	if err != nil {
+		log.WithError(err).Error("retryPendingVoucher#h.GiveawaysRepo.GetWinner")
+		return
+	}
+
+	voucher, err := h.VoucherService.ReserveVoucher(ctx, winner.Id, pendingVoucher.VoucherConfig)
+	if err != nil {
+		h.alertUnexpectedVoucherResponse(ctx, s, pendingVoucher.GuildId, pendingVoucher.UserId, err)
+		pendingVoucher.Attempts++
+		pendingVoucher.LastError = err.Error()
+		pendingVoucher.NextAttemptAt = time.Now().Add(backoff.Exponential(pendingVoucher.Attempts, pendingVoucherRetryDelay, pendingVoucherMaxRetryDelay))
+		log.WithError(err).Warnf("Pending voucher attempt %d failed, next attempt at %s", pendingVoucher.Attempts, pendingVoucher.NextAttemptAt.Format(time.DateTime))
+
+		err = h.GiveawaysRepo.UpdatePendingVoucher(ctx, pendingVoucher)
+		if err != nil {
+			log.WithError(err).Error("retryPendingVoucher#h.GiveawaysRepo.UpdatePendingVoucher")
+		}
+		return
+	}
+
+	err = h.GiveawaysRepo.SetWinnerCode(ctx, winner.Id, voucher.Code, pendingVoucher.VoucherConfig, voucher.ExpiresAt)
+	if errors.Is(err, entities.ErrWinnerCodeAlreadySet) {
+		log.Warnf("Winner already has a code, voucher %s is left unused", voucher.Code)
+		h.VoucherService.ReleaseVoucher(ctx, winner.Id)
+		h.resolvePendingVoucher(ctx, pendingVoucher)
+		return
+	}
+	if err != nil {
+		log.WithError(err).Error("retryPendingVoucher#h.GiveawaysRepo.SetWinnerCode")
+		return
+	}
+	h.VoucherService.ReleaseVoucher(ctx, winner.Id)
+	winner.Code = voucher.Code
+	h.resolvePendingVoucher(ctx, pendingVoucher)
+	log.Infof("Issued pending voucher after %d failed attempts", pendingVoucher.Attempts)
+
+	var customGiveaway *entities.CustomGiveaway
+	if pendingVoucher.VoucherConfig.GiveawayType == entities.CustomGiveawayType {
+		customGiveaway, err = h.GiveawaysRepo.GetCustomGiveaway(ctx, winner.GiveawayId)
+		if err != nil {
+			log.WithError(err).Error("retryPendingVoucher#h.GiveawaysRepo.GetCustomGiveaway")
+			return
+		}
+	}
+
+	err = h.notifyWinner(ctx, s, winner, customGiveaway, "")
+	if err != nil {
+		log.WithError(err).Error("retryPendingVoucher#h.notifyWinner")
+	}
+}
+
+func (h *GiveawayService) resolvePendingVoucher(ctx context.Context, pendingVoucher *entities.PendingVoucher) {
+	now := time.Now()
+	pendingVoucher.ResolvedAt = &now
+	err := h.GiveawaysRepo.UpdatePendingVoucher(ctx, pendingVoucher)
+	if err != nil {
+		logger.GetLoggerFromContext(ctx).WithError(err).Error("resolvePendingVoucher#h.GiveawaysRepo.UpdatePendingVoucher")
+	}
+}
+
+// alertUnexpectedVoucherResponse tells the admins in the admin log channel that the voucher of the winner may have been
+// created by a request that returned an unexpected response. Other errors are only logged by the caller.
+func (h *GiveawayService) alertUnexpectedVoucherResponse(ctx context.Context, s discord.Session, guildId, userId string, err error) {
+	if !errors.Is(err, ErrUnexpectedResponse) {
+		return
+	}
+
+	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId).WithUser(userId)
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, guildId)
+	if err != nil {
+		log.WithError(err).Error("alertUnexpectedVoucherResponse#h.ServerRepo.GetServerConfigForGuild")
+		return
+	}
+	if serverConfig.AdminLogChannel == "" {
+		return
+	}
+
+	message := fmt.Sprintf("⚠️ API Craftserve przyjęło żądanie kodu dla <@%s>, ale zwróciło niepoprawną odpowiedź. Kod mógł zostać utworzony, a bot spróbuje wygenerować go ponownie, sprawdź logi.", userId)
+	_, err = s.ChannelMessageSend(serverConfig.AdminLogChannel, message)
+	if err != nil {
+		log.WithError(err).Error("alertUnexpectedVoucherResponse#s.ChannelMessageSend")
+	}
+}
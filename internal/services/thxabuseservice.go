@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/logger"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	thxAbuseReciprocalWindow   = 30 * 24 * time.Hour
+	thxAbuseBurstWindow        = time.Hour
+	thxAbuseBurstLimit         = 3
+	thxAbuseNewMemberAge       = 7 * 24 * time.Hour
+	thxAbuseConversationWindow = 24 * time.Hour
+	thxAbuseConversationLimit  = 100
+)
+
+// ThxAbuseService flags thanks which look farmed, so the admins can reject them with context. The flags never
+// block a thx on their own.
+type ThxAbuseService struct {
+	GiveawaysRepo entities.GiveawaysRepo
+	mu            sync.Mutex
+	inFlight      []inFlightThx // thanks which passed the limits but are not inserted as participants yet
+}
+
+type inFlightThx struct {
+	guildId     string
+	thankerId   string
+	recipientId string
+}
+
+func NewThxAbuseService(giveawaysRepo entities.GiveawaysRepo) *ThxAbuseService {
+	return &ThxAbuseService{
+		GiveawaysRepo: giveawaysRepo,
+	}
+}
+
+// Check returns the abuse flags of the thx sent with the message and records them. The thx must already be inserted as
+// a participant. A check which cannot be completed is logged and skipped.
+func (h *ThxAbuseService) Check(ctx context.Context, s discord.Session, guildId, channelId, messageId, thankerId, recipientId string) []entities.ThxAbuseFlag {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId).WithUser(thankerId)
+	now := time.Now()
+	var flags []entities.ThxAbuseFlag
+
+	reciprocal, err := h.GiveawaysRepo.CountThanksBetween(ctx, guildId, recipientId, thankerId, now.Add(-thxAbuseReciprocalWindow))
+	if err != nil {
+		log.WithError(err).Error("Check#h.GiveawaysRepo.CountThanksBetween")
+	} else if reciprocal > 0 {
+		flags = append(flags, entities.ThxAbuseFlagReciprocal)
+	}
+
+	// The thx being checked is already a participant, so it is counted too
+	given, err := h.GiveawaysRepo.CountThanksGiven(ctx, guildId, thankerId, now.Add(-thxAbuseBurstWindow))
+	if err != nil {
+		log.WithError(err).Error("Check#h.GiveawaysRepo.CountThanksGiven")
+	} else if given > thxAbuseBurstLimit {
+		flags = append(flags, entities.ThxAbuseFlagBurst)
+	}
+
+	for _, userId := range []string{thankerId, recipientId} {
+		member, err := s.GuildMember(guildId, userId)
+		if err != nil {
+			log.WithError(err).Error("Check#s.GuildMember")
+			continue
+		}
+		if now.Sub(member.JoinedAt) < thxAbuseNewMemberAge {
+			flags = append(flags, entities.ThxAbuseFlagNewMember)
+			break
+		}
+	}
+
+	messages, err := s.ChannelMessages(channelId, thxAbuseConversationLimit, "", "", "")
+	if err != nil {
+		log.WithError(err).Error("Check#s.ChannelMessages")
+	} else {
+		wrote := make(map[string]bool)
+		for _, message := range messages {
+			if message.Author != nil && now.Sub(message.Timestamp) < thxAbuseConversationWindow {
+				wrote[message.Author.ID] = true
+			}
+		}
+		if !wrote[thankerId] || !wrote[recipientId] {
+			flags = append(flags, entities.ThxAbuseFlagNoConversation)
+		}
+	}
+
+	err = h.GiveawaysRepo.InsertThxAbuseCheck(ctx, &entities.ThxAbuseCheck{
+		GuildId:     guildId,
+		MessageId:   messageId,
+		ChannelId:   channelId,
+		ThankerId:   thankerId,
+		RecipientId: recipientId,
+		Flags:       flags,
+		CreatedAt:   now,
+	})
+	if err != nil {
+		log.WithError(err).Error("Check#h.GiveawaysRepo.InsertThxAbuseCheck")
+	}
+	if len(flags) > 0 {
+		log.Infof("Thx for %s flagged as %v", recipientId, flags)
+	}
+
+	return flags
+}
+
+// GetFlags returns the abuse flags recorded for the thx message, or nil if it was not checked.
+func (h *ThxAbuseService) GetFlags(ctx context.Context, messageId string) []entities.ThxAbuseFlag {
+	check, err := h.GiveawaysRepo.GetThxAbuseCheck(ctx, messageId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		logger.GetLoggerFromContext(ctx).WithError(err).Error("GetFlags#h.GiveawaysRepo.GetThxAbuseCheck")
+		return nil
+	}
+
+	return check.Flags
+}
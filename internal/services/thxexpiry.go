@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/logger"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	thxExpiryBatchSize = 50
+	// thxCarryOverWindow is how long before the start of the current giveaway the previous one may have finished for
+	// its unreviewed thx to be carried over, the thx of older giveaways are rejected.
+	thxCarryOverWindow = time.Hour
+)
+
+// RunThxExpiry expires the unanswered /thxme requests and resolves the unreviewed thx of finished giveaways every
+// interval until ctx is done.
+func (h *GiveawayService) RunThxExpiry(ctx context.Context, s discord.Session, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		h.ExpireThx(ctx, s)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireThx expires the unanswered /thxme requests and resolves the unreviewed thx of finished giveaways in every
+// guild with a thx giveaway, following the guild settings.
+func (h *GiveawayService) ExpireThx(ctx context.Context, s discord.Session) {
+	log := logger.GetLoggerFromContext(ctx)
+	giveaways, err := h.GiveawaysRepo.GetUnfinishedGiveaways(ctx, entities.ThxGiveawayType)
+	if err != nil {
+		log.WithError(err).Error("ExpireThx#h.GiveawaysRepo.GetUnfinishedGiveaways")
+		return
+	}
+
+	for i := range giveaways {
+		if ctx.Err() != nil {
+			return
+		}
+
+		serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, giveaways[i].GuildId)
+		if err != nil {
+			log.WithGuild(giveaways[i].GuildId).WithError(err).Error("ExpireThx#h.ServerRepo.GetServerConfigForGuild")
+			continue
+		}
+		h.expireThxmeRequests(ctx, s, serverConfig)
+		h.resolveUnreviewedThx(ctx, s, serverConfig, &giveaways[i])
+	}
+}
+
+func (h *GiveawayService) expireThxmeRequests(ctx context.Context, s discord.Session, serverConfig entities.ServerConfig) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(serverConfig.GuildId)
+	expiryHours := serverConfig.ThxmeExpiryHours
+	if expiryHours <= 0 {
+		expiryHours = entities.DefaultThxmeExpiryHours
+	}
+
+	candidates, err := h.GiveawaysRepo.GetExpiredParticipantCandidates(ctx, serverConfig.GuildId, time.Now().Add(-time.Duration(expiryHours)*time.Hour), thxExpiryBatchSize)
+	if err != nil {
+		log.WithError(err).Error("expireThxmeRequests#h.GiveawaysRepo.GetExpiredParticipantCandidates")
+		return
+	}
+
+	for i := range candidates {
+		candidate := &candidates[i]
+		err = h.GiveawaysRepo.UpdateParticipantCandidate(ctx, candidate, false)
+		if err != nil {
+			log.WithError(err).Error("expireThxmeRequests#h.GiveawaysRepo.UpdateParticipantCandidate")
+			continue
+		}
+		log.Infof("Thx request of %s for %s expired", candidate.CandidateName, candidate.CandidateApproverName)
+
+		content := fmt.Sprintf("<@%s>, czy chcesz podziękować użytkownikowi %s? - Wygasło", candidate.CandidateApproverId, candidate.CandidateName)
+		components := discord.ConstructAcceptRejectComponents(true)
+		_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			Channel:    candidate.ChannelId,
+			ID:         candidate.MessageId,
+			Content:    &content,
+			Components: &components,
+		})
+		if err != nil {
+			log.WithError(err).Error("expireThxmeRequests#s.ChannelMessageEditComplex")
+		}
+	}
+}
+
+// resolveUnreviewedThx rejects the thx left unreviewed in finished giveaways of the guild or carries them over to the
+// current giveaway, where they can still be reviewed. Only the thx of the giveaway which just finished are carried over.
+func (h *GiveawayService) resolveUnreviewedThx(ctx context.Context, s discord.Session, serverConfig entities.ServerConfig, currentGiveaway *entities.Giveaway) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(serverConfig.GuildId)
+	participants, err := h.GiveawaysRepo.GetUnreviewedParticipants(ctx, serverConfig.GuildId, thxExpiryBatchSize)
+	if err != nil {
+		log.WithError(err).Error("resolveUnreviewedThx#h.GiveawaysRepo.GetUnreviewedParticipants")
+		return
+	}
+
+	carryOver := make(map[int]bool)
+	for i := range participants {
+		participant := &participants[i]
+		if serverConfig.UnreviewedThxPolicy == entities.UnreviewedThxPolicyCarryOver {
+			if _, ok := carryOver[participant.GiveawayId]; !ok {
+				giveaway, err := h.GiveawaysRepo.GetGiveawayById(ctx, participant.GiveawayId)
+				if err != nil {
+					log.WithError(err).Error("resolveUnreviewedThx#h.GiveawaysRepo.GetGiveawayById")
+					continue
+				}
+				carryOver[participant.GiveawayId] = giveaway.EndTime != nil && !giveaway.EndTime.Before(currentGiveaway.StartTime.Add(-thxCarryOverWindow))
+			}
+		}
+
+		if !carryOver[participant.GiveawayId] {
+			err = h.GiveawaysRepo.UpdateParticipant(ctx, participant, "", "", false)
+			if err != nil {
+				log.WithError(err).Error("resolveUnreviewedThx#h.GiveawaysRepo.UpdateParticipant")
+				continue
+			}
+			log.Infof("Rejected unreviewed thx for %s in giveaway %d", participant.UserName, participant.GiveawayId)
+
+			err = h.UpdateThxMessages(ctx, s, serverConfig, participant, "", "reject", true)
+			if err != nil {
+				log.WithError(err).Error("resolveUnreviewedThx#h.UpdateThxMessages")
+			}
+			continue
+		}
+
+		previousGiveawayId := participant.GiveawayId
+		err = h.GiveawaysRepo.MoveParticipant(ctx, participant, currentGiveaway.Id)
+		if err != nil {
+			log.WithError(err).Error("resolveUnreviewedThx#h.GiveawaysRepo.MoveParticipant")
+			continue
+		}
+		log.Infof("Carried over unreviewed thx for %s from giveaway %d to %d", participant.UserName, previousGiveawayId, currentGiveaway.Id)
+
+		err = h.UpdateThxMessages(ctx, s, serverConfig, participant, "", "wait", false)
+		if err != nil {
+			log.WithError(err).Error("resolveUnreviewedThx#h.UpdateThxMessages")
+		}
+	}
+}
+
+// UpdateThxMessages refreshes the thx message and its notification on the ThxInfoChannel after the state of the thx
+// changed. The buttons of the thx message are disabled once it can no longer be reviewed.
+func (h *GiveawayService) UpdateThxMessages(ctx context.Context, s discord.Session, serverConfig entities.ServerConfig, participant *entities.GiveawayParticipant, confirmerId, state string, disableButtons bool) error {
+	if participant.MessageId == nil || participant.ChannelId == nil {
+		return nil
+	}
+	messageId, channelId := *participant.MessageId, *participant.ChannelId
+
+	accepted := true
+	participants, err := h.GiveawaysRepo.GetParticipantsForGiveaway(ctx, participant.GiveawayId, &accepted)
+	if err != nil {
+		return err
+	}
+	embed := discord.ConstructThxEmbed(h.CraftserveUrl, serverConfig, participants, participant.UserId, confirmerId, state, h.VoucherService.GetVoucherConfig(ctx, serverConfig.GuildId, entities.ThxGiveawayType))
+	components := discord.ConstructAcceptRejectComponents(disableButtons)
+	_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		Channel:    channelId,
+		ID:         messageId,
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
+	})
+	if err != nil {
+		return err
+	}
+
+	thxNotification, err := h.GiveawaysRepo.GetThxNotification(ctx, messageId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	flags := h.ThxAbuseService.GetFlags(ctx, messageId)
+	notificationMessageId, err := discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, thxNotification.NotificationMessageId, serverConfig.GuildId, channelId, messageId, participant.UserId, confirmerId, state, h.CraftserveUrl, flags)
+	if err != nil {
+		return err
+	}
+	if thxNotification.NotificationMessageId == "" && notificationMessageId != "" {
+		return h.GiveawaysRepo.InsertThxNotification(ctx, messageId, notificationMessageId)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"csrvbot/domain/entities"
+	"testing"
+	"time"
+)
+
+func TestGiveawayService_ResolveUnreviewedThx(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		startDelay time.Duration // between the end of the finished giveaway and the start of the current one
+		carried    bool
+	}{
+		{"carry over at the window boundary", entities.UnreviewedThxPolicyCarryOver, thxCarryOverWindow, true},
+		{"reject past the window", entities.UnreviewedThxPolicyCarryOver, thxCarryOverWindow + time.Second, false},
+		{"reject policy", entities.UnreviewedThxPolicyReject, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newGiveawayTestEnv(t, "thanked")
+			env.updateServerConfig(t, func(serverConfig *entities.ServerConfig) {
+				serverConfig.UnreviewedThxPolicy = tt.policy
+			})
+			err := env.giveawaysRepo.InsertGiveaway(env.ctx, testGuildId, nil, entities.ThxGiveawayType, nil)
+			if err != nil {
+				t.Fatalf("InsertGiveaway: %v", err)
+			}
+			finished := env.giveaway(t, entities.ThxGiveawayType)
+			err = env.giveawaysRepo.InsertParticipant(env.ctx, finished.Id, 0, testGuildId, "thanked", "thanked", nil, nil, nil)
+			if err != nil {
+				t.Fatalf("InsertParticipant: %v", err)
+			}
+			err = env.giveawaysRepo.FinishGiveaway(env.ctx, finished, nil)
+			if err != nil {
+				t.Fatalf("FinishGiveaway: %v", err)
+			}
+			err = env.giveawaysRepo.InsertGiveaway(env.ctx, testGuildId, nil, entities.ThxGiveawayType, nil)
+			if err != nil {
+				t.Fatalf("InsertGiveaway: %v", err)
+			}
+			current := env.giveaway(t, entities.ThxGiveawayType)
+			current.StartTime = finished.EndTime.Add(tt.startDelay)
+			serverConfig, err := env.serverRepo.GetServerConfigForGuild(env.ctx, testGuildId)
+			if err != nil {
+				t.Fatalf("GetServerConfigForGuild: %v", err)
+			}
+
+			env.service.resolveUnreviewedThx(env.ctx, env.session, serverConfig, current)
+
+			participants, err := env.giveawaysRepo.GetParticipantsForGiveaway(env.ctx, current.Id, nil)
+			if err != nil {
+				t.Fatalf("GetParticipantsForGiveaway: %v", err)
+			}
+			if carried := len(participants) == 1 && !participants[0].IsAccepted.Valid; carried != tt.carried {
+				t.Errorf("thx carried over = %t, want %t", carried, tt.carried)
+			}
+			accepted := false
+			participants, err = env.giveawaysRepo.GetParticipantsForGiveaway(env.ctx, finished.Id, &accepted)
+			if err != nil {
+				t.Fatalf("GetParticipantsForGiveaway: %v", err)
+			}
+			if rejected := len(participants) == 1; rejected == tt.carried {
+				t.Errorf("thx rejected = %t, want %t", rejected, !tt.carried)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/logger"
+	"time"
+)
+
+// CheckThxLimits returns the limit the thanker hits by thanking the recipient, or an empty string if the thx is
+// allowed. Hits are recorded for the admins. An allowed thx counts for the next checks until release is called, which
+// must happen once the thx is inserted as a participant or abandoned, so thanks sent in a quick succession cannot all
+// pass the limits before the first one is saved.
+func (h *ThxAbuseService) CheckThxLimits(ctx context.Context, serverConfig entities.ServerConfig, thankerId, recipientId string) (limit string, release func(), err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	thx := inFlightThx{guildId: serverConfig.GuildId, thankerId: thankerId, recipientId: recipientId}
+	given, between := h.countInFlight(thx)
+
+	now := time.Now()
+	if serverConfig.ThxCooldownMinutes > 0 {
+		since := now.Add(-time.Duration(serverConfig.ThxCooldownMinutes) * time.Minute)
+		count, err := h.GiveawaysRepo.CountThanksBetween(ctx, serverConfig.GuildId, thankerId, recipientId, since)
+		if err != nil {
+			return "", nil, err
+		}
+		if count+between > 0 {
+			h.recordLimitHit(ctx, serverConfig.GuildId, thankerId, recipientId, entities.ThxLimitCooldown)
+			return entities.ThxLimitCooldown, func() {}, nil
+		}
+	}
+
+	if serverConfig.ThxDailyLimit > 0 {
+		today := now.In(serverConfig.Location())
+		since := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+		count, err := h.GiveawaysRepo.CountThanksGiven(ctx, serverConfig.GuildId, thankerId, since)
+		if err != nil {
+			return "", nil, err
+		}
+		if count+given >= serverConfig.ThxDailyLimit {
+			h.recordLimitHit(ctx, serverConfig.GuildId, thankerId, recipientId, entities.ThxLimitDaily)
+			return entities.ThxLimitDaily, func() {}, nil
+		}
+	}
+
+	h.inFlight = append(h.inFlight, thx)
+	return "", func() { h.releaseInFlight(thx) }, nil
+}
+
+// countInFlight returns how many in-flight thanks the thanker of thx gives in its guild, in total and to its recipient.
+func (h *ThxAbuseService) countInFlight(thx inFlightThx) (given, between int) {
+	for _, other := range h.inFlight {
+		if other.guildId != thx.guildId || other.thankerId != thx.thankerId {
+			continue
+		}
+		given++
+		if other.recipientId == thx.recipientId {
+			between++
+		}
+	}
+
+	return given, between
+}
+
+func (h *ThxAbuseService) releaseInFlight(thx inFlightThx) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, other := range h.inFlight {
+		if other == thx {
+			h.inFlight = append(h.inFlight[:i], h.inFlight[i+1:]...)
+			return
+		}
+	}
+}
+
+// CheckThxmeLimits returns the limit the requester hits by asking the approver for a thx, or an empty string if the
+// request is allowed. Hits are recorded for the admins.
+func (h *ThxAbuseService) CheckThxmeLimits(ctx context.Context, serverConfig entities.ServerConfig, requesterId, approverId string) (string, error) {
+	if serverConfig.ThxmePendingLimit > 0 {
+		count, err := h.GiveawaysRepo.CountPendingParticipantCandidates(ctx, serverConfig.GuildId, requesterId)
+		if err != nil {
+			return "", err
+		}
+		if count >= serverConfig.ThxmePendingLimit {
+			h.recordLimitHit(ctx, serverConfig.GuildId, requesterId, approverId, entities.ThxLimitPendingThxme)
+			return entities.ThxLimitPendingThxme, nil
+		}
+	}
+
+	return "", nil
+}
+
+func (h *ThxAbuseService) recordLimitHit(ctx context.Context, guildId, userId, targetUserId, limit string) {
+	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId).WithUser(userId)
+	log.Infof("Thx for %s refused by the %s limit", targetUserId, limit)
+	err := h.GiveawaysRepo.InsertThxLimitHit(ctx, &entities.ThxLimitHit{
+		GuildId:      guildId,
+		UserId:       userId,
+		TargetUserId: targetUserId,
+		Limit:        limit,
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		log.WithError(err).Error("recordLimitHit#h.GiveawaysRepo.InsertThxLimitHit")
+	}
+}
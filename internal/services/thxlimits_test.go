@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/internal/repos"
+	"testing"
+)
+
+func TestThxAbuseService_CheckThxLimits(t *testing.T) {
+	ctx := context.Background()
+	giveawaysRepo := repos.NewMemoryGiveawaysRepo()
+	service := NewThxAbuseService(giveawaysRepo)
+	serverConfig := entities.ServerConfig{GuildId: testGuildId, ThxCooldownMinutes: 60, ThxDailyLimit: 2}
+
+	check := func(recipientId, want string) func() {
+		t.Helper()
+		limit, release, err := service.CheckThxLimits(ctx, serverConfig, "thanker", recipientId)
+		if err != nil {
+			t.Fatalf("CheckThxLimits: %v", err)
+		}
+		if limit != want {
+			t.Fatalf("CheckThxLimits(%s) = %q, want %q", recipientId, limit, want)
+		}
+		return release
+	}
+
+	// A thx which is not saved yet counts for the limits
+	release := check("first", "")
+	check("first", entities.ThxLimitCooldown)
+	second := check("second", "")
+	check("third", entities.ThxLimitDaily)
+
+	thankerId := "thanker"
+	err := giveawaysRepo.InsertParticipant(ctx, 1, 0, testGuildId, "first", "first", nil, nil, &thankerId)
+	if err != nil {
+		t.Fatalf("InsertParticipant: %v", err)
+	}
+	release()
+	check("first", entities.ThxLimitCooldown)
+
+	// An abandoned thx does not
+	second()
+	check("second", "")
+}
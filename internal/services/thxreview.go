@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/logger"
+)
+
+// ReviewThx accepts or rejects the thx on behalf of the reviewer and refreshes its messages, so the thx buttons and
+// the review queue stay in sync.
+func (h *GiveawayService) ReviewThx(ctx context.Context, s discord.Session, serverConfig entities.ServerConfig, participant *entities.GiveawayParticipant, reviewerId, reviewerName string, accept bool) error {
+	err := h.GiveawaysRepo.UpdateParticipant(ctx, participant, reviewerId, reviewerName, accept)
+	if err != nil {
+		return err
+	}
+
+	log := logger.GetLoggerFromContext(ctx)
+	state := "reject"
+	action := entities.AuditActionThxReject
+	if accept {
+		state = "confirm"
+		action = entities.AuditActionThxAccept
+		log.Infof("%s accepted %s participation in giveaway %d", reviewerName, participant.UserName, participant.GiveawayId)
+	} else {
+		log.Infof("%s rejected %s participation in giveaway %d", reviewerName, participant.UserName, participant.GiveawayId)
+	}
+	h.AuditService.Record(ctx, s, &entities.AuditLogEntry{
+		GuildId:   serverConfig.GuildId,
+		ActorId:   reviewerId,
+		ActorName: reviewerName,
+		Action:    action,
+		Target:    participant.UserId,
+	})
+
+	err = h.UpdateThxMessages(ctx, s, serverConfig, participant, reviewerId, state, false)
+	if err != nil {
+		log.WithError(err).Error("ReviewThx#h.UpdateThxMessages")
+	}
+
+	return nil
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/logger"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// maxVouchersPerRequest is the largest quantity accepted by the voucher generate endpoint.
+	maxVouchersPerRequest        = 100
+	defaultVoucherPoolMaxAgeDays = 7
+)
+
+type VoucherPoolConfig struct {
+	Size           int    `json:"size"`          // vouchers kept per profile, 0 disables the pool
+	LowThreshold   int    `json:"low_threshold"` // admins are alerted when a profile has fewer vouchers left
+	MaxAgeDays     int    `json:"max_age_days"`  // older vouchers are not handed out so winners get close to the full validity
+	AlertChannelId string `json:"alert_channel_id"`
+}
+
+type VoucherPool struct {
+	CsrvClient  *CsrvClient
+	VoucherRepo entities.VoucherRepo
+	Config      VoucherPoolConfig
+	mu          sync.Mutex
+	low         map[entities.VoucherProfile]bool
+	paused      map[entities.VoucherProfile]bool // refills stopped after an unexpected API response, until a restart
+	refill      chan struct{}
+}
+
+func NewVoucherPool(csrvClient *CsrvClient, voucherRepo entities.VoucherRepo, config VoucherPoolConfig) *VoucherPool {
+	if config.MaxAgeDays <= 0 {
+		config.MaxAgeDays = defaultVoucherPoolMaxAgeDays
+	}
+
+	return &VoucherPool{
+		CsrvClient:  csrvClient,
+		VoucherRepo: voucherRepo,
+		Config:      config,
+		low:         make(map[entities.VoucherProfile]bool),
+		paused:      make(map[entities.VoucherProfile]bool),
+		refill:      make(chan struct{}, 1),
+	}
+}
+
+func (p *VoucherPool) Enabled() bool {
+	return p != nil && p.Config.Size > 0
+}
+
+// Take returns a pre-generated voucher with the voucher settings, or false if the pool has none left.
+func (p *VoucherPool) Take(ctx context.Context, voucherConfig entities.VoucherConfig) (*entities.PooledVoucher, bool) {
+	if !p.Enabled() {
+		return nil, false
+	}
+
+	log := logger.GetLoggerFromContext(ctx)
+	voucher, err := p.VoucherRepo.TakePooledVoucher(ctx, voucherConfig.Profile(), p.freshAfter(), validAfter(voucherConfig.Profile()))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.WithError(err).Error("VoucherPool.Take#p.VoucherRepo.TakePooledVoucher")
+		}
+		log.Warnf("Voucher pool for %s is empty", voucherConfig.Profile())
+		p.RequestRefill()
+		return nil, false
+	}
+
+	p.RequestRefill()
+	return voucher, true
+}
+
+// Reserve reserves a pre-generated voucher with the voucher settings for the winner, or returns false if the pool has none left.
+func (p *VoucherPool) Reserve(ctx context.Context, voucherConfig entities.VoucherConfig, winnerId int) (*entities.PooledVoucher, bool) {
+	if !p.Enabled() {
+		return nil, false
+	}
+
+	log := logger.GetLoggerFromContext(ctx)
+	voucher, err := p.VoucherRepo.ReservePooledVoucher(ctx, voucherConfig.Profile(), p.freshAfter(), validAfter(voucherConfig.Profile()), winnerId)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.WithError(err).Error("VoucherPool.Reserve#p.VoucherRepo.ReservePooledVoucher")
+		}
+		log.Warnf("Voucher pool for %s is empty", voucherConfig.Profile())
+		p.RequestRefill()
+		return nil, false
+	}
+
+	p.RequestRefill()
+	return voucher, true
+}
+
+// RequestRefill wakes up the refill loop without waiting for its interval.
+func (p *VoucherPool) RequestRefill() {
+	select {
+	case p.refill <- struct{}{}:
+	default:
+	}
+}
+
+// Refill removes expired vouchers and tops up the pool of every profile to its size. Vouchers older than MaxAgeDays
+// are only reported, they are still valid and are kept until they expire.
+func (p *VoucherPool) Refill(ctx context.Context, s discord.Session, profiles []entities.VoucherProfile) {
+	log := logger.GetLoggerFromContext(ctx)
+	removed, err := p.VoucherRepo.RemoveExpiredPooledVouchers(ctx, time.Now())
+	if err != nil {
+		log.WithError(err).Error("VoucherPool.Refill#p.VoucherRepo.RemoveExpiredPooledVouchers")
+	} else if removed > 0 {
+		log.Infof("Removed %d expired vouchers from the pool", removed)
+	}
+
+	aged, err := p.VoucherRepo.CountAgedPooledVouchers(ctx, p.freshAfter())
+	if err != nil {
+		log.WithError(err).Error("VoucherPool.Refill#p.VoucherRepo.CountAgedPooledVouchers")
+	} else if aged > 0 {
+		log.Warnf("%d vouchers in the pool are older than %d days and are no longer handed out", aged, p.Config.MaxAgeDays)
+	}
+
+	for _, profile := range profiles {
+		if ctx.Err() != nil {
+			return
+		}
+		p.refillProfile(ctx, s, profile)
+	}
+}
+
+func (p *VoucherPool) refillProfile(ctx context.Context, s discord.Session, profile entities.VoucherProfile) {
+	log := logger.GetLoggerFromContext(ctx).WithField("profile", profile.String())
+	p.mu.Lock()
+	paused := p.paused[profile]
+	p.mu.Unlock()
+	if paused {
+		return
+	}
+
+	count, err := p.VoucherRepo.CountPooledVouchers(ctx, profile, p.freshAfter(), validAfter(profile))
+	if err != nil {
+		log.WithError(err).Error("VoucherPool.refillProfile#p.VoucherRepo.CountPooledVouchers")
+		return
+	}
+
+	var refillErr error
+	for count < p.Config.Size {
+		quantity := min(p.Config.Size-count, maxVouchersPerRequest)
+		voucherConfig := entities.VoucherConfig{
+			Value:          profile.Value,
+			Currency:       profile.Currency,
+			ExpirationDays: profile.ExpirationDays,
+			Prefix:         profile.Prefix,
+			GroupId:        profile.GroupId,
+		}
+		vouchers, err := p.CsrvClient.GenerateVouchers(ctx, voucherConfig, quantity)
+		if errors.Is(err, ErrUnexpectedResponse) {
+			p.pause(ctx, s, profile, err)
+			return
+		}
+		if err != nil {
+			log.WithError(err).Error("VoucherPool.refillProfile#p.CsrvClient.GenerateVouchers")
+			refillErr = err
+			break
+		}
+
+		now := time.Now()
+		pooled := make([]entities.PooledVoucher, len(vouchers))
+		for i, voucher := range vouchers {
+			pooled[i] = newPooledVoucher(voucher, voucherConfig, now)
+		}
+
+		err = p.VoucherRepo.AddPooledVouchers(ctx, pooled)
+		if err != nil {
+			log.WithError(err).Error("VoucherPool.refillProfile#p.VoucherRepo.AddPooledVouchers")
+			refillErr = err
+			break
+		}
+		count += len(pooled)
+		log.Debugf("Added %d vouchers to the pool", len(pooled))
+	}
+
+	p.checkLow(ctx, s, profile, count, refillErr)
+}
+
+// pause stops the refills of the profile until a restart and alerts the admins, because the vouchers of the failed
+// request may have been created and every further refill could create them again.
+func (p *VoucherPool) pause(ctx context.Context, s discord.Session, profile entities.VoucherProfile, cause error) {
+	log := logger.GetLoggerFromContext(ctx).WithField("profile", profile.String())
+	log.WithError(cause).Error("Voucher pool refills are paused after an unexpected response")
+
+	p.mu.Lock()
+	p.paused[profile] = true
+	p.mu.Unlock()
+
+	if p.Config.AlertChannelId == "" {
+		return
+	}
+	message := fmt.Sprintf("🛑 Uzupełnianie puli kodów %s wstrzymano do restartu bota. API Craftserve przyjęło żądanie, ale zwróciło niepoprawną odpowiedź, więc kody mogły zostać utworzone: `%s`", profile, cause)
+	_, err := s.ChannelMessageSend(p.Config.AlertChannelId, message)
+	if err != nil {
+		log.WithError(err).Error("VoucherPool.pause#s.ChannelMessageSend")
+	}
+}
+
+// checkLow alerts the admins once when the pool of the profile runs low, and once more when it is refilled.
+func (p *VoucherPool) checkLow(ctx context.Context, s discord.Session, profile entities.VoucherProfile, count int, refillErr error) {
+	log := logger.GetLoggerFromContext(ctx).WithField("profile", profile.String())
+	low := count < p.Config.LowThreshold
+
+	p.mu.Lock()
+	wasLow := p.low[profile]
+	p.low[profile] = low
+	p.mu.Unlock()
+	if low == wasLow {
+		return
+	}
+
+	var message string
+	if low {
+		log.Errorf("Voucher pool is running low: %d of %d left", count, p.Config.Size)
+		message = fmt.Sprintf("⚠️ W puli kodów %s zostało tylko %d z %d.", profile, count, p.Config.Size)
+		if refillErr != nil {
+			message += fmt.Sprintf(" Nie udało się jej uzupełnić: `%s`", refillErr)
+		}
+	} else {
+		log.Infof("Voucher pool is refilled: %d of %d", count, p.Config.Size)
+		message = fmt.Sprintf("✅ Pula kodów %s została uzupełniona (%d z %d).", profile, count, p.Config.Size)
+	}
+
+	if p.Config.AlertChannelId == "" {
+		return
+	}
+	_, err := s.ChannelMessageSend(p.Config.AlertChannelId, message)
+	if err != nil {
+		log.WithError(err).Error("VoucherPool.checkLow#s.ChannelMessageSend")
+	}
+}
+
+// freshAfter returns the creation time before which pooled vouchers are no longer handed out.
+func (p *VoucherPool) freshAfter() time.Time {
+	return time.Now().Add(-24 * time.Duration(p.Config.MaxAgeDays) * time.Hour)
+}
+
+// validAfter returns the expiry before which pooled vouchers of the profile are no longer handed out, so a winner always
+// gets at least half of the validity of a freshly generated voucher.
+func validAfter(profile entities.VoucherProfile) time.Time {
+	return time.Now().Add(12 * time.Duration(profile.ExpirationDays) * time.Hour)
+}
+
+// newPooledVoucher returns the pool entry of a voucher generated at now, with the expiry reported by the API if there is one.
+func newPooledVoucher(voucher entities.Voucher, voucherConfig entities.VoucherConfig, now time.Time) entities.PooledVoucher {
+	expiresAt := voucherConfig.ExpiresAt(now)
+	if voucher.Expires != nil {
+		expiresAt = *voucher.Expires
+	}
+
+	return entities.PooledVoucher{Code: voucher.Id, Profile: voucherConfig.Profile(), CreatedAt: now, ExpiresAt: expiresAt}
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/internal/repos"
+	"csrvbot/pkg/discord"
+	"testing"
+	"time"
+)
+
+var testPoolVoucherConfig = entities.VoucherConfig{
+	Value:          1000,
+	Currency:       entities.DefaultVoucherCurrency,
+	ExpirationDays: 30,
+	Prefix:         entities.DefaultVoucherPrefix,
+	GroupId:        entities.DefaultVoucherGroupId,
+}
+
+// newTestVoucherPool returns a pool of the given size with the vouchers added in creation order.
+func newTestVoucherPool(t *testing.T, size int, vouchers ...entities.PooledVoucher) (*VoucherPool, *repos.MemoryVoucherRepo) {
+	t.Helper()
+	voucherRepo := repos.NewMemoryVoucherRepo()
+	err := voucherRepo.AddPooledVouchers(context.Background(), vouchers)
+	if err != nil {
+		t.Fatalf("AddPooledVouchers: %v", err)
+	}
+
+	pool := NewVoucherPool(NewCsrvClient("", "development", ""), voucherRepo, VoucherPoolConfig{Size: size, MaxAgeDays: 7})
+	return pool, voucherRepo
+}
+
+func pooledVoucher(code string, profile entities.VoucherProfile, age, validity time.Duration) entities.PooledVoucher {
+	now := time.Now()
+	return entities.PooledVoucher{Code: code, Profile: profile, CreatedAt: now.Add(-age), ExpiresAt: now.Add(validity)}
+}
+
+// freshnessTestVouchers are the pooled vouchers in creation order, only "fresh" can be handed out for testPoolVoucherConfig.
+func freshnessTestVouchers() []entities.PooledVoucher {
+	day := 24 * time.Hour
+	profile := testPoolVoucherConfig.Profile()
+	otherProfile := profile
+	otherProfile.Value = 2000
+	return []entities.PooledVoucher{
+		pooledVoucher("aged", profile, 8*day, 22*day),
+		pooledVoucher("other-profile", otherProfile, day, 29*day),
+		pooledVoucher("short-validity", profile, day, 14*day),
+		pooledVoucher("fresh", profile, day, 29*day),
+	}
+}
+
+func TestVoucherPool_Take(t *testing.T) {
+	ctx := context.Background()
+	pool, _ := newTestVoucherPool(t, 10, freshnessTestVouchers()...)
+
+	voucher, ok := pool.Take(ctx, testPoolVoucherConfig)
+	if !ok || voucher.Code != "fresh" {
+		t.Fatalf("Take() = %+v, %t, want the fresh voucher", voucher, ok)
+	}
+	if voucher, ok := pool.Take(ctx, testPoolVoucherConfig); ok {
+		t.Errorf("Take() = %s, want none left with enough validity", voucher.Code)
+	}
+}
+
+func TestVoucherPool_Reserve(t *testing.T) {
+	ctx := context.Background()
+	pool, voucherRepo := newTestVoucherPool(t, 10, freshnessTestVouchers()...)
+
+	voucher, ok := pool.Reserve(ctx, testPoolVoucherConfig, 1)
+	if !ok || voucher.Code != "fresh" || voucher.WinnerId == nil || *voucher.WinnerId != 1 {
+		t.Fatalf("Reserve() = %+v, %t, want the fresh voucher reserved for winner 1", voucher, ok)
+	}
+	if voucher, ok := pool.Reserve(ctx, testPoolVoucherConfig, 2); ok {
+		t.Errorf("Reserve() = %s, want none left with enough validity", voucher.Code)
+	}
+	if voucher, ok := pool.Take(ctx, testPoolVoucherConfig); ok {
+		t.Errorf("Take() = %s, want the reserved voucher to be kept for its winner", voucher.Code)
+	}
+
+	reserved, err := voucherRepo.GetReservedVoucher(ctx, 1)
+	if err != nil || reserved.Code != "fresh" {
+		t.Fatalf("GetReservedVoucher() = %+v, %v, want the fresh voucher", reserved, err)
+	}
+}
+
+func TestVoucherPool_Refill(t *testing.T) {
+	ctx := context.Background()
+	day := 24 * time.Hour
+	profile := testPoolVoucherConfig.Profile()
+	reserved := pooledVoucher("expired-reserved", profile, 31*day, -day)
+	winnerId := 1
+	reserved.WinnerId = &winnerId
+	pool, voucherRepo := newTestVoucherPool(t, 2,
+		pooledVoucher("expired", profile, 31*day, -day),
+		reserved,
+		pooledVoucher("aged", profile, 8*day, 22*day),
+		pooledVoucher("fresh", profile, day, 29*day),
+	)
+
+	pool.Refill(ctx, discord.NewFakeSession(), []entities.VoucherProfile{profile})
+
+	aged, err := voucherRepo.CountAgedPooledVouchers(ctx, pool.freshAfter())
+	if err != nil || aged != 1 {
+		t.Errorf("CountAgedPooledVouchers() = %d, %v, want the aged voucher kept until it expires", aged, err)
+	}
+	if _, err := voucherRepo.GetReservedVoucher(ctx, winnerId); err != nil {
+		t.Errorf("GetReservedVoucher: %v, want the reservation kept", err)
+	}
+	count, err := voucherRepo.CountPooledVouchers(ctx, profile, pool.freshAfter(), validAfter(profile))
+	if err != nil || count != 2 {
+		t.Errorf("CountPooledVouchers() = %d, %v, want the pool topped up to 2", count, err)
+	}
+
+	if removed, _ := voucherRepo.RemoveExpiredPooledVouchers(ctx, time.Now()); removed != 0 {
+		t.Errorf("%d expired vouchers were left in the pool", removed)
+	}
+}
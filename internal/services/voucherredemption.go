@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/circuitbreaker"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/fairdraw"
+	"csrvbot/pkg/logger"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	redemptionCheckBatchSize = 50
+	redemptionRecheckDelay   = 24 * time.Hour
+)
+
+// RunRedemptionChecks checks the redemption status of the issued vouchers every interval until ctx is done.
+func (h *GiveawayService) RunRedemptionChecks(ctx context.Context, s discord.Session, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		h.CheckRedemptions(ctx, s)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckRedemptions updates the redemption status of the vouchers not checked for a day, or which expired since
+// the last check, and applies the expired voucher policy of the guild to the ones that expired unused.
+func (h *GiveawayService) CheckRedemptions(ctx context.Context, s discord.Session) {
+	log := logger.GetLoggerFromContext(ctx)
+	winners, err := h.GiveawaysRepo.GetWinnersToCheck(ctx, time.Now().Add(-redemptionRecheckDelay), redemptionCheckBatchSize)
+	if err != nil {
+		log.WithError(err).Error("CheckRedemptions#h.GiveawaysRepo.GetWinnersToCheck")
+		return
+	}
+
+	for i := range winners {
+		if ctx.Err() != nil {
+			return
+		}
+
+		err = h.checkRedemption(ctx, s, &winners[i])
+		if errors.Is(err, circuitbreaker.ErrOpen) {
+			log.Warn("Craftserve API is unavailable, postponing redemption checks")
+			return
+		}
+	}
+}
+
+func (h *GiveawayService) checkRedemption(ctx context.Context, s discord.Session, winner *entities.GiveawayWinner) error {
+	log := logger.GetLoggerFromContext(ctx).WithUser(winner.UserId).WithField("winnerId", winner.Id)
+	voucher, err := h.VoucherService.GetVoucher(ctx, winner.Code)
+	if err != nil {
+		log.WithError(err).Error("checkRedemption#h.VoucherService.GetVoucher")
+		return err
+	}
+
+	now := time.Now()
+	previousStatus := winner.RedemptionStatus
+	winner.StatusCheckedAt = &now
+	if voucher.Expires != nil {
+		winner.ExpiresAt = voucher.Expires
+	}
+	winner.RedeemedAt = voucher.RedeemedAt
+	switch {
+	case voucher.RedeemedAt != nil:
+		winner.RedemptionStatus = entities.VoucherStatusRedeemed
+	case voucher.Expires != nil && voucher.Expires.Before(now):
+		winner.RedemptionStatus = entities.VoucherStatusExpired
+	default:
+		winner.RedemptionStatus = entities.VoucherStatusUnclaimed
+	}
+
+	// Only codes seen unused before they expired are replaced, and a replacement is never replaced again
+	if previousStatus == entities.VoucherStatusUnclaimed && winner.RedemptionStatus == entities.VoucherStatusExpired && winner.ReplacesWinnerId == nil {
+		replaced, err := h.replaceExpiredWinner(ctx, s, winner)
+		if err != nil {
+			// The status is left as it was, so the replacement is tried again on the next check
+			log.WithError(err).Error("checkRedemption#h.replaceExpiredWinner")
+			return err
+		}
+		if replaced {
+			return nil
+		}
+	}
+
+	err = h.GiveawaysRepo.UpdateWinnerRedemption(ctx, winner)
+	if err != nil {
+		log.WithError(err).Error("checkRedemption#h.GiveawaysRepo.UpdateWinnerRedemption")
+		return err
+	}
+
+	return nil
+}
+
+// replaceExpiredWinner reissues or redraws the prize of a winner whose code expired unused, following the guild policy.
+// It returns false if the guild policy keeps the expired code.
+func (h *GiveawayService) replaceExpiredWinner(ctx context.Context, s discord.Session, winner *entities.GiveawayWinner) (bool, error) {
+	giveaway, err := h.GiveawaysRepo.GetGiveawayById(ctx, winner.GiveawayId)
+	if err != nil {
+		return false, err
+	}
+	log := logger.GetLoggerFromContext(ctx).WithGuild(giveaway.GuildId).WithUser(winner.UserId).WithField("winnerId", winner.Id)
+
+	serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, giveaway.GuildId)
+	if err != nil {
+		return false, err
+	}
+	if serverConfig.ExpiredVoucherPolicy != entities.ExpiredVoucherPolicyReissue && serverConfig.ExpiredVoucherPolicy != entities.ExpiredVoucherPolicyRedraw {
+		return false, nil
+	}
+
+	var customGiveaway *entities.CustomGiveaway
+	if giveaway.Type == entities.CustomGiveawayType {
+		customGiveaway, err = h.GiveawaysRepo.GetCustomGiveaway(ctx, giveaway.Id)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	voucherConfig, hasVoucher := h.VoucherService.GetVoucherConfigForGiveaway(ctx, giveaway, customGiveaway)
+	if !hasVoucher {
+		return false, nil
+	}
+
+	replacement := &entities.GiveawayWinner{UserId: winner.UserId, UserName: winner.UserName}
+	content := "Twój poprzedni kod z giveawaya wygasł niewykorzystany, dlatego wysyłamy nowy. Pamiętaj, aby go wykorzystać przed upływem terminu ważności!"
+	var redraw *giveawayRedraw
+	if serverConfig.ExpiredVoucherPolicy == entities.ExpiredVoucherPolicyRedraw {
+		redraw, err = h.redrawWinner(ctx, s, giveaway, winner)
+		if err != nil {
+			return false, err
+		}
+		if redraw == nil {
+			log.Info("No participants left to redraw the expired voucher")
+			return false, nil
+		}
+		replacement = &redraw.Winner
+		content = "Poprzedni zwycięzca giveawaya nie wykorzystał swojego kodu, więc nagroda została rozlosowana ponownie. Gratulacje!"
+	}
+
+	voucher, err := h.VoucherService.GenerateVoucher(ctx, voucherConfig)
+	if err != nil {
+		return false, err
+	}
+	replacement.Code = voucher.Code
+	replacement.VoucherValue = &voucherConfig.Value
+	replacement.VoucherCurrency = &voucherConfig.Currency
+	replacement.ExpiresAt = &voucher.ExpiresAt
+
+	err = h.GiveawaysRepo.ReplaceWinner(ctx, winner, replacement)
+	if err != nil {
+		log.Warnf("Voucher %s is left unused", voucher.Code)
+		return false, err
+	}
+	log.Infof("Replaced expired voucher with policy %s, new winner %s", serverConfig.ExpiredVoucherPolicy, replacement.UserId)
+
+	err = h.notifyWinner(ctx, s, replacement, customGiveaway, content)
+	if err != nil {
+		log.WithError(err).Error("replaceExpiredWinner#h.notifyWinner")
+	}
+
+	if redraw != nil {
+		channelId, err := h.getGiveawayChannel(ctx, giveaway)
+		if err != nil {
+			log.WithError(err).Error("replaceExpiredWinner#h.getGiveawayChannel")
+			return true, nil
+		}
+		embed := discord.ConstructRedrawEmbed(h.CraftserveUrl, giveaway.Id, winner.UserId, replacement.UserId, redraw.Seed, redraw.Entries, redraw.Skipped)
+		_, err = s.ChannelMessageSendEmbed(channelId, embed)
+		if err != nil {
+			log.WithError(err).Error("replaceExpiredWinner#s.ChannelMessageSendEmbed")
+		}
+	}
+
+	return true, nil
+}
+
+// giveawayRedraw is the result of a redraw of an expired prize, published so it can be verified like the original draw.
+type giveawayRedraw struct {
+	Winner  entities.GiveawayWinner
+	Seed    string
+	Entries []string
+	Skipped []string
+}
+
+// redrawWinner picks a new winner among the draw participants who did not win it yet. The seed is derived from the
+// giveaway seed and the replaced winner, so the redraw can be verified like the original draw. It returns nil if
+// there is nobody left to pick.
+func (h *GiveawayService) redrawWinner(ctx context.Context, s discord.Session, giveaway *entities.Giveaway, winner *entities.GiveawayWinner) (*giveawayRedraw, error) {
+	draw, err := h.GiveawaysRepo.GetDrawForGiveaway(ctx, giveaway.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Giveaways finished before draws were persisted have no participant list to redraw from
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	winners, err := h.GiveawaysRepo.GetGiveawayWinners(ctx, giveaway.Id)
+	if err != nil {
+		return nil, err
+	}
+	won := make(map[string]bool, len(winners))
+	for _, w := range winners {
+		won[w.UserId] = true
+	}
+
+	var entries []string
+	for _, userId := range draw.Participants {
+		if !won[userId] {
+			entries = append(entries, userId)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	redraw := *giveaway
+	redraw.Seed = fairdraw.RedrawSeed(giveaway.Seed, winner.Id)
+	picked, skipped, err := h.drawWinners(ctx, s, &redraw, entries, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(picked) == 0 {
+		return nil, nil
+	}
+
+	return &giveawayRedraw{Winner: picked[0], Seed: redraw.Seed, Entries: entries, Skipped: skipped}, nil
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/fairdraw"
+	"strings"
+	"testing"
+)
+
+// finishedThxGiveaway finishes a thx giveaway of the participants and returns it with its only winner.
+func (env *giveawayTestEnv) finishedThxGiveaway(t *testing.T, participantIds ...string) (*entities.Giveaway, entities.GiveawayWinner) {
+	t.Helper()
+	guild, _ := env.session.Guild(testGuildId)
+	env.service.CreateMissingThxGiveaways(env.ctx, env.session, guild)
+	giveaway := env.giveaway(t, entities.ThxGiveawayType)
+	for _, userId := range participantIds {
+		env.acceptThx(t, giveaway.Id, userId)
+	}
+
+	env.service.FinishGiveaway(env.ctx, env.session, testGuildId)
+
+	winners, err := env.giveawaysRepo.GetGiveawayWinners(env.ctx, giveaway.Id)
+	if err != nil || len(winners) != 1 || winners[0].Code == "" {
+		t.Fatalf("GetGiveawayWinners() = %v, %v, want one winner with a code", winners, err)
+	}
+	giveaway, err = env.giveawaysRepo.GetGiveawayById(env.ctx, giveaway.Id)
+	if err != nil {
+		t.Fatalf("GetGiveawayById: %v", err)
+	}
+	return giveaway, winners[0]
+}
+
+// replacementOf returns the winner replacing the given one, and checks the draw verification still sees only the original.
+func (env *giveawayTestEnv) replacementOf(t *testing.T, giveaway *entities.Giveaway, winner entities.GiveawayWinner) entities.GiveawayWinner {
+	t.Helper()
+	winners, err := env.giveawaysRepo.GetGiveawayWinners(env.ctx, giveaway.Id)
+	if err != nil || len(winners) != 2 {
+		t.Fatalf("GetGiveawayWinners() = %v, %v, want the replaced winner and the replacement", winners, err)
+	}
+	if winners[0].RedemptionStatus != entities.VoucherStatusReplaced {
+		t.Errorf("replaced winner has status %s", winners[0].RedemptionStatus)
+	}
+	replacement := winners[1]
+	if replacement.ReplacesWinnerId == nil || *replacement.ReplacesWinnerId != winner.Id || replacement.Code == "" || replacement.Code == winner.Code {
+		t.Fatalf("replacement = %+v, want a new code replacing winner %d", replacement, winner.Id)
+	}
+
+	drawWinners, err := env.giveawaysRepo.GetDrawWinners(env.ctx, *winner.DrawId)
+	if err != nil || len(drawWinners) != 1 || drawWinners[0].Id != winner.Id {
+		t.Errorf("GetDrawWinners() = %v, %v, want only the winner picked by the draw", drawWinners, err)
+	}
+	return replacement
+}
+
+func TestGiveawayService_ReplaceExpiredWinnerReissue(t *testing.T) {
+	env := newGiveawayTestEnv(t, "first", "second")
+	env.updateServerConfig(t, func(serverConfig *entities.ServerConfig) {
+		serverConfig.ExpiredVoucherPolicy = entities.ExpiredVoucherPolicyReissue
+	})
+	giveaway, winner := env.finishedThxGiveaway(t, "first", "second")
+	channelMessages := len(env.session.Messages(testChannelId))
+
+	replaced, err := env.service.replaceExpiredWinner(env.ctx, env.session, &winner)
+	if err != nil || !replaced {
+		t.Fatalf("replaceExpiredWinner() = %t, %v, want the code reissued", replaced, err)
+	}
+
+	replacement := env.replacementOf(t, giveaway, winner)
+	if replacement.UserId != winner.UserId {
+		t.Errorf("code reissued to %s, want the same winner %s", replacement.UserId, winner.UserId)
+	}
+	messages := env.session.DirectMessages(winner.UserId)
+	if len(messages) != 2 || !strings.Contains(messages[1].Embeds[0].Description+embedFieldValues(messages[1].Embeds[0]), replacement.Code) {
+		t.Errorf("winner got %d direct messages, want the new code in the second one", len(messages))
+	}
+	if len(env.session.Messages(testChannelId)) != channelMessages {
+		t.Errorf("a reissued code is announced in the giveaway channel")
+	}
+}
+
+func TestGiveawayService_ReplaceExpiredWinnerRedraw(t *testing.T) {
+	participantIds := []string{"first", "second", "third"}
+	env := newGiveawayTestEnv(t, participantIds...)
+	env.updateServerConfig(t, func(serverConfig *entities.ServerConfig) {
+		serverConfig.ExpiredVoucherPolicy = entities.ExpiredVoucherPolicyRedraw
+	})
+	giveaway, winner := env.finishedThxGiveaway(t, participantIds...)
+
+	replaced, err := env.service.replaceExpiredWinner(env.ctx, env.session, &winner)
+	if err != nil || !replaced {
+		t.Fatalf("replaceExpiredWinner() = %t, %v, want the prize redrawn", replaced, err)
+	}
+
+	replacement := env.replacementOf(t, giveaway, winner)
+	if replacement.UserId == winner.UserId {
+		t.Fatalf("prize redrawn to the same winner %s", winner.UserId)
+	}
+	env.assertCodeSent(t, replacement.UserId, replacement.Code)
+
+	// The redraw publishes its seed and entries, replaying them gives the new winner
+	draw, err := env.giveawaysRepo.GetDrawForGiveaway(env.ctx, giveaway.Id)
+	if err != nil {
+		t.Fatalf("GetDrawForGiveaway: %v", err)
+	}
+	var entries []string
+	for _, userId := range draw.Participants {
+		if userId != winner.UserId {
+			entries = append(entries, userId)
+		}
+	}
+	seed := fairdraw.RedrawSeed(giveaway.Seed, winner.Id)
+	picked, _ := fairdraw.Pick(seed, entries, 1, false, func(string) bool { return true })
+	if len(picked) != 1 || picked[0] != replacement.UserId {
+		t.Errorf("replaying the redraw picks %v, want %s", picked, replacement.UserId)
+	}
+
+	messages := env.session.Messages(testChannelId)
+	announcement := messages[len(messages)-1]
+	if len(announcement.Embeds) == 0 {
+		t.Fatalf("redraw is not announced in the giveaway channel")
+	}
+	published := embedFieldValues(announcement.Embeds[0])
+	if !strings.Contains(published, seed) {
+		t.Errorf("redraw announcement does not publish the seed %s", seed)
+	}
+	for _, userId := range entries {
+		if !strings.Contains(published, "<@"+userId+">") {
+			t.Errorf("redraw announcement does not publish the entry of %s", userId)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/discord"
+	"csrvbot/pkg/logger"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+)
+
+type VoucherService struct {
+	CsrvClient            *CsrvClient
+	VoucherPool           *VoucherPool
+	VoucherRepo           entities.VoucherRepo
+	ServerRepo            entities.ServerRepo
+	DefaultValue          int
+	DefaultExpirationDays int
+}
+
+func NewVoucherService(csrvClient *CsrvClient, voucherPool *VoucherPool, voucherRepo entities.VoucherRepo, serverRepo entities.ServerRepo, defaultValue, defaultExpirationDays int) *VoucherService {
+	return &VoucherService{
+		CsrvClient:            csrvClient,
+		VoucherPool:           voucherPool,
+		VoucherRepo:           voucherRepo,
+		ServerRepo:            serverRepo,
+		DefaultValue:          defaultValue,
+		DefaultExpirationDays: defaultExpirationDays,
+	}
+}
+
+// DefaultVoucherConfig returns the voucher settings from config.json.
+func (h *VoucherService) DefaultVoucherConfig(guildId, giveawayType string) entities.VoucherConfig {
+	return entities.VoucherConfig{
+		GuildId:        guildId,
+		GiveawayType:   giveawayType,
+		Value:          h.DefaultValue,
+		Currency:       entities.DefaultVoucherCurrency,
+		ExpirationDays: h.DefaultExpirationDays,
+		Prefix:         entities.DefaultVoucherPrefix,
+		GroupId:        entities.DefaultVoucherGroupId,
+	}
+}
+
+// GetVoucherConfig returns the voucher settings of the giveaway type in the guild, or the defaults if the guild did not change them.
+func (h *VoucherService) GetVoucherConfig(ctx context.Context, guildId, giveawayType string) entities.VoucherConfig {
+	voucherConfig, err := h.ServerRepo.GetVoucherConfig(ctx, guildId, giveawayType)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.GetLoggerFromContext(ctx).WithGuild(guildId).WithError(err).Error("GetVoucherConfig#h.ServerRepo.GetVoucherConfig")
+		}
+		return h.DefaultVoucherConfig(guildId, giveawayType)
+	}
+
+	return voucherConfig
+}
+
+// GetVoucherConfigForGiveaway returns the voucher settings used for the winners of the giveaway.
+// It returns false if the prize of the giveaway is not a voucher.
+func (h *VoucherService) GetVoucherConfigForGiveaway(ctx context.Context, giveaway *entities.Giveaway, customGiveaway *entities.CustomGiveaway) (entities.VoucherConfig, bool) {
+	if giveaway.Type != entities.CustomGiveawayType {
+		return h.GetVoucherConfig(ctx, giveaway.GuildId, giveaway.Type), true
+	}
+	if customGiveaway == nil || customGiveaway.VoucherValue == nil {
+		return entities.VoucherConfig{}, false
+	}
+
+	// Custom giveaways set their own value in PLN
+	voucherConfig := h.DefaultVoucherConfig(giveaway.GuildId, giveaway.Type)
+	voucherConfig.Value = *customGiveaway.VoucherValue
+	return voucherConfig, true
+}
+
+// GenerateVoucher hands out a pre-generated voucher from the pool and only calls the API when the pool is empty.
+func (h *VoucherService) GenerateVoucher(ctx context.Context, voucherConfig entities.VoucherConfig) (*entities.PooledVoucher, error) {
+	if voucher, ok := h.VoucherPool.Take(ctx, voucherConfig); ok {
+		return voucher, nil
+	}
+
+	vouchers, err := h.CsrvClient.GenerateVouchers(ctx, voucherConfig, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	voucher := newPooledVoucher(vouchers[0], voucherConfig, time.Now())
+	return &voucher, nil
+}
+
+// ReserveVoucher returns the voucher reserved for the winner. Without one it reserves a voucher from the pool, or generates
+// a voucher and reserves it right away, so a draw interrupted before the code is saved on the winner reuses the same voucher.
+func (h *VoucherService) ReserveVoucher(ctx context.Context, winnerId int, voucherConfig entities.VoucherConfig) (*entities.PooledVoucher, error) {
+	log := logger.GetLoggerFromContext(ctx).WithField("winnerId", winnerId)
+	voucher, err := h.VoucherRepo.GetReservedVoucher(ctx, winnerId)
+	if err == nil {
+		log.Infof("Reusing voucher %s reserved by an interrupted draw", voucher.Code)
+		return voucher, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if voucher, ok := h.VoucherPool.Reserve(ctx, voucherConfig, winnerId); ok {
+		return voucher, nil
+	}
+
+	vouchers, err := h.CsrvClient.GenerateVouchers(ctx, voucherConfig, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved := newPooledVoucher(vouchers[0], voucherConfig, time.Now())
+	reserved.WinnerId = &winnerId
+	err = h.VoucherRepo.AddPooledVouchers(ctx, []entities.PooledVoucher{reserved})
+	if err != nil {
+		// The code is still saved on the winner by the caller, only a crash before that would lose it
+		log.WithError(err).Errorf("ReserveVoucher#h.VoucherRepo.AddPooledVouchers, voucher %s is not reserved", reserved.Code)
+	}
+
+	return &reserved, nil
+}
+
+// ReleaseVoucher drops the reservation of the winner once the code is saved on the winner.
+func (h *VoucherService) ReleaseVoucher(ctx context.Context, winnerId int) {
+	err := h.VoucherRepo.RemoveReservedVoucher(ctx, winnerId)
+	if err != nil {
+		logger.GetLoggerFromContext(ctx).WithField("winnerId", winnerId).WithError(err).Error("ReleaseVoucher#h.VoucherRepo.RemoveReservedVoucher")
+	}
+}
+
+// HasReservedVoucher reports whether a voucher is reserved for the winner.
+func (h *VoucherService) HasReservedVoucher(ctx context.Context, winnerId int) (bool, error) {
+	_, err := h.VoucherRepo.GetReservedVoucher(ctx, winnerId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// GetVoucher returns the current state of an issued voucher.
+func (h *VoucherService) GetVoucher(ctx context.Context, code string) (*entities.Voucher, error) {
+	return h.CsrvClient.GetVoucher(ctx, code)
+}
+
+// VoucherProfiles returns the distinct voucher settings used by the scheduled giveaways of all guilds.
+// Custom giveaways set their own value and are not pooled.
+func (h *VoucherService) VoucherProfiles(ctx context.Context) []entities.VoucherProfile {
+	profiles := []entities.VoucherProfile{h.DefaultVoucherConfig("", "").Profile()}
+	voucherConfigs, err := h.ServerRepo.GetVoucherConfigs(ctx)
+	if err != nil {
+		logger.GetLoggerFromContext(ctx).WithError(err).Error("VoucherProfiles#h.ServerRepo.GetVoucherConfigs")
+	}
+
+	for _, voucherConfig := range voucherConfigs {
+		if voucherConfig.GiveawayType == entities.CustomGiveawayType || slices.Contains(profiles, voucherConfig.Profile()) {
+			continue
+		}
+		profiles = append(profiles, voucherConfig.Profile())
+	}
+
+	return profiles
+}
+
+// RunVoucherPool keeps the voucher pool filled, every interval or right after a voucher is taken, until ctx is done.
+func (h *VoucherService) RunVoucherPool(ctx context.Context, s discord.Session, interval time.Duration) {
+	if !h.VoucherPool.Enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		h.VoucherPool.Refill(ctx, s, h.VoucherProfiles(ctx))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-h.VoucherPool.refill:
+		}
+	}
+}
@@ -16,18 +16,20 @@ import (
 
 type GuildCreateListener struct {
 	//GiveawaysRepo    entities.GiveawaysRepo // unused
-	ServerRepo       entities.ServerRepo
-	GiveawayService  services.GiveawayService
-	HelperService    services.HelperService
-	SavedRoleService services.SavedroleService
+	ServerRepo        entities.ServerRepo
+	GiveawayService   services.GiveawayService
+	HelperService     services.HelperService
+	SavedRoleService  services.SavedroleService
+	GiveawayScheduler *services.GiveawayScheduler
 }
 
-func NewGuildCreateListener(serverRepo entities.ServerRepo, giveawayService *services.GiveawayService, helperService *services.HelperService, savedRoleService *services.SavedroleService) GuildCreateListener {
+func NewGuildCreateListener(serverRepo entities.ServerRepo, giveawayService *services.GiveawayService, helperService *services.HelperService, savedRoleService *services.SavedroleService, giveawayScheduler *services.GiveawayScheduler) GuildCreateListener {
 	return GuildCreateListener{
-		ServerRepo:       serverRepo,
-		GiveawayService:  *giveawayService,
-		HelperService:    *helperService,
-		SavedRoleService: *savedRoleService,
+		ServerRepo:        serverRepo,
+		GiveawayService:   *giveawayService,
+		HelperService:     *helperService,
+		SavedRoleService:  *savedRoleService,
+		GiveawayScheduler: giveawayScheduler,
 	}
 }
 
@@ -51,6 +53,9 @@ func (h GuildCreateListener) Handle(s *discordgo.Session, g *discordgo.GuildCrea
 	log.Debug("Creating missing conditional giveaways for guild")
 	h.GiveawayService.CreateJoinableGiveaway(ctx, s, g.Guild, true)
 
+	log.Debug("Scheduling giveaways for guild")
+	h.GiveawayScheduler.ScheduleGuild(ctx, s, g.Guild.ID)
+
 	log.Debug("Updating all members saved roles for guild")
 	h.updateAllMembersSavedRoles(ctx, s, g.Guild.ID)
 
@@ -0,0 +1,31 @@
+package listeners
+
+import (
+	"csrvbot/internal/services"
+	"csrvbot/pkg"
+	"csrvbot/pkg/logger"
+	"github.com/bwmarrin/discordgo"
+)
+
+type GuildDeleteListener struct {
+	GiveawayScheduler *services.GiveawayScheduler
+}
+
+func NewGuildDeleteListener(giveawayScheduler *services.GiveawayScheduler) GuildDeleteListener {
+	return GuildDeleteListener{
+		GiveawayScheduler: giveawayScheduler,
+	}
+}
+
+func (h GuildDeleteListener) Handle(s *discordgo.Session, g *discordgo.GuildDelete) {
+	ctx := pkg.CreateContext()
+	log := logger.GetLoggerFromContext(ctx).WithGuild(g.ID)
+	// An outage makes the guild unavailable for a while, its giveaways keep their schedule
+	if g.Unavailable {
+		log.Warn("Guild became unavailable")
+		return
+	}
+
+	log.Info("Removed from guild, unscheduling its giveaways")
+	h.GiveawayScheduler.UnscheduleGuild(g.ID)
+}
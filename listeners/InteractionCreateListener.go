@@ -22,33 +22,41 @@ type InteractionCreateListener struct {
 	ThxmeCommand    commands.ThxmeCommand
 	CsrvbotCommand  commands.CsrvbotCommand
 	DocCommand      commands.DocCommand
-	ResendCommand   commands.ResendCommand
+	WinsCommand     commands.WinsCommand
+	RankingCommand  commands.RankingCommand
+	ProfileCommand  commands.ProfileCommand
 	StatusCommand   commands.StatusCommand
-	GiveawayHours   string
 	CraftserveUrl   string
 	GiveawaysRepo   entities.GiveawaysRepo
 	//MessageGiveawayRepo  entities.MessageGiveawayRepo
-	ServerRepo    entities.ServerRepo
-	HelperService services.HelperService
-	VoucherValue  int
+	ServerRepo         entities.ServerRepo
+	HelperService      services.HelperService
+	VoucherService     *services.VoucherService
+	GiveawayService    *services.GiveawayService
+	ThxAbuseService    *services.ThxAbuseService
+	EligibilityService *services.EligibilityService
 	//JoinableGiveawayRepo entities.JoinableGiveawayRepo
 }
 
-func NewInteractionCreateListener(giveawayCommand commands.GiveawayCommand, thxCommand commands.ThxCommand, thxmeCommand commands.ThxmeCommand, csrvbotCommand commands.CsrvbotCommand, docCommand commands.DocCommand, resendCommand commands.ResendCommand, statusCommand commands.StatusCommand, giveawayHours, craftserveUrl string, giveawaysRepo entities.GiveawaysRepo, serverRepo entities.ServerRepo, helperService *services.HelperService, voucherValue int) InteractionCreateListener {
+func NewInteractionCreateListener(giveawayCommand commands.GiveawayCommand, thxCommand commands.ThxCommand, thxmeCommand commands.ThxmeCommand, csrvbotCommand commands.CsrvbotCommand, docCommand commands.DocCommand, winsCommand commands.WinsCommand, rankingCommand commands.RankingCommand, profileCommand commands.ProfileCommand, statusCommand commands.StatusCommand, craftserveUrl string, giveawaysRepo entities.GiveawaysRepo, serverRepo entities.ServerRepo, helperService *services.HelperService, voucherService *services.VoucherService, giveawayService *services.GiveawayService, thxAbuseService *services.ThxAbuseService, eligibilityService *services.EligibilityService) InteractionCreateListener {
 	return InteractionCreateListener{
-		GiveawayCommand: giveawayCommand,
-		ThxCommand:      thxCommand,
-		ThxmeCommand:    thxmeCommand,
-		CsrvbotCommand:  csrvbotCommand,
-		DocCommand:      docCommand,
-		ResendCommand:   resendCommand,
-		StatusCommand:   statusCommand,
-		GiveawayHours:   giveawayHours,
-		CraftserveUrl:   craftserveUrl,
-		GiveawaysRepo:   giveawaysRepo,
-		ServerRepo:      serverRepo,
-		HelperService:   *helperService,
-		VoucherValue:    voucherValue,
+		GiveawayCommand:    giveawayCommand,
+		ThxCommand:         thxCommand,
+		ThxmeCommand:       thxmeCommand,
+		CsrvbotCommand:     csrvbotCommand,
+		DocCommand:         docCommand,
+		WinsCommand:        winsCommand,
+		RankingCommand:     rankingCommand,
+		ProfileCommand:     profileCommand,
+		StatusCommand:      statusCommand,
+		CraftserveUrl:      craftserveUrl,
+		GiveawaysRepo:      giveawaysRepo,
+		ServerRepo:         serverRepo,
+		HelperService:      *helperService,
+		VoucherService:     voucherService,
+		GiveawayService:    giveawayService,
+		ThxAbuseService:    thxAbuseService,
+		EligibilityService: eligibilityService,
 	}
 }
 
@@ -92,8 +100,12 @@ func (h InteractionCreateListener) handleApplicationCommands(ctx context.Context
 		h.CsrvbotCommand.Handle(ctx, s, i)
 	case "status":
 		h.StatusCommand.Handle(ctx, s, i)
-	case "resend":
-		h.ResendCommand.Handle(ctx, s, i)
+	case "wins":
+		h.WinsCommand.Handle(ctx, s, i)
+	case "ranking":
+		h.RankingCommand.Handle(ctx, s, i)
+	case "profile":
+		h.ProfileCommand.Handle(ctx, s, i)
 	}
 }
 
@@ -110,6 +122,8 @@ func (h InteractionCreateListener) handleModalSubmit(ctx context.Context, s *dis
 	switch strings.Split(i.ModalSubmitData().CustomID, "_")[0] {
 	case "status":
 		h.StatusCommand.HandleModalSubmit(ctx, s, i)
+	case "customgiveaway":
+		h.CsrvbotCommand.HandleModalSubmit(ctx, s, i)
 	}
 }
 
@@ -123,6 +137,18 @@ func (h InteractionCreateListener) handleMessageComponents(ctx context.Context,
 		statusId = splittedCustomID[2]
 	}
 
+	switch splittedCustomID[0] {
+	case "wins":
+		h.WinsCommand.HandleMessageComponents(ctx, s, i)
+		return
+	case "ranking":
+		h.RankingCommand.HandleMessageComponents(ctx, s, i)
+		return
+	case "review", "audit":
+		h.CsrvbotCommand.HandleMessageComponents(ctx, s, i)
+		return
+	}
+
 	switch i.MessageComponentData().CustomID {
 	case "thxwinnercode":
 		log.Debug("User clicked thxwinnercode button")
@@ -144,6 +170,11 @@ func (h InteractionCreateListener) handleMessageComponents(ctx context.Context,
 			return
 		}
 
+		if code == "" {
+			discord.RespondWithEphemeralMessage(ctx, s, i, "Twój kod nie został jeszcze wygenerowany, wyślemy go w wiadomości prywatnej, gdy tylko będzie gotowy.")
+			return
+		}
+
 		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -175,6 +206,11 @@ func (h InteractionCreateListener) handleMessageComponents(ctx context.Context,
 			return
 		}
 
+		if len(codes) == 0 {
+			discord.RespondWithEphemeralMessage(ctx, s, i, "Twoje kody nie zostały jeszcze wygenerowany, wyślemy je w wiadomości prywatnej, gdy tylko będą gotowe.")
+			return
+		}
+
 		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -217,21 +253,41 @@ func (h InteractionCreateListener) handleMessageComponents(ctx context.Context,
 			}
 		}
 
+		serverConfig, err := h.ServerRepo.GetServerConfigForGuild(ctx, i.GuildID)
+		if err != nil {
+			log.WithError(err).Errorf("handleMessageComponents#ServerRepo.GetServerConfigForGuild: %v", err)
+			return
+		}
+
 		memberLevel, err := discord.GetMemberLevel(ctx, s, i.Member, i.GuildID)
 		if err != nil {
 			log.WithError(err).Error("Could not get member level")
 			return
 		}
 
-		if giveaway.Level != nil {
-			if memberLevel < *giveaway.Level {
-				log.Debug("User does not have required level")
-				discord.RespondWithEphemeralMessage(ctx, s, i, "Nie masz wymaganego poziomu, żeby wziąć udział w tym giveawayu!")
+		var customGiveaway *entities.CustomGiveaway
+		requirements := entities.EligibilityRequirements{Level: giveaway.Level}
+		if giveaway.Type == entities.CustomGiveawayType {
+			customGiveaway, err = h.GiveawaysRepo.GetCustomGiveaway(ctx, giveaway.Id)
+			if err != nil {
+				log.WithError(err).Errorf("handleMessageComponents#GiveawaysRepo.GetCustomGiveaway: %v", err)
 				return
 			}
+			requirements.RoleId = customGiveaway.RequiredRoleId
+		}
+
+		ineligibility, err := h.EligibilityService.CheckEligibility(ctx, s, serverConfig, i.Member, requirements)
+		if err != nil {
+			log.WithError(err).Errorf("handleMessageComponents#EligibilityService.CheckEligibility: %v", err)
+			return
+		}
+		if ineligibility != nil {
+			log.Debugf("User is not eligible: %s", ineligibility.Reason)
+			discord.RespondWithEphemeralMessage(ctx, s, i, discord.IneligibilityMessage(ineligibility, i.Locale, true))
+			return
 		}
 
-		err = h.GiveawaysRepo.InsertParticipant(ctx, giveaway.Id, memberLevel, i.Member.GuildID, i.Member.User.ID, i.Member.User.Username, &i.Message.ID, nil)
+		err = h.GiveawaysRepo.InsertParticipant(ctx, giveaway.Id, memberLevel, i.Member.GuildID, i.Member.User.ID, i.Member.User.Username, &i.Message.ID, nil, nil)
 		if err != nil {
 			log.WithError(err).Errorf("handleMessageComponents#GiveawaysRepo.InsertParticipant: %v", err)
 			return
@@ -249,17 +305,23 @@ func (h InteractionCreateListener) handleMessageComponents(ctx context.Context,
 			return
 		}
 
-		var embed *discordgo.MessageEmbed
+		var levelRoleId *string
 		if giveaway.Level != nil {
 			levelRole, err := discord.GetRoleForLevel(ctx, s, i.GuildID, *giveaway.Level)
 			if err != nil {
 				log.WithError(err).Error("Could not get role for level")
 				return
 			}
-			embed = discord.ConstructJoinableGiveawayEmbed(h.CraftserveUrl, participantsCount, &levelRole.ID)
+			levelRoleId = &levelRole.ID
+		}
+
+		var embed *discordgo.MessageEmbed
+		if customGiveaway != nil {
+			embed = discord.ConstructCustomGiveawayEmbed(h.CraftserveUrl, customGiveaway, participantsCount, levelRoleId, false)
 		} else {
-			embed = discord.ConstructJoinableGiveawayEmbed(h.CraftserveUrl, participantsCount, nil)
+			embed = discord.ConstructJoinableGiveawayEmbed(h.CraftserveUrl, participantsCount, levelRoleId)
 		}
+		discord.SetSeedHashFooter(embed, giveaway.Id, giveaway.SeedHash)
 
 		_, err = s.ChannelMessageEditEmbed(i.ChannelID, i.Message.ID, embed)
 		if err != nil {
@@ -288,6 +350,11 @@ func (h InteractionCreateListener) handleMessageComponents(ctx context.Context,
 			return
 		}
 
+		if code == "" {
+			discord.RespondWithEphemeralMessage(ctx, s, i, "Twój kod nie został jeszcze wygenerowany, wyślemy go w wiadomości prywatnej, gdy tylko będzie gotowy.")
+			return
+		}
+
 		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -364,121 +431,21 @@ func (h InteractionCreateListener) handleAcceptDeclineButtons(ctx context.Contex
 			return
 		}
 
-		thxNotification, notificationErr := h.GiveawaysRepo.GetThxNotification(ctx, i.Message.ID)
-		if notificationErr != nil && !errors.Is(notificationErr, sql.ErrNoRows) {
-			log.WithError(notificationErr).Errorf("handleAcceptDeclineButtons#h.GiveawaysRepo.GetThxNotification: %v", notificationErr)
+		log.Debugf("User clicked %s button, updating participant...", componentId)
+		accept := componentId == "accept"
+		err = h.GiveawayService.ReviewThx(ctx, s, serverConfig, participant, member.User.ID, member.User.Username, accept)
+		if err != nil {
+			log.WithError(err).Errorf("handleAcceptDeclineButtons#h.GiveawayService.ReviewThx: %v", err)
 			return
 		}
-
-		switch componentId {
-		case "accept":
-			log.Debug("User clicked accept button, updating participant...")
-			err = h.GiveawaysRepo.UpdateParticipant(ctx, participant, member.User.ID, member.User.Username, true)
-			if err != nil {
-				log.WithError(err).Errorf("handleAcceptDeclineButtons#h.GiveawaysRepo.UpdateParticipant: %v", err)
-				return
-			}
+		if accept {
 			discord.RespondWithEphemeralMessage(ctx, s, i, "Udział użytkownika został potwierdzony!")
-			log.Infof("%s accepted %s participation in giveaway %d", member.User.Username, participant.UserName, participant.GiveawayId)
-
-			accepted := true
-			participants, err := h.GiveawaysRepo.GetParticipantsForGiveaway(ctx, participant.GiveawayId, &accepted)
-			if err != nil {
-				log.WithError(err).Errorf("handleAcceptDeclineButtons#h.GiveawaysRepo.GetParticipantNamesForGiveaway: %v", err)
-				return
-			}
-
-			var participantsNames []string
-			for _, p := range participants {
-				participantsNames = append(participantsNames, p.UserName)
-			}
-
-			embed := discord.ConstructThxEmbed(h.CraftserveUrl, participantsNames, h.GiveawayHours, participant.UserId, member.User.ID, "confirm", h.VoucherValue)
-
-			_, err = s.ChannelMessageEditEmbed(i.ChannelID, i.Message.ID, embed)
-			if err != nil {
-				log.WithError(err).Errorf("handleAcceptDeclineButtons#session.ChannelMessageEditEmbed: %v", err)
-				return
-			}
-
-			if errors.Is(notificationErr, sql.ErrNoRows) {
-				notificationMessageId, err := discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, "", i.GuildID, i.ChannelID, i.Message.ID, participant.UserId, member.User.ID, "confirm", h.CraftserveUrl)
-				if err != nil {
-					log.WithError(err).Error("Could not notify thx on thx info channel")
-					return
-				}
-
-				log.Debug("Inserting thx notification...")
-				err = h.GiveawaysRepo.InsertThxNotification(ctx, i.Message.ID, notificationMessageId)
-				if err != nil {
-					log.WithError(err).Error("Could not insert thx notification")
-					return
-				}
-			} else {
-				_, err = discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, thxNotification.NotificationMessageId, i.GuildID, i.ChannelID, i.Message.ID, participant.UserId, member.User.ID, "confirm", h.CraftserveUrl)
-				if err != nil {
-					log.WithError(err).Error("Could not notify thx on thx info channel")
-					return
-				}
-			}
-
-			log.Debug("Checking if helper role should be given to participant...")
-			h.HelperService.CheckHelper(ctx, s, i.GuildID, participant.UserId)
-		case "reject":
-			log.Debug("User clicked reject button, updating participant...")
-			err := h.GiveawaysRepo.UpdateParticipant(ctx, participant, member.User.ID, member.User.Username, false)
-			if err != nil {
-				log.WithError(err).Errorf("handleAcceptDeclineButtons#h.GiveawaysRepo.UpdateParticipant: %v", err)
-				return
-			}
+		} else {
 			discord.RespondWithEphemeralMessage(ctx, s, i, "Udział użytkownika został odrzucony!")
-			log.Infof("%s rejected %s participation in giveaway %d", member.User.Username, participant.UserName, participant.GiveawayId)
-
-			accepted := true
-			participants, err := h.GiveawaysRepo.GetParticipantsForGiveaway(ctx, participant.GiveawayId, &accepted)
-			if err != nil {
-				log.WithError(err).Errorf("handleAcceptDeclineButtons#h.GiveawaysRepo.GetParticipantNamesForGiveaway: %v", err)
-				return
-			}
-
-			var participantsNames []string
-			for _, p := range participants {
-				participantsNames = append(participantsNames, p.UserName)
-			}
-
-			embed := discord.ConstructThxEmbed(h.CraftserveUrl, participantsNames, h.GiveawayHours, participant.UserId, member.User.ID, "reject", h.VoucherValue)
-
-			_, err = s.ChannelMessageEditEmbed(i.ChannelID, i.Message.ID, embed)
-			if err != nil {
-				log.WithError(err).Errorf("handleAcceptDeclineButtons#session.ChannelMessageEditEmbed: %v", err)
-				return
-			}
-
-			if errors.Is(notificationErr, sql.ErrNoRows) {
-				notificationMessageId, err := discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, "", i.GuildID, i.ChannelID, i.Message.ID, participant.UserId, member.User.ID, "reject", h.CraftserveUrl)
-				if err != nil {
-					log.WithError(err).Error("Could not notify thx on thx info channel")
-					return
-				}
-
-				log.Debug("Inserting thx notification...")
-				err = h.GiveawaysRepo.InsertThxNotification(ctx, i.Message.ID, notificationMessageId)
-				if err != nil {
-					log.WithError(err).Error("Could not insert thx notification")
-					return
-				}
-			} else {
-				_, err = discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, thxNotification.NotificationMessageId, i.GuildID, i.ChannelID, i.Message.ID, participant.UserId, member.User.ID, "reject", h.CraftserveUrl)
-				if err != nil {
-					log.WithError(err).Error("Could not notify thx on thx info channel")
-					return
-				}
-			}
-
-			log.Debug("Checking if helper role should be given to participant...")
-			h.HelperService.CheckHelper(ctx, s, i.GuildID, participant.UserId)
 		}
 
+		log.Debug("Checking if helper role should be given to participant...")
+		h.HelperService.CheckHelper(ctx, s, i.GuildID, participant.UserId)
 	} else if isThxmeMessage {
 		log.Debug("Message is a thxme message")
 		candidate, err := h.GiveawaysRepo.GetParticipantCandidate(ctx, i.Message.ID)
@@ -493,10 +460,45 @@ func (h InteractionCreateListener) handleAcceptDeclineButtons(ctx context.Contex
 			return
 		}
 
+		if candidate.IsAccepted.Valid {
+			log.Debug("Candidate was already answered or expired")
+			discord.RespondWithEphemeralMessage(ctx, s, i, "Ta prośba została już rozpatrzona lub wygasła!")
+			return
+		}
+
 		switch componentId {
 		case "accept":
+			candidateMember, err := s.GuildMember(i.GuildID, candidate.CandidateId)
+			if err != nil {
+				log.WithError(err).Errorf("handleAcceptDeclineButtons#session.GuildMember: %v", err)
+				return
+			}
+			ineligibility, err := h.EligibilityService.CheckEligibility(ctx, s, serverConfig, candidateMember, entities.EligibilityRequirements{})
+			if err != nil {
+				log.WithError(err).Errorf("handleAcceptDeclineButtons#h.EligibilityService.CheckEligibility: %v", err)
+				return
+			}
+			if ineligibility != nil {
+				log.Debugf("Candidate is not eligible: %s", ineligibility.Reason)
+				discord.RespondWithEphemeralMessage(ctx, s, i, discord.IneligibilityMessage(ineligibility, i.Locale, false))
+				return
+			}
+
+			// The approver gives the thx, so the thx limits apply to them
+			limit, releaseLimits, err := h.ThxAbuseService.CheckThxLimits(ctx, serverConfig, member.User.ID, candidate.CandidateId)
+			if err != nil {
+				log.WithError(err).Errorf("handleAcceptDeclineButtons#h.ThxAbuseService.CheckThxLimits: %v", err)
+				return
+			}
+			defer releaseLimits()
+			if limit != "" {
+				log.Debugf("Thx limit %s hit", limit)
+				discord.RespondWithEphemeralMessage(ctx, s, i, discord.ThxLimitMessage(limit, serverConfig))
+				return
+			}
+
 			log.Debug("User clicked accept button, updating participant candidate...")
-			err := h.GiveawaysRepo.UpdateParticipantCandidate(ctx, &candidate, true)
+			err = h.GiveawaysRepo.UpdateParticipantCandidate(ctx, &candidate, true)
 			if err != nil {
 				log.WithError(err).Errorf("handleAcceptDeclineButtons#h.GiveawaysRepo.UpdateParticipantCandidate: %v", err)
 				return
@@ -516,12 +518,7 @@ func (h InteractionCreateListener) handleAcceptDeclineButtons(ctx context.Contex
 				return
 			}
 
-			var participantsNames []string
-			for _, p := range participants {
-				participantsNames = append(participantsNames, p.UserName)
-			}
-
-			embed := discord.ConstructThxEmbed(h.CraftserveUrl, participantsNames, h.GiveawayHours, candidate.CandidateId, "", "wait", h.VoucherValue)
+			embed := discord.ConstructThxEmbed(h.CraftserveUrl, serverConfig, participants, candidate.CandidateId, "", "wait", h.VoucherService.GetVoucherConfig(ctx, serverConfig.GuildId, entities.ThxGiveawayType))
 
 			content := "Prośba o podziękowanie zaakceptowana przez: " + member.User.Mention()
 			_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
@@ -550,7 +547,7 @@ func (h InteractionCreateListener) handleAcceptDeclineButtons(ctx context.Contex
 			}
 
 			//err = h.GiveawaysRepo.InsertParticipant(ctx, giveaway.Id, guild.ID, guild.Name, candidate.CandidateId, candidate.CandidateName, i.ChannelID, i.Message.ID)
-			err = h.GiveawaysRepo.InsertParticipant(ctx, giveaway.Id, memberLevel, guild.ID, candidate.CandidateId, candidate.CandidateName, &i.Message.ID, &i.ChannelID)
+			err = h.GiveawaysRepo.InsertParticipant(ctx, giveaway.Id, memberLevel, guild.ID, candidate.CandidateId, candidate.CandidateName, &i.Message.ID, &i.ChannelID, &member.User.ID)
 			if err != nil {
 				log.WithError(err).Errorf("handleAcceptDeclineButtons#h.GiveawaysRepo.InsertParticipant: %v", err)
 				str := "Coś poszło nie tak przy dodawaniu podziękowania :("
@@ -565,14 +562,16 @@ func (h InteractionCreateListener) handleAcceptDeclineButtons(ctx context.Contex
 
 			log.Infof("%s thanked %s", member.User.Username, candidate.CandidateName)
 
+			flags := h.ThxAbuseService.Check(ctx, s, i.GuildID, i.ChannelID, i.Message.ID, member.User.ID, candidate.CandidateId)
+
 			thxNotification, err := h.GiveawaysRepo.GetThxNotification(ctx, i.Message.ID)
 			if err != nil && !errors.Is(err, sql.ErrNoRows) {
-				log.WithError(err).Error("Could not get thx notification for message %s", i.Message.ID)
+				log.WithError(err).Errorf("Could not get thx notification for message %s", i.Message.ID)
 				return
 			}
 
 			if errors.Is(err, sql.ErrNoRows) {
-				notificationMessageId, err := discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, "", i.GuildID, i.ChannelID, i.Message.ID, candidate.CandidateId, "", "wait", h.CraftserveUrl)
+				notificationMessageId, err := discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, "", i.GuildID, i.ChannelID, i.Message.ID, candidate.CandidateId, "", "wait", h.CraftserveUrl, flags)
 				if err != nil {
 					log.WithError(err).Error("Could not notify thx on thx info channel")
 					return
@@ -585,7 +584,7 @@ func (h InteractionCreateListener) handleAcceptDeclineButtons(ctx context.Contex
 					return
 				}
 			} else {
-				_, err = discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, thxNotification.NotificationMessageId, i.GuildID, i.ChannelID, i.Message.ID, candidate.CandidateId, "", "wait", h.CraftserveUrl)
+				_, err = discord.NotifyThxOnThxInfoChannel(s, serverConfig.ThxInfoChannel, thxNotification.NotificationMessageId, i.GuildID, i.ChannelID, i.Message.ID, candidate.CandidateId, "", "wait", h.CraftserveUrl, flags)
 				if err != nil {
 					log.WithError(err).Error("Could not notify thx on thx info channel")
 					return
@@ -0,0 +1,329 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"csrvbot/pkg/logger"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const migrationsLockName = "csrvbot_schema_migrations"
+
+var migrationFileRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+	Modified  bool
+}
+
+type appliedMigration struct {
+	Version   int
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// LoadMigrations reads the embedded migrations ordered by version.
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileRegexp.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, err
+		}
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+			checksum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(checksum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d is missing its up or down file", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Status lists every known migration with the time it was applied, if it was.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer m.unlock(ctx, conn)
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Migration: migration}
+		if appliedMigration, ok := applied[migration.Version]; ok {
+			appliedAt := appliedMigration.AppliedAt
+			status.AppliedAt = &appliedAt
+			status.Modified = appliedMigration.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Up applies all pending migrations and returns how many were applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	return m.up(ctx, 0)
+}
+
+// up applies at most limit pending migrations, or all of them if limit is 0.
+func (m *Migrator) up(ctx context.Context, limit int) (int, error) {
+	log := logger.GetLoggerFromContext(ctx)
+	conn, err := m.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer m.unlock(ctx, conn)
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if limit > 0 && count == limit {
+			break
+		}
+		if appliedMigration, ok := applied[migration.Version]; ok {
+			if appliedMigration.Checksum != migration.Checksum {
+				return count, fmt.Errorf("migration %04d_%s was modified after being applied", migration.Version, migration.Name)
+			}
+			continue
+		}
+
+		log.WithField("version", migration.Version).Infof("Applying migration %s", migration.Name)
+		err := execStatements(ctx, conn, migration.Up)
+		if err != nil {
+			return count, fmt.Errorf("could not apply migration %04d_%s %w", migration.Version, migration.Name, err)
+		}
+
+		_, err = conn.ExecContext(ctx, "INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)", migration.Version, migration.Name, migration.Checksum, time.Now())
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Down reverts the most recently applied migration. It returns nil if no migration was applied.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	log := logger.GetLoggerFromContext(ctx)
+	conn, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer m.unlock(ctx, conn)
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		log.WithField("version", migration.Version).Infof("Reverting migration %s", migration.Name)
+		err := execStatements(ctx, conn, migration.Down)
+		if err != nil {
+			return nil, fmt.Errorf("could not revert migration %04d_%s %w", migration.Version, migration.Name, err)
+		}
+
+		_, err = conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+		if err != nil {
+			return nil, err
+		}
+		return &migration, nil
+	}
+
+	return nil, nil
+}
+
+// Redo reverts the most recently applied migration and applies it again.
+func (m *Migrator) Redo(ctx context.Context) (*Migration, error) {
+	migration, err := m.Down(ctx)
+	if err != nil || migration == nil {
+		return nil, err
+	}
+
+	_, err = m.up(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return migration, nil
+}
+
+func (m *Migrator) lock(ctx context.Context) (*sql.Conn, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var locked sql.NullInt64
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 60)", migrationsLockName).Scan(&locked)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if locked.Int64 != 1 {
+		_ = conn.Close()
+		return nil, errors.New("could not acquire migrations lock")
+	}
+
+	_, err = conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version int NOT NULL PRIMARY KEY, name varchar(255) NOT NULL, checksum char(64) NOT NULL, applied_at datetime NOT NULL) ENGINE = InnoDB CHARSET = UTF8MB4")
+	if err != nil {
+		m.unlock(ctx, conn)
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (m *Migrator) unlock(ctx context.Context, conn *sql.Conn) {
+	_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", migrationsLockName)
+	if err != nil {
+		logger.GetLoggerFromContext(ctx).WithError(err).Error("Migrator.unlock#conn.ExecContext")
+	}
+	_ = conn.Close()
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var migration appliedMigration
+		err := rows.Scan(&migration.Version, &migration.Checksum, &migration.AppliedAt)
+		if err != nil {
+			return nil, err
+		}
+		applied[migration.Version] = migration
+	}
+
+	return applied, rows.Err()
+}
+
+// execStatements runs a migration file statement by statement, as the driver is not configured for multi statements.
+func execStatements(ctx context.Context, conn *sql.Conn, script string) error {
+	for _, statement := range splitStatements(script) {
+		_, err := conn.ExecContext(ctx, statement)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if strings.TrimSpace(current.String()) != "" {
+		statements = append(statements, strings.TrimSpace(current.String()))
+	}
+
+	return statements
+}
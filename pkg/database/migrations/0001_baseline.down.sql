@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS `status`;
+DROP TABLE IF EXISTS `daily_user_messages`;
+DROP TABLE IF EXISTS `thx_notifications`;
+DROP TABLE IF EXISTS `giveaway_winners`;
+DROP TABLE IF EXISTS `thx_participant_candidates`;
+DROP TABLE IF EXISTS `giveaway_participants`;
+DROP TABLE IF EXISTS `giveaways`;
+DROP TABLE IF EXISTS `helper_blacklists`;
+DROP TABLE IF EXISTS `member_roles`;
+DROP TABLE IF EXISTS `blacklists`;
+DROP TABLE IF EXISTS `server_configs`;
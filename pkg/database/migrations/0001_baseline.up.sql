@@ -0,0 +1,112 @@
+-- Mirrors the schema previously created by gorp CreateTablesIfNotExists,
+-- so existing databases can adopt migrations without changes.
+CREATE TABLE IF NOT EXISTS `server_configs` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `guild_id` varchar(255),
+    `admin_role_id` varchar(255),
+    `status_channel` mediumblob,
+    `main_channel` varchar(255),
+    `thx_info_channel` varchar(255),
+    `helper_role_id` varchar(255),
+    `helper_role_thxes_needed` int,
+    `message_giveaway_winners` int DEFAULT 0,
+    `unconditional_giveaway_channel` varchar(255),
+    `unconditional_giveaway_winners` int DEFAULT 0,
+    `conditional_giveaway_channel` varchar(255),
+    `conditional_giveaway_winners` int DEFAULT 0,
+    `conditional_giveaway_levels` mediumblob
+) ENGINE = InnoDB CHARSET = UTF8MB4;
+
+CREATE TABLE IF NOT EXISTS `blacklists` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `guild_id` varchar(255),
+    `user_id` varchar(255),
+    `blacklister_id` varchar(255),
+    UNIQUE (`guild_id`, `user_id`)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
+
+CREATE TABLE IF NOT EXISTS `member_roles` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `guild_id` varchar(255),
+    `member_id` varchar(255),
+    `role_id` varchar(255)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
+
+CREATE TABLE IF NOT EXISTS `helper_blacklists` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `guild_id` varchar(255),
+    `user_id` varchar(255),
+    `blacklister_id` varchar(255),
+    UNIQUE (`guild_id`, `user_id`)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
+
+CREATE TABLE IF NOT EXISTS `giveaways` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `type` varchar(255),
+    `start_time` datetime,
+    `end_time` datetime,
+    `guild_id` varchar(255),
+    `info_message_id` varchar(255),
+    `level` int
+) ENGINE = InnoDB CHARSET = UTF8MB4;
+
+CREATE TABLE IF NOT EXISTS `giveaway_participants` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `giveaway_id` int,
+    `guild_id` varchar(255),
+    `user_id` varchar(255),
+    `user_name` varchar(255),
+    `join_time` datetime,
+    `user_level` int,
+    `message_id` varchar(255),
+    `channel_id` varchar(255),
+    `is_accepted` tinyint,
+    `accept_time` datetime,
+    `accept_user` varchar(255),
+    `accept_user_id` varchar(255)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
+
+CREATE TABLE IF NOT EXISTS `thx_participant_candidates` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `candidate_id` varchar(255),
+    `candidate_name` varchar(255),
+    `candidate_approver_id` varchar(255),
+    `candidate_approver_name` varchar(255),
+    `giveaway_id` int,
+    `guild_id` varchar(255),
+    `guild_name` varchar(255),
+    `message_id` varchar(255),
+    `channel_id` varchar(255),
+    `is_accepted` tinyint,
+    `accept_time` datetime
+) ENGINE = InnoDB CHARSET = UTF8MB4;
+
+CREATE TABLE IF NOT EXISTS `giveaway_winners` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `giveaway_id` int,
+    `user_id` varchar(255),
+    `code` varchar(255)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
+
+CREATE TABLE IF NOT EXISTS `thx_notifications` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `thx_message_id` varchar(255),
+    `notification_message_id` varchar(255)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
+
+CREATE TABLE IF NOT EXISTS `daily_user_messages` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `user_id` varchar(255),
+    `day` varchar(255),
+    `guild_id` varchar(255),
+    `count` int,
+    UNIQUE (`day`, `user_id`, `guild_id`)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
+
+CREATE TABLE IF NOT EXISTS `status` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `guild_id` varchar(255),
+    `type` varchar(50),
+    `short_name` varchar(100),
+    `content` mediumblob
+) ENGINE = InnoDB CHARSET = UTF8MB4;
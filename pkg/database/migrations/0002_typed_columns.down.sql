@@ -0,0 +1,3 @@
+ALTER TABLE `server_configs` MODIFY `status_channel` mediumblob, MODIFY `conditional_giveaway_levels` mediumblob;
+
+ALTER TABLE `daily_user_messages` MODIFY `day` varchar(255);
@@ -0,0 +1,6 @@
+ALTER TABLE `daily_user_messages` MODIFY `day` date NOT NULL;
+
+ALTER TABLE `server_configs` MODIFY `status_channel` longtext CHARACTER SET utf8mb4, MODIFY `conditional_giveaway_levels` longtext CHARACTER SET utf8mb4;
+UPDATE `server_configs` SET `status_channel` = '{}' WHERE `status_channel` IS NULL OR JSON_VALID(`status_channel`) = 0;
+UPDATE `server_configs` SET `conditional_giveaway_levels` = '[]' WHERE `conditional_giveaway_levels` IS NULL OR JSON_VALID(`conditional_giveaway_levels`) = 0;
+ALTER TABLE `server_configs` MODIFY `status_channel` json NOT NULL, MODIFY `conditional_giveaway_levels` json NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE `giveaway_winners` DROP INDEX `draw_id`, DROP `draw_id`, DROP `user_name`, DROP `notified`;
+
+DROP TABLE IF EXISTS `giveaway_draws`;
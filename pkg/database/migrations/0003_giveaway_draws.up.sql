@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS `giveaway_draws` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `giveaway_id` int NOT NULL,
+    `guild_id` varchar(255) NOT NULL,
+    `state` varchar(20) NOT NULL,
+    `start_time` datetime NOT NULL,
+    `end_time` datetime,
+    `announcement_message_id` varchar(255),
+    UNIQUE (`giveaway_id`),
+    INDEX (`state`)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
+
+-- Winners recorded before this migration were already notified.
+ALTER TABLE `giveaway_winners`
+    ADD `draw_id` int,
+    ADD `user_name` varchar(255) NOT NULL DEFAULT '',
+    ADD `notified` boolean NOT NULL DEFAULT TRUE,
+    ADD INDEX (`draw_id`);
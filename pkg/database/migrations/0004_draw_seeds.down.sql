@@ -0,0 +1,3 @@
+ALTER TABLE `giveaway_draws` DROP `participants`, DROP `skipped`;
+
+ALTER TABLE `giveaways` DROP `seed`, DROP `seed_hash`;
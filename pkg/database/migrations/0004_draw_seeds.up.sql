@@ -0,0 +1,11 @@
+ALTER TABLE `giveaways`
+    ADD `seed` varchar(64),
+    ADD `seed_hash` char(64);
+
+-- Running giveaways get a seed now, finished ones stay without one.
+UPDATE `giveaways` SET `seed` = SHA2(CONCAT(UUID(), RAND(), `id`), 256) WHERE `end_time` IS NULL;
+UPDATE `giveaways` SET `seed_hash` = SHA2(`seed`, 256) WHERE `seed` IS NOT NULL;
+
+ALTER TABLE `giveaway_draws`
+    ADD `participants` json,
+    ADD `skipped` json;
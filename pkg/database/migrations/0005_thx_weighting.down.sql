@@ -0,0 +1 @@
+ALTER TABLE `server_configs` DROP COLUMN `thx_weighting`, DROP COLUMN `thx_weighting_cap`;
@@ -0,0 +1 @@
+ALTER TABLE `server_configs` ADD `thx_weighting` varchar(20) NOT NULL DEFAULT 'thx', ADD `thx_weighting_cap` int NOT NULL DEFAULT 5;
@@ -0,0 +1,14 @@
+-- Details of giveaways created by admins, the giveaway itself is a row in giveaways with type custom.
+CREATE TABLE IF NOT EXISTS `custom_giveaways` (
+    `giveaway_id` int NOT NULL PRIMARY KEY,
+    `guild_id` varchar(255) NOT NULL,
+    `title` varchar(255) NOT NULL,
+    `prize` text NOT NULL,
+    `winners_count` int NOT NULL,
+    `scheduled_end_time` datetime NOT NULL,
+    `channel_id` varchar(255) NOT NULL,
+    `required_level` int,
+    `required_role_id` varchar(255),
+    `voucher_value` int,
+    `created_by` varchar(255) NOT NULL
+) ENGINE = InnoDB CHARSET = UTF8MB4;
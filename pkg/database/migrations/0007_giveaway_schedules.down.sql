@@ -0,0 +1 @@
+ALTER TABLE `server_configs` DROP COLUMN `timezone`, DROP COLUMN `thx_giveaway_schedule`, DROP COLUMN `message_giveaway_schedule`, DROP COLUMN `unconditional_giveaway_schedule`, DROP COLUMN `conditional_giveaway_schedule`;
@@ -0,0 +1 @@
+ALTER TABLE `server_configs` ADD `timezone` varchar(64) NOT NULL DEFAULT 'Europe/Warsaw', ADD `thx_giveaway_schedule` varchar(255) NOT NULL DEFAULT '06:00,12:00,18:00', ADD `message_giveaway_schedule` varchar(255) NOT NULL DEFAULT '06:00,12:00,18:00', ADD `unconditional_giveaway_schedule` varchar(255) NOT NULL DEFAULT '12:00', ADD `conditional_giveaway_schedule` varchar(255) NOT NULL DEFAULT '18:00';
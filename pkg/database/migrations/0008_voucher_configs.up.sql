@@ -0,0 +1,12 @@
+-- Per guild and giveaway type overrides of the voucher settings from config.json.
+CREATE TABLE IF NOT EXISTS `voucher_configs` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `guild_id` varchar(255) NOT NULL,
+    `giveaway_type` varchar(20) NOT NULL,
+    `value` int NOT NULL,
+    `currency` char(3) NOT NULL DEFAULT 'PLN',
+    `expiration_days` int NOT NULL,
+    `prefix` varchar(32) NOT NULL,
+    `group_id` varchar(64) NOT NULL,
+    UNIQUE KEY `voucher_configs_guild_type` (`guild_id`, `giveaway_type`)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
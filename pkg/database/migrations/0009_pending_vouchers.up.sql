@@ -0,0 +1,21 @@
+-- Vouchers of giveaway winners which could not be generated yet, retried in the background until issued.
+CREATE TABLE IF NOT EXISTS `pending_vouchers` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `winner_id` int NOT NULL,
+    `giveaway_id` int NOT NULL,
+    `guild_id` varchar(255) NOT NULL,
+    `user_id` varchar(255) NOT NULL,
+    `giveaway_type` varchar(20) NOT NULL,
+    `value` int NOT NULL,
+    `currency` char(3) NOT NULL DEFAULT 'PLN',
+    `expiration_days` int NOT NULL,
+    `prefix` varchar(32) NOT NULL,
+    `group_id` varchar(64) NOT NULL,
+    `attempts` int NOT NULL DEFAULT 0,
+    `last_error` text NOT NULL,
+    `next_attempt_at` datetime NOT NULL,
+    `created_at` datetime NOT NULL,
+    `resolved_at` datetime NULL,
+    UNIQUE KEY `pending_vouchers_winner` (`winner_id`),
+    KEY `pending_vouchers_due` (`resolved_at`, `next_attempt_at`)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
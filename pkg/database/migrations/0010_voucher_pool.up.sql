@@ -0,0 +1,17 @@
+-- Pre-generated vouchers handed out to winners without calling the Craftserve API during the draw.
+-- Vouchers reserved for a winner stay in the pool until the code is saved on the winner, so an interrupted draw reuses them.
+CREATE TABLE IF NOT EXISTS `voucher_pool` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `code` varchar(255) NOT NULL,
+    `value` int NOT NULL,
+    `currency` char(3) NOT NULL DEFAULT 'PLN',
+    `expiration_days` int NOT NULL,
+    `prefix` varchar(32) NOT NULL,
+    `group_id` varchar(64) NOT NULL,
+    `created_at` datetime NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `winner_id` int,
+    UNIQUE KEY `voucher_pool_code` (`code`),
+    UNIQUE KEY `voucher_pool_winner` (`winner_id`),
+    KEY `voucher_pool_profile` (`value`, `currency`, `expiration_days`, `prefix`, `group_id`, `created_at`)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
@@ -0,0 +1,10 @@
+ALTER TABLE `server_configs`
+    DROP COLUMN `expired_voucher_policy`;
+
+ALTER TABLE `giveaway_winners`
+    DROP KEY `giveaway_winners_redemption`,
+    DROP COLUMN `replaces_winner_id`,
+    DROP COLUMN `status_checked_at`,
+    DROP COLUMN `redeemed_at`,
+    DROP COLUMN `expires_at`,
+    DROP COLUMN `redemption_status`;
@@ -0,0 +1,11 @@
+-- Redemption status of the vouchers given to winners, checked with the Craftserve admin API.
+ALTER TABLE `giveaway_winners`
+    ADD COLUMN `redemption_status` varchar(20) NOT NULL DEFAULT 'unknown',
+    ADD COLUMN `expires_at` datetime NULL,
+    ADD COLUMN `redeemed_at` datetime NULL,
+    ADD COLUMN `status_checked_at` datetime NULL,
+    ADD COLUMN `replaces_winner_id` int NULL,
+    ADD KEY `giveaway_winners_redemption` (`redemption_status`, `status_checked_at`);
+
+ALTER TABLE `server_configs`
+    ADD COLUMN `expired_voucher_policy` varchar(20) NOT NULL DEFAULT 'none';
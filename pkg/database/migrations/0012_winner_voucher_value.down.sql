@@ -0,0 +1,4 @@
+ALTER TABLE `giveaway_winners`
+    DROP KEY `giveaway_winners_user_id`,
+    DROP COLUMN `voucher_currency`,
+    DROP COLUMN `voucher_value`;
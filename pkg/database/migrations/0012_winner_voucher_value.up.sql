@@ -0,0 +1,5 @@
+-- Value of the voucher given to a winner, unknown for codes issued before it was recorded.
+ALTER TABLE `giveaway_winners`
+    ADD COLUMN `voucher_value` int NULL,
+    ADD COLUMN `voucher_currency` varchar(3) NULL,
+    ADD KEY `giveaway_winners_user_id` (`user_id`);
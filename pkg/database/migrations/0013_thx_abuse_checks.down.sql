@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `thx_abuse_checks`;
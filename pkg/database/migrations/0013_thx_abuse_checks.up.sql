@@ -0,0 +1,13 @@
+-- Who thanked whom with each thx and the abuse flags raised for it, used to detect farmed thanks.
+CREATE TABLE IF NOT EXISTS `thx_abuse_checks` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `guild_id` varchar(255) NOT NULL,
+    `message_id` varchar(255) NOT NULL,
+    `channel_id` varchar(255) NOT NULL,
+    `thanker_id` varchar(255) NOT NULL,
+    `recipient_id` varchar(255) NOT NULL,
+    `flags` varchar(255) NOT NULL DEFAULT '',
+    `created_at` datetime NOT NULL,
+    UNIQUE KEY `thx_abuse_checks_message` (`message_id`),
+    KEY `thx_abuse_checks_thanker` (`guild_id`, `thanker_id`, `created_at`)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `thx_limit_hits`;
+ALTER TABLE `server_configs` DROP COLUMN `thx_cooldown_minutes`, DROP COLUMN `thx_daily_limit`, DROP COLUMN `thxme_pending_limit`;
@@ -0,0 +1,16 @@
+-- Per guild limits of /thx and /thxme, 0 disables a limit.
+ALTER TABLE `server_configs`
+    ADD COLUMN `thx_cooldown_minutes` int NOT NULL DEFAULT 0,
+    ADD COLUMN `thx_daily_limit` int NOT NULL DEFAULT 0,
+    ADD COLUMN `thxme_pending_limit` int NOT NULL DEFAULT 0;
+
+-- Thanks and /thxme requests refused by the limits, listed for the admins.
+CREATE TABLE IF NOT EXISTS `thx_limit_hits` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `guild_id` varchar(255) NOT NULL,
+    `user_id` varchar(255) NOT NULL,
+    `target_user_id` varchar(255) NOT NULL,
+    `limit_type` varchar(20) NOT NULL,
+    `created_at` datetime NOT NULL,
+    KEY `thx_limit_hits_guild` (`guild_id`, `created_at`)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
@@ -0,0 +1,2 @@
+ALTER TABLE `thx_participant_candidates` DROP KEY `thx_participant_candidates_pending`, DROP COLUMN `created_at`;
+ALTER TABLE `server_configs` DROP COLUMN `thxme_expiry_hours`, DROP COLUMN `unreviewed_thx_policy`;
@@ -0,0 +1,14 @@
+-- Pending /thxme requests expire after thxme_expiry_hours, unreviewed thx of a finished giveaway are rejected or
+-- carried over to the next one.
+ALTER TABLE `server_configs`
+    ADD COLUMN `thxme_expiry_hours` int NOT NULL DEFAULT 24,
+    ADD COLUMN `unreviewed_thx_policy` varchar(20) NOT NULL DEFAULT 'reject';
+
+-- Existing requests get the creation time of their message, decoded from the Discord snowflake.
+ALTER TABLE `thx_participant_candidates` ADD COLUMN `created_at` datetime NULL;
+UPDATE `thx_participant_candidates`
+SET `created_at` = FROM_UNIXTIME(((CAST(`message_id` AS UNSIGNED) >> 22) + 1420070400000) / 1000)
+WHERE `created_at` IS NULL;
+ALTER TABLE `thx_participant_candidates`
+    MODIFY COLUMN `created_at` datetime NOT NULL,
+    ADD KEY `thx_participant_candidates_pending` (`is_accepted`, `created_at`);
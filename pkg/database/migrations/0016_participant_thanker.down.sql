@@ -0,0 +1 @@
+ALTER TABLE `giveaway_participants` DROP KEY `giveaway_participants_thanker`, DROP COLUMN `thanker_id`;
@@ -0,0 +1,17 @@
+-- The member who gave the thx, NULL for the giveaways joined without a thx and the thx given before it was recorded.
+-- Abuse detection and the thx limits count the thanks given by a member in a time window.
+ALTER TABLE `giveaway_participants`
+    ADD COLUMN `thanker_id` varchar(255) NULL,
+    ADD KEY `giveaway_participants_thanker` (`guild_id`, `thanker_id`, `join_time`);
+
+-- A thx requested with /thxme is given by the member who approved the request.
+UPDATE `giveaway_participants` p
+    JOIN `thx_participant_candidates` c ON c.`message_id` = p.`message_id`
+SET p.`thanker_id` = c.`candidate_approver_id`
+WHERE p.`thanker_id` IS NULL;
+
+-- The thx checked for abuse have their thanker recorded with the check.
+UPDATE `giveaway_participants` p
+    JOIN `thx_abuse_checks` a ON a.`message_id` = p.`message_id`
+SET p.`thanker_id` = a.`thanker_id`
+WHERE p.`thanker_id` IS NULL;
@@ -0,0 +1,10 @@
+ALTER TABLE `server_configs`
+    ADD COLUMN `helper_role_id` varchar(255),
+    ADD COLUMN `helper_role_thxes_needed` int,
+    DROP COLUMN `helper_thx_window_days`,
+    DROP COLUMN `helper_announcement_channel`;
+UPDATE `server_configs` s
+    JOIN `helper_tiers` t ON t.`guild_id` = s.`guild_id`
+SET s.`helper_role_id` = t.`role_id`, s.`helper_role_thxes_needed` = t.`thxes_needed`
+WHERE t.`thxes_needed` = (SELECT MIN(`thxes_needed`) FROM `helper_tiers` WHERE `guild_id` = s.`guild_id`);
+DROP TABLE IF EXISTS `helper_tiers`;
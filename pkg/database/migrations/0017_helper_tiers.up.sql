@@ -0,0 +1,21 @@
+-- The helper roles form a ladder, each role is given for more than thxes_needed accepted thanks.
+CREATE TABLE IF NOT EXISTS `helper_tiers` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `guild_id` varchar(255) NOT NULL,
+    `role_id` varchar(255) NOT NULL,
+    `thxes_needed` int NOT NULL,
+    UNIQUE KEY `helper_tiers_role` (`guild_id`, `role_id`)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
+
+-- The single helper role becomes the first tier of the ladder.
+INSERT INTO `helper_tiers` (`guild_id`, `role_id`, `thxes_needed`)
+SELECT `guild_id`, `helper_role_id`, `helper_role_thxes_needed`
+FROM `server_configs`
+WHERE `helper_role_id` IS NOT NULL AND `helper_role_id` != '' AND `helper_role_thxes_needed` > 0;
+
+-- helper_thx_window_days of 0 counts the thanks of all time.
+ALTER TABLE `server_configs`
+    DROP COLUMN `helper_role_id`,
+    DROP COLUMN `helper_role_thxes_needed`,
+    ADD COLUMN `helper_thx_window_days` int NOT NULL DEFAULT 0,
+    ADD COLUMN `helper_announcement_channel` varchar(255) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE `server_configs` DROP COLUMN `admin_log_channel`;
@@ -0,0 +1,2 @@
+-- An empty admin_log_channel disables the reports of scheduled jobs.
+ALTER TABLE `server_configs` ADD COLUMN `admin_log_channel` varchar(255) NOT NULL DEFAULT '';
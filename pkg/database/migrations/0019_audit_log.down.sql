@@ -0,0 +1,2 @@
+ALTER TABLE `server_configs` DROP COLUMN `audit_channel`;
+DROP TABLE IF EXISTS `audit_log`;
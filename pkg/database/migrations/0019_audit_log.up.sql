@@ -0,0 +1,17 @@
+-- Administrative actions, mirrored to the audit_channel of the guild when it is set.
+CREATE TABLE IF NOT EXISTS `audit_log` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `guild_id` varchar(255) NOT NULL,
+    `actor_id` varchar(255) NOT NULL,
+    `actor_name` varchar(255) NOT NULL,
+    `action` varchar(50) NOT NULL,
+    `target` varchar(255) NOT NULL DEFAULT '',
+    `before_value` text NOT NULL,
+    `after_value` text NOT NULL,
+    `created_at` datetime NOT NULL,
+    KEY `audit_log_guild` (`guild_id`, `created_at`),
+    KEY `audit_log_actor` (`guild_id`, `actor_id`),
+    KEY `audit_log_target` (`guild_id`, `target`)
+) ENGINE = InnoDB CHARSET = UTF8MB4;
+
+ALTER TABLE `server_configs` ADD COLUMN `audit_channel` varchar(255) NOT NULL DEFAULT '';
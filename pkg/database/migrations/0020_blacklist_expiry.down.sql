@@ -0,0 +1,2 @@
+ALTER TABLE `blacklists` DROP KEY `blacklists_expires_at`, DROP COLUMN `reason`, DROP COLUMN `created_at`, DROP COLUMN `expires_at`;
+ALTER TABLE `helper_blacklists` DROP KEY `helper_blacklists_expires_at`, DROP COLUMN `reason`, DROP COLUMN `created_at`, DROP COLUMN `expires_at`;
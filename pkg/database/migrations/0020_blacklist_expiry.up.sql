@@ -0,0 +1,12 @@
+-- A NULL expires_at keeps the entry until it is removed, created_at is NULL for the entries added before this migration.
+ALTER TABLE `blacklists`
+    ADD COLUMN `reason` varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN `created_at` datetime NULL,
+    ADD COLUMN `expires_at` datetime NULL,
+    ADD KEY `blacklists_expires_at` (`expires_at`);
+
+ALTER TABLE `helper_blacklists`
+    ADD COLUMN `reason` varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN `created_at` datetime NULL,
+    ADD COLUMN `expires_at` datetime NULL,
+    ADD KEY `helper_blacklists_expires_at` (`expires_at`);
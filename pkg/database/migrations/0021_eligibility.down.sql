@@ -0,0 +1,4 @@
+ALTER TABLE `server_configs`
+    DROP COLUMN `min_account_age_days`,
+    DROP COLUMN `min_member_days`,
+    DROP COLUMN `eligibility_role_id`;
@@ -0,0 +1,5 @@
+-- Requirements every giveaway participant of the guild has to meet, 0 and '' disable them.
+ALTER TABLE `server_configs`
+    ADD COLUMN `min_account_age_days` int NOT NULL DEFAULT 0,
+    ADD COLUMN `min_member_days` int NOT NULL DEFAULT 0,
+    ADD COLUMN `eligibility_role_id` varchar(255) NOT NULL DEFAULT '';
@@ -63,12 +63,19 @@ func (p *Provider) GetMySQLDatabase(name string) (*gorp.DbMap, error) {
 	return database, nil
 }
 
-func (p *Provider) CreateTablesIfNotExists() error {
+func (p *Provider) MigrateMySQLDatabases(ctx context.Context) error {
+	log := logger.GetLoggerFromContext(ctx)
 	for name, database := range p.databases {
-		err := database.CreateTablesIfNotExists()
+		migrator, err := NewMigrator(database.Db)
+		if err != nil {
+			return fmt.Errorf("could not load migrations %w", err)
+		}
+
+		applied, err := migrator.Up(ctx)
 		if err != nil {
-			return fmt.Errorf("could not create tables in db %s %w", name, err)
+			return fmt.Errorf("could not migrate db %s %w", name, err)
 		}
+		log.WithField("dbname", name).Infof("Applied %d migrations", applied)
 	}
 
 	return nil
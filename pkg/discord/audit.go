@@ -0,0 +1,61 @@
+package discord
+
+import (
+	"csrvbot/domain/entities"
+	"fmt"
+	"unicode/utf8"
+)
+
+// AuditActionNames are the display names of the audit log actions.
+var AuditActionNames = map[string]string{
+	entities.AuditActionBlacklist:         "Dodanie do blacklisty",
+	entities.AuditActionUnblacklist:       "Usunięcie z blacklisty",
+	entities.AuditActionHelperBlacklist:   "Dodanie do helper-blacklisty",
+	entities.AuditActionHelperUnblacklist: "Usunięcie z helper-blacklisty",
+	entities.AuditActionSettings:          "Zmiana ustawień",
+	entities.AuditActionGiveawayStart:     "Ręczne rozstrzygnięcie giveawaya",
+	entities.AuditActionCustomGiveaway:    "Utworzenie specjalnego giveawaya",
+	entities.AuditActionThxDelete:         "Usunięcie z giveawaya za podziękowania",
+	entities.AuditActionThxAccept:         "Akceptacja podziękowania",
+	entities.AuditActionThxReject:         "Odrzucenie podziękowania",
+	entities.AuditActionStatusPublish:     "Publikacja statusu",
+}
+
+// AuditTarget formats the target of the audit log entry, a mention unless the action targets something else than
+// a user.
+func AuditTarget(entry entities.AuditLogEntry) string {
+	switch {
+	case entry.Target == "":
+		return "-"
+	case entry.Action == entities.AuditActionCustomGiveaway:
+		return "#" + entry.Target
+	case entry.Action == entities.AuditActionSettings || entry.Action == entities.AuditActionGiveawayStart || entry.Action == entities.AuditActionStatusPublish:
+		return "`" + entry.Target + "`"
+	default:
+		return fmt.Sprintf("<@%s>", entry.Target)
+	}
+}
+
+// AuditActor formats who took the action of the audit log entry, a mention unless the bot took it on its own.
+func AuditActor(entry entities.AuditLogEntry) string {
+	if entry.ActorId == "" {
+		return "System"
+	}
+	return fmt.Sprintf("<@%s>", entry.ActorId)
+}
+
+// truncateAuditValue shortens the value to at most length characters, so it fits in an embed.
+func truncateAuditValue(value string, length int) string {
+	if utf8.RuneCountInString(value) <= length {
+		return value
+	}
+	return string([]rune(value)[:length-1]) + "…"
+}
+
+// auditValue formats the before or after value of an audit log entry for a single line.
+func auditValue(value string, length int) string {
+	if value == "" {
+		return "-"
+	}
+	return "`" + truncateAuditValue(value, length) + "`"
+}
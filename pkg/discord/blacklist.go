@@ -0,0 +1,48 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// blacklistMessageLength leaves some room below the message limit of Discord.
+const blacklistMessageLength = 1900
+
+// BlacklistExpiry describes when the blacklist ends.
+func BlacklistExpiry(expiresAt *time.Time) string {
+	if expiresAt == nil {
+		return "bezterminowo"
+	}
+	return fmt.Sprintf("do <t:%d:f>", expiresAt.Unix())
+}
+
+// FormatBlacklistEntry formats a single line of the blacklist list.
+func FormatBlacklistEntry(userId, blacklisterId, reason string, expiresAt *time.Time) string {
+	if reason == "" {
+		reason = "bez powodu"
+	}
+	expiry := "bezterminowo"
+	if expiresAt != nil {
+		expiry = fmt.Sprintf("wygasa <t:%d:R>", expiresAt.Unix())
+	}
+	return fmt.Sprintf("<@%s> · %s · %s · <@%s>", userId, reason, expiry, blacklisterId)
+}
+
+// ConstructBlacklistMessage joins the lines under the header, leaving out the ones which would not fit.
+func ConstructBlacklistMessage(header string, lines []string) string {
+	if len(lines) == 0 {
+		return header + "\nBrak aktywnych blokad"
+	}
+
+	var message strings.Builder
+	message.WriteString(header)
+	for n, line := range lines {
+		if message.Len()+len(line)+1 > blacklistMessageLength {
+			fmt.Fprintf(&message, "\n...i %d więcej", len(lines)-n)
+			break
+		}
+		message.WriteString("\n" + line)
+	}
+	return message.String()
+}
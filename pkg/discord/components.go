@@ -3,7 +3,9 @@ package discord
 import (
 	"csrvbot/domain/entities"
 	"encoding/json"
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -215,6 +217,109 @@ func ConstructStatusEditOrCreateModalComponent(data *entities.Status, action str
 	}
 }
 
+// ConstructPaginationComponents returns the buttons switching between the pages of a list, their custom IDs
+// are the prefix followed by the page number.
+func ConstructPaginationComponents(customIdPrefix string, page, pages int) []discordgo.MessageComponent {
+	if pages <= 1 {
+		return []discordgo.MessageComponent{}
+	}
+
+	return []discordgo.MessageComponent{
+		&discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				&discordgo.Button{
+					Label:    "Poprzednia",
+					Style:    discordgo.SecondaryButton,
+					CustomID: customIdPrefix + "_" + strconv.Itoa(page-1),
+					Disabled: page <= 0,
+					Emoji: &discordgo.ComponentEmoji{
+						Name: "⬅️",
+					},
+				},
+				&discordgo.Button{
+					Label:    "Następna",
+					Style:    discordgo.SecondaryButton,
+					CustomID: customIdPrefix + "_" + strconv.Itoa(page+1),
+					Disabled: page >= pages-1,
+					Emoji: &discordgo.ComponentEmoji{
+						Name: "➡️",
+					},
+				},
+			},
+		},
+	}
+}
+
+// ConstructThxReviewComponents lets the admins accept or reject the chosen thx from the page of the review queue, or the
+// whole page at once. The bulk buttons carry the ids of the thx they were shown with, so they never act on thx the
+// admin has not seen.
+func ConstructThxReviewComponents(participants []entities.GiveawayParticipant, page, pages int) []discordgo.MessageComponent {
+	if len(participants) == 0 {
+		return ConstructPaginationComponents("review_page", page, pages)
+	}
+
+	options := make([]discordgo.SelectMenuOption, len(participants))
+	ids := make([]string, len(participants))
+	for i, participant := range participants {
+		ids[i] = strconv.Itoa(participant.Id)
+		options[i] = discordgo.SelectMenuOption{
+			Label: fmt.Sprintf("#%d · %s", participant.Id, participant.UserName),
+			Value: ids[i],
+		}
+	}
+	pageIds := strings.Join(ids, ".")
+	one := 1
+
+	components := []discordgo.MessageComponent{
+		&discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				&discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    "review_accept_" + strconv.Itoa(page),
+					Placeholder: "Zaakceptuj wybrane",
+					MinValues:   &one,
+					MaxValues:   len(options),
+					Options:     options,
+				},
+			},
+		},
+		&discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				&discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    "review_reject_" + strconv.Itoa(page),
+					Placeholder: "Odrzuć wybrane",
+					MinValues:   &one,
+					MaxValues:   len(options),
+					Options:     options,
+				},
+			},
+		},
+		&discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				&discordgo.Button{
+					Label:    "Zaakceptuj całą stronę",
+					Style:    discordgo.SuccessButton,
+					CustomID: "review_acceptall_" + strconv.Itoa(page) + "_" + pageIds,
+					Emoji: &discordgo.ComponentEmoji{
+						Name: "✅",
+					},
+				},
+				&discordgo.Button{
+					Label:    "Odrzuć całą stronę",
+					Style:    discordgo.DangerButton,
+					CustomID: "review_rejectall_" + strconv.Itoa(page) + "_" + pageIds,
+					Emoji: &discordgo.ComponentEmoji{
+						Name: "⛔",
+					},
+				},
+			},
+		},
+	}
+
+	return append(components, ConstructPaginationComponents("review_page", page, pages)...)
+}
+
 func ConstructStatusAcceptRejectComponents(interactionID string) []discordgo.MessageComponent {
 	return []discordgo.MessageComponent{
 		&discordgo.ActionsRow{
@@ -239,3 +344,56 @@ func ConstructStatusAcceptRejectComponents(interactionID string) []discordgo.Mes
 		},
 	}
 }
+
+func ConstructCustomGiveawayModalComponent(customId string) discordgo.InteractionResponseData {
+	return discordgo.InteractionResponseData{
+		CustomID: customId,
+		Title:    "Utwórz giveaway",
+		Flags:    discordgo.MessageFlagsIsComponentsV2,
+		Components: []discordgo.MessageComponent{
+			discordgo.Label{
+				Label: "Tytuł",
+				Component: discordgo.TextInput{
+					Style:     discordgo.TextInputShort,
+					CustomID:  "title",
+					MaxLength: 100,
+				},
+			},
+			discordgo.Label{
+				Label:       "Nagroda",
+				Description: "Opis nagrody widoczny dla uczestników",
+				Component: discordgo.TextInput{
+					Style:     discordgo.TextInputParagraph,
+					CustomID:  "prize",
+					MaxLength: 1000,
+				},
+			},
+			discordgo.Label{
+				Label: "Liczba zwycięzców",
+				Component: discordgo.TextInput{
+					Style:     discordgo.TextInputShort,
+					CustomID:  "winners",
+					MaxLength: 2,
+					Value:     "1",
+				},
+			},
+			discordgo.Label{
+				Label:       "Koniec",
+				Description: "Data w formacie RRRR-MM-DD GG:MM lub czas trwania, np. 90m, 12h, 3d",
+				Component: discordgo.TextInput{
+					Style:       discordgo.TextInputShort,
+					CustomID:    "end_time",
+					Placeholder: "24h",
+				},
+			},
+			discordgo.Label{
+				Label: "Kanał",
+				Component: discordgo.SelectMenu{
+					MenuType:     discordgo.ChannelSelectMenu,
+					CustomID:     "channel",
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package discord
+
+import (
+	"csrvbot/domain/entities"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// IneligibilityMessage explains why a member cannot take part in a giveaway, in Polish unless the locale of the
+// user is another language, then in English. When self is false the message is about another user, e.g. the
+// thanked one.
+func IneligibilityMessage(ineligibility *entities.Ineligibility, locale discordgo.Locale, self bool) string {
+	if locale == "" || locale == discordgo.Polish {
+		return polishIneligibilityMessage(ineligibility, self)
+	}
+	return englishIneligibilityMessage(ineligibility, self)
+}
+
+func polishIneligibilityMessage(ineligibility *entities.Ineligibility, self bool) string {
+	switch {
+	case ineligibility.Reason == entities.IneligibleBlacklisted && self:
+		return "Jesteś na czarnej liście i nie możesz brać udziału w giveawayach!"
+	case ineligibility.Reason == entities.IneligibleBlacklisted:
+		return "Ten użytkownik jest na czarnej liście i nie może brać udziału :("
+	case ineligibility.Reason == entities.IneligibleAccountAge && self:
+		return fmt.Sprintf("Twoje konto Discord musi mieć co najmniej %d dni, żeby brać udział w giveawayach!", ineligibility.Days)
+	case ineligibility.Reason == entities.IneligibleAccountAge:
+		return fmt.Sprintf("Ten użytkownik ma konto Discord młodsze niż %d dni i nie może brać udziału w giveawayach", ineligibility.Days)
+	case ineligibility.Reason == entities.IneligibleMemberAge && self:
+		return fmt.Sprintf("Musisz być na serwerze co najmniej %d dni, żeby brać udział w giveawayach!", ineligibility.Days)
+	case ineligibility.Reason == entities.IneligibleMemberAge:
+		return fmt.Sprintf("Ten użytkownik jest na serwerze krócej niż %d dni i nie może brać udziału w giveawayach", ineligibility.Days)
+	case ineligibility.Reason == entities.IneligibleRole && self:
+		return fmt.Sprintf("Nie masz wymaganej roli <@&%s>, żeby wziąć udział w tym giveawayu!", ineligibility.RoleId)
+	case ineligibility.Reason == entities.IneligibleRole:
+		return fmt.Sprintf("Ten użytkownik nie ma wymaganej roli <@&%s> i nie może brać udziału w giveawayach", ineligibility.RoleId)
+	case ineligibility.Reason == entities.IneligibleLevel && self:
+		return "Nie masz wymaganego poziomu, żeby wziąć udział w tym giveawayu!"
+	case ineligibility.Reason == entities.IneligibleLevel:
+		return fmt.Sprintf("Ten użytkownik nie ma wymaganego poziomu %d i nie może brać udziału w tym giveawayu", ineligibility.Level)
+	}
+
+	return "Nie spełniasz wymagań udziału w giveawayach"
+}
+
+func englishIneligibilityMessage(ineligibility *entities.Ineligibility, self bool) string {
+	switch {
+	case ineligibility.Reason == entities.IneligibleBlacklisted && self:
+		return "You are blacklisted and cannot take part in giveaways!"
+	case ineligibility.Reason == entities.IneligibleBlacklisted:
+		return "This user is blacklisted and cannot take part :("
+	case ineligibility.Reason == entities.IneligibleAccountAge && self:
+		return fmt.Sprintf("Your Discord account must be at least %d days old to take part in giveaways!", ineligibility.Days)
+	case ineligibility.Reason == entities.IneligibleAccountAge:
+		return fmt.Sprintf("The Discord account of this user is less than %d days old, so they cannot take part in giveaways", ineligibility.Days)
+	case ineligibility.Reason == entities.IneligibleMemberAge && self:
+		return fmt.Sprintf("You must be a member of the server for at least %d days to take part in giveaways!", ineligibility.Days)
+	case ineligibility.Reason == entities.IneligibleMemberAge:
+		return fmt.Sprintf("This user joined the server less than %d days ago and cannot take part in giveaways", ineligibility.Days)
+	case ineligibility.Reason == entities.IneligibleRole && self:
+		return fmt.Sprintf("You need the <@&%s> role to take part in this giveaway!", ineligibility.RoleId)
+	case ineligibility.Reason == entities.IneligibleRole:
+		return fmt.Sprintf("This user does not have the required <@&%s> role and cannot take part in giveaways", ineligibility.RoleId)
+	case ineligibility.Reason == entities.IneligibleLevel && self:
+		return fmt.Sprintf("You need level %d to take part in this giveaway!", ineligibility.Level)
+	case ineligibility.Reason == entities.IneligibleLevel:
+		return fmt.Sprintf("This user does not have the required level %d and cannot take part in this giveaway", ineligibility.Level)
+	}
+
+	return "You do not meet the requirements to take part in giveaways"
+}
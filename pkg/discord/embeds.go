@@ -1,24 +1,46 @@
 package discord
 
 import (
+	"csrvbot/domain/entities"
+	"csrvbot/pkg/fairdraw"
+	"csrvbot/pkg/schedule"
 	"fmt"
 	"github.com/bwmarrin/discordgo"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const (
-	ICON_URL = "https://cdn.discordapp.com/avatars/524308413719642118/c2a17b4479bfcc89d2b7e64e6ae15ebe.webp"
-	COLOR    = 0x234d20
+	ICON_URL      = "https://cdn.discordapp.com/avatars/524308413719642118/c2a17b4479bfcc89d2b7e64e6ae15ebe.webp"
+	COLOR         = 0x234d20
+	WARNING_COLOR = 0xd4a017
 )
 
-func ConstructInfoEmbed(url string, participants []string, giveawayHours string, value int) *discordgo.MessageEmbed {
+// GiveawayTypeNames are the display names of the giveaway types.
+var GiveawayTypeNames = map[string]string{
+	entities.ThxGiveawayType:     "Za podziękowania",
+	entities.MessageGiveawayType: "Za wiadomości",
+	entities.JoinedGiveawayType:  "Bezwarunkowy",
+	entities.LevelGiveawayType:   "Z wymaganym poziomem",
+	entities.CustomGiveawayType:  "Specjalny",
+}
+
+// ThxAbuseFlagDescriptions explain the abuse flags to the admins reviewing a thx.
+var ThxAbuseFlagDescriptions = map[entities.ThxAbuseFlag]string{
+	entities.ThxAbuseFlagReciprocal:     "Wzajemne podziękowania w ostatnich 30 dniach",
+	entities.ThxAbuseFlagBurst:          "Wiele podziękowań od tej samej osoby w ciągu godziny",
+	entities.ThxAbuseFlagNewMember:      "Konto dołączyło do serwera w ciągu ostatnich 7 dni",
+	entities.ThxAbuseFlagNoConversation: "Brak niedawnej rozmowy obu osób na tym kanale",
+}
+
+func ConstructInfoEmbed(url string, serverConfig entities.ServerConfig, participants []entities.GiveawayParticipant, voucherConfig entities.VoucherConfig) *discordgo.MessageEmbed {
 	info := "**Ten bot organizuje giveaway kodów na doładowanie portfela Twojego serwera.**\n" +
-		fmt.Sprintf("**Każdy kod doładowuje %d PLN do portfela.**\n", value/100) +
-		"Aby wziąć udział pomagaj innym użytkownikom. Jeżeli komuś pomożesz, to poproś tą osobę aby użyła komendy </thx:1107007500659728405> lub sam użyj komendy </thxme:1107007504308769020> - w ten sposób dostaniesz się do loterii. To jest nasza metoda na rozruszanie tego Discorda, tak, aby każdy mógł liczyć na pomoc. Każde podziękowanie to jeden los, więc warto pomagać!\n\n" +
+		fmt.Sprintf("**Każdy kod doładowuje %s do portfela.**\n", voucherConfig.FormatValue()) +
+		"Aby wziąć udział pomagaj innym użytkownikom. Jeżeli komuś pomożesz, to poproś tą osobę aby użyła komendy </thx:1107007500659728405> lub sam użyj komendy </thxme:1107007504308769020> - w ten sposób dostaniesz się do loterii. To jest nasza metoda na rozruszanie tego Discorda, tak, aby każdy mógł liczyć na pomoc. " + thxWeightingInfo(serverConfig) + "\n\n" +
 		fmt.Sprintf("**Sponsorem tego bota jest %s - hosting serwerów Minecraft.**\n\n", url) +
 		"Pomoc musi odbywać się na tym serwerze na tekstowych kanałach publicznych.\n\n" +
-		"Uczestnicy: " + strings.Join(participants, ", ") + "\n\nNagrody rozdajemy o " + giveawayHours + ", Powodzenia!"
+		"Uczestnicy: " + strings.Join(thxParticipantsOdds(serverConfig, participants), ", ") + "\n\n" + giveawayHoursInfo(serverConfig)
 	embed := &discordgo.MessageEmbed{
 		Author: &discordgo.MessageEmbedAuthor{
 			URL:     url,
@@ -32,8 +54,76 @@ func ConstructInfoEmbed(url string, participants []string, giveawayHours string,
 	return embed
 }
 
-func ConstructThxEmbed(url string, participants []string, giveawayHours, participantId, confirmerId, state string, voucherValue int) *discordgo.MessageEmbed {
-	embed := ConstructInfoEmbed(url, participants, giveawayHours, voucherValue)
+// giveawayHoursInfo describes when the thx giveaway is finished, based on the guild schedule and time zone.
+func giveawayHoursInfo(serverConfig entities.ServerConfig) string {
+	daily, err := schedule.Parse(serverConfig.ThxGiveawaySchedule)
+	if err != nil || len(daily) == 0 {
+		return "Nagrody są obecnie rozdawane przez administrację, Powodzenia!"
+	}
+
+	return fmt.Sprintf("Nagrody rozdajemy o %s (%s), Powodzenia!", JoinWithAnd(daily.Times()), serverConfig.Location().String())
+}
+
+// JoinWithAnd joins the items into a Polish list, e.g. "6:00, 12:00 i 18:00".
+func JoinWithAnd(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+
+	return strings.Join(items[:len(items)-1], ", ") + " i " + items[len(items)-1]
+}
+
+func thxWeightingInfo(serverConfig entities.ServerConfig) string {
+	switch serverConfig.ThxWeighting {
+	case entities.ThxWeightingPerParticipant:
+		return "Każdy uczestnik ma jeden los, niezależnie od liczby podziękowań."
+	case entities.ThxWeightingDiminishing:
+		return fmt.Sprintf("Pierwsze podziękowanie to jeden los, a każdy kolejny los wymaga dwa razy więcej podziękowań (1, 2, 4, 8...), maksymalnie %d.", serverConfig.ThxWeightingCap)
+	default:
+		return "Każde podziękowanie to jeden los, więc warto pomagać!"
+	}
+}
+
+// thxParticipantsOdds lists the accepted participants with their entries and chance to win the thx giveaway.
+func thxParticipantsOdds(serverConfig entities.ServerConfig, participants []entities.GiveawayParticipant) []string {
+	entries := serverConfig.ThxGiveawayEntries(participants)
+
+	userNames := make(map[string]string)
+	for _, participant := range participants {
+		userNames[participant.UserId] = participant.UserName
+	}
+
+	var userIds []string
+	userEntries := make(map[string]int)
+	for _, userId := range entries {
+		if userEntries[userId] == 0 {
+			userIds = append(userIds, userId)
+		}
+		userEntries[userId]++
+	}
+
+	result := make([]string, len(userIds))
+	for i, userId := range userIds {
+		odds := float64(userEntries[userId]) * 100 / float64(len(entries))
+		result[i] = fmt.Sprintf("%s (%s, %.1f%%)", userNames[userId], entriesCount(userEntries[userId]), odds)
+	}
+
+	return result
+}
+
+func entriesCount(count int) string {
+	switch {
+	case count == 1:
+		return "1 los"
+	case count%10 >= 2 && count%10 <= 4 && (count%100 < 12 || count%100 > 14):
+		return fmt.Sprintf("%d losy", count)
+	default:
+		return fmt.Sprintf("%d losów", count)
+	}
+}
+
+func ConstructThxEmbed(url string, serverConfig entities.ServerConfig, participants []entities.GiveawayParticipant, participantId, confirmerId, state string, voucherConfig entities.VoucherConfig) *discordgo.MessageEmbed {
+	embed := ConstructInfoEmbed(url, serverConfig, participants, voucherConfig)
 	embed.Fields = []*discordgo.MessageEmbedField{}
 	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Dodany", Value: "<@" + participantId + ">", Inline: true})
 
@@ -60,7 +150,7 @@ func ConstructThxEmbed(url string, participants []string, giveawayHours, partici
 	return embed
 }
 
-func ConstructThxNotificationEmbed(url, guildId, thxChannelId, thxMessageId, participantId, confirmerId, state string) *discordgo.MessageEmbed {
+func ConstructThxNotificationEmbed(url, guildId, thxChannelId, thxMessageId, participantId, confirmerId, state string, flags []entities.ThxAbuseFlag) *discordgo.MessageEmbed {
 	embed := &discordgo.MessageEmbed{
 		Timestamp: time.Now().Format(time.RFC3339),
 		Author: &discordgo.MessageEmbedAuthor{
@@ -91,6 +181,14 @@ func ConstructThxNotificationEmbed(url, guildId, thxChannelId, thxMessageId, par
 		}
 		break
 	}
+	if len(flags) > 0 {
+		descriptions := make([]string, len(flags))
+		for i, flag := range flags {
+			descriptions[i] = "⚠️ " + ThxAbuseFlagDescriptions[flag]
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Podejrzane", Value: strings.Join(descriptions, "\n"), Inline: false})
+		embed.Color = WARNING_COLOR
+	}
 	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Link", Value: "https://discordapp.com/channels/" + guildId + "/" + thxChannelId + "/" + thxMessageId, Inline: false})
 
 	return embed
@@ -177,20 +275,264 @@ func ConstructChannelMessageWinnerEmbed(url string, usernames []string) *discord
 	return embed
 }
 
-func ConstructResendEmbed(url string, codes []string) *discordgo.MessageEmbed {
+// ConstructWinsEmbed lists one page of the wins of a user in all guilds, guildNames maps guild IDs to their names.
+func ConstructWinsEmbed(url string, wins []entities.GiveawayWin, guildNames map[string]string, page, pages, total int) *discordgo.MessageEmbed {
+	lines := make([]string, len(wins))
+	for i, win := range wins {
+		guildName, ok := guildNames[win.GuildId]
+		if !ok {
+			guildName = win.GuildId
+		}
+		header := fmt.Sprintf("%s · %s", GiveawayTypeNames[win.GiveawayType], guildName)
+		if win.GiveawayEndTime != nil {
+			header = fmt.Sprintf("<t:%d:d> · %s", win.GiveawayEndTime.Unix(), header)
+		}
+
+		line := WinnerCodeLine(win.GiveawayWinner)
+		switch {
+		case win.Code == "" && win.GiveawayType == entities.CustomGiveawayType:
+			line = "nagroda przekazywana przez administrację"
+		case win.Code == "":
+			line = "kod w przygotowaniu, wyślemy go w wiadomości prywatnej"
+		}
+		if value := win.FormatVoucherValue(); value != "" {
+			line += " · " + value
+		}
+		lines[i] = "**" + header + "**\n" + line
+	}
+
+	description := strings.Join(lines, "\n\n")
+	if total == 0 {
+		description = "Nie wygrałeś jeszcze żadnego giveawaya."
+	}
+
 	embed := &discordgo.MessageEmbed{
 		Author: &discordgo.MessageEmbedAuthor{
 			URL:     url,
-			Name:    "Twoje ostatnie wygrane kody",
+			Name:    "Twoje wygrane",
 			IconURL: ICON_URL,
 		},
-		Description: strings.Join(codes, "\n"),
+		Description: description,
 		Color:       COLOR,
-		Timestamp:   time.Now().Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Strona %d z %d · wygranych: %d", page+1, max(pages, 1), total),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	return embed
+}
+
+// ConstructThxRankingEmbed lists one page of the users with the most accepted thanks, offset is the position
+// of the first entry in the whole ranking.
+func ConstructThxRankingEmbed(url, periodName string, ranking []entities.ThxParticipantWithThxAmount, offset, page, pages int) *discordgo.MessageEmbed {
+	lines := make([]string, len(ranking))
+	for i, participant := range ranking {
+		lines[i] = fmt.Sprintf("**%d.** <@%s> - %d", offset+i+1, participant.UserId, participant.ThxAmount)
+	}
+
+	description := strings.Join(lines, "\n")
+	if len(ranking) == 0 {
+		description = "Nikt nie otrzymał jeszcze zaakceptowanego podziękowania w tym okresie."
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			URL:     url,
+			Name:    "Ranking podziękowań - " + periodName,
+			IconURL: ICON_URL,
+		},
+		Description: description,
+		Color:       COLOR,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Strona %d z %d", page+1, max(pages, 1)),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
 	}
 	return embed
 }
 
+// ConstructThxProfileEmbed shows the thanks statistics of a user, helperTiers is empty when no helper role is given
+// for thanks.
+func ConstructThxProfileEmbed(url string, user *discordgo.User, stats entities.ThxStats, rank int, helperTiers []entities.HelperTier, helperThxAmount int, thankers, thanked []entities.ThxParticipantWithThxAmount) *discordgo.MessageEmbed {
+	rankValue := "brak"
+	if rank > 0 {
+		rankValue = fmt.Sprintf("#%d", rank)
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Wszystkie", Value: strconv.Itoa(stats.Total), Inline: true},
+		{Name: "Zaakceptowane", Value: strconv.Itoa(stats.Accepted), Inline: true},
+		{Name: "Odrzucone", Value: strconv.Itoa(stats.Rejected), Inline: true},
+		{Name: "Oczekujące", Value: strconv.Itoa(stats.Pending), Inline: true},
+		{Name: "Miejsce w rankingu", Value: rankValue, Inline: true},
+		{Name: "Od różnych osób", Value: strconv.Itoa(stats.Thankers), Inline: true},
+		{Name: "Wysłane", Value: strconv.Itoa(stats.Given), Inline: true},
+	}
+
+	if len(helperTiers) > 0 {
+		// A helper role is given for more than ThxesNeeded accepted thanks
+		progress := fmt.Sprintf("<@&%s> ✅", helperTiers[len(helperTiers)-1].RoleId)
+		if next := entities.NextHelperTier(helperTiers, helperThxAmount); next != nil {
+			progress = fmt.Sprintf("%d/%d do <@&%s>", helperThxAmount, next.ThxesNeeded+1, next.RoleId)
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Postęp do rangi helpera", Value: progress, Inline: true})
+	}
+
+	if len(thankers) > 0 {
+		lines := make([]string, len(thankers))
+		for i, thanker := range thankers {
+			lines[i] = fmt.Sprintf("<@%s> - %d", thanker.UserId, thanker.ThxAmount)
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Najczęściej dziękowali", Value: strings.Join(lines, "\n")})
+	}
+
+	if len(thanked) > 0 {
+		lines := make([]string, len(thanked))
+		for i, participant := range thanked {
+			lines[i] = fmt.Sprintf("<@%s> - %d", participant.UserId, participant.ThxAmount)
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Najczęściej otrzymywali podziękowania", Value: strings.Join(lines, "\n")})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			URL:     url,
+			Name:    "Podziękowania użytkownika " + user.Username,
+			IconURL: ICON_URL,
+		},
+		Thumbnail: &discordgo.MessageEmbedThumbnail{
+			URL: user.AvatarURL(""),
+		},
+		Fields:    fields,
+		Color:     COLOR,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	return embed
+}
+
+// WinnerCodeLine returns the code of the winner with its redemption status.
+func WinnerCodeLine(winner entities.GiveawayWinner) string {
+	switch winner.RedemptionStatus {
+	case entities.VoucherStatusRedeemed:
+		if winner.RedeemedAt != nil {
+			return fmt.Sprintf("`%s` - wykorzystany <t:%d:d>", winner.Code, winner.RedeemedAt.Unix())
+		}
+		return fmt.Sprintf("`%s` - wykorzystany", winner.Code)
+	case entities.VoucherStatusUnclaimed:
+		if winner.ExpiresAt != nil {
+			return fmt.Sprintf("`%s` - niewykorzystany, ważny do <t:%d:d>", winner.Code, winner.ExpiresAt.Unix())
+		}
+		return fmt.Sprintf("`%s` - niewykorzystany", winner.Code)
+	case entities.VoucherStatusExpired:
+		return fmt.Sprintf("~~`%s`~~ - wygasł niewykorzystany", winner.Code)
+	case entities.VoucherStatusReplaced:
+		return fmt.Sprintf("~~`%s`~~ - wygasł, wydano nowy kod", winner.Code)
+	}
+
+	if winner.ExpiresAt != nil {
+		return fmt.Sprintf("`%s` - ważny do <t:%d:d>", winner.Code, winner.ExpiresAt.Unix())
+	}
+	return fmt.Sprintf("`%s`", winner.Code)
+}
+
+// ConstructHelperReconciliationEmbed reports the helper roles granted and revoked by the scheduled reconciliation.
+func ConstructHelperReconciliationEmbed(url string, changes []entities.HelperTierChange) *discordgo.MessageEmbed {
+	var granted, revoked []string
+	for _, change := range changes {
+		if change.Tier != nil {
+			granted = append(granted, fmt.Sprintf("<@%s> - <@&%s>", change.UserId, change.Tier.RoleId))
+		}
+		if change.PreviousTier != nil {
+			revoked = append(revoked, fmt.Sprintf("<@%s> - <@&%s>", change.UserId, change.PreviousTier.RoleId))
+		}
+	}
+
+	lines := func(lines []string) string {
+		if len(lines) == 0 {
+			return "Brak"
+		}
+		var value string
+		for i, line := range lines {
+			more := fmt.Sprintf("… i %d więcej", len(lines)-i)
+			if len(value)+len(line)+len(more)+1 > 1024 {
+				return value + more
+			}
+			value += line + "\n"
+		}
+		return value
+	}
+
+	return &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			URL:     url,
+			Name:    "Przegląd ról helperów",
+			IconURL: ICON_URL,
+		},
+		Color: COLOR,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: fmt.Sprintf("Nadane (%d)", len(granted)), Value: lines(granted)},
+			{Name: fmt.Sprintf("Odebrane (%d)", len(revoked)), Value: lines(revoked)},
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+}
+
+// ConstructAuditLogEntryEmbed mirrors an audit log entry to the audit channel.
+func ConstructAuditLogEntryEmbed(url string, entry entities.AuditLogEntry) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			URL:     url,
+			Name:    AuditActionNames[entry.Action],
+			IconURL: ICON_URL,
+		},
+		Color: COLOR,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Kto", Value: AuditActor(entry), Inline: true},
+			{Name: "Cel", Value: AuditTarget(entry), Inline: true},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("#%d", entry.Id),
+		},
+		Timestamp: entry.CreatedAt.Format(time.RFC3339),
+	}
+	if entry.Before != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Przed", Value: "```" + truncateAuditValue(entry.Before, 1000) + "```"})
+	}
+	if entry.After != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Po", Value: "```" + truncateAuditValue(entry.After, 1000) + "```"})
+	}
+	return embed
+}
+
+// ConstructAuditLogEmbed lists a page of the audit log entries, newest first.
+func ConstructAuditLogEmbed(url string, entries []entities.AuditLogEntry, page, pages, total int) *discordgo.MessageEmbed {
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = fmt.Sprintf("<t:%d:f> %s · %s · %s", entry.CreatedAt.Unix(), AuditActor(entry), AuditActionNames[entry.Action], AuditTarget(entry))
+		if entry.Before != "" || entry.After != "" {
+			lines[i] += fmt.Sprintf("\n%s → %s", auditValue(entry.Before, 80), auditValue(entry.After, 80))
+		}
+	}
+
+	description := strings.Join(lines, "\n")
+	if len(entries) == 0 {
+		description = "Brak wpisów pasujących do filtrów."
+	}
+
+	return &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			URL:     url,
+			Name:    "Dziennik działań administracji",
+			IconURL: ICON_URL,
+		},
+		Description: description,
+		Color:       COLOR,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Strona %d z %d · Wpisów: %d", page+1, max(pages, 1), total),
+		},
+	}
+}
+
 func ConstructJoinableGiveawayEmbed(url string, participantsCount int, levelRoleId *string) *discordgo.MessageEmbed {
 	var title, description string
 	if levelRoleId != nil {
@@ -242,3 +584,309 @@ func ConstructJoinableWinnersEmbed(url string, participantsIds []string, levelRo
 		Description: description,
 	}
 }
+
+// ConstructThxGiveawayStartEmbed announces a new thx giveaway, its footer carries the seed hash set by SetSeedHashFooter.
+func ConstructThxGiveawayStartEmbed(url string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			URL:     url,
+			Name:    "Nowy giveaway!",
+			IconURL: ICON_URL,
+		},
+		Description: "Rozpoczął się nowy giveaway za pomoc innym. Skrót ziarna poniżej pozwala po losowaniu sprawdzić, że wynik nie został zmieniony.",
+		Color:       COLOR,
+	}
+	return embed
+}
+
+// ConstructMessageGiveawayStartEmbed announces a new message giveaway, its footer carries the seed hash set by SetSeedHashFooter.
+func ConstructMessageGiveawayStartEmbed(url string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			URL:     url,
+			Name:    "Nowy giveaway!",
+			IconURL: ICON_URL,
+		},
+		Description: "Rozpoczęła się nowa loteria za aktywność. Skrót ziarna poniżej pozwala po losowaniu sprawdzić, że wynik nie został zmieniony.",
+		Color:       COLOR,
+	}
+	return embed
+}
+
+// SetSeedHashFooter publishes the hash of the giveaway seed, which commits to the draw before it happens.
+func SetSeedHashFooter(embed *discordgo.MessageEmbed, giveawayId int, seedHash string) {
+	if seedHash == "" {
+		return
+	}
+
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: fmt.Sprintf("Giveaway #%d • SHA-256 ziarna: %s", giveawayId, seedHash),
+	}
+}
+
+// AddDrawVerificationFields reveals the seed, the ordered entries the winners were drawn from and the picked users
+// skipped as ineligible.
+func AddDrawVerificationFields(embed *discordgo.MessageEmbed, giveawayId int, seed string, participantsIds, skippedIds []string) {
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "Weryfikacja losowania",
+		Value: fmt.Sprintf("Ziarno: `%s`\nLiczba losów: %d\nSprawdź wynik: `/giveaway verify id:%d`", seed, len(participantsIds), giveawayId),
+	})
+
+	if len(participantsIds) == 0 {
+		return
+	}
+
+	var participants string
+	for i, id := range participantsIds {
+		entry := fmt.Sprintf("%d. <@%s>\n", i+1, id)
+		if len(participants)+len(entry) > 960 {
+			participants += fmt.Sprintf("… i %d więcej, pełna lista w `/giveaway verify`", len(participantsIds)-i)
+			break
+		}
+		participants += entry
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "Kolejność losów",
+		Value: participants,
+	})
+
+	if len(skippedIds) == 0 {
+		return
+	}
+
+	var skipped string
+	for i, id := range skippedIds {
+		entry := fmt.Sprintf("<@%s>\n", id)
+		if len(skipped)+len(entry) > 960 {
+			skipped += fmt.Sprintf("… i %d więcej, pełna lista w `/giveaway verify`", len(skippedIds)-i)
+			break
+		}
+		skipped += entry
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "Pominięci",
+		Value: skipped,
+	})
+}
+
+func ConstructDrawVerificationEmbed(url string, giveawayId int, seed, seedHash string, participantsCount int, skippedIds, winnerIds, expectedWinnerIds []string) *discordgo.MessageEmbed {
+	hashMatches := fairdraw.HashSeed(seed) == seedHash
+	winnersMatch := strings.Join(winnerIds, ",") == strings.Join(expectedWinnerIds, ",")
+
+	var result string
+	if hashMatches && winnersMatch {
+		result = "✅ Ziarno zgadza się z opublikowanym hashem, a ponowne losowanie daje tych samych zwycięzców."
+	} else if !hashMatches {
+		result = "❌ Ziarno nie zgadza się z opublikowanym hashem!"
+	} else {
+		result = "❌ Ponowne losowanie daje innych zwycięzców!"
+	}
+
+	mentions := func(ids []string) string {
+		if len(ids) == 0 {
+			return "Brak"
+		}
+		var result string
+		for _, id := range ids {
+			result += "<@" + id + ">\n"
+		}
+		return result
+	}
+
+	return &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			URL:     url,
+			Name:    fmt.Sprintf("Weryfikacja giveawaya #%d", giveawayId),
+			IconURL: ICON_URL,
+		},
+		Description: "Przed losowaniem publikujemy SHA-256 tajnego ziarna, a po losowaniu samo ziarno i listę losów w kolejności. " +
+			"K-te losowanie wybiera los o indeksie równym pierwszym 8 bajtom SHA-256(`ziarno:k`) (big endian) modulo liczba pozostałych losów, k liczone od 0. " +
+			"Wylosowana osoba, której nie ma już na serwerze albo która w chwili losowania nie spełnia wymagań udziału " +
+			"(jest na czarnej liście, ma za młode konto Discord, jest na serwerze za krótko albo nie ma wymaganej roli lub poziomu), " +
+			"jest pomijana i usuwana z puli razem ze wszystkimi swoimi losami. " +
+			"W giveawayach z przyciskiem zwycięzca również jest usuwany z puli.\n\n" + result,
+		Color: COLOR,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Ziarno", Value: "`" + seed + "`"},
+			{Name: "SHA-256 ziarna", Value: "`" + seedHash + "`"},
+			{Name: "Liczba losów", Value: fmt.Sprintf("%d", participantsCount), Inline: true},
+			{Name: "Pominięci", Value: mentions(skippedIds), Inline: true},
+			{Name: "Zwycięzcy", Value: mentions(winnerIds), Inline: true},
+			{Name: "Wynik ponownego losowania", Value: mentions(expectedWinnerIds), Inline: true},
+		},
+	}
+}
+
+// AddRedrawsField lists the prizes redrawn after their codes expired unused, with the seed of each redraw. The replaced
+// winners stay in the verification of the original draw.
+func AddRedrawsField(embed *discordgo.MessageEmbed, seed string, winners []entities.GiveawayWinner) {
+	userIds := make(map[int]string, len(winners))
+	for _, winner := range winners {
+		userIds[winner.Id] = winner.UserId
+	}
+
+	var redraws string
+	for _, winner := range winners {
+		// A reissued code goes to the same user, only a redraw picks someone else
+		if winner.ReplacesWinnerId == nil || userIds[*winner.ReplacesWinnerId] == winner.UserId {
+			continue
+		}
+		redraws += fmt.Sprintf("<@%s> → <@%s>, ziarno `%s`\n", userIds[*winner.ReplacesWinnerId], winner.UserId, fairdraw.RedrawSeed(seed, *winner.ReplacesWinnerId))
+	}
+	if redraws == "" {
+		return
+	}
+
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "Nagrody rozlosowane ponownie",
+		Value: redraws,
+	})
+}
+
+// ConstructRedrawEmbed announces the new winner of a prize whose code expired unused, with the seed and the entries of the redraw.
+func ConstructRedrawEmbed(url string, giveawayId int, replacedUserId, winnerUserId, seed string, entries, skipped []string) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			URL:     url,
+			Name:    "Ponowne losowanie nagrody!",
+			IconURL: ICON_URL,
+		},
+		Color:       COLOR,
+		Description: fmt.Sprintf("Kod <@%s> z giveawaya #%d wygasł niewykorzystany, więc nagroda została rozlosowana ponownie wśród uczestników, którzy jeszcze jej nie wygrali. Nowy zwycięzca: <@%s>!", replacedUserId, giveawayId, winnerUserId),
+	}
+	AddDrawVerificationFields(embed, giveawayId, seed, entries, skipped)
+	return embed
+}
+
+func ConstructCustomGiveawayEmbed(url string, customGiveaway *entities.CustomGiveaway, participantsCount int, levelRoleId *string, ended bool) *discordgo.MessageEmbed {
+	description := customGiveaway.Prize
+	if !ended {
+		description += "\n\nKliknij w przycisk poniżej, aby wziąć udział. Powodzenia!"
+	}
+
+	endTime := customGiveaway.ScheduledEndTime.Unix()
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Koniec", Value: fmt.Sprintf("<t:%d:f> (<t:%d:R>)", endTime, endTime), Inline: true},
+		{Name: "Liczba zwycięzców", Value: fmt.Sprintf("%d", customGiveaway.WinnersCount), Inline: true},
+		{Name: "Liczba uczestników", Value: fmt.Sprintf("%d", participantsCount), Inline: true},
+	}
+
+	var requirements []string
+	if levelRoleId != nil {
+		requirements = append(requirements, fmt.Sprintf("Rola <@&%s> lub wyższa", *levelRoleId))
+	}
+	if customGiveaway.RequiredRoleId != nil {
+		requirements = append(requirements, fmt.Sprintf("Rola <@&%s>", *customGiveaway.RequiredRoleId))
+	}
+	if len(requirements) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Wymagania", Value: strings.Join(requirements, "\n")})
+	}
+	if customGiveaway.VoucherValue != nil {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Kod Craftserve", Value: fmt.Sprintf("Każdy zwycięzca otrzyma kod o wartości %d PLN", *customGiveaway.VoucherValue/100)})
+	}
+
+	return &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			URL:     url,
+			Name:    customGiveaway.Title,
+			IconURL: ICON_URL,
+		},
+		Color:       COLOR,
+		Description: description,
+		Fields:      fields,
+	}
+}
+
+func ConstructCustomGiveawayWinnersEmbed(url string, customGiveaway *entities.CustomGiveaway, participantsIds []string) *discordgo.MessageEmbed {
+	description := fmt.Sprintf("Oto zwycięzcy giveawaya **%s**! Gratulacje!", customGiveaway.Title)
+	for _, id := range participantsIds {
+		description += "\n- <@" + id + ">"
+	}
+	if customGiveaway.VoucherValue == nil {
+		description += "\n\nW sprawie odbioru nagrody skontaktuje się z Wami administracja."
+	}
+
+	return &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			URL:     url,
+			Name:    "Zakończono giveaway!",
+			IconURL: ICON_URL,
+		},
+		Color:       COLOR,
+		Description: description,
+	}
+}
+
+func ConstructCustomGiveawayWinnerEmbed(url string, customGiveaway *entities.CustomGiveaway, code string) *discordgo.MessageEmbed {
+	if customGiveaway.VoucherValue != nil {
+		embed := ConstructWinnerEmbed(url, code)
+		embed.Description = fmt.Sprintf("Gratulacje! Wygrałeś giveaway **%s** i darmowy kod na doładowanie portfela na Craftserve! Możesz go użyć w zakładce *Płatności* pod przyciskiem *Zrealizuj kupon*.", customGiveaway.Title)
+		return embed
+	}
+
+	return &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			URL:     url,
+			Name:    "Wygrałeś giveaway!",
+			IconURL: ICON_URL,
+		},
+		Description: fmt.Sprintf("Gratulacje! Wygrałeś giveaway **%s**. W sprawie odbioru nagrody skontaktuje się z Tobą administracja.", customGiveaway.Title),
+		Color:       COLOR,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name: "NAGRODA", Value: customGiveaway.Prize,
+			},
+		},
+	}
+}
+
+// ConstructThxReviewEmbed lists one page of the thx awaiting review, checks hold the abuse flags by thx message id.
+func ConstructThxReviewEmbed(url string, participants []entities.GiveawayParticipant, checks map[string]*entities.ThxAbuseCheck, page, pages, total int) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Author: &discordgo.MessageEmbedAuthor{
+			URL:     url,
+			Name:    "Podziękowania do rozpatrzenia",
+			IconURL: ICON_URL,
+		},
+		Color: COLOR,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Strona %d z %d · do rozpatrzenia: %d", page+1, max(pages, 1), total),
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	if total == 0 {
+		embed.Description = "Brak podziękowań oczekujących na rozpatrzenie."
+		return embed
+	}
+
+	for _, participant := range participants {
+		thanker := "nieznany"
+		if participant.ThankerId != nil {
+			thanker = "<@" + *participant.ThankerId + ">"
+		}
+		var flags []entities.ThxAbuseFlag
+		if participant.MessageId != nil {
+			if check, ok := checks[*participant.MessageId]; ok {
+				flags = check.Flags
+			}
+		}
+
+		lines := []string{fmt.Sprintf("Od: %s · Dla: <@%s>", thanker, participant.UserId)}
+		if participant.MessageId != nil && participant.ChannelId != nil {
+			lines = append(lines, fmt.Sprintf("<t:%d:R> · <#%s> · [Przejdź do wiadomości](https://discordapp.com/channels/%s/%s/%s)", participant.JoinTime.Unix(), *participant.ChannelId, participant.GuildId, *participant.ChannelId, *participant.MessageId))
+		}
+		for _, flag := range flags {
+			lines = append(lines, "⚠️ "+ThxAbuseFlagDescriptions[flag])
+		}
+		if len(flags) > 0 {
+			embed.Color = WARNING_COLOR
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("#%d · %s", participant.Id, participant.UserName),
+			Value: strings.Join(lines, "\n"),
+		})
+	}
+
+	return embed
+}
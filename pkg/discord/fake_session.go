@@ -0,0 +1,502 @@
+package discord
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// FakeSession is an in-memory Session that keeps guilds, channels, members and messages
+// and records everything sent through it. It is meant for running the services offline.
+type FakeSession struct {
+	mu sync.Mutex
+
+	BotUser *discordgo.User
+
+	guilds    map[string]*discordgo.Guild
+	channels  map[string]*discordgo.Channel
+	members   map[string][]*discordgo.Member
+	messages  map[string][]*discordgo.Message
+	dmClosed  map[string]bool
+	responses map[string]*discordgo.Message
+
+	SentMessages         []*discordgo.Message
+	EditedMessages       []*discordgo.Message
+	InteractionResponses []*discordgo.InteractionResponse
+	RoleChanges          []FakeRoleChange
+
+	lastId int
+}
+
+// FakeRoleChange records a single role added to or removed from a member.
+type FakeRoleChange struct {
+	GuildId string
+	UserId  string
+	RoleId  string
+	Added   bool
+}
+
+var _ Session = (*FakeSession)(nil)
+
+func NewFakeSession() *FakeSession {
+	return &FakeSession{
+		BotUser:   &discordgo.User{ID: "1", Username: "csrvbot", Bot: true},
+		guilds:    make(map[string]*discordgo.Guild),
+		channels:  make(map[string]*discordgo.Channel),
+		members:   make(map[string][]*discordgo.Member),
+		messages:  make(map[string][]*discordgo.Message),
+		dmClosed:  make(map[string]bool),
+		responses: make(map[string]*discordgo.Message),
+	}
+}
+
+func (s *FakeSession) AddGuild(guild *discordgo.Guild) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.guilds[guild.ID] = guild
+	for _, channel := range guild.Channels {
+		channel.GuildID = guild.ID
+		s.channels[channel.ID] = channel
+	}
+}
+
+func (s *FakeSession) AddRole(guildId string, role *discordgo.Role) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	guild := s.guild(guildId)
+	guild.Roles = append(guild.Roles, role)
+}
+
+func (s *FakeSession) AddChannel(channel *discordgo.Channel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[channel.ID] = channel
+	if channel.GuildID != "" {
+		guild := s.guild(channel.GuildID)
+		guild.Channels = append(guild.Channels, channel)
+	}
+}
+
+func (s *FakeSession) AddMember(guildId string, member *discordgo.Member) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.guild(guildId)
+	member.GuildID = guildId
+	s.members[guildId] = append(s.members[guildId], member)
+}
+
+// CloseDMs makes every direct message to the user fail like it does when the user blocks the bot.
+func (s *FakeSession) CloseDMs(userId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dmClosed[userId] = true
+}
+
+// AddMessage stores a message written by a user in the channel.
+func (s *FakeSession) AddMessage(message *discordgo.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if message.ID == "" {
+		message.ID = s.nextId()
+	}
+	s.messages[message.ChannelID] = append(s.messages[message.ChannelID], message)
+}
+
+// Messages returns the messages currently present in the channel, oldest first.
+func (s *FakeSession) Messages(channelId string) []*discordgo.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*discordgo.Message(nil), s.messages[channelId]...)
+}
+
+// DirectMessages returns the messages sent to the user in direct messages.
+func (s *FakeSession) DirectMessages(userId string) []*discordgo.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, channel := range s.channels {
+		if channel.Type == discordgo.ChannelTypeDM && len(channel.Recipients) > 0 && channel.Recipients[0].ID == userId {
+			return append([]*discordgo.Message(nil), s.messages[channel.ID]...)
+		}
+	}
+	return nil
+}
+
+// Member returns the stored member, which reflects role changes made through the session.
+func (s *FakeSession) Member(guildId, userId string) *discordgo.Member {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.member(guildId, userId)
+}
+
+func (s *FakeSession) Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	guild, ok := s.guilds[guildID]
+	if !ok {
+		return nil, fakeRESTError(discordgo.ErrCodeUnknownGuild, "Unknown Guild")
+	}
+	return guild, nil
+}
+
+func (s *FakeSession) GuildChannels(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	guild, ok := s.guilds[guildID]
+	if !ok {
+		return nil, fakeRESTError(discordgo.ErrCodeUnknownGuild, "Unknown Guild")
+	}
+	return append([]*discordgo.Channel(nil), guild.Channels...), nil
+}
+
+func (s *FakeSession) GuildRoles(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	guild, ok := s.guilds[guildID]
+	if !ok {
+		return nil, fakeRESTError(discordgo.ErrCodeUnknownGuild, "Unknown Guild")
+	}
+	return append([]*discordgo.Role(nil), guild.Roles...), nil
+}
+
+func (s *FakeSession) GuildMember(guildID, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	member := s.member(guildID, userID)
+	if member == nil {
+		return nil, fakeRESTError(discordgo.ErrCodeUnknownMember, "Unknown Member")
+	}
+	return member, nil
+}
+
+func (s *FakeSession) GuildMembers(guildID string, after string, limit int, options ...discordgo.RequestOption) ([]*discordgo.Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.guilds[guildID]; !ok {
+		return nil, fakeRESTError(discordgo.ErrCodeUnknownGuild, "Unknown Guild")
+	}
+
+	members := s.members[guildID]
+	start := 0
+	if after != "" {
+		for i, member := range members {
+			if member.User.ID == after {
+				start = i + 1
+				break
+			}
+		}
+	}
+	end := start + limit
+	if end > len(members) {
+		end = len(members)
+	}
+	return append([]*discordgo.Member(nil), members[start:end]...), nil
+}
+
+func (s *FakeSession) GuildMemberRoleAdd(guildID, userID, roleID string, options ...discordgo.RequestOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	member := s.member(guildID, userID)
+	if member == nil {
+		return fakeRESTError(discordgo.ErrCodeUnknownMember, "Unknown Member")
+	}
+	if FindRole(s.guilds[guildID], roleID) == nil {
+		return fakeRESTError(discordgo.ErrCodeUnknownRole, "Unknown Role")
+	}
+	if !HasRoleById(member, roleID) {
+		member.Roles = append(member.Roles, roleID)
+	}
+	s.RoleChanges = append(s.RoleChanges, FakeRoleChange{GuildId: guildID, UserId: userID, RoleId: roleID, Added: true})
+	return nil
+}
+
+func (s *FakeSession) GuildMemberRoleRemove(guildID, userID, roleID string, options ...discordgo.RequestOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	member := s.member(guildID, userID)
+	if member == nil {
+		return fakeRESTError(discordgo.ErrCodeUnknownMember, "Unknown Member")
+	}
+	for i, role := range member.Roles {
+		if role == roleID {
+			member.Roles = append(member.Roles[:i], member.Roles[i+1:]...)
+			break
+		}
+	}
+	s.RoleChanges = append(s.RoleChanges, FakeRoleChange{GuildId: guildID, UserId: userID, RoleId: roleID, Added: false})
+	return nil
+}
+
+func (s *FakeSession) Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	channel, ok := s.channels[channelID]
+	if !ok {
+		return nil, fakeRESTError(discordgo.ErrCodeUnknownChannel, "Unknown Channel")
+	}
+	return channel, nil
+}
+
+// ChannelMessages returns the latest messages of the channel, newest first like the Discord API. Only limit is supported.
+func (s *FakeSession) ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.channels[channelID]; !ok {
+		return nil, fakeRESTError(discordgo.ErrCodeUnknownChannel, "Unknown Channel")
+	}
+
+	var messages []*discordgo.Message
+	for i := len(s.messages[channelID]) - 1; i >= 0 && len(messages) < limit; i-- {
+		messages = append(messages, s.messages[channelID][i])
+	}
+	return messages, nil
+}
+
+func (s *FakeSession) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, channel := range s.channels {
+		if channel.Type == discordgo.ChannelTypeDM && len(channel.Recipients) > 0 && channel.Recipients[0].ID == recipientID {
+			return channel, nil
+		}
+	}
+
+	channel := &discordgo.Channel{
+		ID:         s.nextId(),
+		Type:       discordgo.ChannelTypeDM,
+		Recipients: []*discordgo.User{{ID: recipientID}},
+	}
+	s.channels[channel.ID] = channel
+	return channel, nil
+}
+
+func (s *FakeSession) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Content: content}, options...)
+}
+
+func (s *FakeSession) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}, options...)
+}
+
+func (s *FakeSession) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	channel, ok := s.channels[channelID]
+	if !ok {
+		return nil, fakeRESTError(discordgo.ErrCodeUnknownChannel, "Unknown Channel")
+	}
+	if channel.Type == discordgo.ChannelTypeDM && len(channel.Recipients) > 0 && s.dmClosed[channel.Recipients[0].ID] {
+		return nil, fakeRESTError(discordgo.ErrCodeCannotSendMessagesToThisUser, "Cannot send messages to this user")
+	}
+
+	embeds := data.Embeds
+	if data.Embed != nil {
+		embeds = append([]*discordgo.MessageEmbed{data.Embed}, embeds...)
+	}
+	message := &discordgo.Message{
+		ID:         s.nextId(),
+		ChannelID:  channelID,
+		GuildID:    channel.GuildID,
+		Content:    data.Content,
+		Embeds:     embeds,
+		Components: data.Components,
+		Flags:      data.Flags,
+		Author:     s.BotUser,
+	}
+	s.messages[channelID] = append(s.messages[channelID], message)
+	s.SentMessages = append(s.SentMessages, message)
+	return message, nil
+}
+
+func (s *FakeSession) ChannelMessageEdit(channelID, messageID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return s.ChannelMessageEditComplex(discordgo.NewMessageEdit(channelID, messageID).SetContent(content), options...)
+}
+
+func (s *FakeSession) ChannelMessageEditEmbed(channelID, messageID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return s.ChannelMessageEditComplex(discordgo.NewMessageEdit(channelID, messageID).SetEmbed(embed), options...)
+}
+
+func (s *FakeSession) ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	message := s.message(m.Channel, m.ID)
+	if message == nil {
+		return nil, fakeRESTError(discordgo.ErrCodeUnknownMessage, "Unknown Message")
+	}
+
+	if m.Content != nil {
+		message.Content = *m.Content
+	}
+	if m.Embed != nil {
+		message.Embeds = []*discordgo.MessageEmbed{m.Embed}
+	}
+	if m.Embeds != nil {
+		message.Embeds = *m.Embeds
+	}
+	if m.Components != nil {
+		message.Components = *m.Components
+	}
+	s.EditedMessages = append(s.EditedMessages, message)
+	return message, nil
+}
+
+func (s *FakeSession) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.InteractionResponses = append(s.InteractionResponses, resp)
+
+	switch resp.Type {
+	case discordgo.InteractionResponseChannelMessageWithSource, discordgo.InteractionResponseDeferredChannelMessageWithSource:
+		message := &discordgo.Message{
+			ID:        s.nextId(),
+			ChannelID: interaction.ChannelID,
+			GuildID:   interaction.GuildID,
+			Author:    s.BotUser,
+		}
+		if resp.Data != nil {
+			message.Content = resp.Data.Content
+			message.Embeds = resp.Data.Embeds
+			message.Components = resp.Data.Components
+			message.Flags = resp.Data.Flags
+		}
+		s.messages[interaction.ChannelID] = append(s.messages[interaction.ChannelID], message)
+		s.responses[interaction.Token] = message
+	case discordgo.InteractionResponseUpdateMessage:
+		if interaction.Message == nil || resp.Data == nil {
+			break
+		}
+		message := s.message(interaction.ChannelID, interaction.Message.ID)
+		if message == nil {
+			return fakeRESTError(discordgo.ErrCodeUnknownMessage, "Unknown Message")
+		}
+		message.Content = resp.Data.Content
+		message.Embeds = resp.Data.Embeds
+		message.Components = resp.Data.Components
+		s.EditedMessages = append(s.EditedMessages, message)
+	}
+	return nil
+}
+
+func (s *FakeSession) InteractionResponse(interaction *discordgo.Interaction, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	message, ok := s.responses[interaction.Token]
+	if !ok {
+		return nil, fakeRESTError(discordgo.ErrCodeUnknownMessage, "Unknown Message")
+	}
+	return message, nil
+}
+
+func (s *FakeSession) InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	message, ok := s.responses[interaction.Token]
+	if !ok {
+		return nil, fakeRESTError(discordgo.ErrCodeUnknownMessage, "Unknown Message")
+	}
+
+	if newresp.Content != nil {
+		message.Content = *newresp.Content
+	}
+	if newresp.Embeds != nil {
+		message.Embeds = *newresp.Embeds
+	}
+	if newresp.Components != nil {
+		message.Components = *newresp.Components
+	}
+	s.EditedMessages = append(s.EditedMessages, message)
+	return message, nil
+}
+
+func (s *FakeSession) FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	message := &discordgo.Message{
+		ID:         s.nextId(),
+		ChannelID:  interaction.ChannelID,
+		GuildID:    interaction.GuildID,
+		Content:    data.Content,
+		Embeds:     data.Embeds,
+		Components: data.Components,
+		Flags:      data.Flags,
+		Author:     s.BotUser,
+	}
+	s.messages[interaction.ChannelID] = append(s.messages[interaction.ChannelID], message)
+	s.SentMessages = append(s.SentMessages, message)
+	return message, nil
+}
+
+func (s *FakeSession) WebhookMessageDelete(webhookID, token, messageID string, options ...discordgo.RequestOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	message, ok := s.responses[token]
+	if !ok {
+		return fakeRESTError(discordgo.ErrCodeUnknownMessage, "Unknown Message")
+	}
+	if messageID != "@original" && messageID != message.ID {
+		return fakeRESTError(discordgo.ErrCodeUnknownMessage, "Unknown Message")
+	}
+
+	messages := s.messages[message.ChannelID]
+	for i, m := range messages {
+		if m.ID == message.ID {
+			s.messages[message.ChannelID] = append(messages[:i], messages[i+1:]...)
+			break
+		}
+	}
+	delete(s.responses, token)
+	return nil
+}
+
+func (s *FakeSession) guild(guildId string) *discordgo.Guild {
+	guild, ok := s.guilds[guildId]
+	if !ok {
+		guild = &discordgo.Guild{ID: guildId}
+		s.guilds[guildId] = guild
+	}
+	return guild
+}
+
+func (s *FakeSession) member(guildId, userId string) *discordgo.Member {
+	for _, member := range s.members[guildId] {
+		if member.User.ID == userId {
+			return member
+		}
+	}
+	return nil
+}
+
+func (s *FakeSession) message(channelId, messageId string) *discordgo.Message {
+	for _, message := range s.messages[channelId] {
+		if message.ID == messageId {
+			return message
+		}
+	}
+	return nil
+}
+
+func (s *FakeSession) nextId() string {
+	s.lastId++
+	return strconv.Itoa(1000000 + s.lastId)
+}
+
+func fakeRESTError(code int, message string) error {
+	body, _ := json.Marshal(DiscordGoError{Message: message, Code: code})
+	status := http.StatusNotFound
+	if code == discordgo.ErrCodeCannotSendMessagesToThisUser {
+		status = http.StatusForbidden
+	}
+	return &discordgo.RESTError{
+		Response: &http.Response{
+			Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+			StatusCode: status,
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		},
+		ResponseBody: body,
+		Message:      &discordgo.APIErrorMessage{Code: code, Message: message},
+	}
+}
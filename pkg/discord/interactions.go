@@ -7,7 +7,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-func RespondLoading(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+func RespondLoading(ctx context.Context, s Session, i *discordgo.InteractionCreate) {
 	log := logger.GetLoggerFromContext(ctx)
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
@@ -17,7 +17,7 @@ func RespondLoading(ctx context.Context, s *discordgo.Session, i *discordgo.Inte
 	}
 }
 
-func DeleteResponseMessage(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+func DeleteResponseMessage(ctx context.Context, s Session, i *discordgo.InteractionCreate) {
 	log := logger.GetLoggerFromContext(ctx)
 
 	err := s.WebhookMessageDelete(i.AppID, i.Interaction.Token, i.Message.ID)
@@ -26,7 +26,7 @@ func DeleteResponseMessage(ctx context.Context, s *discordgo.Session, i *discord
 	}
 }
 
-func DeferMessageUpdate(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) {
+func DeferMessageUpdate(ctx context.Context, s Session, i *discordgo.InteractionCreate) {
 	log := logger.GetLoggerFromContext(ctx)
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseDeferredMessageUpdate,
@@ -36,7 +36,7 @@ func DeferMessageUpdate(ctx context.Context, s *discordgo.Session, i *discordgo.
 	}
 }
 
-func EditResponseMessage(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
+func EditResponseMessage(ctx context.Context, s Session, i *discordgo.InteractionCreate, message string) {
 	log := logger.GetLoggerFromContext(ctx)
 	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 		Content: &message,
@@ -46,7 +46,7 @@ func EditResponseMessage(ctx context.Context, s *discordgo.Session, i *discordgo
 	}
 }
 
-func RespondWithMessage(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
+func RespondWithMessage(ctx context.Context, s Session, i *discordgo.InteractionCreate, message string) {
 	log := logger.GetLoggerFromContext(ctx)
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -59,7 +59,7 @@ func RespondWithMessage(ctx context.Context, s *discordgo.Session, i *discordgo.
 	}
 }
 
-func RespondWithEphemeralMessage(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
+func RespondWithEphemeralMessage(ctx context.Context, s Session, i *discordgo.InteractionCreate, message string) {
 	log := logger.GetLoggerFromContext(ctx)
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -73,7 +73,7 @@ func RespondWithEphemeralMessage(ctx context.Context, s *discordgo.Session, i *d
 	}
 }
 
-func RespondFollowUpEphemeralMessage(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
+func RespondFollowUpEphemeralMessage(ctx context.Context, s Session, i *discordgo.InteractionCreate, message string) {
 	log := logger.GetLoggerFromContext(ctx)
 	_, err := s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
 		Content: message,
@@ -85,7 +85,7 @@ func RespondFollowUpEphemeralMessage(ctx context.Context, s *discordgo.Session,
 	}
 }
 
-func RespondWithModal(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, modal discordgo.InteractionResponseData) {
+func RespondWithModal(ctx context.Context, s Session, i *discordgo.InteractionCreate, modal discordgo.InteractionResponseData) {
 	log := logger.GetLoggerFromContext(ctx)
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseModal,
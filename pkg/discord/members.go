@@ -7,7 +7,7 @@ import (
 	"time"
 )
 
-func GetAllMembers(ctx context.Context, session *discordgo.Session, guildId string) []*discordgo.Member {
+func GetAllMembers(ctx context.Context, session Session, guildId string) []*discordgo.Member {
 	log := logger.GetLoggerFromContext(ctx)
 	startTime := time.Now()
 	log.Debug("Started getting all members")
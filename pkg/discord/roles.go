@@ -8,20 +8,20 @@ import (
 	"strconv"
 )
 
-func HasPermission(ctx context.Context, session *discordgo.Session, member *discordgo.Member, guildId string, permission int64) bool {
+func HasPermission(ctx context.Context, session Session, member *discordgo.Member, guildId string, permission int64) bool {
 	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId).WithUser(member.User.ID)
-	g, err := session.Guild(guildId)
+	g, err := cachedGuild(session, guildId)
 	if err != nil {
-		log.WithError(err).Error("hasPermisson#session.Guild")
+		log.WithError(err).Error("hasPermisson#cachedGuild")
 		return false
 	}
 	if g.OwnerID == member.User.ID {
 		return true
 	}
 	for _, roleId := range member.Roles {
-		role, err := session.State.Role(guildId, roleId)
-		if err != nil {
-			log.Println("("+guildId+") "+"hasPermisson#session.State.Role", err)
+		role := FindRole(g, roleId)
+		if role == nil {
+			log.WithField("role", roleId).Error("hasPermisson#FindRole")
 			return false
 		}
 		if role.Permissions&permission != 0 {
@@ -41,7 +41,7 @@ func HasRoleById(member *discordgo.Member, roleId string) bool {
 	return false
 }
 
-func HasAdminPermissions(ctx context.Context, session *discordgo.Session, member *discordgo.Member, adminRoleId, guildId string) bool {
+func HasAdminPermissions(ctx context.Context, session Session, member *discordgo.Member, adminRoleId, guildId string) bool {
 	if HasPermission(ctx, session, member, guildId, 8) {
 		return true
 	}
@@ -53,14 +53,21 @@ func HasAdminPermissions(ctx context.Context, session *discordgo.Session, member
 
 var LevelPrefix string
 
-func GetMemberLevel(ctx context.Context, session *discordgo.Session, member *discordgo.Member, guildId string) (int, error) {
+func GetMemberLevel(ctx context.Context, session Session, member *discordgo.Member, guildId string) (int, error) {
 	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId).WithUser(member.User.ID)
 	log.Debug("Checking member level")
 
+	guild, err := cachedGuild(session, guildId)
+	if err != nil {
+		log.WithError(err).Error("GetMemberLevel#cachedGuild")
+		return 0, err
+	}
+
 	for _, roleId := range member.Roles {
-		role, err := session.State.Role(guildId, roleId)
-		if err != nil {
-			log.WithError(err).Error("GetMemberLevel#session.State.Role")
+		role := FindRole(guild, roleId)
+		if role == nil {
+			err := fmt.Errorf("role %s not found", roleId)
+			log.WithError(err).Error("GetMemberLevel#FindRole")
 			return 0, err
 		}
 
@@ -78,13 +85,13 @@ func GetMemberLevel(ctx context.Context, session *discordgo.Session, member *dis
 	return 0, nil
 }
 
-func GetRoleForLevel(ctx context.Context, session *discordgo.Session, guildId string, level int) (*discordgo.Role, error) {
+func GetRoleForLevel(ctx context.Context, session Session, guildId string, level int) (*discordgo.Role, error) {
 	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId).WithField("level", level)
 	log.Debug("Getting role for level")
 
-	guild, err := session.Guild(guildId)
+	guild, err := cachedGuild(session, guildId)
 	if err != nil {
-		log.WithError(err).Error("GetRoleForLevel#session.Guild")
+		log.WithError(err).Error("GetRoleForLevel#cachedGuild")
 		return nil, err
 	}
 
@@ -105,13 +112,13 @@ func GetRoleForLevel(ctx context.Context, session *discordgo.Session, guildId st
 	return nil, fmt.Errorf("role for level %d not found", level)
 }
 
-func ValidateLevels(ctx context.Context, session *discordgo.Session, guildId string, levels []int) (bool, error) {
+func ValidateLevels(ctx context.Context, session Session, guildId string, levels []int) (bool, error) {
 	log := logger.GetLoggerFromContext(ctx).WithGuild(guildId)
 	log.Debug("Validating levels")
 
-	guild, err := session.Guild(guildId)
+	guild, err := cachedGuild(session, guildId)
 	if err != nil {
-		log.WithError(err).Error("ValidateLevels#session.Guild")
+		log.WithError(err).Error("ValidateLevels#cachedGuild")
 		return false, err
 	}
 
@@ -0,0 +1,57 @@
+package discord
+
+import "github.com/bwmarrin/discordgo"
+
+// Session is the subset of *discordgo.Session used by the services and helpers.
+// It is satisfied by *discordgo.Session and by FakeSession.
+type Session interface {
+	Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error)
+	GuildChannels(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Channel, error)
+	GuildRoles(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Role, error)
+	GuildMember(guildID, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error)
+	GuildMembers(guildID string, after string, limit int, options ...discordgo.RequestOption) ([]*discordgo.Member, error)
+	GuildMemberRoleAdd(guildID, userID, roleID string, options ...discordgo.RequestOption) error
+	GuildMemberRoleRemove(guildID, userID, roleID string, options ...discordgo.RequestOption) error
+
+	Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error)
+	UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageEdit(channelID, messageID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageEditEmbed(channelID, messageID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
+
+	InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error
+	InteractionResponse(interaction *discordgo.Interaction, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	WebhookMessageDelete(webhookID, token, messageID string, options ...discordgo.RequestOption) error
+}
+
+var _ Session = (*discordgo.Session)(nil)
+
+// FindRole returns the role with the given id from the guild or nil if it does not exist.
+func FindRole(guild *discordgo.Guild, roleId string) *discordgo.Role {
+	for _, role := range guild.Roles {
+		if role.ID == roleId {
+			return role
+		}
+	}
+
+	return nil
+}
+
+// cachedGuild returns the guild from the state cache of the session, falling back to the REST API when the guild
+// is not cached or the session has no state.
+func cachedGuild(session Session, guildId string) (*discordgo.Guild, error) {
+	if s, ok := session.(*discordgo.Session); ok && s.StateEnabled && s.State != nil {
+		guild, err := s.State.Guild(guildId)
+		if err == nil {
+			return guild, nil
+		}
+	}
+
+	return session.Guild(guildId)
+}
@@ -1,11 +1,12 @@
 package discord
 
 import (
-	"github.com/bwmarrin/discordgo"
+	"csrvbot/domain/entities"
+	"fmt"
 )
 
-func NotifyThxOnThxInfoChannel(s *discordgo.Session, thxInfoChannelId, thxNotificationMessageId, guildId, channelId, thxMessageId, participantId, confirmerId, state, url string) (string, error) {
-	embed := ConstructThxNotificationEmbed(url, guildId, channelId, thxMessageId, participantId, confirmerId, state)
+func NotifyThxOnThxInfoChannel(s Session, thxInfoChannelId, thxNotificationMessageId, guildId, channelId, thxMessageId, participantId, confirmerId, state, url string, flags []entities.ThxAbuseFlag) (string, error) {
+	embed := ConstructThxNotificationEmbed(url, guildId, channelId, thxMessageId, participantId, confirmerId, state, flags)
 
 	if thxInfoChannelId == "" {
 		return "", nil
@@ -25,3 +26,24 @@ func NotifyThxOnThxInfoChannel(s *discordgo.Session, thxInfoChannelId, thxNotifi
 		return thxNotificationMessageId, nil
 	}
 }
+
+// ThxLimitMessage explains to the user why the thx limit of the guild refused their thx or /thxme request.
+func ThxLimitMessage(limit string, serverConfig entities.ServerConfig) string {
+	switch limit {
+	case entities.ThxLimitCooldown:
+		return fmt.Sprintf("Temu użytkownikowi można ponownie podziękować dopiero %d min po poprzednim podziękowaniu", serverConfig.ThxCooldownMinutes)
+	case entities.ThxLimitDaily:
+		return fmt.Sprintf("Dzienny limit podziękowań (%d) został wykorzystany, spróbuj ponownie jutro", serverConfig.ThxDailyLimit)
+	case entities.ThxLimitPendingThxme:
+		return fmt.Sprintf("Osiągnięto limit nierozpatrzonych próśb o podziękowanie (%d), poczekaj na odpowiedź", serverConfig.ThxmePendingLimit)
+	}
+
+	return "Przekroczono limit podziękowań"
+}
+
+// ThxLimitNames are the display names of the thx limits.
+var ThxLimitNames = map[string]string{
+	entities.ThxLimitCooldown:     "Odstęp między podziękowaniami",
+	entities.ThxLimitDaily:        "Dzienny limit",
+	entities.ThxLimitPendingThxme: "Oczekujące prośby /thxme",
+}
@@ -0,0 +1,72 @@
+package fairdraw
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+)
+
+// NewSeed returns a random secret seed. Its hash is published when a giveaway starts and the seed itself
+// is revealed with the results, so anyone can recompute the winners.
+func NewSeed() (string, error) {
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(seed), nil
+}
+
+// HashSeed returns the hex encoded SHA-256 of the seed.
+func HashSeed(seed string) string {
+	hash := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(hash[:])
+}
+
+// RedrawSeed returns the seed of a redraw of the prize of the given winner, derived from the giveaway seed so the redraw
+// can be verified once the giveaway seed is revealed.
+func RedrawSeed(seed string, winnerId int) string {
+	return seed + ":redraw:" + strconv.Itoa(winnerId)
+}
+
+// Index returns the k-th pick from a pool of n entries: the first 8 bytes of SHA-256(seed + ":" + k)
+// read as a big endian uint64, modulo n.
+func Index(seed string, k, n int) int {
+	hash := sha256.Sum256([]byte(seed + ":" + strconv.Itoa(k)))
+	return int(binary.BigEndian.Uint64(hash[:8]) % uint64(n))
+}
+
+// Pick draws up to count winners from the ordered participants, where a user may appear more than once.
+// A picked user that is not eligible is skipped and removed from the pool together with all their entries,
+// so passing the skipped users of a draw as ineligible reproduces it exactly.
+func Pick(seed string, participants []string, count int, withReplacement bool, eligible func(userId string) bool) (winners []string, skipped []string) {
+	pool := append([]string(nil), participants...)
+	for k := 0; len(winners) < count && len(pool) > 0; k++ {
+		userId := pool[Index(seed, k, len(pool))]
+		if !eligible(userId) {
+			skipped = append(skipped, userId)
+			pool = removeUser(pool, userId)
+			continue
+		}
+
+		winners = append(winners, userId)
+		if !withReplacement {
+			pool = removeUser(pool, userId)
+		}
+	}
+
+	return winners, skipped
+}
+
+func removeUser(pool []string, userId string) []string {
+	result := pool[:0]
+	for _, entry := range pool {
+		if entry != userId {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
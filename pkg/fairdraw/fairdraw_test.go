@@ -0,0 +1,124 @@
+package fairdraw
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	tests := []struct {
+		seed string
+		k, n int
+		want int
+	}{
+		{"seed", 0, 10, 8},
+		{"seed", 1, 10, 3},
+		{"seed", 2, 7, 5},
+		{"abc", 0, 1000, 519},
+		{"abc", 5, 3, 0},
+		{"abc", 7, 1, 0},
+	}
+
+	for _, test := range tests {
+		if got := Index(test.seed, test.k, test.n); got != test.want {
+			t.Errorf("Index(%q, %d, %d) = %d, want %d", test.seed, test.k, test.n, got, test.want)
+		}
+	}
+}
+
+func TestPick(t *testing.T) {
+	tests := []struct {
+		name            string
+		seed            string
+		participants    []string
+		count           int
+		withReplacement bool
+		ineligible      []string
+		wantWinners     string
+		wantSkipped     string
+	}{
+		{"without replacement", "seed", []string{"a", "b", "c", "d", "e"}, 3, false, nil, "d,b,c", ""},
+		{"more winners than participants", "seed", []string{"a", "b", "c", "d", "e"}, 10, false, nil, "d,b,c,e,a", ""},
+		{"with replacement", "seed", []string{"a", "a", "b", "c"}, 4, true, nil, "b,a,c,c", ""},
+		{"no participants", "seed", nil, 1, false, nil, "", ""},
+		{"ineligible user removed with all entries", "seed", []string{"a", "b", "c", "b", "d"}, 2, false, []string{"b"}, "c,d", "b"},
+		{"ineligible users with replacement", "s2", []string{"a", "b", "c", "b", "d"}, 2, true, []string{"b", "c"}, "d,d", "c,b"},
+		{"nobody eligible", "seed", []string{"a", "b", "a", "b"}, 2, false, []string{"a", "b"}, "", "a,b"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ineligible := make(map[string]bool)
+			for _, userId := range test.ineligible {
+				ineligible[userId] = true
+			}
+
+			winners, skipped := Pick(test.seed, test.participants, test.count, test.withReplacement, func(userId string) bool {
+				return !ineligible[userId]
+			})
+			if got := strings.Join(winners, ","); got != test.wantWinners {
+				t.Errorf("winners = %s, want %s", got, test.wantWinners)
+			}
+			if got := strings.Join(skipped, ","); got != test.wantSkipped {
+				t.Errorf("skipped = %s, want %s", got, test.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestPickDoesNotModifyParticipants(t *testing.T) {
+	participants := []string{"a", "b", "c", "b", "d"}
+	Pick("seed", participants, 2, false, func(userId string) bool { return userId != "b" })
+	if got := strings.Join(participants, ","); got != "a,b,c,b,d" {
+		t.Errorf("participants = %s after the draw", got)
+	}
+}
+
+// Replaying a draw with its skipped users as the only ineligible ones gives the same result, whatever the original
+// eligibility checks were.
+func TestPickReplay(t *testing.T) {
+	participants := []string{"a", "b", "c", "a", "d", "e", "b", "f", "g", "a"}
+	checks := map[string]func(userId string) bool{
+		"everyone":        func(string) bool { return true },
+		"without a":       func(userId string) bool { return userId != "a" },
+		"only a and f":    func(userId string) bool { return userId == "a" || userId == "f" },
+		"before d":        func(userId string) bool { return userId < "d" },
+		"nobody eligible": func(string) bool { return false },
+	}
+
+	for i := 0; i < 20; i++ {
+		seed, err := NewSeed()
+		if err != nil {
+			t.Fatalf("NewSeed: %v", err)
+		}
+
+		for name, eligible := range checks {
+			for _, withReplacement := range []bool{false, true} {
+				winners, skipped := Pick(seed, participants, 3, withReplacement, eligible)
+
+				replaySkipped := make(map[string]bool)
+				for _, userId := range skipped {
+					replaySkipped[userId] = true
+				}
+				replayWinners, replayedSkipped := Pick(seed, participants, 3, withReplacement, func(userId string) bool {
+					return !replaySkipped[userId]
+				})
+
+				if strings.Join(replayWinners, ",") != strings.Join(winners, ",") {
+					t.Errorf("%s, replacement %t, seed %s: replayed winners %v, want %v", name, withReplacement, seed, replayWinners, winners)
+				}
+				if strings.Join(replayedSkipped, ",") != strings.Join(skipped, ",") {
+					t.Errorf("%s, replacement %t, seed %s: replayed skipped %v, want %v", name, withReplacement, seed, replayedSkipped, skipped)
+				}
+			}
+		}
+	}
+}
+
+func TestHashSeed(t *testing.T) {
+	// SHA-256 of "abc"
+	want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if got := HashSeed("abc"); got != want {
+		t.Errorf("HashSeed(abc) = %s, want %s", got, want)
+	}
+}
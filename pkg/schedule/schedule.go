@@ -0,0 +1,80 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Daily is a set of times of day, in minutes after midnight, at which a giveaway is finished.
+type Daily []int
+
+// Parse reads a comma separated list of HH:MM times. An empty list means the giveaway is never finished automatically.
+func Parse(value string) (Daily, error) {
+	var daily Daily
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		clock, err := time.Parse("15:04", part)
+		if err != nil {
+			// Allow a missing leading zero and whole hours, e.g. 6:00 or 18
+			clock, err = time.Parse("15", part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid time %q", part)
+			}
+		}
+
+		minutes := clock.Hour()*60 + clock.Minute()
+		if !seen[minutes] {
+			seen[minutes] = true
+			daily = append(daily, minutes)
+		}
+	}
+	sort.Ints(daily)
+
+	return daily, nil
+}
+
+// Next returns the first time after t at which the schedule fires in the given location.
+func (d Daily) Next(t time.Time, location *time.Location) (time.Time, bool) {
+	if len(d) == 0 {
+		return time.Time{}, false
+	}
+
+	t = t.In(location)
+	for day := 0; day <= 1; day++ {
+		for _, minutes := range d {
+			next := time.Date(t.Year(), t.Month(), t.Day()+day, minutes/60, minutes%60, 0, 0, location)
+			if next.After(t) {
+				return next, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Times returns the schedule as H:MM strings.
+func (d Daily) Times() []string {
+	times := make([]string, len(d))
+	for i, minutes := range d {
+		times[i] = fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+	}
+
+	return times
+}
+
+// String returns the schedule in the format accepted by Parse.
+func (d Daily) String() string {
+	times := make([]string, len(d))
+	for i, minutes := range d {
+		times[i] = fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+	}
+
+	return strings.Join(times, ",")
+}
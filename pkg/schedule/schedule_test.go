@@ -0,0 +1,114 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: ""},
+		{value: " , ", want: ""},
+		{value: "6", want: "06:00"},
+		{value: "6:00", want: "06:00"},
+		{value: "06:00", want: "06:00"},
+		{value: "18:30, 6", want: "06:00,18:30"},
+		{value: "6,06:00,6:00", want: "06:00"},
+		{value: "0:00,23:59", want: "00:00,23:59"},
+		{value: "24:00", wantErr: true},
+		{value: "6:60", wantErr: true},
+		{value: "6am", wantErr: true},
+		{value: "6;18", wantErr: true},
+	}
+
+	for _, test := range tests {
+		daily, err := Parse(test.value)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) = %s, want an error", test.value, daily)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) returned %v", test.value, err)
+			continue
+		}
+		if got := daily.String(); got != test.want {
+			t.Errorf("Parse(%q) = %s, want %s", test.value, got, test.want)
+		}
+	}
+}
+
+func TestDaily_Next(t *testing.T) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		schedule string
+		now      time.Time
+		want     time.Time
+	}{
+		{"later the same day", "6:00,18:00", time.Date(2024, 5, 10, 5, 0, 0, 0, warsaw), time.Date(2024, 5, 10, 6, 0, 0, 0, warsaw)},
+		{"exactly at a run", "6:00,18:00", time.Date(2024, 5, 10, 6, 0, 0, 0, warsaw), time.Date(2024, 5, 10, 18, 0, 0, 0, warsaw)},
+		{"rollover to the next day", "6:00,18:00", time.Date(2024, 5, 10, 19, 0, 0, 0, warsaw), time.Date(2024, 5, 11, 6, 0, 0, 0, warsaw)},
+		{"rollover to the next month", "6", time.Date(2024, 5, 31, 23, 59, 0, 0, warsaw), time.Date(2024, 6, 1, 6, 0, 0, 0, warsaw)},
+		{"now in another location", "6", time.Date(2024, 5, 10, 3, 30, 0, 0, time.UTC), time.Date(2024, 5, 10, 6, 0, 0, 0, warsaw)},
+		{"rollover to the day after spring forward", "6", time.Date(2024, 3, 30, 19, 0, 0, 0, warsaw), time.Date(2024, 3, 31, 4, 0, 0, 0, time.UTC)},
+		{"rollover to the day after fall back", "6", time.Date(2024, 10, 26, 19, 0, 0, 0, warsaw), time.Date(2024, 10, 27, 5, 0, 0, 0, time.UTC)},
+		// 2:30 does not exist on the spring forward day, the run happens an hour later
+		{"skipped hour on spring forward", "2:30", time.Date(2024, 3, 31, 1, 0, 0, 0, warsaw), time.Date(2024, 3, 31, 1, 30, 0, 0, time.UTC)},
+		{"after the skipped hour", "2:30", time.Date(2024, 3, 31, 1, 30, 0, 0, time.UTC), time.Date(2024, 4, 1, 2, 30, 0, 0, warsaw)},
+		// 2:30 happens twice on the fall back day, the giveaway runs only at the second one
+		{"repeated hour on fall back", "2:30", time.Date(2024, 10, 27, 0, 0, 0, 0, warsaw), time.Date(2024, 10, 27, 1, 30, 0, 0, time.UTC)},
+		{"first of the repeated hours", "2:30", time.Date(2024, 10, 27, 0, 30, 0, 0, time.UTC), time.Date(2024, 10, 27, 1, 30, 0, 0, time.UTC)},
+		{"after the repeated hour", "2:30", time.Date(2024, 10, 27, 1, 30, 0, 0, time.UTC), time.Date(2024, 10, 28, 2, 30, 0, 0, warsaw)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			daily, err := Parse(test.schedule)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", test.schedule, err)
+			}
+
+			next, ok := daily.Next(test.now, warsaw)
+			if !ok {
+				t.Fatalf("Next(%s) found no run", test.now)
+			}
+			if !next.Equal(test.want) {
+				t.Errorf("Next(%s) = %s, want %s", test.now, next, test.want.In(warsaw))
+			}
+			if next.Location() != warsaw {
+				t.Errorf("Next(%s) is in %s, want %s", test.now, next.Location(), warsaw)
+			}
+		})
+	}
+}
+
+func TestDaily_NextEmpty(t *testing.T) {
+	daily, err := Parse("")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if next, ok := daily.Next(time.Now(), time.UTC); ok {
+		t.Errorf("empty schedule runs at %s", next)
+	}
+}
+
+func TestDaily_Times(t *testing.T) {
+	daily, err := Parse("18:05,6")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := strings.Join(daily.Times(), ","); got != "6:00,18:05" {
+		t.Errorf("Times() = %s, want 6:00,18:05", got)
+	}
+}